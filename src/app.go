@@ -4,19 +4,48 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"code-search/src/lib"
 )
 
+// Version is the version of code-search
+const Version = "1.0.0"
+
 // CLI represents the main CLI application
 type CLI struct {
-	searchCommand *SearchCommand
-	indexCommand  *IndexCommand
+	searchCommand  *SearchCommand
+	indexCommand   *IndexCommand
+	symbolsCommand *SymbolsCommand
+	refsCommand    *ReferencesCommand
+	callersCommand *ReferencesCommand
+	depsCommand    *DepsCommand
+	tuiCommand     *TUICommand
+	serveCommand   *ServeCommand
+	mcpCommand     *MCPCommand
+	lspCommand     *LSPCommand
+	configCommand  *ConfigCommand
+	statusCommand  *StatusCommand
+	listCommand    *ListCommand
+	removeCommand  *RemoveCommand
 }
 
 // NewCLI creates a new CLI application
 func NewCLI() *CLI {
 	return &CLI{
-		searchCommand: NewSearchCommand(),
-		indexCommand:  NewIndexCommand(),
+		searchCommand:  NewSearchCommand(),
+		indexCommand:   NewIndexCommand(),
+		symbolsCommand: NewSymbolsCommand(),
+		refsCommand:    NewReferencesCommand(false),
+		callersCommand: NewReferencesCommand(true),
+		depsCommand:    NewDepsCommand(),
+		tuiCommand:     NewTUICommand(),
+		serveCommand:   NewServeCommand(),
+		mcpCommand:     NewMCPCommand(),
+		lspCommand:     NewLSPCommand(),
+		configCommand:  NewConfigCommand(),
+		statusCommand:  NewStatusCommand(),
+		listCommand:    NewListCommand(),
+		removeCommand:  NewRemoveCommand(),
 	}
 }
 
@@ -37,6 +66,42 @@ func (cli *CLI) Run(args []string) error {
 	case "index":
 		return cli.indexCommand.Execute(commandArgs)
 
+	case "symbols":
+		return cli.symbolsCommand.Execute(commandArgs)
+
+	case "refs":
+		return cli.refsCommand.Execute(commandArgs)
+
+	case "callers":
+		return cli.callersCommand.Execute(commandArgs)
+
+	case "deps":
+		return cli.depsCommand.Execute(commandArgs)
+
+	case "tui":
+		return cli.tuiCommand.Execute(commandArgs)
+
+	case "serve":
+		return cli.serveCommand.Execute(commandArgs)
+
+	case "mcp":
+		return cli.mcpCommand.Execute(commandArgs)
+
+	case "lsp":
+		return cli.lspCommand.Execute(commandArgs)
+
+	case "config":
+		return cli.configCommand.Execute(commandArgs)
+
+	case "status":
+		return cli.statusCommand.Execute(commandArgs)
+
+	case "list":
+		return cli.listCommand.Execute(commandArgs)
+
+	case "rm":
+		return cli.removeCommand.Execute(commandArgs)
+
 	case "help", "--help", "-h":
 		cli.printMainHelp()
 		return nil
@@ -60,6 +125,18 @@ USAGE:
 COMMANDS:
     search      Search the indexed codebase
     index       Index the current directory for searching
+    symbols     Find function, type and variable definitions
+    refs        Find definitions and references of a Go identifier
+    callers     Find call sites of a Go function or method
+    deps        Show the files a file imports, or the files importing it
+    tui         Search interactively with live results and a file preview
+    serve       Serve searches of the index over local HTTP
+    mcp         Run a Model Context Protocol server for AI assistants
+    lsp         Run a language server for editors
+    config      Show the effective configuration and where it comes from
+    status      Show index health and whether it needs reindexing
+    list        List every indexed directory with its size, age and state
+    rm          Delete the index of a directory, or every stale index
     help        Show this help message
     version     Show version information
 
@@ -74,6 +151,33 @@ EXAMPLES:
     # Search with specific options
     code-search search "database query" --max-results 5 --with-context
     code-search search "function.*error" --semantic --format json
+    code-search search "retry policy" --dir ~/src/api --dir ~/src/web
+
+    # Jump to definitions
+    code-search symbols "Handle*" --kind method
+    code-search callers Server.Start
+
+    # Who imports this file?
+    code-search deps src/models/user.go --reverse
+
+    # Search interactively, opening results in $EDITOR
+    code-search tui
+
+    # Keep the index loaded for other tools
+    code-search serve --addr 127.0.0.1:7420
+
+    # Let an AI assistant search the index
+    code-search mcp --dir ~/src/api
+
+    # See which settings .code-search.yaml and the environment change
+    code-search config show
+
+    # Fail a CI job when the index is out of date
+    code-search status --format json
+
+    # Clean up indexes of projects that moved on
+    code-search list
+    code-search rm --all-stale
 
 OPTIONS:
     Use 'code-search <command> --help' for command-specific options
@@ -95,6 +199,7 @@ EXIT CODES:
     1    Error
     2    Invalid arguments
     3    Index not found (for search command)
+    4    Index needs reindexing (for status command)
 
 For more information, visit: https://github.com/your-repo/code-search
 `)
@@ -102,7 +207,7 @@ For more information, visit: https://github.com/your-repo/code-search
 
 // printVersion prints version information
 func (cli *CLI) printVersion() {
-	fmt.Printf("code-search version 1.0.0\n")
+	fmt.Printf("code-search version %s\n", Version)
 	fmt.Printf("Built with Go 1.21+\n")
 }
 
@@ -114,3 +219,18 @@ func GetDefaultIndexPath() string {
 	}
 	return filepath.Join(wd, ".code-search-index")
 }
+
+// ResolveIndexPath returns the index file for the current directory, or for the given
+// directory's .clindex when one is specified
+func ResolveIndexPath(directory string) (string, error) {
+	if directory == "" {
+		return ".code-search-index", nil
+	}
+
+	fileUtils := lib.NewFileUtilities()
+	absDir, err := fileUtils.ResolvePath(directory)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve directory path: %w", err)
+	}
+	return fileUtils.CreateIndexLocation(absDir).DataFile, nil
+}
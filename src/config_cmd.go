@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"code-search/src/services"
+)
+
+// ConfigCommand implements the config command
+type ConfigCommand struct{}
+
+// NewConfigCommand creates a new config command
+func NewConfigCommand() *ConfigCommand {
+	return &ConfigCommand{}
+}
+
+// ConfigOptions contains config command options
+type ConfigOptions struct {
+	directory string
+	format    string
+}
+
+// Execute executes the config command with the given arguments
+func (cmd *ConfigCommand) Execute(args []string) error {
+	if len(args) < 1 || args[0] == "--help" || args[0] == "-h" {
+		cmd.printConfigHelp()
+		return nil
+	}
+	if args[0] != "show" {
+		return NewInvalidArgumentError(fmt.Sprintf("unknown config command: %s (supported: show)", args[0]), nil)
+	}
+
+	options, err := cmd.parseConfigOptions(args[1:])
+	if err != nil {
+		return NewInvalidArgumentError("invalid config options", err)
+	}
+
+	config, err := loadConfig(options.directory)
+	if err != nil {
+		return err
+	}
+
+	if options.format == "json" {
+		return cmd.displayJSONConfig(config)
+	}
+	cmd.displayTableConfig(config)
+	return nil
+}
+
+// parseConfigOptions parses command line options for config show
+func (cmd *ConfigCommand) parseConfigOptions(args []string) (ConfigOptions, error) {
+	options := ConfigOptions{
+		format: "table",
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch arg {
+		case "--dir", "-d":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--dir requires a directory path", nil)
+			}
+			options.directory = args[i+1]
+			i++
+
+		case "--format":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--format requires a value", nil)
+			}
+			format := strings.ToLower(args[i+1])
+			if format != "table" && format != "json" {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid format: %s (supported: table, json)", format), nil)
+			}
+			options.format = format
+			i++
+
+		case "--help", "-h":
+			cmd.printConfigHelp()
+			os.Exit(0)
+
+		default:
+			return options, NewInvalidArgumentError(fmt.Sprintf("unknown option: %s", arg), nil)
+		}
+	}
+
+	return options, nil
+}
+
+// displayTableConfig prints the configuration files and each setting with its source
+func (cmd *ConfigCommand) displayTableConfig(config *services.Config) {
+	if len(config.Files()) == 0 {
+		fmt.Println("No configuration files found")
+	} else {
+		fmt.Println("Configuration files (later files win):")
+		for _, file := range config.Files() {
+			fmt.Printf("  %s\n", file)
+		}
+	}
+	fmt.Println()
+
+	for _, setting := range config.Settings() {
+		source := string(setting.Source)
+		if setting.Origin != "" {
+			source = fmt.Sprintf("%s (%s)", setting.Source, setting.Origin)
+		}
+		fmt.Printf("%-24s %-32s %s\n", setting.Key, formatConfigValue(setting.Value), source)
+	}
+}
+
+// displayJSONConfig prints the configuration as JSON
+func (cmd *ConfigCommand) displayJSONConfig(config *services.Config) error {
+	files := config.Files()
+	if files == nil {
+		files = []string{}
+	}
+	output := map[string]interface{}{
+		"files":    files,
+		"settings": config.Settings(),
+	}
+
+	jsonData, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return NewGeneralError("failed to generate JSON output", err)
+	}
+	fmt.Println(string(jsonData))
+	return nil
+}
+
+// formatConfigValue formats a setting value for the table
+func formatConfigValue(value interface{}) string {
+	if list, ok := value.([]string); ok {
+		return "[" + strings.Join(list, ", ") + "]"
+	}
+	return fmt.Sprint(value)
+}
+
+// loadConfig loads the configuration that applies to a target directory, or to the
+// current directory when none is given
+func loadConfig(directory string) (*services.Config, error) {
+	if directory == "" {
+		directory = "."
+	}
+	config, err := services.LoadConfig(directory)
+	if err != nil {
+		return nil, NewInvalidArgumentError("invalid configuration", err)
+	}
+	return config, nil
+}
+
+// argValue returns the value following the first of the given flags, or "" when none
+// is present
+func argValue(args []string, flags ...string) string {
+	for i := 0; i+1 < len(args); i++ {
+		for _, flag := range flags {
+			if args[i] == flag {
+				return args[i+1]
+			}
+		}
+	}
+	return ""
+}
+
+// printConfigHelp prints help for the config command
+func (cmd *ConfigCommand) printConfigHelp() {
+	fmt.Printf(`Usage: code-search config show [options]
+
+Shows the effective configuration and where each value comes from. Settings are read
+from, in increasing priority:
+
+  1. Built-in defaults
+  2. The user file, $XDG_CONFIG_HOME/code-search/config.yaml
+     (~/.config/code-search/config.yaml when XDG_CONFIG_HOME is not set)
+  3. The nearest .code-search.yaml (or .code-search.yml) in the target directory or
+     one of its parents
+  4. CODE_SEARCH_* environment variables; lists are comma-separated
+  5. Command line flags
+
+Options:
+  -d, --dir <directory>    Show the configuration for this directory
+      --format <format>    Output format: table, json (default: table)
+  -h, --help               Show this help message
+
+Example .code-search.yaml:
+  index:
+    include: ["src/**", "cmd/**"]
+    exclude:
+      - "vendor/"
+      - "**/*_generated.go"
+    file_types: [.go, .proto]
+    max_file_size: 2MB
+    chunker: ast             # simple or ast
+  embedding:
+    model: all-MiniLM-L6-v2
+  search:
+    format: json
+    context_lines: 5
+    semantic_weight: 0.6
+    text_weight: 0.4
+`)
+}
+
+// GetHelp returns help text for the command
+func (cmd *ConfigCommand) GetHelp() string {
+	return `config show [options] - Show the effective configuration
+
+Use 'code-search config --help' for detailed usage information.`
+}
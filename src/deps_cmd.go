@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code-search/src/lib"
+	"code-search/src/services"
+)
+
+// DepsCommand implements the deps command
+type DepsCommand struct {
+	dependencyService *services.DependencyService
+	fileUtils         *lib.FileUtilities
+}
+
+// NewDepsCommand creates a new deps command
+func NewDepsCommand() *DepsCommand {
+	return &DepsCommand{
+		dependencyService: services.NewDependencyService(&services.SilentLogger{}),
+		fileUtils:         lib.NewFileUtilities(),
+	}
+}
+
+// DepsOptions contains deps command options
+type DepsOptions struct {
+	reverse   bool
+	depth     int
+	format    string
+	directory string
+}
+
+// Execute executes the deps command with the given arguments
+func (cmd *DepsCommand) Execute(args []string) error {
+	if len(args) < 1 {
+		return NewInvalidArgumentError("file path is required", nil)
+	}
+	if args[0] == "--help" || args[0] == "-h" {
+		cmd.printDepsHelp()
+		return nil
+	}
+
+	options, err := cmd.parseDepsOptions(args[1:])
+	if err != nil {
+		return NewInvalidArgumentError("invalid deps options", err)
+	}
+
+	repositoryPath := options.directory
+	if repositoryPath == "" {
+		repositoryPath = "."
+	}
+	repositoryPath, err = cmd.fileUtils.ResolvePath(repositoryPath)
+	if err != nil {
+		return NewInvalidArgumentError("failed to resolve directory", err)
+	}
+
+	file, err := cmd.repositoryRelativePath(repositoryPath, args[0])
+	if err != nil {
+		return NewInvalidArgumentError("invalid file path", err)
+	}
+
+	result, err := cmd.dependencyService.GetDependencies(repositoryPath, services.DependencyQuery{
+		File:    file,
+		Reverse: options.reverse,
+		Depth:   options.depth,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") || strings.Contains(err.Error(), "not in dependency graph") {
+			return NewNotFoundError("dependency lookup failed (run 'code-search index' to build the dependency graph)", err)
+		}
+		return NewGeneralError("dependency lookup failed", err)
+	}
+
+	switch options.format {
+	case "json":
+		return cmd.displayJSONDependencies(result)
+	case "dot":
+		return cmd.displayDotDependencies(result)
+	default:
+		return cmd.displayTableDependencies(result)
+	}
+}
+
+// parseDepsOptions parses command line options for deps
+func (cmd *DepsCommand) parseDepsOptions(args []string) (DepsOptions, error) {
+	options := DepsOptions{
+		depth:  1,
+		format: "table",
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch arg {
+		case "--reverse", "-r":
+			options.reverse = true
+
+		case "--depth":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--depth requires a value", nil)
+			}
+			var depth int
+			if _, err := fmt.Sscanf(args[i+1], "%d", &depth); err != nil || depth < 0 {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid depth value: %s (must be 0 or greater)", args[i+1]), nil)
+			}
+			options.depth = depth
+			i++
+
+		case "--format":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--format requires a value", nil)
+			}
+			format := strings.ToLower(args[i+1])
+			if format != "table" && format != "json" && format != "dot" {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid format: %s (supported: table, json, dot)", format), nil)
+			}
+			options.format = format
+			i++
+
+		case "--dir", "-d":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--dir requires a directory path", nil)
+			}
+			options.directory = args[i+1]
+			i++
+
+		case "--help", "-h":
+			cmd.printDepsHelp()
+			os.Exit(0)
+
+		default:
+			return options, NewInvalidArgumentError(fmt.Sprintf("unknown option: %s", arg), nil)
+		}
+	}
+
+	return options, nil
+}
+
+// repositoryRelativePath resolves a file argument, given relative to the current directory
+// or to the repository, to a path relative to the repository root
+func (cmd *DepsCommand) repositoryRelativePath(repositoryPath, file string) (string, error) {
+	absFile := file
+	if !filepath.IsAbs(file) {
+		if _, err := os.Stat(file); err == nil {
+			absFile, _ = filepath.Abs(file)
+		} else {
+			absFile = filepath.Join(repositoryPath, file)
+		}
+	}
+
+	rel, err := cmd.fileUtils.GetSafeRelativePath(repositoryPath, absFile)
+	if err != nil {
+		return "", fmt.Errorf("%s is not inside %s: %w", file, repositoryPath, err)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// displayTableDependencies displays reached files as an indented list
+func (cmd *DepsCommand) displayTableDependencies(result *services.DependencyResult) error {
+	if result.Reverse {
+		fmt.Printf("Files importing %s:\n\n", result.File)
+	} else {
+		fmt.Printf("Files imported by %s:\n\n", result.File)
+	}
+
+	if len(result.Files) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, file := range result.Files {
+		fmt.Printf("%s%s\n", strings.Repeat("  ", file.Depth), file.Path)
+	}
+
+	if !result.Reverse && len(result.Imports) > 0 {
+		fmt.Printf("\nImports: %s\n", strings.Join(result.Imports, ", "))
+	}
+
+	return nil
+}
+
+// displayJSONDependencies displays the dependency result in JSON format
+func (cmd *DepsCommand) displayJSONDependencies(result *services.DependencyResult) error {
+	if result.Edges == nil {
+		result.Edges = []lib.DependencyEdge{}
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to generate JSON output: %w", err)
+	}
+
+	fmt.Println(string(jsonData))
+	return nil
+}
+
+// displayDotDependencies displays the traversed edges as a Graphviz digraph
+func (cmd *DepsCommand) displayDotDependencies(result *services.DependencyResult) error {
+	fmt.Println("digraph dependencies {")
+	fmt.Println("  rankdir=LR;")
+	fmt.Printf("  %q [style=bold];\n", result.File)
+	for _, edge := range result.Edges {
+		fmt.Printf("  %q -> %q;\n", edge.From, edge.To)
+	}
+	fmt.Println("}")
+	return nil
+}
+
+// printDepsHelp prints help for the deps command
+func (cmd *DepsCommand) printDepsHelp() {
+	fmt.Printf(`Usage: code-search deps <file> [options]
+
+Arguments:
+  <file>                   File to start from, relative to the current directory or the indexed directory
+
+Options:
+  -r, --reverse            Show the files that import <file> instead of the files it imports
+      --depth <n>          Follow imports this many levels deep, 0 for unlimited (default: 1)
+      --format <fmt>       Output format: table, json, dot (default: table)
+  -d, --dir <directory>    Specify indexed directory (default: current directory)
+  -h, --help               Show this help message
+
+Examples:
+  code-search deps src/app.go
+  code-search deps src/models/query.go --reverse --depth 0
+  code-search deps src/app.go --depth 2 --format dot | dot -Tsvg > deps.svg
+
+Imports are extracted for Go, Python, JavaScript, TypeScript, Java and Rust,
+and includes for C and C++. Only imports that resolve to files in the indexed
+directory appear in the graph, which is stored in .clindex/dependencies.json.
+`)
+}
+
+// GetHelp returns help text for the deps command
+func (cmd *DepsCommand) GetHelp() string {
+	return `deps <file> [options] - Show the files a file imports, or the files importing it
+
+Use 'code-search deps --help' for detailed usage information.`
+}
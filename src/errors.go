@@ -1,6 +1,9 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // ExitCode represents the exit code for different error types
 type ExitCode int
@@ -12,11 +15,21 @@ const (
 	ExitCodeNotFound ExitCode = 3
 )
 
+// Exit codes of searches run with --grep-exit-codes, following grep
+const (
+	ExitCodeNoMatches ExitCode = 1 // The search succeeded but found nothing
+	ExitCodeGrepError ExitCode = 2 // Any error
+)
+
+// ExitCodeStale is the exit code of status when the index needs reindexing
+const ExitCodeStale ExitCode = 4
+
 // CLIError represents a CLI error with a specific exit code
 type CLIError struct {
 	Code    ExitCode
 	Message string
 	Err     error
+	Quiet   bool // Exit with Code without printing the error
 }
 
 func (e *CLIError) Error() string {
@@ -39,6 +52,15 @@ func NewInvalidArgumentError(message string, err error) *CLIError {
 	}
 }
 
+// NewQueryParseError creates an invalid argument error that points at the offending column of a query (exit code 2)
+func NewQueryParseError(query string, column int, message string) *CLIError {
+	return &CLIError{
+		Code:    ExitCodeInvalid,
+		Message: fmt.Sprintf("invalid query at column %d: %s\n  %s\n  %s^", column, message, query, strings.Repeat(" ", column-1)),
+		Err:     nil,
+	}
+}
+
 // NewNotFoundError creates a new not found error (exit code 3)
 func NewNotFoundError(message string, err error) *CLIError {
 	return &CLIError{
@@ -48,6 +70,44 @@ func NewNotFoundError(message string, err error) *CLIError {
 	}
 }
 
+// NewNoMatchesError creates the quiet error returned when a search run with
+// --grep-exit-codes finds nothing (exit code 1)
+func NewNoMatchesError() *CLIError {
+	return &CLIError{
+		Code:    ExitCodeNoMatches,
+		Message: "no matches found",
+		Quiet:   true,
+	}
+}
+
+// NewGrepError converts an error to the grep convention of exit code 2, keeping its message
+func NewGrepError(err error) *CLIError {
+	if cliErr, ok := err.(*CLIError); ok {
+		if cliErr.Quiet {
+			return cliErr
+		}
+		return &CLIError{
+			Code:    ExitCodeGrepError,
+			Message: cliErr.Message,
+			Err:     cliErr.Err,
+		}
+	}
+	return &CLIError{
+		Code:    ExitCodeGrepError,
+		Message: err.Error(),
+	}
+}
+
+// NewIndexStaleError creates the quiet error returned by status when the index is
+// out of date (exit code 4)
+func NewIndexStaleError() *CLIError {
+	return &CLIError{
+		Code:    ExitCodeStale,
+		Message: "index needs reindexing",
+		Quiet:   true,
+	}
+}
+
 // NewGeneralError creates a new general error (exit code 1)
 func NewGeneralError(message string, err error) *CLIError {
 	return &CLIError{
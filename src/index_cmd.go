@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"code-search/src/lib"
+	"code-search/src/models"
 	"code-search/src/services"
 )
 
@@ -20,26 +21,62 @@ type IndexCommand struct {
 
 // NewIndexCommand creates a new index command
 func NewIndexCommand() *IndexCommand {
-	// Create dependencies
-	fileScanner := lib.NewFileSystemScanner()
-	codeParser := lib.NewSimpleCodeParser()
-	vectorStore := lib.NewInMemoryVectorStore(".code-search-index.db")
 	logger := &services.DefaultLogger{}
-	options := services.DefaultIndexingOptions()
 
 	return &IndexCommand{
-		indexingService: services.NewIndexingService(
-			fileScanner,
-			codeParser,
-			vectorStore,
-			logger,
-			options,
-		),
-		logger:    logger,
-		validator: lib.NewDirectoryValidator(),
+		indexingService: newIndexingService(services.DefaultConfig(), services.DefaultIndexingOptions(), logger),
+		logger:          logger,
+		validator:       lib.NewDirectoryValidator(),
 	}
 }
 
+// newIndexingService creates an indexing service using the configured chunker
+func newIndexingService(config *services.Config, options models.IndexingOptions, logger services.Logger) *services.IndexingService {
+	return services.NewIndexingService(
+		lib.NewFileSystemScanner(),
+		config.CodeParser(),
+		lib.NewInMemoryVectorStore(".code-search-index.db"),
+		logger,
+		options,
+	)
+}
+
+// indexingOptions returns the configured indexing options with the filter flags applied.
+// Each filter given here or in the configuration overrides the one stored with the index.
+func (cmd *IndexCommand) indexingOptions(options IndexOptions, config *services.Config) models.IndexingOptions {
+	indexing := config.IndexingOptions()
+	override := func(filter string) {
+		for _, name := range indexing.FilterOverrides {
+			if name == filter {
+				return
+			}
+		}
+		indexing.FilterOverrides = append(indexing.FilterOverrides, filter)
+	}
+
+	if options.includeHidden {
+		indexing.IncludeHidden = true
+		override("include_hidden")
+	}
+	if options.noIgnore {
+		indexing.NoIgnore = true
+		override("no_ignore")
+	}
+	if options.fileTypes != nil {
+		indexing.FileTypes = options.fileTypes
+		override("file_types")
+	}
+	if options.excludePatterns != nil {
+		indexing.ExcludePatterns = options.excludePatterns
+		override("exclude_patterns")
+	}
+	if options.maxFileSize > 0 {
+		indexing.MaxFileSize = options.maxFileSize
+		override("max_file_size")
+	}
+	return indexing
+}
+
 // Execute executes the index command with the given arguments
 func (cmd *IndexCommand) Execute(args []string) error {
 	// Parse arguments
@@ -64,6 +101,13 @@ func (cmd *IndexCommand) Execute(args []string) error {
 		return NewInvalidArgumentError("directory validation failed", err)
 	}
 
+	// Settings from configuration files and the environment apply to this directory
+	config, err := loadConfig(dirConfig.Path)
+	if err != nil {
+		return err
+	}
+	cmd.indexingService = newIndexingService(config, cmd.indexingOptions(options, config), cmd.logger)
+
 	// Show progress
 	progressCallback := func(current, total int, filePath string) {
 		if current%10 == 0 || current == total {
@@ -83,6 +127,7 @@ func (cmd *IndexCommand) Execute(args []string) error {
 	// Perform indexing based on whether directory is specified
 	start := time.Now()
 	var result *services.IndexingResult
+	var indexPath string
 
 	if options.directory != "" {
 		// Index specified directory using validated config
@@ -94,7 +139,7 @@ func (cmd *IndexCommand) Execute(args []string) error {
 		)
 	} else {
 		// Index current directory (backward compatibility)
-		indexPath := cmd.getIndexPath(options.force)
+		indexPath = cmd.getIndexPath(options.force)
 		fmt.Printf("Indexing repository: %s\n", dirConfig.Path)
 
 		result, err = cmd.indexingService.IndexRepository(
@@ -110,6 +155,17 @@ func (cmd *IndexCommand) Execute(args []string) error {
 		return NewGeneralError("indexing failed", err)
 	}
 
+	// Record the index so list and rm can find it from anywhere
+	if options.directory != "" {
+		indexPath = cmd.validator.GetFileUtilities().CreateIndexLocation(dirConfig.Path).DataFile
+	} else {
+		indexPath = filepath.Join(dirConfig.Path, indexPath)
+	}
+	registry := lib.NewIndexRegistry(lib.DefaultRegistryPath())
+	if err := registry.Register(dirConfig.Path, indexPath); err != nil {
+		cmd.logger.Warn("Failed to record the index in %s: %v", registry.Path(), err)
+	}
+
 	// Show final progress line
 	fmt.Printf("\rIndexing progress: %d/%d files (100.0%%) - Complete!\n",
 		result.FilesIndexed, result.FilesIndexed+result.FilesSkipped)
@@ -126,6 +182,7 @@ func (cmd *IndexCommand) Execute(args []string) error {
 type IndexOptions struct {
 	force           bool
 	includeHidden   bool
+	noIgnore        bool
 	fileTypes       []string
 	excludePatterns []string
 	maxFileSize     int64
@@ -137,13 +194,10 @@ type IndexOptions struct {
 // parseIndexOptions parses command line options for index
 func (cmd *IndexCommand) parseIndexOptions(args []string) (IndexOptions, error) {
 	options := IndexOptions{
-		force:           false,
-		includeHidden:   false,
-		fileTypes:       []string{"*"},
-		excludePatterns: []string{},
-		maxFileSize:     1024 * 1024, // 1MB
-		verbose:         false,
-		quiet:           false,
+		force:         false,
+		includeHidden: false,
+		verbose:       false,
+		quiet:         false,
 	}
 
 	for i := 0; i < len(args); i++ {
@@ -156,6 +210,9 @@ func (cmd *IndexCommand) parseIndexOptions(args []string) (IndexOptions, error)
 		case "--include-hidden", "-i":
 			options.includeHidden = true
 
+		case "--no-ignore":
+			options.noIgnore = true
+
 		case "--file-types", "-t":
 			if i+1 >= len(args) {
 				return options, NewInvalidArgumentError("--file-types requires a value", nil)
@@ -184,9 +241,9 @@ func (cmd *IndexCommand) parseIndexOptions(args []string) (IndexOptions, error)
 			if i+1 >= len(args) {
 				return options, NewInvalidArgumentError("--max-file-size requires a value", nil)
 			}
-			var size int64
-			if _, err := fmt.Sscanf(args[i+1], "%d", &size); err != nil || size <= 0 {
-				return options, NewInvalidArgumentError(fmt.Sprintf("invalid max-file-size value: %s", args[i+1]), nil)
+			size, err := services.ParseByteSize(args[i+1])
+			if err != nil || size <= 0 {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid max-file-size value: %s (e.g. 1048576, 512KB, 2MB)", args[i+1]), nil)
 			}
 			options.maxFileSize = size
 			i++
@@ -295,9 +352,10 @@ func (cmd *IndexCommand) printIndexHelp() {
 Options:
   -f, --force                 Force re-indexing even if index exists
   -i, --include-hidden        Include hidden files and directories
+      --no-ignore             Index files excluded by .gitignore, .ignore and .codesearchignore
   -t, --file-types <types>     Specify file types to include (comma-separated)
   -e, --exclude <patterns>    Exclude patterns (comma-separated)
-  -s, --max-file-size <size>  Maximum file size in bytes (default: 1MB)
+  -s, --max-file-size <size>  Maximum file size, in bytes or with KB/MB/GB (default: 1MB)
   -d, --dir <directory>       Specify directory to index (default: current directory)
   -v, --verbose               Show detailed progress and statistics
   -q, --quiet                 Suppress progress output
@@ -330,13 +388,28 @@ Exclude Patterns:
   - *.pyc, __pycache__/*
   - Build artifacts and IDE files
 
+Ignore Files:
+  Files matched by .gitignore, .ignore and .codesearchignore are skipped, using
+  .gitignore syntax. Each file applies to its directory and everything below it, deeper
+  files override shallower ones, and within a directory .codesearchignore overrides
+  .ignore, which overrides .gitignore. Inside a git repository, .git/info/exclude and
+  the global excludes file (core.excludesFile) apply too, with the lowest priority.
+  A "!pattern" line re-includes files, but not inside an ignored directory.
+
+Filters:
+  The index remembers its file types, exclude patterns, hidden-file and ignore-file
+  settings and size limit. Later runs reuse them unless a flag or a configuration file sets them again,
+  and warn when they change; files that no longer match are removed from the index.
+
 Examples:
   code-search index
   code-search index --force
   code-search index --include-hidden --verbose
+  code-search index --no-ignore
   code-search index --file-types "*.go,*.js,*.py"
   code-search index --exclude "*.min.js,*.test.go"
   code-search index --max-file-size 2048000
+  code-search index --max-file-size 2MB
   code-search index --dir /path/to/my-project
   code-search index --dir ../sibling-project --force
   code-search index --dir ~/project --verbose
@@ -55,41 +55,69 @@ func NewASTCodeParser(config *ChunkingConfig) *ASTCodeParser {
 
 // ParseFile parses a file using AST-based chunking when possible
 func (p *ASTCodeParser) ParseFile(filePath string) ([]models.CodeChunk, error) {
+	chunks, _, err := p.parse(filePath, false)
+	return chunks, err
+}
+
+// ParseFileWithSymbols parses a file into chunks and builds its symbol table from the
+// same read, and for Go from the same syntax tree
+func (p *ASTCodeParser) ParseFileWithSymbols(filePath string) ([]models.CodeChunk, []models.Symbol, error) {
+	return p.parse(filePath, true)
+}
+
+// parse reads a file and chunks it, extracting its symbols when asked
+func (p *ASTCodeParser) parse(filePath string, withSymbols bool) ([]models.CodeChunk, []models.Symbol, error) {
 	// Read file content
 	content, err := os.ReadFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
 	contentStr := string(content)
 	if contentStr == "" {
-		return []models.CodeChunk{}, nil
+		return []models.CodeChunk{}, nil, nil
 	}
 
 	// Detect language
 	language := p.simpleParser.detectLanguage(filePath)
 
 	// Use AST-based chunking for supported languages
+	var chunks []models.CodeChunk
 	switch language {
 	case "Go":
-		return p.parseGoAST(filePath, contentStr)
+		// The symbols come from the syntax tree the chunks were built from
+		chunks, symbols := p.parseGoAST(filePath, contentStr)
+		if !withSymbols {
+			return chunks, nil, nil
+		}
+		return chunks, labelSymbols(symbols, filePath, language), nil
 	case "Python":
-		return p.parsePythonSmart(contentStr)
+		chunks, err = p.parsePythonSmart(contentStr)
 	case "JavaScript", "TypeScript":
-		return p.parseJavaScriptSmart(contentStr)
+		chunks, err = p.parseJavaScriptSmart(contentStr)
 	default:
 		// Fall back to enhanced chunking for other languages
-		return p.createEnhancedChunks(contentStr, language)
+		chunks, err = p.createEnhancedChunks(contentStr, language)
+	}
+	if err != nil || !withSymbols {
+		return chunks, nil, err
 	}
+	return chunks, ExtractSymbols(filePath, contentStr, language), nil
 }
 
-// parseGoAST uses Go's AST parser for intelligent chunking
-func (p *ASTCodeParser) parseGoAST(filePath, content string) ([]models.CodeChunk, error) {
+// parseGoAST uses Go's AST parser for intelligent chunking, and returns the symbols
+// declared in the file
+func (p *ASTCodeParser) parseGoAST(filePath, content string) ([]models.CodeChunk, []models.Symbol) {
 	fset := token.NewFileSet()
-	node, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
+	node, err := parser.ParseFile(fset, filePath, content, parser.ParseComments)
 	if err != nil {
-		// Fall back to enhanced chunking if AST parsing fails
-		return p.createEnhancedChunks(content, "Go")
+		// Fall back to enhanced chunking if AST parsing fails, keeping the declarations
+		// that did parse
+		chunks, _ := p.createEnhancedChunks(content, "Go")
+		if node == nil {
+			return chunks, nil
+		}
+		return chunks, goSymbols(fset, node, content)
 	}
 
 	var chunks []models.CodeChunk
@@ -113,7 +141,7 @@ func (p *ASTCodeParser) parseGoAST(filePath, content string) ([]models.CodeChunk
 	// Add non-AST chunks for code not covered by declarations
 	chunks = append(chunks, p.createNonStructuralChunks(content, chunks, "Go")...)
 
-	return chunks, nil
+	return chunks, goSymbols(fset, node, content)
 }
 
 // createFunctionChunk creates a chunk from a Go function declaration
@@ -258,6 +258,28 @@ func (bs *BinaryStorage) DeserializeIndex(filePath string, vectorStore models.Ve
 	return index, nil
 }
 
+// DetectIndexFormat reports whether an index file is stored as "json" or "binary"
+func DetectIndexFormat(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	header := make([]byte, 4)
+	n, _ := io.ReadFull(file, header)
+	trimmed := bytes.TrimLeft(header[:n], " \t\r\n")
+	switch {
+	case len(trimmed) > 0 && trimmed[0] == '{':
+		return "json", nil
+	case n == 4 && binary.LittleEndian.Uint32(header) == MagicNumber:
+		return "binary", nil
+	case n >= 2 && header[0] == 0x1f && header[1] == 0x8b:
+		return "binary", nil // Compressed
+	}
+	return "unknown", nil
+}
+
 // serializeHeaders converts headers to binary format
 func (bs *BinaryStorage) serializeHeaders(header FileHeader, indexHeader IndexHeader) []byte {
 	buf := make([]byte, HeaderSize+IndexHeaderSize)
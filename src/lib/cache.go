@@ -45,6 +45,7 @@ type CacheEntry struct {
 	Expiration time.Time            `json:"expiration"`
 	CreatedAt  time.Time            `json:"created_at"`
 	AccessCount int64               `json:"access_count"`
+	Window      int                 `json:"window"` // Ranked results computed, 0 for all matches
 	LastAccess  time.Time           `json:"last_access"`
 }
 
@@ -122,23 +123,24 @@ func NewPatternCache() *PatternCache {
 	}
 }
 
-// Get retrieves cached results for a query
-func (qc *QueryCache) Get(query *models.SearchQuery) (*models.SearchResults, bool) {
+// Get retrieves cached results for a query. The scope identifies what was searched, such
+// as an index and its version, so results are never shared between indexes.
+func (qc *QueryCache) Get(scope string, query *models.SearchQuery) (*models.SearchResults, bool) {
 	qc.statistics.mu.Lock()
 	qc.statistics.TotalQueries++
 	qc.statistics.mu.Unlock()
 
-	queryHash := qc.hashQuery(query)
+	queryHash := qc.hashQuery(scope, query)
 
 	// Try L1 cache (in-memory)
-	if entry, found := qc.getFromL1(queryHash); found {
+	if entry, found := qc.getFromL1(queryHash); found && entry.covers(query) {
 		qc.incrementL1Hits()
 		return entry.Results, true
 	}
 	qc.incrementL1Misses()
 
 	// Try L2 cache (disk-based)
-	if entry, found := qc.getFromL2(queryHash); found {
+	if entry, found := qc.getFromL2(queryHash); found && entry.covers(query) {
 		qc.incrementL2Hits()
 		// Promote to L1
 		qc.putToL1(queryHash, entry)
@@ -156,9 +158,9 @@ func (qc *QueryCache) Get(query *models.SearchQuery) (*models.SearchResults, boo
 	return nil, false
 }
 
-// Put stores search results in the cache
-func (qc *QueryCache) Put(query *models.SearchQuery, results *models.SearchResults) {
-	queryHash := qc.hashQuery(query)
+// Put stores search results in the cache under the scope they were found in
+func (qc *QueryCache) Put(scope string, query *models.SearchQuery, results *models.SearchResults) {
+	queryHash := qc.hashQuery(scope, query)
 
 	entry := &CacheEntry{
 		Results:     results,
@@ -167,6 +169,7 @@ func (qc *QueryCache) Put(query *models.SearchQuery, results *models.SearchResul
 		CreatedAt:   time.Now(),
 		AccessCount: 1,
 		LastAccess:  time.Now(),
+		Window:      query.CandidateWindow(),
 	}
 
 	// Store in L1 and L2
@@ -174,27 +177,15 @@ func (qc *QueryCache) Put(query *models.SearchQuery, results *models.SearchResul
 	qc.putToL2(queryHash, entry)
 }
 
-// hashQuery creates a deterministic hash for a query
-func (qc *QueryCache) hashQuery(query *models.SearchQuery) string {
-	h := md5.New()
-
-	// Include all relevant query fields
-	h.Write([]byte(query.QueryText))
-	h.Write([]byte(query.SearchType))
-	h.Write([]byte(fmt.Sprintf("%f", query.Threshold)))
-	h.Write([]byte(fmt.Sprintf("%d", query.MaxResults)))
-
-	// Include file filter
-	if query.FileFilter != "" {
-		h.Write([]byte(query.FileFilter))
-	}
-
-	// Include language filter
-	if query.LanguageFilter != "" {
-		h.Write([]byte(query.LanguageFilter))
-	}
+// hashQuery creates a deterministic hash for a query in a scope. Entries hold the full
+// ranked result list, so every page of a query shares one entry.
+func (qc *QueryCache) hashQuery(scope string, query *models.SearchQuery) string {
+	return fmt.Sprintf("%x", md5.Sum([]byte(scope+"\x00"+query.Fingerprint())))
+}
 
-	return fmt.Sprintf("%x", h.Sum(nil))
+// covers reports whether the entry holds enough ranked results to serve the query's page
+func (e *CacheEntry) covers(query *models.SearchQuery) bool {
+	return e.Window == 0 || e.Window >= query.CandidateWindow()
 }
 
 // L1 Cache Methods (In-memory)
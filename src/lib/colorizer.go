@@ -0,0 +1,225 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ColorMode selects when terminal output is colored
+type ColorMode string
+
+const (
+	ColorAuto   ColorMode = "auto"   // Color terminals, unless NO_COLOR is set
+	ColorAlways ColorMode = "always" // Color even when output is redirected
+	ColorNever  ColorMode = "never"  // Never color
+)
+
+// ANSI escape sequences used by the colorizer
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiDim     = "\x1b[2m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiYellow  = "\x1b[33m"
+	ansiBlue    = "\x1b[34m"
+	ansiMagenta = "\x1b[35m"
+	ansiCyan    = "\x1b[36m"
+	ansiGray    = "\x1b[90m"
+)
+
+// ParseColorMode parses a --color value
+func ParseColorMode(value string) (ColorMode, error) {
+	switch mode := ColorMode(strings.ToLower(value)); mode {
+	case ColorAuto, ColorAlways, ColorNever:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid color mode: %s (supported: auto, always, never)", value)
+	}
+}
+
+// UseColor decides whether output written to out is colored. "always" and "never" are
+// followed as given; "auto" colors terminals unless NO_COLOR is set or TERM is "dumb".
+func UseColor(mode ColorMode, out *os.File) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return IsTerminal(out)
+}
+
+// IsTerminal reports whether a file is a character device such as a terminal
+func IsTerminal(file *os.File) bool {
+	if file == nil {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Colorizer styles text for terminal output. A disabled colorizer returns text unchanged.
+type Colorizer struct {
+	enabled bool
+}
+
+// NewColorizer creates a colorizer that styles text when enabled is set
+func NewColorizer(enabled bool) *Colorizer {
+	return &Colorizer{enabled: enabled}
+}
+
+// Enabled reports whether the colorizer styles text
+func (c *Colorizer) Enabled() bool {
+	return c != nil && c.enabled
+}
+
+// Path styles a file path
+func (c *Colorizer) Path(text string) string {
+	return c.style(ansiMagenta, text)
+}
+
+// LineNumber styles a line number or range
+func (c *Colorizer) LineNumber(text string) string {
+	return c.style(ansiGreen, text)
+}
+
+// Match styles matched text
+func (c *Colorizer) Match(text string) string {
+	return c.style(ansiBold+ansiRed, text)
+}
+
+// Dim styles secondary information such as scores
+func (c *Colorizer) Dim(text string) string {
+	return c.style(ansiDim, text)
+}
+
+// Heading styles a heading such as a group name
+func (c *Colorizer) Heading(text string) string {
+	return c.style(ansiBold, text)
+}
+
+// Snippet styles a line of source code in the given language with light token coloring,
+// and highlights the byte ranges in matches over it
+func (c *Colorizer) Snippet(line, language string, matches [][2]int) string {
+	if !c.Enabled() {
+		return line
+	}
+
+	classes := classifyTokens(line, language)
+	matched := make([]bool, len(line))
+	for _, match := range matches {
+		for i := match[0]; i < match[1] && i < len(line); i++ {
+			if i >= 0 {
+				matched[i] = true
+			}
+		}
+	}
+
+	var b strings.Builder
+	start := 0
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && classes[i] == classes[start] && matched[i] == matched[start] {
+			continue
+		}
+		segment := line[start:i]
+		switch {
+		case matched[start]:
+			b.WriteString(c.Match(segment))
+		case classes[start] != tokenPlain:
+			b.WriteString(c.style(tokenStyles[classes[start]], segment))
+		default:
+			b.WriteString(segment)
+		}
+		start = i
+	}
+	return b.String()
+}
+
+// style wraps text in an ANSI style when the colorizer is enabled
+func (c *Colorizer) style(code, text string) string {
+	if !c.Enabled() || text == "" {
+		return text
+	}
+	return code + text + ansiReset
+}
+
+// tokenStyles maps token classes to their styles
+var tokenStyles = map[tokenClass]string{
+	tokenKeyword: ansiBlue,
+	tokenString:  ansiYellow,
+	tokenComment: ansiGray,
+	tokenNumber:  ansiCyan,
+}
+
+// TruncateAround shortens a line to about width bytes, keeping the window centered on the
+// first match and marking cut ends with "…". It returns the shortened line and the
+// matches moved into it, dropping those outside the window.
+func TruncateAround(line string, matches [][2]int, width int) (string, [][2]int) {
+	if width <= 0 || len(line) <= width {
+		return line, matches
+	}
+
+	start := 0
+	if len(matches) > 0 {
+		matchStart, matchEnd := matches[0][0], matches[0][1]
+		if matchEnd-matchStart < width {
+			start = matchStart - (width-(matchEnd-matchStart))/2
+		} else {
+			start = matchStart
+		}
+	}
+	if start > len(line)-width {
+		start = len(line) - width
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := start + width
+
+	// Cut on rune boundaries
+	for start > 0 && !isRuneStart(line[start]) {
+		start--
+	}
+	for end < len(line) && !isRuneStart(line[end]) {
+		end++
+	}
+
+	prefix, suffix := "", ""
+	if start > 0 {
+		prefix = "…"
+	}
+	if end < len(line) {
+		suffix = "…"
+	}
+
+	var moved [][2]int
+	for _, match := range matches {
+		from, to := match[0], match[1]
+		if to <= start || from >= end {
+			continue
+		}
+		if from < start {
+			from = start
+		}
+		if to > end {
+			to = end
+		}
+		moved = append(moved, [2]int{from - start + len(prefix), to - start + len(prefix)})
+	}
+
+	return prefix + line[start:end] + suffix, moved
+}
+
+// isRuneStart reports whether a byte starts a UTF-8 encoded rune
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
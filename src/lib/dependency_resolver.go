@@ -0,0 +1,347 @@
+package lib
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Extensions tried when resolving extensionless JavaScript and TypeScript imports
+var jsResolveExtensions = []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs"}
+
+// dependencyResolver maps import specifiers to files in a repository. All paths are
+// slash-separated and relative to the repository root.
+type dependencyResolver struct {
+	rootPath   string
+	files      map[string]bool
+	dirs       map[string][]string // Directory to the files it contains
+	byBase     map[string][]string // File name to the files with that name
+	modulePath string              // Go module path from go.mod
+}
+
+// newDependencyResolver creates a resolver for the given repository files
+func newDependencyResolver(rootPath string, files []string) *dependencyResolver {
+	r := &dependencyResolver{
+		rootPath: rootPath,
+		files:    make(map[string]bool, len(files)),
+		dirs:     make(map[string][]string),
+		byBase:   make(map[string][]string),
+	}
+
+	for _, file := range files {
+		rel, ok := r.relativePath(file)
+		if !ok {
+			continue
+		}
+		r.files[rel] = true
+		r.dirs[path.Dir(rel)] = append(r.dirs[path.Dir(rel)], rel)
+		r.byBase[path.Base(rel)] = append(r.byBase[path.Base(rel)], rel)
+	}
+
+	r.modulePath = readGoModulePath(filepath.Join(rootPath, "go.mod"))
+	return r
+}
+
+// relativePath converts a file path to a slash-separated path relative to the root
+func (r *dependencyResolver) relativePath(file string) (string, bool) {
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(r.rootPath, file)
+	}
+
+	rel, err := filepath.Rel(r.rootPath, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// resolve returns the repository files that a file's imports refer to
+func (r *dependencyResolver) resolve(from, language string, deps *FileDependencies) []string {
+	var resolved []string
+
+	switch language {
+	case "Go":
+		for _, spec := range deps.Imports {
+			resolved = append(resolved, r.resolveGo(spec)...)
+		}
+	case "Python":
+		for _, spec := range deps.Imports {
+			resolved = append(resolved, r.resolvePython(from, spec)...)
+		}
+	case "JavaScript", "TypeScript":
+		for _, spec := range append(append([]string{}, deps.Imports...), deps.Requires...) {
+			resolved = append(resolved, r.resolveJS(from, spec)...)
+		}
+	case "Java":
+		for _, spec := range deps.Imports {
+			resolved = append(resolved, r.resolveJava(spec)...)
+		}
+	case "Rust":
+		for _, module := range deps.Includes {
+			resolved = append(resolved, r.resolveRustModule(from, module)...)
+		}
+		for _, spec := range deps.Imports {
+			resolved = append(resolved, r.resolveRustUse(from, spec)...)
+		}
+	default:
+		for _, spec := range deps.Includes {
+			resolved = append(resolved, r.resolveInclude(from, spec)...)
+		}
+	}
+
+	// A file never depends on itself
+	var files []string
+	for _, file := range uniqueStrings(resolved) {
+		if file != from {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+
+	if files == nil {
+		return []string{}
+	}
+	return files
+}
+
+// resolveGo resolves a Go import path to the non-test files of a package in this module
+func (r *dependencyResolver) resolveGo(spec string) []string {
+	if r.modulePath == "" || (spec != r.modulePath && !strings.HasPrefix(spec, r.modulePath+"/")) {
+		return nil
+	}
+
+	dir := strings.TrimPrefix(strings.TrimPrefix(spec, r.modulePath), "/")
+	if dir == "" {
+		dir = "."
+	}
+
+	var files []string
+	for _, file := range r.dirs[dir] {
+		if strings.HasSuffix(file, ".go") && !strings.HasSuffix(file, "_test.go") {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// resolvePython resolves an absolute or relative Python module to a module file or package __init__.py
+func (r *dependencyResolver) resolvePython(from, spec string) []string {
+	module := strings.TrimLeft(spec, ".")
+	modulePath := strings.ReplaceAll(module, ".", "/")
+
+	var bases []string
+	if dots := len(spec) - len(module); dots > 0 {
+		// Relative import: one dot is the current package, each further dot its parent
+		base := path.Dir(from)
+		for i := 1; i < dots; i++ {
+			base = path.Dir(base)
+		}
+		bases = append(bases, base)
+	} else {
+		bases = append(bases, ".", path.Dir(from))
+	}
+
+	for _, base := range bases {
+		candidate := path.Join(base, modulePath)
+		if file := r.firstExisting(candidate+".py", path.Join(candidate, "__init__.py")); file != "" {
+			return []string{file}
+		}
+	}
+
+	// Absolute imports may be rooted at a source directory such as src/
+	if len(spec) == len(module) && module != "" {
+		if matches := r.suffixMatches(modulePath + ".py"); len(matches) == 1 {
+			return matches
+		}
+		if matches := r.suffixMatches(modulePath + "/__init__.py"); len(matches) == 1 {
+			return matches
+		}
+	}
+
+	return nil
+}
+
+// resolveJS resolves a relative JavaScript or TypeScript import; packages are external
+func (r *dependencyResolver) resolveJS(from, spec string) []string {
+	if !strings.HasPrefix(spec, ".") && !strings.HasPrefix(spec, "/") {
+		return nil
+	}
+
+	target := path.Join(path.Dir(from), spec)
+	if strings.HasPrefix(spec, "/") {
+		target = strings.TrimPrefix(path.Clean(spec), "/")
+	}
+
+	candidates := []string{target}
+	// TypeScript sources import their compiled names, e.g. "./util.js" for util.ts
+	stem := strings.TrimSuffix(target, path.Ext(target))
+	for _, ext := range jsResolveExtensions {
+		candidates = append(candidates, target+ext, stem+ext)
+	}
+	for _, ext := range jsResolveExtensions {
+		candidates = append(candidates, path.Join(target, "index"+ext))
+	}
+
+	if file := r.firstExisting(candidates...); file != "" {
+		return []string{file}
+	}
+	return nil
+}
+
+// resolveJava resolves a Java import of a class, a static member or a whole package
+func (r *dependencyResolver) resolveJava(spec string) []string {
+	if strings.HasSuffix(spec, ".*") {
+		dir := strings.ReplaceAll(strings.TrimSuffix(spec, ".*"), ".", "/")
+		var files []string
+		for d, dirFiles := range r.dirs {
+			if d == dir || strings.HasSuffix(d, "/"+dir) {
+				for _, file := range dirFiles {
+					if strings.HasSuffix(file, ".java") {
+						files = append(files, file)
+					}
+				}
+			}
+		}
+		return files
+	}
+
+	// Drop trailing members until a class file matches, for static imports and nested classes
+	parts := strings.Split(spec, ".")
+	for n := len(parts); n > 0; n-- {
+		if matches := r.suffixMatches(strings.Join(parts[:n], "/") + ".java"); len(matches) > 0 {
+			return matches
+		}
+	}
+	return nil
+}
+
+// resolveRustModule resolves an out-of-line `mod name;` declaration
+func (r *dependencyResolver) resolveRustModule(from, module string) []string {
+	base := rustModuleDir(from)
+	if file := r.firstExisting(path.Join(base, module+".rs"), path.Join(base, module, "mod.rs")); file != "" {
+		return []string{file}
+	}
+	return nil
+}
+
+// resolveRustUse resolves a crate-local use path to the file of the longest matching module
+func (r *dependencyResolver) resolveRustUse(from, spec string) []string {
+	segments := strings.Split(spec, "::")
+	if len(segments) < 2 {
+		return nil
+	}
+
+	var base string
+	switch segments[0] {
+	case "crate":
+		base = r.rustCrateRoot(from)
+	case "self":
+		base = rustModuleDir(from)
+	case "super":
+		base = path.Dir(rustModuleDir(from))
+	default:
+		return nil // External crate
+	}
+	segments = segments[1:]
+
+	for n := len(segments); n > 0; n-- {
+		modulePath := path.Join(base, strings.Join(segments[:n], "/"))
+		if file := r.firstExisting(modulePath+".rs", path.Join(modulePath, "mod.rs")); file != "" {
+			return []string{file}
+		}
+	}
+	return nil
+}
+
+// rustCrateRoot finds the directory of the crate root (lib.rs or main.rs) above a file
+func (r *dependencyResolver) rustCrateRoot(from string) string {
+	for dir := path.Dir(from); ; dir = path.Dir(dir) {
+		if r.files[path.Join(dir, "lib.rs")] || r.files[path.Join(dir, "main.rs")] {
+			return dir
+		}
+		if dir == "." || dir == "/" {
+			return path.Dir(from)
+		}
+	}
+}
+
+// rustModuleDir returns the directory holding the submodules of a Rust source file
+func rustModuleDir(from string) string {
+	switch path.Base(from) {
+	case "mod.rs", "lib.rs", "main.rs":
+		return path.Dir(from)
+	default:
+		return strings.TrimSuffix(from, ".rs")
+	}
+}
+
+// resolveInclude resolves a C/C++ include relative to the including file, the root or an include directory
+func (r *dependencyResolver) resolveInclude(from, spec string) []string {
+	if file := r.firstExisting(
+		path.Join(path.Dir(from), spec),
+		path.Clean(spec),
+		path.Join("include", spec),
+	); file != "" {
+		return []string{file}
+	}
+
+	if matches := r.suffixMatches(spec); len(matches) == 1 {
+		return matches
+	}
+	return nil
+}
+
+// firstExisting returns the first candidate that is a repository file
+func (r *dependencyResolver) firstExisting(candidates ...string) string {
+	for _, candidate := range candidates {
+		if r.files[candidate] {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// suffixMatches returns the repository files whose path ends with suffix at a directory boundary
+func (r *dependencyResolver) suffixMatches(suffix string) []string {
+	var matches []string
+	for _, file := range r.byBase[path.Base(suffix)] {
+		if file == suffix || strings.HasSuffix(file, "/"+suffix) {
+			matches = append(matches, file)
+		}
+	}
+	return matches
+}
+
+// readGoModulePath returns the module path declared in a go.mod file, if any
+func readGoModulePath(goModPath string) string {
+	file, err := os.Open(goModPath)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
+
+// uniqueStrings returns the distinct strings in order of first appearance
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
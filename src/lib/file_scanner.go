@@ -12,12 +12,14 @@ import (
 // FileSystemScanner implements the FileScanner interface
 type FileSystemScanner struct {
 	ignorePatterns []string
+	ignoreFiles    ignoreCache
 	perfOptimizer  *PerformanceOptimizer
 }
 
 // OptimizedFileSystemScanner implements the FileScanner interface with performance optimizations
 type OptimizedFileSystemScanner struct {
 	ignorePatterns []string
+	ignoreFiles    ignoreCache
 	perfOptimizer  *PerformanceOptimizer
 }
 
@@ -25,6 +27,7 @@ type OptimizedFileSystemScanner struct {
 func NewFileSystemScanner() *FileSystemScanner {
 	ignorePatterns := []string{
 		".git/*",
+		".clindex/*",
 		"node_modules/*",
 		"*.tmp",
 		"*.log",
@@ -50,6 +53,7 @@ func NewFileSystemScanner() *FileSystemScanner {
 func NewOptimizedFileSystemScanner() *OptimizedFileSystemScanner {
 	ignorePatterns := []string{
 		".git/*",
+		".clindex/*",
 		"node_modules/*",
 		"*.tmp",
 		"*.log",
@@ -75,23 +79,27 @@ func NewOptimizedFileSystemScanner() *OptimizedFileSystemScanner {
 func (fs *FileSystemScanner) ScanFiles(rootPath string, options models.IndexingOptions) ([]string, error) {
 	var files []string
 
+	// Ignore files are read again for every scan
+	var ignoreRules *IgnoreRules
+	if !options.NoIgnore {
+		ignoreRules = fs.ignoreFiles.get(rootPath, true)
+	}
+
 	err := filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		// Skip directories
+		// Skip directories, and everything below ignored ones
 		if info.IsDir() {
+			if ignoreRules != nil && path != rootPath && ignoreRules.Ignored(path, true) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
-		// Skip hidden files unless explicitly included
-		if !options.IncludeHidden && fs.isHiddenFile(path) {
-			return nil
-		}
-
-		// Skip files matching exclude patterns
-		if fs.shouldExcludeFile(path, options.ExcludePatterns) {
+		// Skip hidden, excluded and not included files
+		if skip, _ := fs.ShouldSkipFile(rootPath, path, options); skip {
 			return nil
 		}
 
@@ -131,6 +139,57 @@ func (fs *FileSystemScanner) GetFileStats(filePath string) (models.FileStats, er
 	}, nil
 }
 
+// ShouldSkipFile checks a file against the hidden, exclude and include filters of the
+// options, returning why it is left out
+func (fs *FileSystemScanner) ShouldSkipFile(rootPath, path string, options models.IndexingOptions) (bool, string) {
+	switch {
+	case !options.IncludeHidden && (fs.isHiddenFile(path) || inHiddenDirectory(rootPath, path)):
+		return true, "hidden file"
+	case fs.shouldExcludeFile(path, options.ExcludePatterns) || matchRelativeGlob(rootPath, path, options.ExcludePatterns):
+		return true, "matches an exclude pattern"
+	case !IsIncludedFile(rootPath, path, options.IncludePatterns):
+		return true, "outside the include patterns"
+	case !options.NoIgnore && fs.ignoreFiles.get(rootPath, false).Ignored(path, false):
+		return true, "ignored by an ignore file"
+	}
+	return false, ""
+}
+
+// inHiddenDirectory checks whether a file is below a hidden directory of the root
+func inHiddenDirectory(rootPath, path string) bool {
+	relPath, err := filepath.Rel(rootPath, filepath.Dir(path))
+	if err != nil || relPath == "." {
+		return false
+	}
+	for _, part := range strings.Split(filepath.ToSlash(relPath), "/") {
+		if strings.HasPrefix(part, ".") && part != ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesFileType checks a path against a file type written as ".go", "go" or "*.go"
+func MatchesFileType(path, fileType string) bool {
+	suffix := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(fileType), "*"))
+	return suffix != "" && strings.HasSuffix(strings.ToLower(path), suffix)
+}
+
+// IsIncludedFile checks a file against include patterns relative to the root; no
+// patterns include every file
+func IsIncludedFile(rootPath, path string, includePatterns []string) bool {
+	return len(includePatterns) == 0 || matchRelativeGlob(rootPath, path, includePatterns)
+}
+
+// matchRelativeGlob matches a file's path relative to the root against glob patterns
+func matchRelativeGlob(rootPath, path string, patterns []string) bool {
+	relPath, err := filepath.Rel(rootPath, path)
+	if err != nil {
+		return false
+	}
+	return MatchAnyGlob(patterns, relPath)
+}
+
 // isHiddenFile checks if a file is hidden
 func (fs *FileSystemScanner) isHiddenFile(path string) bool {
 	base := filepath.Base(path)
@@ -169,7 +228,7 @@ func (fs *FileSystemScanner) isSupportedFileType(path string, fileTypes []string
 
 	// Check specific file types
 	for _, fileType := range fileTypes {
-		if strings.HasSuffix(strings.ToLower(path), strings.ToLower(fileType)) {
+		if MatchesFileType(path, fileType) {
 			return true
 		}
 	}
@@ -328,16 +387,16 @@ func (ofs *OptimizedFileSystemScanner) ScanFiles(rootPath string, options models
 		return nil, fmt.Errorf("fast directory scan failed: %w", err)
 	}
 
+	// Ignore files are read again for every scan
+	if !options.NoIgnore {
+		ofs.ignoreFiles.get(rootPath, true)
+	}
+
 	// Convert FileInfo to file paths, applying additional filters
 	var files []string
 	for _, fileInfo := range result.Files {
-		// Skip hidden files unless explicitly included
-		if !options.IncludeHidden && ofs.isHiddenFile(fileInfo.Path) {
-			continue
-		}
-
-		// Apply custom exclude patterns
-		if ofs.shouldExcludeFile(fileInfo.Path, options.ExcludePatterns) {
+		// Skip hidden, excluded and not included files
+		if skip, _ := ofs.ShouldSkipFile(rootPath, fileInfo.Path, options); skip {
 			continue
 		}
 
@@ -375,23 +434,26 @@ func (ofs *OptimizedFileSystemScanner) GetFileStats(filePath string) (models.Fil
 func (ofs *OptimizedFileSystemScanner) basicScan(rootPath string, options models.IndexingOptions) ([]string, error) {
 	var files []string
 
+	var ignoreRules *IgnoreRules
+	if !options.NoIgnore {
+		ignoreRules = ofs.ignoreFiles.get(rootPath, true)
+	}
+
 	err := filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		// Skip directories
+		// Skip directories, and everything below ignored ones
 		if info.IsDir() {
+			if ignoreRules != nil && path != rootPath && ignoreRules.Ignored(path, true) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
-		// Skip hidden files unless explicitly included
-		if !options.IncludeHidden && ofs.isHiddenFile(path) {
-			return nil
-		}
-
-		// Skip files matching exclude patterns
-		if ofs.shouldExcludeFile(path, options.ExcludePatterns) {
+		// Skip hidden, excluded and not included files
+		if skip, _ := ofs.ShouldSkipFile(rootPath, path, options); skip {
 			return nil
 		}
 
@@ -424,6 +486,20 @@ func (ofs *OptimizedFileSystemScanner) extractExcludeDirs(excludePatterns []stri
 }
 
 // Copy methods from FileSystemScanner for OptimizedFileSystemScanner
+func (ofs *OptimizedFileSystemScanner) ShouldSkipFile(rootPath, path string, options models.IndexingOptions) (bool, string) {
+	switch {
+	case !options.IncludeHidden && (ofs.isHiddenFile(path) || inHiddenDirectory(rootPath, path)):
+		return true, "hidden file"
+	case ofs.shouldExcludeFile(path, options.ExcludePatterns) || matchRelativeGlob(rootPath, path, options.ExcludePatterns):
+		return true, "matches an exclude pattern"
+	case !IsIncludedFile(rootPath, path, options.IncludePatterns):
+		return true, "outside the include patterns"
+	case !options.NoIgnore && ofs.ignoreFiles.get(rootPath, false).Ignored(path, false):
+		return true, "ignored by an ignore file"
+	}
+	return false, ""
+}
+
 func (ofs *OptimizedFileSystemScanner) isHiddenFile(path string) bool {
 	base := filepath.Base(path)
 	return strings.HasPrefix(base, ".")
@@ -459,7 +535,7 @@ func (ofs *OptimizedFileSystemScanner) isSupportedFileType(path string, fileType
 
 	// Check specific file types
 	for _, fileType := range fileTypes {
-		if strings.HasSuffix(strings.ToLower(path), strings.ToLower(fileType)) {
+		if MatchesFileType(path, fileType) {
 			return true
 		}
 	}
@@ -73,8 +73,8 @@ func (fu *FileUtilities) ValidatePathSecurity(path string, allowedBasePaths []st
 		// Clean base path
 		cleanBasePath := filepath.Clean(absBasePath)
 
-		// Check if the path is within this base path
-		if strings.HasPrefix(cleanPath, cleanBasePath) {
+		// Check if the path is within this base path, and not a sibling sharing its prefix
+		if cleanPath == cleanBasePath || strings.HasPrefix(cleanPath, strings.TrimSuffix(cleanBasePath, string(filepath.Separator))+string(filepath.Separator)) {
 			return nil // Path is within allowed bounds
 		}
 	}
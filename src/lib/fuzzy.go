@@ -0,0 +1,301 @@
+package lib
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// identifierPattern matches programming language identifiers
+var identifierPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+// Subsequence scoring constants, modelled on fzf's scoring scheme
+const (
+	fuzzyScoreMatch        = 16
+	fuzzyScoreGapStart     = -3
+	fuzzyScoreGapExtension = -1
+	fuzzyBonusBoundary     = 8
+	fuzzyBonusCamelCase    = 7
+	fuzzyBonusConsecutive  = 4
+	fuzzyBonusFirstChar    = 2 // Multiplier applied to the bonus of the first pattern character
+)
+
+// FuzzyOptions controls how query terms are matched against identifiers
+type FuzzyOptions struct {
+	MaxEdits      int  // Maximum edit distance for long terms
+	CaseSensitive bool // Compare characters exactly rather than case-folded
+	WholeWord     bool // Only match whole identifiers, disabling subsequence matches
+}
+
+// FuzzyMatch describes how a query term matched a candidate identifier
+type FuzzyMatch struct {
+	Candidate string  `json:"candidate"`
+	Score     float64 `json:"score"`     // Normalized 0.0 to 1.0
+	Distance  int     `json:"distance"`  // Edit distance, or -1 for subsequence matches
+	Positions []int   `json:"positions"` // Byte offsets of matched characters in Candidate
+}
+
+// ExtractIdentifiers returns the byte ranges of all identifiers in content
+func ExtractIdentifiers(content string) [][]int {
+	return identifierPattern.FindAllStringIndex(content, -1)
+}
+
+// DamerauLevenshtein returns the optimal string alignment distance between a and b,
+// counting insertions, deletions, substitutions and adjacent transpositions.
+// Comparison is case-sensitive. When the distance is known to exceed maxDistance
+// the function stops early and returns maxDistance+1; a negative maxDistance disables the cutoff.
+func DamerauLevenshtein(a, b string, maxDistance int) int {
+	ra := []rune(a)
+	rb := []rune(b)
+
+	if maxDistance >= 0 && absInt(len(ra)-len(rb)) > maxDistance {
+		return maxDistance + 1
+	}
+
+	// Three rows are enough for the transposition lookback
+	prevPrev := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(minInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = minInt(curr[j], prevPrev[j-2]+1)
+			}
+
+			if curr[j] < rowMin {
+				rowMin = curr[j]
+			}
+		}
+
+		if maxDistance >= 0 && rowMin > maxDistance {
+			return maxDistance + 1
+		}
+
+		prevPrev, prev, curr = prev, curr, prevPrev
+	}
+
+	return prev[len(rb)]
+}
+
+// MaxEditsForTerm limits the allowed edit distance for short terms, where a
+// single edit already changes most of the word
+func MaxEditsForTerm(term string, maxEdits int) int {
+	length := len([]rune(term))
+	switch {
+	case length < 3:
+		return 0
+	case length < 6:
+		return minInt(maxEdits, 1)
+	default:
+		return maxEdits
+	}
+}
+
+// FuzzyMatchTerm matches a query term against a candidate identifier. Edit distance
+// is tried first; if that fails the term is matched as a subsequence of the candidate.
+func FuzzyMatchTerm(term, candidate string, options FuzzyOptions) (*FuzzyMatch, bool) {
+	if term == "" || candidate == "" {
+		return nil, false
+	}
+
+	compareTerm, compareCandidate := term, candidate
+	if !options.CaseSensitive {
+		compareTerm, compareCandidate = strings.ToLower(term), strings.ToLower(candidate)
+	}
+
+	allowed := MaxEditsForTerm(term, options.MaxEdits)
+	if distance := DamerauLevenshtein(compareTerm, compareCandidate, allowed); distance <= allowed {
+		positions := make([]int, 0, len(candidate))
+		for i := range candidate {
+			positions = append(positions, i)
+		}
+
+		longest := maxInt(len([]rune(term)), len([]rune(candidate)))
+		return &FuzzyMatch{
+			Candidate: candidate,
+			Score:     1.0 - float64(distance)/float64(longest+1),
+			Distance:  distance,
+			Positions: positions,
+		}, true
+	}
+
+	// Subsequence matching is too permissive for very short terms and
+	// never matches a whole word
+	if options.WholeWord || len([]rune(term)) < 3 {
+		return nil, false
+	}
+
+	score, positions, ok := SubsequenceScore(term, candidate, options.CaseSensitive)
+	if !ok {
+		return nil, false
+	}
+
+	return &FuzzyMatch{
+		Candidate: candidate,
+		Score:     score,
+		Distance:  -1,
+		Positions: positions,
+	}, true
+}
+
+// SubsequenceScore scores pattern as a subsequence of candidate in the style of fzf: matches on word boundaries and camelCase humps and consecutive runs earn
+// bonuses, gaps are penalised. It returns a normalized score and the byte offsets of the
+// matched characters in candidate.
+func SubsequenceScore(pattern, candidate string, caseSensitive bool) (float64, []int, bool) {
+	fold := unicode.ToLower
+	if caseSensitive {
+		fold = func(r rune) rune { return r }
+	}
+
+	patternRunes := []rune(pattern)
+	for i, r := range patternRunes {
+		patternRunes[i] = fold(r)
+	}
+	if len(patternRunes) == 0 {
+		return 0, nil, false
+	}
+
+	type indexedRune struct {
+		offset int
+		r      rune
+	}
+	var candidateRunes []indexedRune
+	for offset, r := range candidate {
+		candidateRunes = append(candidateRunes, indexedRune{offset, r})
+	}
+
+	// Forward pass: find the earliest position where the whole pattern has matched
+	pi := 0
+	end := -1
+	for ci, cr := range candidateRunes {
+		if fold(cr.r) == patternRunes[pi] {
+			pi++
+			if pi == len(patternRunes) {
+				end = ci
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	// Backward pass: shrink the window to the shortest match ending at end
+	pi = len(patternRunes) - 1
+	start := end
+	for ci := end; ci >= 0; ci-- {
+		if fold(candidateRunes[ci].r) == patternRunes[pi] {
+			pi--
+			if pi < 0 {
+				start = ci
+				break
+			}
+		}
+	}
+
+	// Score the window, matching greedily from the left
+	score := 0
+	positions := make([]int, 0, len(patternRunes))
+	pi = 0
+	inGap := false
+	lastMatch := -2
+	for ci := start; ci <= end && pi < len(patternRunes); ci++ {
+		cr := candidateRunes[ci].r
+		if fold(cr) != patternRunes[pi] {
+			if inGap {
+				score += fuzzyScoreGapExtension
+			} else {
+				score += fuzzyScoreGapStart
+				inGap = true
+			}
+			continue
+		}
+
+		var prev rune
+		if ci > 0 {
+			prev = candidateRunes[ci-1].r
+		}
+		bonus := characterBonus(prev, cr, ci == 0)
+		if lastMatch == ci-1 && bonus < fuzzyBonusConsecutive {
+			bonus = fuzzyBonusConsecutive
+		}
+		if pi == 0 {
+			bonus *= fuzzyBonusFirstChar
+		}
+
+		score += fuzzyScoreMatch + bonus
+		positions = append(positions, candidateRunes[ci].offset)
+		lastMatch = ci
+		inGap = false
+		pi++
+	}
+
+	maxScore := len(patternRunes)*(fuzzyScoreMatch+fuzzyBonusBoundary) + fuzzyBonusBoundary*(fuzzyBonusFirstChar-1)
+	normalized := float64(score) / float64(maxScore)
+	if normalized < 0 {
+		normalized = 0
+	}
+	if normalized > 1 {
+		normalized = 1
+	}
+
+	return normalized, positions, true
+}
+
+// SpansFromPositions collapses sorted byte positions of single characters into
+// contiguous [start, end) ranges within text
+func SpansFromPositions(text string, positions []int) [][2]int {
+	var spans [][2]int
+	for _, pos := range positions {
+		if pos < 0 || pos >= len(text) {
+			continue
+		}
+
+		_, size := utf8.DecodeRuneInString(text[pos:])
+		if n := len(spans); n > 0 && spans[n-1][1] == pos {
+			spans[n-1][1] = pos + size
+			continue
+		}
+		spans = append(spans, [2]int{pos, pos + size})
+	}
+
+	return spans
+}
+
+// characterBonus returns the positional bonus for matching current after prev
+func characterBonus(prev, current rune, atStart bool) int {
+	switch {
+	case atStart:
+		return fuzzyBonusBoundary
+	case !unicode.IsLetter(prev) && !unicode.IsDigit(prev):
+		return fuzzyBonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(current):
+		return fuzzyBonusCamelCase
+	case !unicode.IsDigit(prev) && unicode.IsDigit(current):
+		return fuzzyBonusCamelCase
+	default:
+		return 0
+	}
+}
+
+// absInt returns the absolute value of an int
+func absInt(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
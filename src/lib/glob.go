@@ -0,0 +1,70 @@
+package lib
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// MatchGlob reports whether a slash-separated path relative to the repository root
+// matches a glob pattern. Patterns without a slash match the file name in any
+// directory, "**" matches any number of directories, and a trailing slash matches
+// everything below a directory.
+func MatchGlob(pattern, relPath string) bool {
+	pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+	relPath = strings.TrimPrefix(filepath.ToSlash(relPath), "./")
+	if pattern == "" {
+		return false
+	}
+
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(relPath))
+		return matched
+	}
+
+	return matchGlobSegments(strings.Split(strings.TrimPrefix(pattern, "/"), "/"), strings.Split(relPath, "/"))
+}
+
+// MatchAnyGlob reports whether the path matches any of the patterns
+func MatchAnyGlob(patterns []string, relPath string) bool {
+	for _, pattern := range patterns {
+		if MatchGlob(pattern, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlobSegments matches path segments against pattern segments. A trailing "**"
+// matches everything below a directory but not the directory itself, as in gitignore.
+func matchGlobSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse repeated ** and try every number of skipped segments
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return len(segments) > 0
+			}
+			for skip := 0; skip <= len(segments); skip++ {
+				if matchGlobSegments(pattern, segments[skip:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(segments) == 0 {
+			return false
+		}
+		if matched, err := path.Match(pattern[0], segments[0]); err != nil || !matched {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
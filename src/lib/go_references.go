@@ -0,0 +1,501 @@
+package lib
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"code-search/src/models"
+)
+
+// GoReferenceAnalyzer type-checks Go packages from source and records where identifiers
+// are defined, referenced and called. Imports are resolved from the repository, its vendor
+// directory, GOROOT and the local module cache; nothing is downloaded.
+type GoReferenceAnalyzer struct {
+	fset        *token.FileSet
+	buildCtx    build.Context
+	rootDir     string
+	modulePath  string
+	requires    map[string]string // Module path to required version
+	modCache    string
+	packages    map[string]*types.Package
+	loading     map[string]bool
+	fieldOwners map[*types.Package]map[*types.Var]string
+}
+
+// NewGoReferenceAnalyzer creates a new Go reference analyzer
+func NewGoReferenceAnalyzer() *GoReferenceAnalyzer {
+	ctx := build.Default
+	ctx.CgoEnabled = false
+
+	modCache := os.Getenv("GOMODCACHE")
+	if modCache == "" {
+		if gopath := filepath.SplitList(ctx.GOPATH); len(gopath) > 0 {
+			modCache = filepath.Join(gopath[0], "pkg", "mod")
+		}
+	}
+
+	return &GoReferenceAnalyzer{
+		buildCtx: ctx,
+		modCache: modCache,
+	}
+}
+
+// Analyze type-checks the packages containing the given Go files and returns the
+// references found in each file, keyed by file path
+func (a *GoReferenceAnalyzer) Analyze(rootDir string, files []string) (map[string][]models.Reference, error) {
+	a.fset = token.NewFileSet()
+	a.rootDir = rootDir
+	a.modulePath = ""
+	a.requires = make(map[string]string)
+	a.packages = make(map[string]*types.Package)
+	a.loading = make(map[string]bool)
+	a.fieldOwners = make(map[*types.Package]map[*types.Var]string)
+
+	if err := a.readGoMod(filepath.Join(rootDir, "go.mod")); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read go.mod: %w", err)
+	}
+
+	// Group the indexed files by package directory
+	byDir := make(map[string]map[string]bool)
+	for _, file := range files {
+		dir := filepath.Dir(file)
+		if byDir[dir] == nil {
+			byDir[dir] = make(map[string]bool)
+		}
+		byDir[dir][filepath.Base(file)] = true
+	}
+
+	dirs := make([]string, 0, len(byDir))
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	references := make(map[string][]models.Reference)
+	for _, dir := range dirs {
+		importPath := a.localImportPath(dir)
+		for _, group := range a.packageFiles(dir, byDir[dir]) {
+			path := importPath
+			if strings.HasSuffix(group.name, "_test") {
+				path += "_test"
+			}
+			a.checkPackage(path, group.files, references)
+		}
+	}
+
+	return references, nil
+}
+
+// goFileGroup is a set of files type-checked together as one package
+type goFileGroup struct {
+	name  string
+	files []*ast.File
+}
+
+// packageFiles parses the indexed files in dir that belong to the build, split into the
+// package itself (with its in-package tests) and its external test package
+func (a *GoReferenceAnalyzer) packageFiles(dir string, indexed map[string]bool) []goFileGroup {
+	var names []string
+	if pkg, err := a.buildCtx.ImportDir(dir, 0); err == nil {
+		names = append(names, pkg.GoFiles...)
+		names = append(names, pkg.TestGoFiles...)
+		names = append(names, pkg.XTestGoFiles...)
+	} else {
+		for name := range indexed {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	var groups []goFileGroup
+	for _, name := range names {
+		if !indexed[name] {
+			continue
+		}
+
+		file, err := parser.ParseFile(a.fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil && file == nil {
+			continue
+		}
+
+		found := false
+		for i := range groups {
+			if groups[i].name == file.Name.Name {
+				groups[i].files = append(groups[i].files, file)
+				found = true
+				break
+			}
+		}
+		if !found {
+			groups = append(groups, goFileGroup{name: file.Name.Name, files: []*ast.File{file}})
+		}
+	}
+
+	return groups
+}
+
+// checkPackage type-checks one package and appends its references to references
+func (a *GoReferenceAnalyzer) checkPackage(path string, files []*ast.File, references map[string][]models.Reference) {
+	info := &types.Info{
+		Defs: make(map[*ast.Ident]types.Object),
+		Uses: make(map[*ast.Ident]types.Object),
+	}
+
+	// Type errors are expected when dependencies are missing; everything that does resolve is still recorded
+	conf := types.Config{
+		Importer:    a,
+		FakeImportC: true,
+		Error:       func(error) {},
+	}
+	conf.Check(path, a.fset, files, info)
+
+	for _, file := range files {
+		a.recordFile(file, info, references)
+	}
+}
+
+// recordFile records the resolved identifiers in a file
+func (a *GoReferenceAnalyzer) recordFile(file *ast.File, info *types.Info, references map[string][]models.Reference) {
+	filePath := a.fset.File(file.Pos()).Name()
+
+	for _, decl := range file.Decls {
+		caller := ""
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			if obj := info.Defs[fn.Name]; obj != nil {
+				caller, _ = a.objectTarget(obj)
+			}
+		}
+
+		calls := make(map[*ast.Ident]bool)
+		ast.Inspect(decl, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.CallExpr:
+				if ident := calleeIdent(node.Fun); ident != nil {
+					calls[ident] = true
+				}
+			case *ast.Ident:
+				if ref, ok := a.resolveIdent(node, info, calls[node], caller); ok {
+					ref.FilePath = filePath
+					references[filePath] = append(references[filePath], ref)
+				}
+			}
+			return true
+		})
+	}
+}
+
+// resolveIdent builds the reference for an identifier, if it resolves to a trackable object
+func (a *GoReferenceAnalyzer) resolveIdent(ident *ast.Ident, info *types.Info, isCallee bool, caller string) (models.Reference, bool) {
+	kind := models.ReferenceKindDefinition
+	obj := info.Defs[ident]
+	if obj == nil {
+		obj = info.Uses[ident]
+		kind = models.ReferenceKindUse
+		if _, isFunc := obj.(*types.Func); isFunc && isCallee {
+			kind = models.ReferenceKindCall
+		}
+	}
+	if obj == nil {
+		return models.Reference{}, false
+	}
+
+	target, ok := a.objectTarget(obj)
+	if !ok {
+		return models.Reference{}, false
+	}
+
+	pos := a.fset.Position(ident.Pos())
+	ref := models.Reference{
+		Name:      ident.Name,
+		Target:    target,
+		Kind:      kind,
+		Line:      pos.Line,
+		Column:    pos.Column,
+		EndColumn: pos.Column + len(ident.Name),
+	}
+	if kind != models.ReferenceKindDefinition {
+		ref.Caller = caller
+	}
+
+	return ref, true
+}
+
+// objectTarget returns the package-qualified name of an object. Local variables, labels,
+// package names and builtins are not tracked.
+func (a *GoReferenceAnalyzer) objectTarget(obj types.Object) (string, bool) {
+	if obj.Pkg() == nil || obj.Name() == "_" {
+		return "", false
+	}
+	pkgPath := obj.Pkg().Path()
+
+	switch o := obj.(type) {
+	case *types.Func:
+		o = o.Origin()
+		if recv := o.Type().(*types.Signature).Recv(); recv != nil {
+			owner := namedTypeName(recv.Type())
+			if owner == "" {
+				return "", false
+			}
+			return pkgPath + "." + owner + "." + o.Name(), true
+		}
+		if o.Parent() != o.Pkg().Scope() {
+			return "", false
+		}
+		return pkgPath + "." + o.Name(), true
+
+	case *types.Var:
+		o = o.Origin()
+		if o.IsField() {
+			owner := a.fieldOwner(o)
+			if owner == "" {
+				return "", false
+			}
+			return pkgPath + "." + owner + "." + o.Name(), true
+		}
+		if o.Parent() != o.Pkg().Scope() {
+			return "", false
+		}
+		return pkgPath + "." + o.Name(), true
+
+	case *types.TypeName, *types.Const:
+		if obj.Parent() != obj.Pkg().Scope() {
+			return "", false
+		}
+		return pkgPath + "." + obj.Name(), true
+
+	default:
+		return "", false
+	}
+}
+
+// fieldOwner returns the name of the package-level struct type declaring a field
+func (a *GoReferenceAnalyzer) fieldOwner(field *types.Var) string {
+	owners, ok := a.fieldOwners[field.Pkg()]
+	if !ok {
+		owners = make(map[*types.Var]string)
+		scope := field.Pkg().Scope()
+		for _, name := range scope.Names() {
+			typeName, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok {
+				continue
+			}
+			if st, ok := typeName.Type().Underlying().(*types.Struct); ok {
+				for i := 0; i < st.NumFields(); i++ {
+					owners[st.Field(i)] = name
+				}
+			}
+		}
+		a.fieldOwners[field.Pkg()] = owners
+	}
+
+	return owners[field]
+}
+
+// namedTypeName returns the name of a receiver's named type
+func namedTypeName(t types.Type) string {
+	if ptr, ok := types.Unalias(t).(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if named, ok := types.Unalias(t).(*types.Named); ok {
+		return named.Obj().Name()
+	}
+	return ""
+}
+
+// calleeIdent returns the identifier naming the function in a call expression
+func calleeIdent(expr ast.Expr) *ast.Ident {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e
+	case *ast.SelectorExpr:
+		return e.Sel
+	case *ast.ParenExpr:
+		return calleeIdent(e.X)
+	case *ast.IndexExpr:
+		return calleeIdent(e.X)
+	case *ast.IndexListExpr:
+		return calleeIdent(e.X)
+	default:
+		return nil
+	}
+}
+
+// Import implements types.Importer
+func (a *GoReferenceAnalyzer) Import(path string) (*types.Package, error) {
+	return a.ImportFrom(path, "", 0)
+}
+
+// ImportFrom implements types.ImporterFrom. Dependencies are type-checked from source
+// without function bodies, since only their declarations are needed.
+func (a *GoReferenceAnalyzer) ImportFrom(path, fromDir string, mode types.ImportMode) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+	if pkg, ok := a.packages[path]; ok {
+		return pkg, nil
+	}
+	if a.loading[path] {
+		return nil, fmt.Errorf("import cycle through %s", path)
+	}
+
+	dir := a.resolveImport(path, fromDir)
+	if dir == "" {
+		return nil, fmt.Errorf("package %s not found locally", path)
+	}
+
+	bp, err := a.buildCtx.ImportDir(dir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load package %s: %w", path, err)
+	}
+
+	var files []*ast.File
+	for _, name := range bp.GoFiles {
+		file, err := parser.ParseFile(a.fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil && file == nil {
+			continue
+		}
+		files = append(files, file)
+	}
+
+	a.loading[path] = true
+	defer delete(a.loading, path)
+
+	conf := types.Config{
+		Importer:         a,
+		FakeImportC:      true,
+		IgnoreFuncBodies: true,
+		Error:            func(error) {},
+	}
+	pkg, _ := conf.Check(path, a.fset, files, nil)
+	a.packages[path] = pkg
+
+	return pkg, nil
+}
+
+// resolveImport finds the source directory of an import path
+func (a *GoReferenceAnalyzer) resolveImport(path, fromDir string) string {
+	// Packages of the indexed module
+	if a.modulePath != "" && (path == a.modulePath || strings.HasPrefix(path, a.modulePath+"/")) {
+		return filepath.Join(a.rootDir, filepath.FromSlash(strings.TrimPrefix(path, a.modulePath)))
+	}
+
+	// Vendored dependencies
+	if dir := filepath.Join(a.rootDir, "vendor", filepath.FromSlash(path)); isDirectory(dir) {
+		return dir
+	}
+
+	// Standard library, including the standard library's own vendored packages
+	if a.buildCtx.GOROOT != "" {
+		gorootSrc := filepath.Join(a.buildCtx.GOROOT, "src")
+		if dir := filepath.Join(gorootSrc, filepath.FromSlash(path)); isDirectory(dir) {
+			return dir
+		}
+		if strings.HasPrefix(fromDir, gorootSrc) {
+			if dir := filepath.Join(gorootSrc, "vendor", filepath.FromSlash(path)); isDirectory(dir) {
+				return dir
+			}
+		}
+	}
+
+	// Required modules in the local module cache
+	if a.modCache != "" {
+		best := ""
+		for module := range a.requires {
+			if (path == module || strings.HasPrefix(path, module+"/")) && len(module) > len(best) {
+				best = module
+			}
+		}
+		if best != "" {
+			moduleDir := filepath.Join(a.modCache, escapeModulePath(best)+"@"+escapeModulePath(a.requires[best]))
+			if dir := filepath.Join(moduleDir, filepath.FromSlash(strings.TrimPrefix(path, best))); isDirectory(dir) {
+				return dir
+			}
+		}
+	}
+
+	return ""
+}
+
+// localImportPath returns the import path of a package directory in the indexed repository
+func (a *GoReferenceAnalyzer) localImportPath(dir string) string {
+	rel, err := filepath.Rel(a.rootDir, dir)
+	if err != nil {
+		rel = dir
+	}
+	rel = filepath.ToSlash(rel)
+
+	switch {
+	case a.modulePath == "":
+		return rel
+	case rel == ".":
+		return a.modulePath
+	default:
+		return a.modulePath + "/" + rel
+	}
+}
+
+// readGoMod reads the module path and required module versions from a go.mod file
+func (a *GoReferenceAnalyzer) readGoMod(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	inRequire := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch {
+		case inRequire && fields[0] == ")":
+			inRequire = false
+		case inRequire && len(fields) >= 2:
+			a.requires[strings.Trim(fields[0], `"`)] = fields[1]
+		case fields[0] == "module" && len(fields) >= 2:
+			a.modulePath = strings.Trim(fields[1], `"`)
+		case fields[0] == "require" && len(fields) >= 2 && fields[1] == "(":
+			inRequire = true
+		case fields[0] == "require" && len(fields) >= 3:
+			a.requires[strings.Trim(fields[1], `"`)] = fields[2]
+		}
+	}
+
+	return scanner.Err()
+}
+
+// escapeModulePath applies the module cache's case encoding, e.g. "github.com/Azure" to "github.com/!azure"
+func escapeModulePath(path string) string {
+	var sb strings.Builder
+	for _, r := range path {
+		if r >= 'A' && r <= 'Z' {
+			sb.WriteByte('!')
+			sb.WriteRune(r + ('a' - 'A'))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// isDirectory checks if a path exists and is a directory
+func isDirectory(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
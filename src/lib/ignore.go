@@ -0,0 +1,329 @@
+package lib
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// IgnoreFileNames are the per-directory ignore files, in increasing priority
+var IgnoreFileNames = []string{".gitignore", ".ignore", ".codesearchignore"}
+
+// IgnoreRules decides which paths below a root are excluded by ignore files, following
+// .gitignore semantics. Files in deeper directories override those above them, and
+// .git/info/exclude and the global excludes file have the lowest priority.
+type IgnoreRules struct {
+	root     string
+	top      string // Highest directory whose ignore files apply: the worktree or the root
+	base     []ignorePattern
+	patterns map[string][]ignorePattern
+	ignored  map[string]bool
+	mu       sync.Mutex
+}
+
+// ignorePattern is one line of an ignore file
+type ignorePattern struct {
+	dir      string // Directory the pattern is relative to
+	glob     string
+	segments []string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// NewIgnoreRules creates the ignore rules for a root. When the root is inside a git
+// worktree, .gitignore files between the worktree and the root also apply.
+func NewIgnoreRules(root string) *IgnoreRules {
+	root = filepath.Clean(root)
+	rules := &IgnoreRules{
+		root:     root,
+		top:      root,
+		patterns: make(map[string][]ignorePattern),
+		ignored:  make(map[string]bool),
+	}
+
+	worktree, gitDir := findGitWorktree(root)
+	if worktree != "" {
+		rules.top = worktree
+	}
+	if excludesFile := globalExcludesFile(); excludesFile != "" {
+		rules.base = append(rules.base, readIgnoreFile(excludesFile, rules.top)...)
+	}
+	if gitDir != "" {
+		rules.base = append(rules.base, readIgnoreFile(filepath.Join(gitDir, "info", "exclude"), rules.top)...)
+	}
+	return rules
+}
+
+// Ignored reports whether a path below the root, or one of its parent directories, is
+// excluded by ignore files
+func (r *IgnoreRules) Ignored(filePath string, isDir bool) bool {
+	rel, err := filepath.Rel(r.root, filePath)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// A file cannot be re-included when a parent directory is excluded
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	dir := r.root
+	for _, part := range parts[:len(parts)-1] {
+		dir = filepath.Join(dir, part)
+		if r.dirIgnored(dir) {
+			return true
+		}
+	}
+
+	if isDir {
+		return r.dirIgnored(filePath)
+	}
+	return r.match(filePath, false)
+}
+
+// dirIgnored reports whether a directory itself is excluded, caching the answer
+func (r *IgnoreRules) dirIgnored(dir string) bool {
+	if ignored, ok := r.ignored[dir]; ok {
+		return ignored
+	}
+	ignored := r.match(dir, true)
+	r.ignored[dir] = ignored
+	return ignored
+}
+
+// match finds the pattern that decides a path: the last matching pattern of the
+// deepest directory with one, then the base patterns
+func (r *IgnoreRules) match(filePath string, isDir bool) bool {
+	for dir := filepath.Dir(filePath); ; dir = filepath.Dir(dir) {
+		patterns := r.directoryPatterns(dir)
+		for i := len(patterns) - 1; i >= 0; i-- {
+			if patterns[i].matches(filePath, isDir) {
+				return !patterns[i].negate
+			}
+		}
+		if dir == r.top || dir == filepath.Dir(dir) {
+			break
+		}
+	}
+
+	for i := len(r.base) - 1; i >= 0; i-- {
+		if r.base[i].matches(filePath, isDir) {
+			return !r.base[i].negate
+		}
+	}
+	return false
+}
+
+// directoryPatterns returns the patterns of a directory's ignore files, loading them once
+func (r *IgnoreRules) directoryPatterns(dir string) []ignorePattern {
+	if patterns, ok := r.patterns[dir]; ok {
+		return patterns
+	}
+
+	var patterns []ignorePattern
+	for _, name := range IgnoreFileNames {
+		patterns = append(patterns, readIgnoreFile(filepath.Join(dir, name), dir)...)
+	}
+	r.patterns[dir] = patterns
+	return patterns
+}
+
+// matches reports whether the pattern matches a path
+func (p ignorePattern) matches(filePath string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+
+	rel, err := filepath.Rel(p.dir, filePath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	if !p.anchored {
+		matched, _ := path.Match(p.glob, path.Base(rel))
+		return matched
+	}
+	return matchGlobSegments(p.segments, strings.Split(rel, "/"))
+}
+
+// readIgnoreFile reads the patterns of an ignore file relative to a directory; a missing
+// or unreadable file has none
+func readIgnoreFile(filePath, dir string) []ignorePattern {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var patterns []ignorePattern
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if pattern, ok := parseIgnorePattern(scanner.Text(), dir); ok {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// parseIgnorePattern parses one line of an ignore file
+func parseIgnorePattern(line, dir string) (ignorePattern, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false
+	}
+
+	// Trailing spaces are ignored unless escaped
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+
+	pattern := ignorePattern{dir: dir}
+	if strings.HasPrefix(line, "!") {
+		pattern.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignorePattern{}, false
+	}
+
+	// gitignore writes negated character classes as [!...]
+	line = strings.ReplaceAll(line, "[!", "[^")
+
+	// A slash anywhere but the end anchors the pattern to the file's directory
+	if strings.Contains(line, "/") {
+		pattern.anchored = true
+		pattern.segments = strings.Split(strings.TrimPrefix(line, "/"), "/")
+	} else {
+		pattern.glob = line
+	}
+	return pattern, true
+}
+
+// findGitWorktree returns the worktree containing a directory and its git directory,
+// or empty strings when it is not in one
+func findGitWorktree(dir string) (string, string) {
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		if info, err := os.Stat(dotGit); err == nil {
+			if info.IsDir() {
+				return dir, dotGit
+			}
+			// Linked worktrees and submodules have a .git file naming the git directory
+			if data, err := os.ReadFile(dotGit); err == nil {
+				gitDir := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(data)), "gitdir:"))
+				if !filepath.IsAbs(gitDir) {
+					gitDir = filepath.Join(dir, gitDir)
+				}
+				return dir, gitDir
+			}
+			return dir, ""
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+// globalExcludesFile returns git's core.excludesFile, or its default location
+func globalExcludesFile() string {
+	home, _ := os.UserHomeDir()
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" && home != "" {
+		configHome = filepath.Join(home, ".config")
+	}
+
+	var configFiles []string
+	if configHome != "" {
+		configFiles = append(configFiles, filepath.Join(configHome, "git", "config"))
+	}
+	if home != "" {
+		configFiles = append(configFiles, filepath.Join(home, ".gitconfig"))
+	}
+
+	// ~/.gitconfig is read after the XDG file, so it wins
+	excludesFile := ""
+	for _, configFile := range configFiles {
+		if value := readGitConfigValue(configFile, "core", "excludesfile"); value != "" {
+			excludesFile = value
+		}
+	}
+
+	if excludesFile == "" {
+		if configHome == "" {
+			return ""
+		}
+		return filepath.Join(configHome, "git", "ignore")
+	}
+	if strings.HasPrefix(excludesFile, "~/") && home != "" {
+		excludesFile = filepath.Join(home, excludesFile[2:])
+	}
+	return excludesFile
+}
+
+// readGitConfigValue reads a key from a section of a git config file; section and key
+// names are case-insensitive
+func readGitConfigValue(configFile, section, key string) string {
+	file, err := os.Open(configFile)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	value := ""
+	inSection := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			name := strings.TrimSpace(strings.Trim(line, "[]"))
+			inSection = strings.EqualFold(name, section)
+			continue
+		}
+
+		name, raw, found := strings.Cut(line, "=")
+		if inSection && found && strings.EqualFold(strings.TrimSpace(name), key) {
+			value = strings.Trim(strings.TrimSpace(raw), "\"")
+		}
+	}
+	return value
+}
+
+// ignoreCache keeps the ignore rules of each scanned root
+type ignoreCache struct {
+	mu    sync.Mutex
+	rules map[string]*IgnoreRules
+}
+
+// get returns the ignore rules of a root, reading its ignore files again when reload is set
+func (c *ignoreCache) get(root string, reload bool) *IgnoreRules {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.rules == nil {
+		c.rules = make(map[string]*IgnoreRules)
+	}
+	rules, ok := c.rules[root]
+	if !ok || reload {
+		rules = NewIgnoreRules(root)
+		c.rules[root] = rules
+	}
+	return rules
+}
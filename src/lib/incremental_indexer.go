@@ -4,10 +4,15 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"go/parser"
+	"go/token"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -58,11 +63,19 @@ type FileMetadata struct {
 
 // FileDependencies represents dependencies for a file
 type FileDependencies struct {
-	Includes      []string `json:"includes"`
-	Imports       []string `json:"imports"`
-	Requires      []string `json:"requires"`
-	ReferencedBy  []string `json:"referenced_by"`
-	LastAnalyzed  time.Time `json:"last_analyzed"`
+	Includes     []string  `json:"includes"`
+	Imports      []string  `json:"imports"`
+	Requires     []string  `json:"requires"`
+	Files        []string  `json:"files"` // Repository files the imports resolve to
+	ReferencedBy []string  `json:"referenced_by"`
+	LastAnalyzed time.Time `json:"last_analyzed"`
+}
+
+// DependencyEdge records that From imports To, found at Depth steps from the start of a traversal
+type DependencyEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Depth int    `json:"depth"`
 }
 
 // FileChangeEvent represents a file system change event
@@ -111,7 +124,7 @@ func DefaultIncrementalOptions() IncrementalOptions {
 // NewIncrementalIndexer creates a new incremental indexer
 func NewIncrementalIndexer(indexPath string, options IncrementalOptions) *IncrementalIndexer {
 	return &IncrementalIndexer{
-		metadataPath:    filepath.Join(indexPath, "metadata.json"),
+		metadataPath:    filepath.Join(indexPath, "dependencies.json"), // metadata.json holds directory metadata
 		fileMetadata:    make(map[string]*FileMetadata),
 		dependencyGraph: make(map[string]*FileDependencies),
 		options:         options,
@@ -174,6 +187,10 @@ func (ii *IncrementalIndexer) SaveMetadata() error {
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
+	if err := os.MkdirAll(filepath.Dir(ii.metadataPath), 0755); err != nil {
+		return fmt.Errorf("failed to create metadata directory: %w", err)
+	}
+
 	// Write to temporary file first
 	tempPath := ii.metadataPath + ".tmp"
 	if err := os.WriteFile(tempPath, data, 0644); err != nil {
@@ -367,6 +384,11 @@ func (ii *IncrementalIndexer) detectLanguage(path string) string {
 		".ts":   "TypeScript",
 		".py":   "Python",
 		".java": "Java",
+		".jsx":  "JavaScript",
+		".tsx":  "TypeScript",
+		".mjs":  "JavaScript",
+		".cc":   "C++",
+		".hpp":  "C++",
 		".cpp":  "C++",
 		".c":    "C",
 		".h":    "C/C++ Header",
@@ -401,51 +423,253 @@ func (ii *IncrementalIndexer) analyzeDependencies(path string) *FileDependencies
 		Includes:     []string{},
 		Imports:      []string{},
 		Requires:     []string{},
+		Files:        []string{},
 		ReferencedBy: []string{},
 		LastAnalyzed: time.Now(),
 	}
 
-	// This is a simplified implementation
-	// In a real system, you'd parse the file and extract actual dependencies
-	language := ii.detectLanguage(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return deps
+	}
+	content := string(data)
 
-	switch language {
+	switch ii.detectLanguage(path) {
 	case "Go":
-		deps.Imports = ii.extractGoImports(path)
+		deps.Imports = ii.extractGoImports(path, content)
 	case "Python":
-		deps.Imports = ii.extractPythonImports(path)
+		deps.Imports = ii.extractPythonImports(content)
 	case "JavaScript", "TypeScript":
-		deps.Imports = ii.extractJSImports(path)
-	case "C", "C++":
-		deps.Includes = ii.extractCIncludes(path)
+		deps.Imports, deps.Requires = ii.extractJSImports(content)
+	case "Java":
+		deps.Imports = ii.extractJavaImports(content)
+	case "Rust":
+		deps.Imports, deps.Includes = ii.extractRustImports(content)
+	case "C", "C++", "C/C++ Header":
+		deps.Includes = ii.extractCIncludes(content)
 	}
 
 	return deps
 }
 
+// Import statement patterns for languages without a parser in the standard library
+var (
+	pythonImportPattern     = regexp.MustCompile(`(?m)^[ \t]*import[ \t]+([\w.]+(?:[ \t]+as[ \t]+\w+)?(?:[ \t]*,[ \t]*[\w.]+(?:[ \t]+as[ \t]+\w+)?)*)`)
+	pythonFromImportPattern = regexp.MustCompile(`(?m)^[ \t]*from[ \t]+(\.*[\w.]*)[ \t]+import[ \t]+\(?[ \t]*([\w, \t]+)`)
+	jsImportPattern         = regexp.MustCompile(`(?m)(?:^|[^.\w])(?:import|export)\s[^'"]*?from\s*['"]([^'"]+)['"]|(?:^|[^.\w])import\s*\(?\s*['"]([^'"]+)['"]`)
+	jsRequirePattern        = regexp.MustCompile(`(?:^|[^.\w])require\s*\(\s*['"]([^'"]+)['"]\s*\)`)
+	javaImportPattern       = regexp.MustCompile(`(?m)^\s*import\s+(?:static\s+)?([\w.]+(?:\.\*)?)\s*;`)
+	rustUsePattern          = regexp.MustCompile(`(?m)^\s*(?:pub(?:\([^)]*\))?\s+)?use\s+([\w:]+)`)
+	rustModPattern          = regexp.MustCompile(`(?m)^\s*(?:pub(?:\([^)]*\))?\s+)?mod\s+(\w+)\s*;`)
+	cIncludePattern         = regexp.MustCompile(`(?m)^\s*#\s*include\s*[<"]([^>"]+)[>"]`)
+)
+
 // extractGoImports extracts imports from Go files
-func (ii *IncrementalIndexer) extractGoImports(path string) []string {
-	// Simplified Go import extraction
-	// In a real implementation, you'd use go/parser
-	return []string{}
+func (ii *IncrementalIndexer) extractGoImports(path, content string) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), path, content, parser.ImportsOnly)
+	if err != nil && file == nil {
+		return []string{}
+	}
+
+	imports := make([]string, 0, len(file.Imports))
+	for _, spec := range file.Imports {
+		if importPath, err := strconv.Unquote(spec.Path.Value); err == nil {
+			imports = append(imports, importPath)
+		}
+	}
+	return imports
+}
+
+// extractPythonImports extracts imported modules from Python files. Names imported
+// from a package are recorded as submodules, since they may be modules themselves.
+func (ii *IncrementalIndexer) extractPythonImports(content string) []string {
+	imports := []string{}
+
+	for _, match := range pythonImportPattern.FindAllStringSubmatch(content, -1) {
+		for _, part := range strings.Split(match[1], ",") {
+			if fields := strings.Fields(part); len(fields) > 0 {
+				imports = append(imports, fields[0])
+			}
+		}
+	}
+
+	for _, match := range pythonFromImportPattern.FindAllStringSubmatch(content, -1) {
+		module := match[1]
+		if strings.Trim(module, ".") != "" {
+			imports = append(imports, module)
+		}
+
+		separator := "."
+		if strings.HasSuffix(module, ".") {
+			separator = ""
+		}
+		for _, part := range strings.Split(match[2], ",") {
+			if fields := strings.Fields(part); len(fields) > 0 && fields[0] != "*" {
+				imports = append(imports, module+separator+fields[0])
+			}
+		}
+	}
+
+	return uniqueStrings(imports)
 }
 
-// extractPythonImports extracts imports from Python files
-func (ii *IncrementalIndexer) extractPythonImports(path string) []string {
-	// Simplified Python import extraction
-	return []string{}
+// extractJSImports extracts ES module imports and CommonJS requires from JavaScript/TypeScript files
+func (ii *IncrementalIndexer) extractJSImports(content string) ([]string, []string) {
+	imports := []string{}
+	for _, match := range jsImportPattern.FindAllStringSubmatch(content, -1) {
+		if match[1] != "" {
+			imports = append(imports, match[1])
+		} else if match[2] != "" {
+			imports = append(imports, match[2])
+		}
+	}
+
+	requires := []string{}
+	for _, match := range jsRequirePattern.FindAllStringSubmatch(content, -1) {
+		requires = append(requires, match[1])
+	}
+
+	return uniqueStrings(imports), uniqueStrings(requires)
 }
 
-// extractJSImports extracts imports from JavaScript/TypeScript files
-func (ii *IncrementalIndexer) extractJSImports(path string) []string {
-	// Simplified JS/TS import extraction
-	return []string{}
+// extractJavaImports extracts imports from Java files
+func (ii *IncrementalIndexer) extractJavaImports(content string) []string {
+	imports := []string{}
+	for _, match := range javaImportPattern.FindAllStringSubmatch(content, -1) {
+		imports = append(imports, match[1])
+	}
+	return uniqueStrings(imports)
+}
+
+// extractRustImports extracts use paths and out-of-line module declarations from Rust files
+func (ii *IncrementalIndexer) extractRustImports(content string) ([]string, []string) {
+	imports := []string{}
+	for _, match := range rustUsePattern.FindAllStringSubmatch(content, -1) {
+		imports = append(imports, strings.TrimSuffix(match[1], "::"))
+	}
+
+	modules := []string{}
+	for _, match := range rustModPattern.FindAllStringSubmatch(content, -1) {
+		modules = append(modules, match[1])
+	}
+
+	return uniqueStrings(imports), uniqueStrings(modules)
 }
 
 // extractCIncludes extracts includes from C/C++ files
-func (ii *IncrementalIndexer) extractCIncludes(path string) []string {
-	// Simplified C/C++ include extraction
-	return []string{}
+func (ii *IncrementalIndexer) extractCIncludes(content string) []string {
+	includes := []string{}
+	for _, match := range cIncludePattern.FindAllStringSubmatch(content, -1) {
+		includes = append(includes, match[1])
+	}
+	return uniqueStrings(includes)
+}
+
+// BuildDependencyGraph analyzes the given files and resolves their imports to other files
+// in the repository. The graph is keyed by slash-separated paths relative to rootPath and
+// replaces any previously built graph.
+func (ii *IncrementalIndexer) BuildDependencyGraph(rootPath string, files []string) {
+	resolver := newDependencyResolver(rootPath, files)
+
+	graph := make(map[string]*FileDependencies, len(files))
+	for _, file := range files {
+		rel, ok := resolver.relativePath(file)
+		if !ok {
+			continue
+		}
+
+		deps := ii.analyzeDependencies(file)
+		deps.Files = resolver.resolve(rel, ii.detectLanguage(file), deps)
+		graph[rel] = deps
+	}
+
+	// Record the reverse edges
+	for rel, deps := range graph {
+		for _, target := range deps.Files {
+			if targetDeps, exists := graph[target]; exists {
+				targetDeps.ReferencedBy = append(targetDeps.ReferencedBy, rel)
+			}
+		}
+	}
+	for _, deps := range graph {
+		sort.Strings(deps.ReferencedBy)
+	}
+
+	ii.mu.Lock()
+	ii.dependencyGraph = graph
+	ii.mu.Unlock()
+}
+
+// GetDependencies returns the dependencies recorded for a repository-relative file path
+func (ii *IncrementalIndexer) GetDependencies(path string) (*FileDependencies, bool) {
+	ii.mu.RLock()
+	defer ii.mu.RUnlock()
+	deps, exists := ii.dependencyGraph[path]
+	return deps, exists
+}
+
+// GetDependents returns the files that directly import any of the given files
+func (ii *IncrementalIndexer) GetDependents(paths []string) []string {
+	ii.mu.RLock()
+	defer ii.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if deps, exists := ii.dependencyGraph[path]; exists {
+			for _, dependent := range deps.ReferencedBy {
+				seen[dependent] = true
+			}
+		}
+	}
+
+	dependents := make([]string, 0, len(seen))
+	for path := range seen {
+		dependents = append(dependents, path)
+	}
+	sort.Strings(dependents)
+	return dependents
+}
+
+// TraverseDependencies walks the graph breadth-first from path, following imports or,
+// when reverse is set, importers. Edges always point from importer to imported file.
+// A maxDepth of 0 or less walks the whole graph.
+func (ii *IncrementalIndexer) TraverseDependencies(path string, reverse bool, maxDepth int) []DependencyEdge {
+	ii.mu.RLock()
+	defer ii.mu.RUnlock()
+
+	var edges []DependencyEdge
+	visited := map[string]bool{path: true}
+	frontier := []string{path}
+
+	for depth := 1; len(frontier) > 0 && (maxDepth <= 0 || depth <= maxDepth); depth++ {
+		var next []string
+		for _, from := range frontier {
+			deps, exists := ii.dependencyGraph[from]
+			if !exists {
+				continue
+			}
+
+			neighbours := deps.Files
+			if reverse {
+				neighbours = deps.ReferencedBy
+			}
+			for _, neighbour := range neighbours {
+				edge := DependencyEdge{From: from, To: neighbour, Depth: depth}
+				if reverse {
+					edge.From, edge.To = neighbour, from
+				}
+				edges = append(edges, edge)
+
+				if !visited[neighbour] {
+					visited[neighbour] = true
+					next = append(next, neighbour)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	return edges
 }
 
 // getDependentFiles returns files that depend on the given files
@@ -472,6 +696,11 @@ func (ii *IncrementalIndexer) getDependentFiles(changedFiles []string) []string
 
 // dependsOn checks if dependencies contain the target file
 func (ii *IncrementalIndexer) dependsOn(deps *FileDependencies, target string) bool {
+	// Check resolved files
+	if ii.containsString(deps.Files, target) {
+		return true
+	}
+
 	// Check imports
 	for _, imp := range deps.Imports {
 		if strings.Contains(imp, target) {
@@ -0,0 +1,152 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+)
+
+// RegistryEntry is one index recorded in the registry
+type RegistryEntry struct {
+	Directory   string    `json:"directory"`
+	IndexPath   string    `json:"index_path"`
+	LastIndexed time.Time `json:"last_indexed"`
+}
+
+// IndexRegistry records every index built by the current user, so indexes can be listed
+// and removed from anywhere. The registry is a JSON file guarded by a lock file, since
+// several index runs may update it at once.
+type IndexRegistry struct {
+	path string
+}
+
+// DefaultRegistryPath returns the registry file in $XDG_DATA_HOME, or ~/.local/share when
+// it is not set, or "" when neither is known
+func DefaultRegistryPath() string {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, "code-search", "indexes.json")
+}
+
+// NewIndexRegistry creates a registry stored at the given path; an empty path disables it
+func NewIndexRegistry(path string) *IndexRegistry {
+	return &IndexRegistry{path: path}
+}
+
+// Path returns the registry file
+func (r *IndexRegistry) Path() string {
+	return r.path
+}
+
+// Entries returns the registered indexes, ordered by directory
+func (r *IndexRegistry) Entries() ([]RegistryEntry, error) {
+	var entries []RegistryEntry
+	err := r.update(func(current []RegistryEntry) []RegistryEntry {
+		entries = current
+		return nil
+	})
+	return entries, err
+}
+
+// Register records an index, replacing any earlier entry for the same index file
+func (r *IndexRegistry) Register(directory, indexPath string) error {
+	return r.update(func(entries []RegistryEntry) []RegistryEntry {
+		var kept []RegistryEntry
+		for _, entry := range entries {
+			if entry.IndexPath != indexPath {
+				kept = append(kept, entry)
+			}
+		}
+		return append(kept, RegistryEntry{
+			Directory:   directory,
+			IndexPath:   indexPath,
+			LastIndexed: time.Now(),
+		})
+	})
+}
+
+// Unregister forgets the given index files
+func (r *IndexRegistry) Unregister(indexPaths ...string) error {
+	forget := make(map[string]bool, len(indexPaths))
+	for _, indexPath := range indexPaths {
+		forget[indexPath] = true
+	}
+
+	return r.update(func(entries []RegistryEntry) []RegistryEntry {
+		kept := []RegistryEntry{}
+		for _, entry := range entries {
+			if !forget[entry.IndexPath] {
+				kept = append(kept, entry)
+			}
+		}
+		return kept
+	})
+}
+
+// update reads the entries under the registry lock and writes back the ones change
+// returns, unless it returns nil
+func (r *IndexRegistry) update(change func([]RegistryEntry) []RegistryEntry) error {
+	if r.path == "" {
+		change(nil)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("failed to create registry directory: %w", err)
+	}
+
+	lockFile, err := os.OpenFile(r.path+".lock", os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open registry lock: %w", err)
+	}
+	defer lockFile.Close()
+	if err := syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("failed to lock registry: %w", err)
+	}
+	defer syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
+
+	var entries []RegistryEntry
+	data, err := os.ReadFile(r.path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read registry: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return fmt.Errorf("failed to parse registry %s: %w", r.path, err)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Directory != entries[j].Directory {
+			return entries[i].Directory < entries[j].Directory
+		}
+		return entries[i].IndexPath < entries[j].IndexPath
+	})
+
+	updated := change(entries)
+	if updated == nil {
+		return nil
+	}
+
+	data, err = json.MarshalIndent(updated, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal registry: %w", err)
+	}
+	tempPath := r.path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write registry: %w", err)
+	}
+	if err := os.Rename(tempPath, r.path); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to write registry: %w", err)
+	}
+	return nil
+}
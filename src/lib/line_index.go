@@ -0,0 +1,92 @@
+package lib
+
+import (
+	"sort"
+	"strings"
+)
+
+// LineIndex maps byte offsets in a text buffer to 1-based line and column positions
+type LineIndex struct {
+	content     string
+	lineOffsets []int // Byte offset of the first character of each line
+}
+
+// NewLineIndex builds a line index for the given content
+func NewLineIndex(content string) *LineIndex {
+	offsets := []int{0}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+
+	return &LineIndex{
+		content:     content,
+		lineOffsets: offsets,
+	}
+}
+
+// LineCount returns the number of lines in the content
+func (li *LineIndex) LineCount() int {
+	return len(li.lineOffsets)
+}
+
+// Position converts a byte offset into a 1-based line and 1-based byte column
+func (li *LineIndex) Position(offset int) (line, column int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(li.content) {
+		offset = len(li.content)
+	}
+
+	// Find the last line starting at or before offset
+	lineIdx := sort.Search(len(li.lineOffsets), func(i int) bool {
+		return li.lineOffsets[i] > offset
+	}) - 1
+
+	return lineIdx + 1, offset - li.lineOffsets[lineIdx] + 1
+}
+
+// LineStart returns the byte offset at which the given 1-based line starts
+func (li *LineIndex) LineStart(line int) int {
+	if line < 1 {
+		return 0
+	}
+	if line > len(li.lineOffsets) {
+		return len(li.content)
+	}
+	return li.lineOffsets[line-1]
+}
+
+// LineText returns the text of a 1-based line without its trailing newline
+func (li *LineIndex) LineText(line int) string {
+	if line < 1 || line > len(li.lineOffsets) {
+		return ""
+	}
+
+	start := li.lineOffsets[line-1]
+	end := len(li.content)
+	if line < len(li.lineOffsets) {
+		end = li.lineOffsets[line] - 1
+	}
+
+	return strings.TrimSuffix(li.content[start:end], "\r")
+}
+
+// LinesText returns the text of an inclusive 1-based line range joined by newlines
+func (li *LineIndex) LinesText(startLine, endLine int) string {
+	if startLine < 1 {
+		startLine = 1
+	}
+	if endLine > len(li.lineOffsets) {
+		endLine = len(li.lineOffsets)
+	}
+
+	lines := make([]string, 0, endLine-startLine+1)
+	for line := startLine; line <= endLine; line++ {
+		lines = append(lines, li.LineText(line))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -8,6 +8,9 @@ import (
 	"time"
 )
 
+// DefaultModelVersion is the version recorded for an embedding model
+const DefaultModelVersion = "1.0.0"
+
 // ModelMetadata stores information about the embedding model used for an index
 type ModelMetadata struct {
 	ModelName      string    `json:"model_name"`
@@ -36,7 +39,7 @@ type IndexMetadata struct {
 func NewModelMetadata(modelName string, vectorDim int) ModelMetadata {
 	return ModelMetadata{
 		ModelName:      modelName,
-		ModelVersion:   "1.0.0",
+		ModelVersion:   DefaultModelVersion,
 		VectorDim:      vectorDim,
 		ModelType:      "semantic",
 		CreatedAt:      time.Now(),
@@ -33,15 +33,27 @@ func NewSimpleCodeParser() *SimpleCodeParser {
 
 // ParseFile parses a file into code chunks
 func (p *SimpleCodeParser) ParseFile(filePath string) ([]models.CodeChunk, error) {
+	chunks, _, err := p.parse(filePath, false)
+	return chunks, err
+}
+
+// ParseFileWithSymbols parses a file into code chunks and builds its symbol table from
+// the same read
+func (p *SimpleCodeParser) ParseFileWithSymbols(filePath string) ([]models.CodeChunk, []models.Symbol, error) {
+	return p.parse(filePath, true)
+}
+
+// parse reads a file and splits it into chunks, extracting its symbols when asked
+func (p *SimpleCodeParser) parse(filePath string, withSymbols bool) ([]models.CodeChunk, []models.Symbol, error) {
 	// Read file content
 	content, err := os.ReadFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
 	contentStr := string(content)
 	if contentStr == "" {
-		return []models.CodeChunk{}, nil
+		return []models.CodeChunk{}, nil, nil
 	}
 
 	// Detect language
@@ -50,7 +62,11 @@ func (p *SimpleCodeParser) ParseFile(filePath string) ([]models.CodeChunk, error
 	// Split content into chunks based on language
 	chunks := p.createChunks(contentStr, language)
 
-	return chunks, nil
+	var symbols []models.Symbol
+	if withSymbols {
+		symbols = ExtractSymbols(filePath, contentStr, language)
+	}
+	return chunks, symbols, nil
 }
 
 // GetEmbedding generates a vector embedding for text
@@ -0,0 +1,366 @@
+package lib
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"code-search/src/models"
+)
+
+// symbolRule recognises a definition on a single line of a brace-delimited language
+type symbolRule struct {
+	pattern   *regexp.Regexp
+	kind      models.SymbolKind
+	container bool // Opens a scope whose name becomes the container of nested symbols
+}
+
+var (
+	pythonDefPattern   = regexp.MustCompile(`^(\s*)(?:async\s+)?def\s+(\w+)\s*\(`)
+	pythonClassPattern = regexp.MustCompile(`^(\s*)class\s+(\w+)`)
+	pythonConstPattern = regexp.MustCompile(`^([A-Z][A-Z0-9_]*)\s*(?::[^=]+)?=`)
+	pythonVarPattern   = regexp.MustCompile(`^([a-z_]\w*)\s*(?::[^=]+)?=[^=]`)
+
+	jsSymbolRules = []symbolRule{
+		{pattern: regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+(\w+)`), kind: models.SymbolKindClass, container: true},
+		{pattern: regexp.MustCompile(`^\s*(?:export\s+)?interface\s+(\w+)`), kind: models.SymbolKindType},
+		{pattern: regexp.MustCompile(`^\s*(?:export\s+)?(?:const\s+)?enum\s+(\w+)`), kind: models.SymbolKindType},
+		{pattern: regexp.MustCompile(`^\s*(?:export\s+)?type\s+(\w+)\s*(?:<[^>]*>)?\s*=`), kind: models.SymbolKindType},
+		{pattern: regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:async\s+)?function\s*\*?\s*(\w+)`), kind: models.SymbolKindFunc},
+		{pattern: regexp.MustCompile(`^\s*(?:export\s+)?(?:const|let|var)\s+(\w+)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:function\b|\([^)]*\)\s*(?::[^=]+)?=>|\w+\s*=>)`), kind: models.SymbolKindFunc},
+		{pattern: regexp.MustCompile(`^(?:export\s+)?const\s+(\w+)`), kind: models.SymbolKindConst},
+		{pattern: regexp.MustCompile(`^(?:export\s+)?(?:let|var)\s+(\w+)`), kind: models.SymbolKindVar},
+		{pattern: regexp.MustCompile(`^\s+(?:(?:public|private|protected|static|async|readonly|get|set)\s+)*(\w+)\s*(?:<[^>]*>)?\([^)]*\)\s*(?::[^{]+)?\{`), kind: models.SymbolKindMethod},
+	}
+
+	// braceSymbolRules holds the line rules of each brace-delimited language
+	braceSymbolRules = map[string][]symbolRule{
+		"JavaScript": jsSymbolRules,
+		"TypeScript": jsSymbolRules,
+		"Java": {
+			{pattern: regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|final|abstract|sealed)\s+)*(?:class|enum|record)\s+(\w+)`), kind: models.SymbolKindClass, container: true},
+			{pattern: regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|sealed)\s+)*(?:@)?interface\s+(\w+)`), kind: models.SymbolKindType, container: true},
+			{pattern: regexp.MustCompile(`^\s*(?:(?:public|private|protected)\s+)?static\s+final\s+[\w<>\[\],\s]+?\s+([A-Z][A-Z0-9_]*)\s*=`), kind: models.SymbolKindConst},
+			{pattern: regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|final|abstract|synchronized|native|default)\s+)*(?:<[^>]+>\s+)?[\w<>\[\],.?]+(?:\s*<[^>]*>)?\s+(\w+)\s*\([^;]*$`), kind: models.SymbolKindMethod},
+		},
+		"Rust": {
+			{pattern: regexp.MustCompile(`^\s*impl(?:\s*<[^>]*>)?\s+(?:[\w:<>, ]+\s+for\s+)?(\w+)`), container: true},
+			{pattern: regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:struct|enum|union)\s+(\w+)`), kind: models.SymbolKindType},
+			{pattern: regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?trait\s+(\w+)`), kind: models.SymbolKindType, container: true},
+			{pattern: regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?type\s+(\w+)`), kind: models.SymbolKindType},
+			{pattern: regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:const|static)\s+(?:mut\s+)?(\w+)`), kind: models.SymbolKindConst},
+			{pattern: regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:(?:async|const|unsafe|extern(?:\s+"[^"]*")?)\s+)*fn\s+(\w+)`), kind: models.SymbolKindFunc},
+		},
+	}
+)
+
+// ExtractSymbols builds the symbol table of a file from content that has already been
+// read. Parsers that have parsed a Go file walk its syntax tree with goSymbols instead.
+func ExtractSymbols(filePath, content, language string) []models.Symbol {
+	var symbols []models.Symbol
+
+	switch language {
+	case "Go":
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, filePath, content, parser.SkipObjectResolution)
+		if err != nil && file == nil {
+			return nil
+		}
+		symbols = goSymbols(fset, file, content)
+	case "Python":
+		symbols = extractPythonSymbols(content)
+	default:
+		rules, ok := braceSymbolRules[language]
+		if !ok {
+			return nil
+		}
+		symbols = extractBraceSymbols(content, rules)
+	}
+
+	return labelSymbols(symbols, filePath, language)
+}
+
+// labelSymbols records the file and language of symbols
+func labelSymbols(symbols []models.Symbol, filePath, language string) []models.Symbol {
+	for i := range symbols {
+		symbols[i].FilePath = filePath
+		symbols[i].Language = language
+	}
+	return symbols
+}
+
+// goSymbols walks the top-level declarations of a parsed Go file
+func goSymbols(fset *token.FileSet, file *ast.File, content string) []models.Symbol {
+	var symbols []models.Symbol
+	source := func(from, to token.Pos) string {
+		start, end := fset.Position(from).Offset, fset.Position(to).Offset
+		if start < 0 || end > len(content) || start >= end {
+			return ""
+		}
+		return strings.Join(strings.Fields(content[start:end]), " ")
+	}
+	span := func(node ast.Node) (int, int) {
+		return fset.Position(node.Pos()).Line, fset.Position(node.End()).Line
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			symbol := models.Symbol{Name: d.Name.Name, Kind: models.SymbolKindFunc}
+			symbol.StartLine, symbol.EndLine = span(d)
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				symbol.Kind = models.SymbolKindMethod
+				symbol.Container = goReceiverTypeName(d.Recv.List[0].Type)
+			}
+
+			signatureEnd := d.End()
+			if d.Body != nil {
+				signatureEnd = d.Body.Lbrace
+			}
+			symbol.Signature = source(d.Pos(), signatureEnd)
+			symbols = append(symbols, symbol)
+
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					symbol := models.Symbol{Name: s.Name.Name, Kind: models.SymbolKindType}
+					symbol.StartLine, symbol.EndLine = span(s)
+					symbol.Signature = "type " + source(s.Pos(), goTypeSignatureEnd(s))
+					symbols = append(symbols, symbol)
+
+				case *ast.ValueSpec:
+					kind := models.SymbolKindVar
+					if d.Tok == token.CONST {
+						kind = models.SymbolKindConst
+					}
+					for _, name := range s.Names {
+						if name.Name == "_" {
+							continue
+						}
+						symbol := models.Symbol{Name: name.Name, Kind: kind}
+						symbol.StartLine, symbol.EndLine = span(s)
+						symbol.Signature = d.Tok.String() + " " + source(s.Pos(), s.End())
+						symbols = append(symbols, symbol)
+					}
+				}
+			}
+		}
+	}
+
+	return symbols
+}
+
+// goReceiverTypeName returns the base type name of a method receiver
+func goReceiverTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return goReceiverTypeName(t.X)
+	case *ast.IndexExpr:
+		return goReceiverTypeName(t.X)
+	case *ast.IndexListExpr:
+		return goReceiverTypeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	default:
+		return ""
+	}
+}
+
+// goTypeSignatureEnd returns where a type's signature ends: before the body of
+// structs and interfaces, after the full type expression otherwise
+func goTypeSignatureEnd(spec *ast.TypeSpec) token.Pos {
+	switch t := spec.Type.(type) {
+	case *ast.StructType:
+		return t.Fields.Opening
+	case *ast.InterfaceType:
+		return t.Methods.Opening
+	default:
+		return spec.End()
+	}
+}
+
+// extractPythonSymbols uses indentation to find definitions and their containers
+func extractPythonSymbols(content string) []models.Symbol {
+	lines := strings.Split(content, "\n")
+
+	type scope struct {
+		name   string
+		indent int
+	}
+	var classes []scope
+	var symbols []models.Symbol
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		for len(classes) > 0 && indent <= classes[len(classes)-1].indent {
+			classes = classes[:len(classes)-1]
+		}
+
+		container := ""
+		if len(classes) > 0 {
+			container = classes[len(classes)-1].name
+		}
+
+		if match := pythonClassPattern.FindStringSubmatch(line); match != nil {
+			symbols = append(symbols, models.Symbol{
+				Name:      match[2],
+				Kind:      models.SymbolKindClass,
+				Container: container,
+				StartLine: i + 1,
+				EndLine:   pythonBlockEnd(lines, i, indent),
+				Signature: strings.TrimSuffix(trimmed, ":"),
+			})
+			classes = append(classes, scope{name: match[2], indent: indent})
+			continue
+		}
+
+		if match := pythonDefPattern.FindStringSubmatch(line); match != nil {
+			kind := models.SymbolKindFunc
+			if container != "" {
+				kind = models.SymbolKindMethod
+			}
+			symbols = append(symbols, models.Symbol{
+				Name:      match[2],
+				Kind:      kind,
+				Container: container,
+				StartLine: i + 1,
+				EndLine:   pythonBlockEnd(lines, i, indent),
+				Signature: strings.TrimSuffix(trimmed, ":"),
+			})
+			continue
+		}
+
+		// Module-level assignments: UPPER_CASE names are constants by convention
+		if indent == 0 {
+			if match := pythonConstPattern.FindStringSubmatch(line); match != nil {
+				symbols = append(symbols, models.Symbol{Name: match[1], Kind: models.SymbolKindConst, StartLine: i + 1, EndLine: i + 1, Signature: trimmed})
+			} else if match := pythonVarPattern.FindStringSubmatch(line); match != nil {
+				symbols = append(symbols, models.Symbol{Name: match[1], Kind: models.SymbolKindVar, StartLine: i + 1, EndLine: i + 1, Signature: trimmed})
+			}
+		}
+	}
+
+	return symbols
+}
+
+// pythonBlockEnd returns the 1-based last line of the block opened at startIdx
+func pythonBlockEnd(lines []string, startIdx, indent int) int {
+	end := startIdx
+	for i := startIdx + 1; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if len(lines[i])-len(strings.TrimLeft(lines[i], " \t")) <= indent {
+			break
+		}
+		end = i
+	}
+	return end + 1
+}
+
+// extractBraceSymbols applies line rules to brace-delimited languages, tracking
+// brace depth to find where definitions end and which scope contains them
+func extractBraceSymbols(content string, rules []symbolRule) []models.Symbol {
+	lines := strings.Split(content, "\n")
+
+	type scope struct {
+		name  string
+		depth int
+	}
+	var scopes []scope
+	var symbols []models.Symbol
+	depth := 0
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "*") || strings.HasPrefix(trimmed, "/*") {
+			continue
+		}
+
+		container, memberDepth := "", false
+		if len(scopes) > 0 {
+			container = scopes[len(scopes)-1].name
+			memberDepth = depth == scopes[len(scopes)-1].depth+1
+		}
+
+		for _, rule := range rules {
+			match := rule.pattern.FindStringSubmatch(line)
+			if match == nil || isControlKeyword(match[1]) {
+				continue
+			}
+
+			// Member rules only apply directly inside a class-like scope
+			if rule.kind == models.SymbolKindMethod && !memberDepth {
+				continue
+			}
+
+			if rule.container {
+				scopes = append(scopes, scope{name: match[1], depth: depth})
+			}
+			if rule.kind == "" {
+				break
+			}
+
+			kind := rule.kind
+			if kind == models.SymbolKindFunc && container != "" {
+				kind = models.SymbolKindMethod
+			}
+
+			symbols = append(symbols, models.Symbol{
+				Name:      match[1],
+				Kind:      kind,
+				Container: container,
+				StartLine: i + 1,
+				EndLine:   braceBlockEnd(lines, i),
+				Signature: strings.TrimSpace(strings.TrimSuffix(trimmed, "{")),
+			})
+			break
+		}
+
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		for len(scopes) > 0 && depth <= scopes[len(scopes)-1].depth && strings.Contains(line, "}") {
+			scopes = scopes[:len(scopes)-1]
+		}
+	}
+
+	return symbols
+}
+
+// braceBlockEnd returns the 1-based line on which the block opened at startIdx closes.
+// Definitions without a block end on their own line.
+func braceBlockEnd(lines []string, startIdx int) int {
+	balance := 0
+	opened := false
+	for i := startIdx; i < len(lines); i++ {
+		balance += strings.Count(lines[i], "{") - strings.Count(lines[i], "}")
+		if strings.Contains(lines[i], "{") {
+			opened = true
+		}
+		if opened && balance <= 0 {
+			return i + 1
+		}
+		if !opened && (strings.HasSuffix(strings.TrimSpace(lines[i]), ";") || i > startIdx+1) {
+			return startIdx + 1
+		}
+	}
+	return startIdx + 1
+}
+
+// isControlKeyword filters out control statements that look like method definitions
+func isControlKeyword(word string) bool {
+	switch word {
+	case "if", "for", "while", "switch", "catch", "return", "new", "else", "do", "try", "synchronized", "function":
+		return true
+	default:
+		return false
+	}
+}
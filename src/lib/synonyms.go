@@ -0,0 +1,159 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SynonymFileName is the project file that extends the built-in synonym dictionary
+const SynonymFileName = ".code-search-synonyms.json"
+
+// builtinSynonyms maps common abbreviations and words in code to their expansions
+var builtinSynonyms = map[string][]string{
+	"arg":    {"argument"},
+	"args":   {"arguments"},
+	"auth":   {"authentication", "authorization", "authenticate", "authorize"},
+	"authn":  {"authentication"},
+	"authz":  {"authorization"},
+	"btn":    {"button"},
+	"buf":    {"buffer"},
+	"calc":   {"calculate"},
+	"cb":     {"callback"},
+	"cfg":    {"config", "configuration"},
+	"cmd":    {"command"},
+	"config": {"configuration"},
+	"conn":   {"connection"},
+	"ctx":    {"context"},
+	"db":     {"database"},
+	"del":    {"delete", "remove"},
+	"delete": {"remove"},
+	"dest":   {"destination"},
+	"dir":    {"directory"},
+	"doc":    {"document"},
+	"dst":    {"destination"},
+	"env":    {"environment"},
+	"err":    {"error"},
+	"evt":    {"event"},
+	"exc":    {"exception"},
+	"i18n":   {"internationalization"},
+	"idx":    {"index"},
+	"img":    {"image"},
+	"impl":   {"implementation"},
+	"info":   {"information"},
+	"k8s":    {"kubernetes"},
+	"len":    {"length"},
+	"lib":    {"library"},
+	"max":    {"maximum"},
+	"mgr":    {"manager"},
+	"min":    {"minimum"},
+	"msg":    {"message"},
+	"num":    {"number"},
+	"obj":    {"object"},
+	"param":  {"parameter"},
+	"params": {"parameters"},
+	"pkg":    {"package"},
+	"prev":   {"previous"},
+	"pwd":    {"password"},
+	"repo":   {"repository"},
+	"req":    {"request"},
+	"res":    {"response", "result"},
+	"resp":   {"response"},
+	"rm":     {"remove", "delete"},
+	"src":    {"source"},
+	"str":    {"string"},
+	"svc":    {"service"},
+	"tmp":    {"temporary", "temp"},
+	"tx":     {"transaction"},
+	"usr":    {"user"},
+	"util":   {"utility"},
+	"val":    {"value"},
+}
+
+// SynonymDictionary expands query terms to their synonyms and abbreviations. Entries are
+// symmetric, so "cfg" expands to "config" and "config" to "cfg", but two expansions of
+// the same abbreviation are not synonyms of each other.
+type SynonymDictionary struct {
+	synonyms map[string][]string
+}
+
+// NewSynonymDictionary creates a dictionary holding the built-in synonyms
+func NewSynonymDictionary() *SynonymDictionary {
+	dict := &SynonymDictionary{
+		synonyms: make(map[string][]string),
+	}
+
+	for term, synonyms := range builtinSynonyms {
+		dict.Add(term, synonyms...)
+	}
+
+	return dict
+}
+
+// LoadSynonymDictionary creates a dictionary of the built-in synonyms extended by the
+// project's synonym file, if the repository has one. The file is a JSON object mapping
+// each term to a list of synonyms, e.g. {"k8s": ["kubernetes"], "tx": ["transaction"]}.
+func LoadSynonymDictionary(repositoryPath string) (*SynonymDictionary, error) {
+	dict := NewSynonymDictionary()
+
+	data, err := os.ReadFile(filepath.Join(repositoryPath, SynonymFileName))
+	if os.IsNotExist(err) {
+		return dict, nil
+	}
+	if err != nil {
+		return dict, fmt.Errorf("failed to read %s: %w", SynonymFileName, err)
+	}
+
+	var entries map[string][]string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return dict, fmt.Errorf("invalid %s: %w", SynonymFileName, err)
+	}
+
+	for term, synonyms := range entries {
+		dict.Add(term, synonyms...)
+	}
+
+	return dict, nil
+}
+
+// Add records synonyms of a term in both directions
+func (sd *SynonymDictionary) Add(term string, synonyms ...string) {
+	term = strings.ToLower(strings.TrimSpace(term))
+	if term == "" {
+		return
+	}
+
+	for _, synonym := range synonyms {
+		synonym = strings.ToLower(strings.TrimSpace(synonym))
+		if synonym == "" || synonym == term {
+			continue
+		}
+		sd.addOneWay(term, synonym)
+		sd.addOneWay(synonym, term)
+	}
+}
+
+// addOneWay records synonym as an expansion of term unless it already is one
+func (sd *SynonymDictionary) addOneWay(term, synonym string) {
+	for _, existing := range sd.synonyms[term] {
+		if existing == synonym {
+			return
+		}
+	}
+	sd.synonyms[term] = append(sd.synonyms[term], synonym)
+}
+
+// Expand returns the synonyms of a term in sorted order, excluding the term itself
+func (sd *SynonymDictionary) Expand(term string) []string {
+	synonyms := append([]string(nil), sd.synonyms[strings.ToLower(term)]...)
+	sort.Strings(synonyms)
+	return synonyms
+}
+
+// Size returns the number of terms with synonyms
+func (sd *SynonymDictionary) Size() int {
+	return len(sd.synonyms)
+}
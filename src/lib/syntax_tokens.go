@@ -0,0 +1,200 @@
+package lib
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tokenClass is the kind of token a byte of source code belongs to
+type tokenClass int
+
+const (
+	tokenPlain tokenClass = iota
+	tokenKeyword
+	tokenString
+	tokenComment
+	tokenNumber
+)
+
+// syntaxRules describes the lexical conventions of a language, enough for light coloring
+type syntaxRules struct {
+	lineComments []string
+	blockComment [2]string
+	quotes       string
+	keywords     map[string]bool
+}
+
+// keywordSet creates a set from a space-separated list of keywords
+func keywordSet(keywords string) map[string]bool {
+	set := make(map[string]bool)
+	for _, keyword := range strings.Fields(keywords) {
+		set[keyword] = true
+	}
+	return set
+}
+
+var (
+	cStyleComments = []string{"//"}
+	hashComments   = []string{"#"}
+
+	goRules = syntaxRules{cStyleComments, [2]string{"/*", "*/"}, "\"'`", keywordSet(
+		"break case chan const continue default defer else fallthrough for func go goto if import " +
+			"interface map package range return select struct switch type var nil true false iota")}
+	pythonRules = syntaxRules{hashComments, [2]string{}, "\"'", keywordSet(
+		"and as assert async await break class continue def del elif else except finally for from " +
+			"global if import in is lambda nonlocal not or pass raise return try while with yield None True False self")}
+	javaScriptRules = syntaxRules{cStyleComments, [2]string{"/*", "*/"}, "\"'`", keywordSet(
+		"async await break case catch class const continue debugger default delete do else export extends " +
+			"finally for function if import in instanceof interface let new of return super switch this throw " +
+			"try type typeof var void while yield null undefined true false")}
+	javaRules = syntaxRules{cStyleComments, [2]string{"/*", "*/"}, "\"'", keywordSet(
+		"abstract boolean break byte case catch char class const continue default do double else enum " +
+			"extends final finally float for if implements import instanceof int interface long new package " +
+			"private protected public return short static super switch this throw throws try void while null true false " +
+			"fun val var override object when")}
+	cRules = syntaxRules{cStyleComments, [2]string{"/*", "*/"}, "\"'", keywordSet(
+		"auto break case char class const continue default delete do double else enum extern float for goto " +
+			"if inline int long namespace new private protected public return short signed sizeof static struct " +
+			"switch template this typedef union unsigned using virtual void volatile while nullptr true false " +
+			"#include #define")}
+	rustRules = syntaxRules{cStyleComments, [2]string{"/*", "*/"}, "\"", keywordSet(
+		"as async await break const continue crate else enum extern fn for if impl in let loop match mod " +
+			"move mut pub ref return self Self static struct super trait type unsafe use where while true false")}
+	rubyRules = syntaxRules{hashComments, [2]string{}, "\"'", keywordSet(
+		"begin class def do else elsif end ensure false if module nil not or rescue return self true unless until when while yield")}
+	shellRules = syntaxRules{hashComments, [2]string{}, "\"'", keywordSet(
+		"case do done elif else esac export fi for function if in local return then until while")}
+	sqlRules = syntaxRules{[]string{"--"}, [2]string{"/*", "*/"}, "'\"", keywordSet(
+		"select from where insert into update delete create table drop alter join left right inner outer on " +
+			"group by order having limit and or not null as values set index primary key " +
+			"SELECT FROM WHERE INSERT INTO UPDATE DELETE CREATE TABLE DROP ALTER JOIN LEFT RIGHT INNER OUTER ON " +
+			"GROUP BY ORDER HAVING LIMIT AND OR NOT NULL AS VALUES SET INDEX PRIMARY KEY")}
+	genericRules = syntaxRules{[]string{"//", "#"}, [2]string{"/*", "*/"}, "\"'", map[string]bool{}}
+)
+
+// rulesForLanguage returns the syntax rules of a language as named by the indexer
+func rulesForLanguage(language string) syntaxRules {
+	switch language {
+	case "Go":
+		return goRules
+	case "Python":
+		return pythonRules
+	case "JavaScript", "TypeScript":
+		return javaScriptRules
+	case "Java", "Kotlin", "Scala", "C#", "Swift":
+		return javaRules
+	case "C", "C++", "C/C++ Header", "C++ Header":
+		return cRules
+	case "Rust":
+		return rustRules
+	case "Ruby":
+		return rubyRules
+	case "Shell", "PowerShell", "YAML":
+		return shellRules
+	case "SQL":
+		return sqlRules
+	default:
+		return genericRules
+	}
+}
+
+// classifyTokens returns the token class of every byte of a line of source code. It works
+// one line at a time, so block comments and strings spanning lines are not recognized
+// past their first line.
+func classifyTokens(line, language string) []tokenClass {
+	rules := rulesForLanguage(language)
+	classes := make([]tokenClass, len(line))
+
+	mark := func(from, to int, class tokenClass) {
+		for i := from; i < to; i++ {
+			classes[i] = class
+		}
+	}
+
+	for i := 0; i < len(line); {
+		rest := line[i:]
+
+		if prefix := commentStart(rest, rules.lineComments); prefix != "" {
+			mark(i, len(line), tokenComment)
+			break
+		}
+
+		if open := rules.blockComment[0]; open != "" && strings.HasPrefix(rest, open) {
+			end := strings.Index(rest[len(open):], rules.blockComment[1])
+			if end < 0 {
+				mark(i, len(line), tokenComment)
+				break
+			}
+			end += len(open) + len(rules.blockComment[1])
+			mark(i, i+end, tokenComment)
+			i += end
+			continue
+		}
+
+		if strings.IndexByte(rules.quotes, line[i]) >= 0 {
+			end := stringEnd(line, i)
+			mark(i, end, tokenString)
+			i = end
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(rest)
+		if unicode.IsDigit(r) {
+			end := i + size
+			for end < len(line) && (isWordByte(line[end]) || line[end] == '.') {
+				end++
+			}
+			mark(i, end, tokenNumber)
+			i = end
+			continue
+		}
+
+		if isWordByte(line[i]) || line[i] == '#' || r >= utf8.RuneSelf {
+			end := i + size
+			for end < len(line) && (isWordByte(line[end]) || line[end] >= utf8.RuneSelf) {
+				end++
+			}
+			if rules.keywords[line[i:end]] {
+				mark(i, end, tokenKeyword)
+			}
+			i = end
+			continue
+		}
+
+		i += size
+	}
+
+	return classes
+}
+
+// commentStart returns the line comment prefix text starts with, if any
+func commentStart(text string, prefixes []string) string {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(text, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// stringEnd returns the index after the string literal starting at start, honoring
+// backslash escapes except in Go raw strings
+func stringEnd(line string, start int) int {
+	quote := line[start]
+	for i := start + 1; i < len(line); i++ {
+		if line[i] == '\\' && quote != '`' {
+			i++
+			continue
+		}
+		if line[i] == quote {
+			return i + 1
+		}
+	}
+	return len(line)
+}
+
+// isWordByte reports whether an ASCII byte can be part of an identifier
+func isWordByte(b byte) bool {
+	return b == '_' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
@@ -0,0 +1,55 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// TerminalState is the saved mode of a terminal, restored with RestoreTerminal
+type TerminalState struct {
+	fd      int
+	termios unix.Termios
+}
+
+// MakeRaw puts a terminal into raw mode, in which input is read key by key without echo
+// and output is written as is. It returns the previous mode.
+func MakeRaw(file *os.File) (*TerminalState, error) {
+	fd := int(file.Fd())
+	termios, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read terminal mode: %w", err)
+	}
+	state := &TerminalState{fd: fd, termios: *termios}
+
+	raw := *termios
+	raw.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	raw.Oflag &^= unix.OPOST
+	raw.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	raw.Cflag &^= unix.CSIZE | unix.PARENB
+	raw.Cflag |= unix.CS8
+	raw.Cc[unix.VMIN] = 1
+	raw.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &raw); err != nil {
+		return nil, fmt.Errorf("failed to set raw terminal mode: %w", err)
+	}
+	return state, nil
+}
+
+// RestoreTerminal returns a terminal to a mode saved by MakeRaw
+func RestoreTerminal(state *TerminalState) error {
+	if err := unix.IoctlSetTermios(state.fd, ioctlSetTermios, &state.termios); err != nil {
+		return fmt.Errorf("failed to restore terminal mode: %w", err)
+	}
+	return nil
+}
+
+// TerminalSize returns the width and height of a terminal in characters
+func TerminalSize(file *os.File) (int, int, error) {
+	size, err := unix.IoctlGetWinsize(int(file.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read terminal size: %w", err)
+	}
+	return int(size.Col), int(size.Row), nil
+}
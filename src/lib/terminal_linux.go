@@ -0,0 +1,9 @@
+package lib
+
+import "golang.org/x/sys/unix"
+
+// Requests reading and writing the terminal mode
+const (
+	ioctlGetTermios = unix.TCGETS
+	ioctlSetTermios = unix.TCSETS
+)
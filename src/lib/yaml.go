@@ -0,0 +1,198 @@
+package lib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// YAMLValue is a scalar or a list of scalars read from a YAML document
+type YAMLValue struct {
+	Scalar string
+	List   []string
+	IsList bool
+	Line   int
+}
+
+// ParseSimpleYAML reads the subset of YAML used by configuration files: nested mappings,
+// scalars, quoted strings, block lists ("- item") and flow lists ("[a, b]"), with
+// comments. The result maps dotted keys, such as "index.exclude", to their values.
+func ParseSimpleYAML(data []byte) (map[string]YAMLValue, error) {
+	type level struct {
+		indent int
+		prefix string
+	}
+
+	values := make(map[string]YAMLValue)
+	var stack []level
+	listKey := ""
+	listIndent := -1
+
+	for number, raw := range strings.Split(string(data), "\n") {
+		line := number + 1
+		text := strings.TrimRight(stripYAMLComment(raw), " \r")
+		if strings.TrimSpace(text) == "" || text == "---" {
+			continue
+		}
+
+		indent := len(text) - len(strings.TrimLeft(text, " "))
+		if strings.HasPrefix(strings.TrimLeft(text, " "), "\t") {
+			return nil, fmt.Errorf("line %d: tabs cannot indent YAML", line)
+		}
+		content := strings.TrimSpace(text)
+
+		// Items of a block list under the last key without a value
+		if content == "-" || strings.HasPrefix(content, "- ") {
+			if listKey == "" || indent < listIndent {
+				return nil, fmt.Errorf("line %d: list item without a key", line)
+			}
+			item, err := parseYAMLScalar(strings.TrimSpace(strings.TrimPrefix(content, "-")), line)
+			if err != nil {
+				return nil, err
+			}
+			value := values[listKey]
+			if !value.IsList {
+				value = YAMLValue{IsList: true, Line: line}
+			}
+			value.List = append(value.List, item)
+			values[listKey] = value
+			continue
+		}
+
+		colon := yamlKeySeparator(content)
+		if colon < 0 {
+			return nil, fmt.Errorf("line %d: expected 'key: value'", line)
+		}
+		key := strings.TrimSpace(content[:colon])
+		if unquoted, err := parseYAMLScalar(key, line); err == nil {
+			key = unquoted
+		}
+		rest := strings.TrimSpace(content[colon+1:])
+
+		for len(stack) > 0 && indent <= stack[len(stack)-1].indent {
+			stack = stack[:len(stack)-1]
+		}
+		fullKey := key
+		if len(stack) > 0 {
+			fullKey = stack[len(stack)-1].prefix + key
+		}
+		if _, exists := values[fullKey]; exists {
+			return nil, fmt.Errorf("line %d: %s is set twice", line, fullKey)
+		}
+
+		if rest == "" {
+			// Either a nested mapping or a block list follows
+			stack = append(stack, level{indent: indent, prefix: fullKey + "."})
+			listKey, listIndent = fullKey, indent
+			continue
+		}
+		listKey = ""
+
+		if strings.HasPrefix(rest, "[") {
+			items, err := parseYAMLFlowList(rest, line)
+			if err != nil {
+				return nil, err
+			}
+			values[fullKey] = YAMLValue{List: items, IsList: true, Line: line}
+			continue
+		}
+
+		scalar, err := parseYAMLScalar(rest, line)
+		if err != nil {
+			return nil, err
+		}
+		values[fullKey] = YAMLValue{Scalar: scalar, Line: line}
+	}
+	return values, nil
+}
+
+// stripYAMLComment removes a comment, which starts at a # outside quotes that begins
+// the line or follows a space
+func stripYAMLComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// yamlKeySeparator returns the index of the colon ending a key, outside quotes
+func yamlKeySeparator(content string) int {
+	var quote rune
+	for i, r := range content {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ':' && (i+1 == len(content) || content[i+1] == ' '):
+			return i
+		}
+	}
+	return -1
+}
+
+// parseYAMLScalar unquotes a scalar
+func parseYAMLScalar(text string, line int) (string, error) {
+	if len(text) >= 2 && text[0] == '"' && text[len(text)-1] == '"' {
+		unquoted, err := strconv.Unquote(text)
+		if err != nil {
+			return "", fmt.Errorf("line %d: invalid quoted string %s", line, text)
+		}
+		return unquoted, nil
+	}
+	if len(text) >= 2 && text[0] == '\'' && text[len(text)-1] == '\'' {
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	}
+	if strings.HasPrefix(text, "\"") || strings.HasPrefix(text, "'") {
+		return "", fmt.Errorf("line %d: unterminated quoted string %s", line, text)
+	}
+	return text, nil
+}
+
+// parseYAMLFlowList reads a list written as [a, "b", c]
+func parseYAMLFlowList(text string, line int) ([]string, error) {
+	if !strings.HasSuffix(text, "]") {
+		return nil, fmt.Errorf("line %d: unterminated list %s", line, text)
+	}
+	inner := strings.TrimSpace(text[1 : len(text)-1])
+	items := []string{}
+	if inner == "" {
+		return items, nil
+	}
+
+	var quote rune
+	start := 0
+	for i, r := range inner + "," {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ',':
+			item, err := parseYAMLScalar(strings.TrimSpace(inner[start:i]), line)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			start = i + 1
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("line %d: unterminated quoted string in %s", line, text)
+	}
+	return items, nil
+}
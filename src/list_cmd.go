@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"code-search/src/lib"
+	"code-search/src/services"
+)
+
+// States of a registered index
+const (
+	indexStateCurrent  = "current"  // Up to date with the files on disk
+	indexStateStale    = "stale"    // Files changed since it was built
+	indexStateMissing  = "missing"  // The index file was removed
+	indexStateOrphaned = "orphaned" // The indexed directory was removed
+)
+
+// ListCommand implements the list command
+type ListCommand struct {
+	fileUtils *lib.FileUtilities
+}
+
+// NewListCommand creates a new list command
+func NewListCommand() *ListCommand {
+	return &ListCommand{
+		fileUtils: lib.NewFileUtilities(),
+	}
+}
+
+// ListOptions contains list command options
+type ListOptions struct {
+	format string
+}
+
+// RegisteredIndex is a registered index with its current state
+type RegisteredIndex struct {
+	Directory   string    `json:"directory"`
+	IndexPath   string    `json:"index_path"`
+	Size        int64     `json:"size"`
+	LastIndexed time.Time `json:"last_indexed"`
+	State       string    `json:"state"`
+	Changes     int       `json:"changes"` // Stale, new and deleted files
+	Locked      bool      `json:"locked"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// Stale reports whether the index is out of date or its index file or directory is gone
+func (r RegisteredIndex) Stale() bool {
+	return r.State != indexStateCurrent
+}
+
+// Execute executes the list command with the given arguments
+func (cmd *ListCommand) Execute(args []string) error {
+	options, err := cmd.parseListOptions(args)
+	if err != nil {
+		return NewInvalidArgumentError("invalid list options", err)
+	}
+
+	registry := lib.NewIndexRegistry(lib.DefaultRegistryPath())
+	indexes, err := registeredIndexes(registry, cmd.fileUtils)
+	if err != nil {
+		return NewGeneralError("failed to read the index registry", err)
+	}
+
+	if options.format == "json" {
+		if indexes == nil {
+			indexes = []RegisteredIndex{}
+		}
+		jsonData, err := json.MarshalIndent(indexes, "", "  ")
+		if err != nil {
+			return NewGeneralError("failed to generate JSON output", err)
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	if len(indexes) == 0 {
+		fmt.Println("No indexes registered. Indexes are recorded when 'code-search index' builds them.")
+		return nil
+	}
+
+	fmt.Printf("%-48s %10s %8s  %s\n", "DIRECTORY", "SIZE", "AGE", "STATE")
+	for _, index := range indexes {
+		age := "-"
+		if !index.LastIndexed.IsZero() {
+			age = formatAge(time.Since(index.LastIndexed))
+		}
+		fmt.Printf("%-48s %10s %8s  %s\n", index.Directory, cmd.fileUtils.FormatBytes(index.Size), age, describeIndexState(index))
+	}
+	return nil
+}
+
+// parseListOptions parses command line options for list
+func (cmd *ListCommand) parseListOptions(args []string) (ListOptions, error) {
+	options := ListOptions{
+		format: "table",
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch arg {
+		case "--format":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--format requires a value", nil)
+			}
+			format := strings.ToLower(args[i+1])
+			if format != "table" && format != "json" {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid format: %s (supported: table, json)", format), nil)
+			}
+			options.format = format
+			i++
+
+		case "--help", "-h":
+			cmd.printListHelp()
+			os.Exit(0)
+
+		default:
+			return options, NewInvalidArgumentError(fmt.Sprintf("unknown option: %s", arg), nil)
+		}
+	}
+
+	return options, nil
+}
+
+// registeredIndexes returns every registered index with its current state
+func registeredIndexes(registry *lib.IndexRegistry, fileUtils *lib.FileUtilities) ([]RegisteredIndex, error) {
+	entries, err := registry.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	var indexes []RegisteredIndex
+	for _, entry := range entries {
+		indexes = append(indexes, inspectIndex(entry, fileUtils))
+	}
+	return indexes, nil
+}
+
+// inspectIndex compares a registered index with the files on disk, using the
+// configuration of its directory
+func inspectIndex(entry lib.RegistryEntry, fileUtils *lib.FileUtilities) RegisteredIndex {
+	index := RegisteredIndex{
+		Directory:   entry.Directory,
+		IndexPath:   entry.IndexPath,
+		LastIndexed: entry.LastIndexed,
+	}
+
+	if !fileUtils.DirectoryExists(entry.Directory) {
+		index.State = indexStateOrphaned
+		return index
+	}
+	info, err := os.Stat(entry.IndexPath)
+	if err != nil {
+		index.State = indexStateMissing
+		return index
+	}
+	index.LastIndexed = info.ModTime()
+	index.Size = indexSize(entry, fileUtils)
+	index.Locked = fileUtils.IsLocked(entry.Directory)
+
+	config, err := services.LoadConfig(entry.Directory)
+	if err != nil {
+		config = services.DefaultConfig()
+	}
+	health, err := newInspectionService(config).GetIndexHealth(entry.IndexPath)
+	if err != nil {
+		index.State = indexStateStale
+		index.Error = err.Error()
+		return index
+	}
+
+	index.Changes = len(health.StaleFiles) + len(health.NewFiles) + len(health.DeletedFiles)
+	index.State = indexStateCurrent
+	if health.NeedsReindex() {
+		index.State = indexStateStale
+	}
+	return index
+}
+
+// indexSize returns the size of an index file and the directory's .clindex
+func indexSize(entry lib.RegistryEntry, fileUtils *lib.FileUtilities) int64 {
+	indexDir := fileUtils.CreateIndexLocation(entry.Directory).IndexDir
+
+	var size int64
+	if info, err := os.Stat(entry.IndexPath); err == nil && filepath.Dir(entry.IndexPath) != indexDir {
+		size += info.Size()
+	}
+	filepath.Walk(indexDir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// describeIndexState describes an index state for the table
+func describeIndexState(index RegisteredIndex) string {
+	state := index.State
+	switch {
+	case index.State == indexStateStale && index.Error != "":
+		state = fmt.Sprintf("stale (%s)", index.Error)
+	case index.State == indexStateStale:
+		state = fmt.Sprintf("stale (%d changed files)", index.Changes)
+	case index.State == indexStateMissing:
+		state = "missing (index file removed)"
+	case index.State == indexStateOrphaned:
+		state = "orphaned (directory removed)"
+	}
+	if index.Locked {
+		state += ", locked"
+	}
+	return state
+}
+
+// printListHelp prints help for the list command
+func (cmd *ListCommand) printListHelp() {
+	fmt.Printf(`Usage: code-search list [options]
+
+Lists every directory indexed by 'code-search index', with the size and age of its
+index and its state:
+
+  current   Up to date with the files on disk
+  stale     Files changed, were added or were deleted since the last index run
+  missing   The index file was removed
+  orphaned  The indexed directory was removed
+
+Indexes are recorded in $XDG_DATA_HOME/code-search/indexes.json
+(~/.local/share/code-search/indexes.json when XDG_DATA_HOME is not set).
+
+Options:
+      --format <format>    Output format: table, json (default: table)
+  -h, --help               Show this help message
+
+Examples:
+  code-search list
+  code-search list --format json
+`)
+}
+
+// GetHelp returns help text for the command
+func (cmd *ListCommand) GetHelp() string {
+	return `list [options] - List every indexed directory
+
+Use 'code-search list --help' for detailed usage information.`
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"code-search/src/lib"
+	"code-search/src/services"
+)
+
+// LSPCommand implements the lsp command
+type LSPCommand struct {
+	fileUtils *lib.FileUtilities
+}
+
+// NewLSPCommand creates a new lsp command
+func NewLSPCommand() *LSPCommand {
+	return &LSPCommand{
+		fileUtils: lib.NewFileUtilities(),
+	}
+}
+
+// LSPOptions contains lsp command options
+type LSPOptions struct {
+	directory string
+	timeout   time.Duration
+}
+
+// Execute executes the lsp command with the given arguments
+func (cmd *LSPCommand) Execute(args []string) error {
+	options, err := cmd.parseLSPOptions(args)
+	if err != nil {
+		return NewInvalidArgumentError("invalid lsp options", err)
+	}
+
+	// Standard output carries the protocol, so anything else printed goes to standard error
+	protocolOut := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = protocolOut }()
+
+	logger := &services.DefaultLogger{}
+	var opened *services.SearchServer
+	// --dir overrides the workspace the editor opens. Otherwise the server moves to the
+	// workspace root, where 'code-search index' would have been run.
+	open := func(root string) (*services.SearchServer, error) {
+		if options.directory == "" && root != "" {
+			if err := os.Chdir(root); err != nil {
+				return nil, fmt.Errorf("failed to open workspace %s: %w", root, err)
+			}
+		}
+		server, err := newSearchServer(cmd.fileUtils, options.directory, options.timeout, logger)
+		if err != nil {
+			return nil, err
+		}
+		opened = server
+		return server, nil
+	}
+	defer func() {
+		if opened != nil {
+			opened.Close()
+		}
+	}()
+
+	symbolService := services.NewSymbolService(lib.NewInMemoryVectorStore(""), &services.SilentLogger{})
+	lspServer := services.NewLSPServer(open, symbolService, Version, logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := lspServer.Serve(ctx, os.Stdin, protocolOut); err != nil && ctx.Err() == nil {
+		return NewGeneralError("language server stopped", err)
+	}
+	return nil
+}
+
+// parseLSPOptions parses command line options for lsp
+func (cmd *LSPCommand) parseLSPOptions(args []string) (LSPOptions, error) {
+	options := LSPOptions{
+		timeout: services.DefaultSearchOptions().Timeout,
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch arg {
+		case "--dir", "-d":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--dir requires a directory path", nil)
+			}
+			options.directory = args[i+1]
+			i++
+
+		case "--timeout":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--timeout requires a duration", nil)
+			}
+			timeout, err := time.ParseDuration(args[i+1])
+			if err != nil || timeout <= 0 {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid timeout: %s (e.g. 10s, 1m)", args[i+1]), nil)
+			}
+			options.timeout = timeout
+			i++
+
+		case "--stdio":
+			// Editors commonly pass --stdio; it is the only transport
+
+		case "--help", "-h":
+			cmd.printLSPHelp()
+			os.Exit(0)
+
+		default:
+			return options, NewInvalidArgumentError(fmt.Sprintf("unknown option: %s", arg), nil)
+		}
+	}
+
+	return options, nil
+}
+
+// printLSPHelp prints help for the lsp command
+func (cmd *LSPCommand) printLSPHelp() {
+	fmt.Printf(`Usage: code-search lsp [options]
+
+Runs a language server on standard input and output for the workspace the editor opens,
+so any editor with LSP support can search the index.
+
+Options:
+  -d, --dir <directory>    Serve this indexed directory instead of the editor's workspace
+      --timeout <d>        Maximum time a search may take (default: 30s)
+      --stdio              Accepted for editors that pass it; stdio is the only transport
+  -h, --help               Show this help message
+
+Features:
+  workspace/symbol         Find definitions from the symbol index
+  codeSearch/search        Custom request running any search type; params are
+                           {"query": "...", "searchType": "hybrid", "maxResults": 10, ...}
+                           and results carry LSP locations
+  textDocument/didSave     Reindexes the saved file and reloads the index
+`)
+}
+
+// GetHelp returns help text for the command
+func (cmd *LSPCommand) GetHelp() string {
+	return `lsp [options] - Run a language server for editors
+
+Use 'code-search lsp --help' for detailed usage information.`
+}
@@ -13,15 +13,19 @@ func main() {
 		var exitCode int = 1 // default error code
 
 		// Check for specific error types
+		quiet := false
 		if cliErr, ok := err.(*CLIError); ok {
 			exitCode = int(cliErr.Code)
+			quiet = cliErr.Quiet
 		} else if IsInvalidArgumentError(err) {
 			exitCode = 2
 		} else if IsNotFoundError(err) {
 			exitCode = 3
 		}
 
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if !quiet {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
 		os.Exit(exitCode)
 	}
 }
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"code-search/src/lib"
+	"code-search/src/services"
+)
+
+// MCPCommand implements the mcp command
+type MCPCommand struct {
+	fileUtils *lib.FileUtilities
+}
+
+// NewMCPCommand creates a new mcp command
+func NewMCPCommand() *MCPCommand {
+	return &MCPCommand{
+		fileUtils: lib.NewFileUtilities(),
+	}
+}
+
+// MCPOptions contains mcp command options
+type MCPOptions struct {
+	directory string
+	timeout   time.Duration
+}
+
+// Execute executes the mcp command with the given arguments
+func (cmd *MCPCommand) Execute(args []string) error {
+	options, err := cmd.parseMCPOptions(args)
+	if err != nil {
+		return NewInvalidArgumentError("invalid mcp options", err)
+	}
+
+	// Standard output carries the protocol, so anything else printed goes to standard error
+	protocolOut := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = protocolOut }()
+
+	logger := &services.DefaultLogger{}
+	server, err := newSearchServer(cmd.fileUtils, options.directory, options.timeout, logger)
+	if err != nil {
+		return err
+	}
+	// Without an index the server still starts, so the assistant can build one with the
+	// reindex tool
+	if err := server.Load(); err != nil {
+		logger.Warn("No index loaded for %s: %v", server.Root(), err)
+	}
+	defer server.Close()
+
+	symbolService := services.NewSymbolService(lib.NewInMemoryVectorStore(""), &services.SilentLogger{})
+	mcpServer := services.NewMCPServer(server, symbolService, Version, logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := mcpServer.Serve(ctx, os.Stdin, protocolOut); err != nil && ctx.Err() == nil {
+		return NewGeneralError("MCP server failed", err)
+	}
+	return nil
+}
+
+// parseMCPOptions parses command line options for mcp
+func (cmd *MCPCommand) parseMCPOptions(args []string) (MCPOptions, error) {
+	options := MCPOptions{
+		timeout: services.DefaultSearchOptions().Timeout,
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch arg {
+		case "--dir", "-d":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--dir requires a directory path", nil)
+			}
+			options.directory = args[i+1]
+			i++
+
+		case "--timeout":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--timeout requires a duration", nil)
+			}
+			timeout, err := time.ParseDuration(args[i+1])
+			if err != nil || timeout <= 0 {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid timeout: %s (e.g. 10s, 1m)", args[i+1]), nil)
+			}
+			options.timeout = timeout
+			i++
+
+		case "--help", "-h":
+			cmd.printMCPHelp()
+			os.Exit(0)
+
+		default:
+			return options, NewInvalidArgumentError(fmt.Sprintf("unknown option: %s", arg), nil)
+		}
+	}
+
+	return options, nil
+}
+
+// printMCPHelp prints help for the mcp command
+func (cmd *MCPCommand) printMCPHelp() {
+	fmt.Printf(`Usage: code-search mcp [options]
+
+Runs a Model Context Protocol server on standard input and output, so that AI coding
+assistants can search the index. The index is loaded once and kept in memory.
+
+Options:
+  -d, --dir <directory>    Specify indexed directory (default: current directory)
+      --timeout <d>        Maximum time a search may take (default: 30s)
+  -h, --help               Show this help message
+
+Tools:
+  search_code        Search with every search type and filter of 'code-search search'
+  get_file_snippet   Read a range of lines from a file in the directory
+  list_symbols       Find definitions by name, prefix, abbreviation or glob
+  index_status       Describe the index and whether files changed since
+  reindex            Incrementally reindex and load the new index
+
+Example assistant configuration:
+  {"mcpServers": {"code-search": {"command": "code-search", "args": ["mcp", "--dir", "/path/to/repo"]}}}
+`)
+}
+
+// GetHelp returns help text for the command
+func (cmd *MCPCommand) GetHelp() string {
+	return `mcp [options] - Run a Model Context Protocol server for AI assistants
+
+Use 'code-search mcp --help' for detailed usage information.`
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 )
@@ -18,8 +19,10 @@ type CodeIndex struct {
 	RepositoryPath string                `json:"repository_path"`
 	LastModified   time.Time             `json:"last_modified"`
 	FileEntries    map[string]*FileEntry `json:"file_entries"`
-	vectorStore    VectorStore           `json:"-"` // Not serialized
-	mu             sync.RWMutex          `json:"-"` // For concurrent access
+	Filters        *IndexFilters         `json:"filters,omitempty"` // Filters the files were chosen with
+	Model          *IndexModel           `json:"model,omitempty"`   // Embedding model the index was built with
+	vectorStore    VectorStore           `json:"-"`                 // Not serialized
+	mu             sync.RWMutex          `json:"-"`                 // For concurrent access
 }
 
 // VectorStore interface for vector database operations
@@ -192,6 +195,24 @@ func (ci *CodeIndex) GetAllFiles() []*FileEntry {
 	return files
 }
 
+// FileIndex returns an index holding only the given entry of this index. It has no
+// vector store, so it can only serve lexical searches.
+func (ci *CodeIndex) FileIndex(entry *FileEntry) *CodeIndex {
+	ci.mu.RLock()
+	defer ci.mu.RUnlock()
+
+	fileIndex := NewCodeIndex(ci.RepositoryPath, nil)
+	fileIndex.ID = ci.ID
+	fileIndex.LastModified = ci.LastModified
+	for relativePath, existing := range ci.FileEntries {
+		if existing == entry {
+			fileIndex.FileEntries[relativePath] = entry
+		}
+	}
+
+	return fileIndex
+}
+
 // GetStats returns statistics about the index
 func (ci *CodeIndex) GetStats() IndexStats {
 	ci.mu.RLock()
@@ -272,6 +293,41 @@ func (ci *CodeIndex) ShouldReindex() (bool, error) {
 	return false, nil
 }
 
+// ChangedFiles compares the indexed files with the disk, returning the files whose
+// content changed since they were indexed and the files that no longer exist
+func (ci *CodeIndex) ChangedFiles() ([]string, []string, error) {
+	ci.mu.RLock()
+	defer ci.mu.RUnlock()
+
+	var changed, deleted []string
+	for _, entry := range ci.FileEntries {
+		fileInfo, err := os.Stat(entry.FilePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				deleted = append(deleted, entry.FilePath)
+				continue
+			}
+			return nil, nil, fmt.Errorf("failed to stat file %s: %w", entry.FilePath, err)
+		}
+
+		// Only files touched since indexing are hashed
+		if !fileInfo.ModTime().After(entry.LastModified) {
+			continue
+		}
+		currentHash, err := calculateFileHash(entry.FilePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to calculate file hash: %w", err)
+		}
+		if currentHash != entry.ContentHash {
+			changed = append(changed, entry.FilePath)
+		}
+	}
+
+	sort.Strings(changed)
+	sort.Strings(deleted)
+	return changed, deleted, nil
+}
+
 // Close closes the index and releases resources
 func (ci *CodeIndex) Close() error {
 	ci.mu.Lock()
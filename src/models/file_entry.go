@@ -16,6 +16,8 @@ type FileEntry struct {
 	LastModified time.Time   `json:"last_modified"`
 	ContentHash  string      `json:"content_hash"`
 	Chunks       []CodeChunk `json:"chunks"`
+	Symbols      []Symbol    `json:"symbols,omitempty"`
+	References   []Reference `json:"references,omitempty"`
 	Size         int64       `json:"size"`
 	Language     string      `json:"language"`
 }
@@ -55,6 +57,17 @@ func (fe *FileEntry) AddChunk(chunk CodeChunk) {
 	fe.Chunks = append(fe.Chunks, chunk)
 }
 
+// GetSymbolsDefinedAt returns the symbols whose definition starts on the given line
+func (fe *FileEntry) GetSymbolsDefinedAt(line int) []Symbol {
+	var symbols []Symbol
+	for _, symbol := range fe.Symbols {
+		if symbol.StartLine == line {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols
+}
+
 // GetContent reads and returns the file content
 func (fe *FileEntry) GetContent() (string, error) {
 	file, err := os.Open(fe.FilePath)
@@ -71,6 +84,65 @@ func (fe *FileEntry) GetContent() (string, error) {
 	return string(content), nil
 }
 
+// GetContextLines returns up to before lines preceding startLine and after lines following
+// endLine. The lines come from the chunks stored in the index when they cover the range,
+// which keeps context consistent with the indexed line numbers; otherwise the file is read.
+func (fe *FileEntry) GetContextLines(startLine, endLine, before, after int) ([]ContextLine, []ContextLine, error) {
+	if before <= 0 && after <= 0 {
+		return nil, nil, nil
+	}
+
+	stored := fe.storedLines()
+	first := startLine - before
+	if first < 1 {
+		first = 1
+	}
+	covered := true
+	for line := first; line <= endLine+after; line++ {
+		if _, ok := stored[line]; !ok {
+			covered = false
+			break
+		}
+	}
+
+	if covered {
+		var beforeLines, afterLines []ContextLine
+		for line := first; line < startLine; line++ {
+			beforeLines = append(beforeLines, ContextLine{Line: line, Text: stored[line]})
+		}
+		for line := endLine + 1; line <= endLine+after; line++ {
+			afterLines = append(afterLines, ContextLine{Line: line, Text: stored[line]})
+		}
+		return beforeLines, afterLines, nil
+	}
+
+	content, err := fe.GetContent()
+	if err != nil {
+		return nil, nil, err
+	}
+	// A trailing newline ends the last line rather than starting another
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	beforeLines, afterLines := ContextFromLines(lines, startLine, endLine, before, after)
+	return beforeLines, afterLines, nil
+}
+
+// storedLines maps line numbers to the text stored in the file's chunks. Chunk content is
+// trimmed when indexed, so chunks whose line count no longer matches their range are
+// skipped, as is the first line of each chunk, which may have lost its indentation.
+func (fe *FileEntry) storedLines() map[int]string {
+	lines := make(map[int]string)
+	for _, chunk := range fe.Chunks {
+		chunkLines := strings.Split(chunk.Content, "\n")
+		if len(chunkLines) != chunk.EndLine-chunk.StartLine+1 {
+			continue
+		}
+		for i := 1; i < len(chunkLines); i++ {
+			lines[chunk.StartLine+i] = chunkLines[i]
+		}
+	}
+	return lines
+}
+
 // GetLineCount returns the number of lines in the file
 func (fe *FileEntry) GetLineCount() (int, error) {
 	content, err := fe.GetContent()
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
 	"path/filepath"
 	"time"
 )
@@ -32,14 +33,21 @@ type DirectoryLimits struct {
 
 // DirectoryMetadata represents directory information
 type DirectoryMetadata struct {
-	FileCount    int64     `json:"file_count"`
-	TotalSize    int64     `json:"total_size"`
-	LastIndexed  time.Time `json:"last_indexed"`
-	IndexVersion string    `json:"index_version"`
-	CreatedAt    time.Time `json:"created_at"`
-	ModifiedAt   time.Time `json:"modified_at"`
-	ScanDuration string    `json:"scan_duration,omitempty"`
-	MemoryUsed   float64   `json:"memory_used_mb,omitempty"`
+	FileCount    int64       `json:"file_count"`
+	TotalSize    int64       `json:"total_size"`
+	LastIndexed  time.Time   `json:"last_indexed"`
+	IndexVersion string      `json:"index_version"`
+	CreatedAt    time.Time   `json:"created_at"`
+	ModifiedAt   time.Time   `json:"modified_at"`
+	ScanDuration string      `json:"scan_duration,omitempty"`
+	MemoryUsed   float64     `json:"memory_used_mb,omitempty"`
+	Model        *IndexModel `json:"model,omitempty"`
+}
+
+// IndexModel names the embedding model an index was built with
+type IndexModel struct {
+	ModelName    string `json:"model_name"`
+	ModelVersion string `json:"model_version"`
 }
 
 // IndexLocation represents information about where index files are stored
@@ -120,6 +128,8 @@ func (dm *DirectoryMetadata) MarkIndexed() {
 // IndexingOptions contains options for the indexing process
 type IndexingOptions struct {
 	IncludeHidden     bool          `json:"include_hidden"`
+	IncludePatterns   []string      `json:"include_patterns,omitempty"`
+	NoIgnore          bool          `json:"no_ignore"`
 	FileTypes         []string      `json:"file_types"`
 	ExcludePatterns   []string      `json:"exclude_patterns"`
 	MaxFileSize       int64         `json:"max_file_size"`
@@ -128,6 +138,79 @@ type IndexingOptions struct {
 	MaxConcurrency    int           `json:"max_concurrency"`
 	Timeout           time.Duration `json:"timeout"`
 	EnableIncremental bool          `json:"enable_incremental"`
+	EmbeddingModel    string        `json:"embedding_model,omitempty"`
+	// FilterOverrides names the filters chosen for this run, by their IndexFilters JSON
+	// names; an incremental run takes the others from the existing index
+	FilterOverrides []string `json:"-"`
+}
+
+// IndexFilters are the indexing options that decide which files an index covers
+type IndexFilters struct {
+	IncludeHidden   bool     `json:"include_hidden"`
+	IncludePatterns []string `json:"include_patterns"`
+	FileTypes       []string `json:"file_types"`
+	ExcludePatterns []string `json:"exclude_patterns"`
+	MaxFileSize     int64    `json:"max_file_size"`
+	NoIgnore        bool     `json:"no_ignore"`
+}
+
+// Filters returns the file filters of the options
+func (o IndexingOptions) Filters() IndexFilters {
+	return IndexFilters{
+		IncludeHidden:   o.IncludeHidden,
+		IncludePatterns: append([]string{}, o.IncludePatterns...),
+		FileTypes:       append([]string{}, o.FileTypes...),
+		ExcludePatterns: append([]string{}, o.ExcludePatterns...),
+		MaxFileSize:     o.MaxFileSize,
+		NoIgnore:        o.NoIgnore,
+	}
+}
+
+// WithStoredFilters returns the options with each filter that is not overridden taken
+// from filters stored with an existing index
+func (o IndexingOptions) WithStoredFilters(stored IndexFilters) IndexingOptions {
+	overridden := make(map[string]bool, len(o.FilterOverrides))
+	for _, name := range o.FilterOverrides {
+		overridden[name] = true
+	}
+
+	if !overridden["include_hidden"] {
+		o.IncludeHidden = stored.IncludeHidden
+	}
+	if !overridden["include_patterns"] {
+		o.IncludePatterns = append([]string{}, stored.IncludePatterns...)
+	}
+	if !overridden["file_types"] {
+		o.FileTypes = append([]string{}, stored.FileTypes...)
+	}
+	if !overridden["exclude_patterns"] {
+		o.ExcludePatterns = append([]string{}, stored.ExcludePatterns...)
+	}
+	if !overridden["max_file_size"] {
+		o.MaxFileSize = stored.MaxFileSize
+	}
+	if !overridden["no_ignore"] {
+		o.NoIgnore = stored.NoIgnore
+	}
+	return o
+}
+
+// Changes describes each filter that differs from another set of filters
+func (f IndexFilters) Changes(other IndexFilters) []string {
+	var changes []string
+	describe := func(name string, from, to interface{}) {
+		if fmt.Sprint(from) != fmt.Sprint(to) {
+			changes = append(changes, fmt.Sprintf("%s %v -> %v", name, from, to))
+		}
+	}
+
+	describe("include_hidden", f.IncludeHidden, other.IncludeHidden)
+	describe("include_patterns", f.IncludePatterns, other.IncludePatterns)
+	describe("file_types", f.FileTypes, other.FileTypes)
+	describe("exclude_patterns", f.ExcludePatterns, other.ExcludePatterns)
+	describe("max_file_size", f.MaxFileSize, other.MaxFileSize)
+	describe("no_ignore", f.NoIgnore, other.NoIgnore)
+	return changes
 }
 
 // FileStats contains file statistics
@@ -0,0 +1,622 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// QueryNodeType represents the kind of a node in a parsed query expression
+type QueryNodeType string
+
+const (
+	QueryNodeTerm   QueryNodeType = "term"   // Bare word
+	QueryNodePhrase QueryNodeType = "phrase" // Quoted exact phrase
+	QueryNodeAnd    QueryNodeType = "and"    // All children must match
+	QueryNodeOr     QueryNodeType = "or"     // Any child must match
+	QueryNodeNot    QueryNodeType = "not"    // Single child must not match
+
+	queryNodeField QueryNodeType = "field" // Field filter; removed from the tree after parsing
+)
+
+// Query fields that compile into SearchQuery filters
+const (
+	queryFieldLanguage = "lang"
+	queryFieldPath     = "path"
+	queryFieldSymbol   = "sym"
+	queryFieldRepo     = "repo"
+)
+
+// QueryNode is a node in a parsed boolean query expression
+type QueryNode struct {
+	Type     QueryNodeType `json:"type"`
+	Value    string        `json:"value,omitempty"`
+	Children []*QueryNode  `json:"children,omitempty"`
+	Column   int           `json:"column"` // 1-based column in the raw query
+}
+
+// QueryParseError reports a syntax error in a structured query
+type QueryParseError struct {
+	Query   string
+	Column  int // 1-based column of the offending character
+	Message string
+}
+
+func (e *QueryParseError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Message)
+}
+
+// Matches evaluates the expression, using match to decide whether a term or phrase matches
+func (n *QueryNode) Matches(match func(value string) bool) bool {
+	switch n.Type {
+	case QueryNodeTerm, QueryNodePhrase:
+		return match(n.Value)
+	case QueryNodeAnd:
+		for _, child := range n.Children {
+			if !child.Matches(match) {
+				return false
+			}
+		}
+		return true
+	case QueryNodeOr:
+		for _, child := range n.Children {
+			if child.Matches(match) {
+				return true
+			}
+		}
+		return false
+	case QueryNodeNot:
+		return !n.Children[0].Matches(match)
+	default:
+		return false
+	}
+}
+
+// PositiveValues returns the terms and phrases that are not negated, in query order
+func (n *QueryNode) PositiveValues() []string {
+	return n.collectValues(false)
+}
+
+// NegativeValues returns the terms and phrases under an odd number of negations
+func (n *QueryNode) NegativeValues() []string {
+	return n.collectValues(true)
+}
+
+// collectValues gathers leaf values whose negation parity equals negated
+func (n *QueryNode) collectValues(negated bool) []string {
+	var values []string
+	var walk func(node *QueryNode, parity bool)
+	walk = func(node *QueryNode, parity bool) {
+		switch node.Type {
+		case QueryNodeTerm, QueryNodePhrase:
+			if parity == negated {
+				values = append(values, node.Value)
+			}
+		case QueryNodeNot:
+			walk(node.Children[0], !parity)
+		default:
+			for _, child := range node.Children {
+				walk(child, parity)
+			}
+		}
+	}
+	walk(n, false)
+	return values
+}
+
+// ParseStructuredQuery parses field filters and boolean syntax in QueryText, e.g.
+// `lang:go path:internal/ -path:_test.go sym:Handler repo:api "exact phrase" (retry OR backoff) -deprecated`.
+// Filters are moved into the query's filter fields and QueryText is replaced by the
+// positive search terms. Boolean syntax is kept in Expression only when the query uses
+// it, so plain queries behave as before: parentheses only group when the query uses OR or
+// negates a group, and a "-" that does not start a negated term, as in "1 - 2" or "-1",
+// is searched for like any other word. Regex and exact queries only have field filters
+// extracted; the remainder is used verbatim as the pattern or phrase.
+// Errors are returned as *QueryParseError. Parsing an already parsed query is a no-op.
+func (sq *SearchQuery) ParseStructuredQuery() error {
+	if sq.RawQuery != "" {
+		return nil
+	}
+
+	raw := sq.QueryText
+	var err error
+	if sq.SearchType == SearchTypeRegex || sq.SearchType == SearchTypeExact {
+		err = sq.parseFieldFilters(raw)
+	} else {
+		err = sq.parseExpression(raw)
+	}
+	if err != nil {
+		return err
+	}
+
+	sq.RawQuery = raw
+	return nil
+}
+
+// parseExpression parses the full structured syntax
+func (sq *SearchQuery) parseExpression(raw string) error {
+	tokens, err := tokenizeQuery(raw, true)
+	if err != nil {
+		return err
+	}
+
+	// Parentheses in plain text, as in "if (err != nil)", are part of the words
+	if !usesGrouping(tokens) {
+		if tokens, err = tokenizeQuery(raw, false); err != nil {
+			return err
+		}
+	}
+
+	parser := &queryParser{raw: raw, tokens: tokens}
+	root, err := parser.parse()
+	if err != nil {
+		return err
+	}
+
+	expression, err := sq.extractFilters(raw, root)
+	if err != nil {
+		return err
+	}
+
+	if expression == nil {
+		return &QueryParseError{Query: raw, Column: 1, Message: "query must contain at least one search term"}
+	}
+
+	positive := expression.PositiveValues()
+	if len(positive) == 0 {
+		return &QueryParseError{Query: raw, Column: expression.Column, Message: "query must contain at least one term that is not negated"}
+	}
+
+	// Semantic search embeds the positive terms as one text, so it cannot weigh alternatives
+	if or := findNodeOfType(expression, QueryNodeOr); or != nil && sq.SearchType == SearchTypeSemantic {
+		return &QueryParseError{Query: raw, Column: or.Column, Message: "OR is not supported by semantic search; use text, hybrid or fuzzy search"}
+	}
+
+	sq.QueryText = strings.Join(positive, " ")
+	if usesBooleanSyntax(expression) {
+		sq.Expression = expression
+	}
+
+	return nil
+}
+
+// parseFieldFilters extracts whitespace-separated field filters, keeping the rest verbatim
+func (sq *SearchQuery) parseFieldFilters(raw string) error {
+	runes := []rune(raw)
+	var pattern strings.Builder
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			pattern.WriteRune(runes[i])
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(runes) && !unicode.IsSpace(runes[i]) {
+			i++
+		}
+		word := string(runes[start:i])
+
+		negated := strings.HasPrefix(word, "-")
+		name, value, isField := splitQueryField(strings.TrimPrefix(word, "-"))
+		if !isField {
+			pattern.WriteString(word)
+			continue
+		}
+
+		column := start + 1
+		if value == "" {
+			return &QueryParseError{Query: raw, Column: column, Message: fmt.Sprintf("%s: requires a value", name)}
+		}
+		if err := sq.applyFilter(raw, column, name, value, negated); err != nil {
+			return err
+		}
+
+		// Drop the whitespace following a filter
+		for i < len(runes) && unicode.IsSpace(runes[i]) {
+			i++
+		}
+	}
+
+	sq.QueryText = strings.TrimSpace(pattern.String())
+	if sq.QueryText == "" {
+		return &QueryParseError{Query: raw, Column: 1, Message: "query must contain at least one search term"}
+	}
+
+	return nil
+}
+
+// extractFilters removes field nodes from the top level of the expression and applies them
+func (sq *SearchQuery) extractFilters(raw string, root *QueryNode) (*QueryNode, error) {
+	if root == nil {
+		return nil, nil
+	}
+
+	children := []*QueryNode{root}
+	if root.Type == QueryNodeAnd {
+		children = root.Children
+	}
+
+	var remaining []*QueryNode
+	for _, child := range children {
+		field, negated := child, false
+		if child.Type == QueryNodeNot && child.Children[0].Type == queryNodeField {
+			field, negated = child.Children[0], true
+		}
+
+		if field.Type != queryNodeField {
+			if nested := findFieldNode(child); nested != nil {
+				return nil, &QueryParseError{Query: raw, Column: nested.Column, Message: "field filters cannot be used inside groups or with OR"}
+			}
+			remaining = append(remaining, child)
+			continue
+		}
+
+		name, value, _ := splitQueryField(field.Value)
+		if err := sq.applyFilter(raw, field.Column, name, value, negated); err != nil {
+			return nil, err
+		}
+	}
+
+	switch len(remaining) {
+	case 0:
+		return nil, nil
+	case 1:
+		return remaining[0], nil
+	default:
+		return &QueryNode{Type: QueryNodeAnd, Children: remaining, Column: remaining[0].Column}, nil
+	}
+}
+
+// applyFilter stores a single field filter on the query
+func (sq *SearchQuery) applyFilter(raw string, column int, name, value string, negated bool) error {
+	if negated && name != queryFieldPath && name != queryFieldRepo {
+		return &QueryParseError{Query: raw, Column: column, Message: fmt.Sprintf("%s: filters cannot be negated", name)}
+	}
+
+	switch name {
+	case queryFieldLanguage:
+		if sq.LanguageFilter != "" && !strings.EqualFold(sq.LanguageFilter, value) {
+			return &QueryParseError{Query: raw, Column: column, Message: "only one lang: filter is allowed"}
+		}
+		sq.LanguageFilter = value
+	case queryFieldPath:
+		if negated {
+			sq.ExcludePaths = append(sq.ExcludePaths, value)
+		} else {
+			sq.PathFilters = append(sq.PathFilters, value)
+		}
+	case queryFieldSymbol:
+		sq.SymbolFilters = append(sq.SymbolFilters, value)
+	case queryFieldRepo:
+		if negated {
+			sq.ExcludeRepos = append(sq.ExcludeRepos, value)
+		} else {
+			sq.RepoFilters = append(sq.RepoFilters, value)
+		}
+	}
+
+	return nil
+}
+
+// findFieldNode returns the first field node in a subtree
+func findFieldNode(node *QueryNode) *QueryNode {
+	return findNodeOfType(node, queryNodeField)
+}
+
+// findNodeOfType returns the first node of the given type in a subtree
+func findNodeOfType(node *QueryNode, nodeType QueryNodeType) *QueryNode {
+	if node.Type == nodeType {
+		return node
+	}
+	for _, child := range node.Children {
+		if found := findNodeOfType(child, nodeType); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// usesBooleanSyntax reports whether an expression needs boolean evaluation rather than
+// being a plain list of words
+func usesBooleanSyntax(node *QueryNode) bool {
+	if node.Type == QueryNodeTerm {
+		return false
+	}
+	if node.Type != QueryNodeAnd {
+		return true
+	}
+	for _, child := range node.Children {
+		if child.Type != QueryNodeTerm {
+			return true
+		}
+	}
+	return false
+}
+
+// splitQueryField splits a known field filter such as "lang:go" into name and value
+func splitQueryField(word string) (name, value string, ok bool) {
+	idx := strings.Index(word, ":")
+	if idx <= 0 {
+		return "", "", false
+	}
+
+	name = word[:idx]
+	switch name {
+	case queryFieldLanguage, queryFieldPath, queryFieldSymbol, queryFieldRepo:
+		return name, strings.Trim(word[idx+1:], `"`), true
+	default:
+		return "", "", false
+	}
+}
+
+// queryTokenType represents the kind of a lexical query token
+type queryTokenType int
+
+const (
+	queryTokenWord queryTokenType = iota
+	queryTokenPhrase
+	queryTokenField
+	queryTokenOr
+	queryTokenNot
+	queryTokenOpen
+	queryTokenClose
+)
+
+// queryToken is a lexical token with its 1-based column
+type queryToken struct {
+	typ    queryTokenType
+	value  string
+	column int
+}
+
+// usesGrouping reports whether a query uses OR or negates a group, the only cases in
+// which its parentheses group terms
+func usesGrouping(tokens []queryToken) bool {
+	for i, token := range tokens {
+		if token.typ == queryTokenOr {
+			return true
+		}
+		if token.typ == queryTokenNot && i+1 < len(tokens) && tokens[i+1].typ == queryTokenOpen {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenizeQuery splits a structured query into tokens. Without groups, parentheses are
+// part of the words they are in.
+func tokenizeQuery(raw string, groups bool) ([]queryToken, error) {
+	runes := []rune(raw)
+	var tokens []queryToken
+
+	isDelimiter := func(r rune) bool {
+		return unicode.IsSpace(r) || r == '"' || (groups && (r == '(' || r == ')'))
+	}
+
+	// A "-" negates only what can be searched for: a word, a phrase or a group
+	startsNegation := func(i int) bool {
+		if i+1 >= len(runes) || (i > 0 && !isDelimiter(runes[i-1])) {
+			return false
+		}
+		next := runes[i+1]
+		return unicode.IsLetter(next) || next == '_' || next == '"' || (groups && next == '(')
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		column := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case groups && r == '(':
+			tokens = append(tokens, queryToken{typ: queryTokenOpen, column: column})
+			i++
+
+		case groups && r == ')':
+			tokens = append(tokens, queryToken{typ: queryTokenClose, column: column})
+			i++
+
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, &QueryParseError{Query: raw, Column: column, Message: "unterminated quoted phrase"}
+			}
+			phrase := string(runes[i+1 : end])
+			if strings.TrimSpace(phrase) == "" {
+				return nil, &QueryParseError{Query: raw, Column: column, Message: "empty quoted phrase"}
+			}
+			tokens = append(tokens, queryToken{typ: queryTokenPhrase, value: phrase, column: column})
+			i = end + 1
+
+		case r == '-' && startsNegation(i):
+			tokens = append(tokens, queryToken{typ: queryTokenNot, column: column})
+			i++
+
+		default:
+			// Parentheses inside a word, as in "func(ctx)", belong to the word
+			end, depth := i, 0
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '"' {
+				if !groups {
+					end++
+					continue
+				}
+				if runes[end] == '(' {
+					depth++
+				} else if runes[end] == ')' {
+					if depth == 0 {
+						break
+					}
+					depth--
+				}
+				end++
+			}
+			word := string(runes[i:end])
+
+			// Field values may be quoted, e.g. path:"my dir/"
+			if strings.HasSuffix(word, ":") && end < len(runes) && runes[end] == '"' {
+				if _, _, ok := splitQueryField(word); ok {
+					closing := end + 1
+					for closing < len(runes) && runes[closing] != '"' {
+						closing++
+					}
+					if closing >= len(runes) {
+						return nil, &QueryParseError{Query: raw, Column: end + 1, Message: "unterminated quoted phrase"}
+					}
+					word += string(runes[end+1 : closing])
+					end = closing + 1
+				}
+			}
+
+			if name, value, ok := splitQueryField(word); ok {
+				if value == "" {
+					return nil, &QueryParseError{Query: raw, Column: column, Message: fmt.Sprintf("%s: requires a value", name)}
+				}
+				tokens = append(tokens, queryToken{typ: queryTokenField, value: name + ":" + value, column: column})
+			} else if word == "OR" {
+				tokens = append(tokens, queryToken{typ: queryTokenOr, column: column})
+			} else {
+				tokens = append(tokens, queryToken{typ: queryTokenWord, value: word, column: column})
+			}
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+// queryParser is a recursive descent parser over query tokens:
+//
+//	expr    := and ("OR" and)*
+//	and     := unary+
+//	unary   := "-" unary | primary
+//	primary := "(" expr ")" | word | phrase | field
+type queryParser struct {
+	raw    string
+	tokens []queryToken
+	pos    int
+}
+
+// parse parses the whole token stream
+func (p *queryParser) parse() (*QueryNode, error) {
+	if len(p.tokens) == 0 {
+		return nil, &QueryParseError{Query: p.raw, Column: 1, Message: "query is empty"}
+	}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		token := p.tokens[p.pos]
+		return nil, &QueryParseError{Query: p.raw, Column: token.column, Message: "unmatched ')'"}
+	}
+
+	return node, nil
+}
+
+// parseOr parses a sequence of AND groups separated by OR
+func (p *queryParser) parseOr() (*QueryNode, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	children := []*QueryNode{first}
+	for p.pos < len(p.tokens) && p.tokens[p.pos].typ == queryTokenOr {
+		p.pos++
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, next)
+	}
+
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &QueryNode{Type: QueryNodeOr, Children: children, Column: first.Column}, nil
+}
+
+// parseAnd parses one or more adjacent unary expressions
+func (p *queryParser) parseAnd() (*QueryNode, error) {
+	var children []*QueryNode
+	for p.pos < len(p.tokens) {
+		typ := p.tokens[p.pos].typ
+		if typ == queryTokenOr || typ == queryTokenClose {
+			break
+		}
+
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, node)
+	}
+
+	if len(children) == 0 {
+		column := len([]rune(p.raw)) + 1
+		if p.pos < len(p.tokens) {
+			column = p.tokens[p.pos].column
+		}
+		return nil, &QueryParseError{Query: p.raw, Column: column, Message: "expected a search term"}
+	}
+
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return &QueryNode{Type: QueryNodeAnd, Children: children, Column: children[0].Column}, nil
+}
+
+// parseUnary parses an optionally negated primary expression
+func (p *queryParser) parseUnary() (*QueryNode, error) {
+	token := p.tokens[p.pos]
+	if token.typ != queryTokenNot {
+		return p.parsePrimary()
+	}
+
+	p.pos++
+	if p.pos >= len(p.tokens) {
+		return nil, &QueryParseError{Query: p.raw, Column: token.column, Message: "negation must be followed by a term"}
+	}
+
+	child, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &QueryNode{Type: QueryNodeNot, Children: []*QueryNode{child}, Column: token.column}, nil
+}
+
+// parsePrimary parses a group, word, phrase or field filter
+func (p *queryParser) parsePrimary() (*QueryNode, error) {
+	token := p.tokens[p.pos]
+	p.pos++
+
+	switch token.typ {
+	case queryTokenWord:
+		return &QueryNode{Type: QueryNodeTerm, Value: token.value, Column: token.column}, nil
+	case queryTokenPhrase:
+		return &QueryNode{Type: QueryNodePhrase, Value: token.value, Column: token.column}, nil
+	case queryTokenField:
+		return &QueryNode{Type: queryNodeField, Value: token.value, Column: token.column}, nil
+	case queryTokenOpen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos].typ != queryTokenClose {
+			return nil, &QueryParseError{Query: p.raw, Column: token.column, Message: "unclosed '('"}
+		}
+		p.pos++
+		return node, nil
+	default:
+		return nil, &QueryParseError{Query: p.raw, Column: token.column, Message: "unexpected token"}
+	}
+}
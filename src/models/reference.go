@@ -0,0 +1,33 @@
+package models
+
+import "strings"
+
+// ReferenceKind describes how an identifier occurrence relates to the object it resolves to
+type ReferenceKind string
+
+const (
+	ReferenceKindDefinition ReferenceKind = "definition" // Declaration of the object
+	ReferenceKindUse        ReferenceKind = "reference"  // Any other use of the object
+	ReferenceKindCall       ReferenceKind = "call"       // Use as the callee of a function call
+)
+
+// Reference records a type-checked occurrence of an identifier
+type Reference struct {
+	Name      string        `json:"name"`
+	Target    string        `json:"target"` // Package-qualified object, e.g. "example.com/app/server.Server.Start"
+	Kind      ReferenceKind `json:"kind"`
+	Caller    string        `json:"caller,omitempty"` // Package-qualified enclosing function
+	FilePath  string        `json:"file_path"`
+	Line      int           `json:"line"`
+	Column    int           `json:"column"`
+	EndColumn int           `json:"end_column"`
+}
+
+// MatchesName checks if the reference resolves to an object with the given name. The name
+// may be qualified by receiver and package, e.g. "Start", "Server.Start" or "server.Server.Start".
+func (r *Reference) MatchesName(name string) bool {
+	return r.Name == name ||
+		r.Target == name ||
+		strings.HasSuffix(r.Target, "."+name) ||
+		strings.HasSuffix(r.Target, "/"+name)
+}
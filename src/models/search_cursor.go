@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// searchCursor is the decoded form of a page cursor
+type searchCursor struct {
+	Fingerprint string `json:"f"`
+	Offset      int    `json:"o"`
+}
+
+// EncodeCursor returns an opaque cursor for the page of a query's results starting at offset
+func EncodeCursor(query *SearchQuery, offset int) string {
+	data, _ := json.Marshal(searchCursor{Fingerprint: query.Fingerprint(), Offset: offset})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor returns the offset a cursor points at. It fails if the cursor is malformed
+// or was issued for a different query, since offsets only make sense for one ranking.
+func DecodeCursor(query *SearchQuery, cursor string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("malformed cursor")
+	}
+
+	var decoded searchCursor
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Offset < 0 {
+		return 0, fmt.Errorf("malformed cursor")
+	}
+
+	if decoded.Fingerprint != query.Fingerprint() {
+		return 0, fmt.Errorf("cursor belongs to a different query")
+	}
+
+	return decoded.Offset, nil
+}
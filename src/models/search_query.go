@@ -1,22 +1,43 @@
 package models
 
 import (
+	"crypto/md5"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"regexp"
+	"regexp/syntax"
 	"strings"
 	"time"
+	"unicode"
 )
 
 // SearchQuery represents a search request from the user
 type SearchQuery struct {
 	QueryText      string            `json:"query_text"`
 	MaxResults     int               `json:"max_results"`
+	Offset         int               `json:"offset"` // Number of ranked results to skip
 	IncludeContext bool              `json:"include_context"`
 	FileFilter     string            `json:"file_filter"`
 	LanguageFilter string            `json:"language_filter"`
 	Threshold      float64           `json:"threshold"`
 	SearchType     SearchType        `json:"search_type"`
+	Multiline      bool              `json:"multiline"`
+	MaxEdits       int               `json:"max_edits"`
+	CaseSensitive  bool              `json:"case_sensitive"`
+	SmartCase      bool              `json:"smart_case"`
+	WholeWord      bool              `json:"whole_word"`
+	NoSynonyms     bool              `json:"no_synonyms"`
+	ContextBefore  int               `json:"context_before"`           // Lines of context before each match (-B)
+	ContextAfter   int               `json:"context_after"`            // Lines of context after each match (-A)
+	RawQuery       string            `json:"raw_query,omitempty"`      // Query as typed, before structured parsing
+	Expression     *QueryNode        `json:"expression,omitempty"`     // Boolean expression, when the query uses one
+	PathFilters    []string          `json:"path_filters,omitempty"`   // Paths must match all of these
+	ExcludePaths   []string          `json:"exclude_paths,omitempty"`  // Paths must match none of these
+	SymbolFilters  []string          `json:"symbol_filters,omitempty"` // Matches must define these symbols
+	RepoFilters    []string          `json:"repo_filters,omitempty"`   // Repositories must match one of these
+	ExcludeRepos   []string          `json:"exclude_repos,omitempty"`  // Repositories must match none of these
+	SortOrder      string            `json:"sort_order,omitempty"`     // Order of the ranking: score (default), path or mtime
 	Options        map[string]string `json:"options"`
 	CreatedAt      time.Time         `json:"created_at"`
 }
@@ -33,6 +54,16 @@ const (
 	SearchTypeFuzzy    SearchType = "fuzzy"    // Fuzzy string matching
 )
 
+// DefaultMaxEdits is the default edit distance allowed by fuzzy search
+const DefaultMaxEdits = 2
+
+// MaxResultsLimit is the largest page a query may ask for
+const MaxResultsLimit = 1000
+
+// RankedWindow is how many results semantic and hybrid searches rank at once. Pages
+// within the window are sliced from one ranking, so they can share a cached entry.
+const RankedWindow = 1000
+
 // NewSearchQuery creates a new SearchQuery with default values
 func NewSearchQuery(queryText string) *SearchQuery {
 	return &SearchQuery{
@@ -43,6 +74,11 @@ func NewSearchQuery(queryText string) *SearchQuery {
 		LanguageFilter: "",               // No language filter by default
 		Threshold:      0.7,              // Default similarity threshold
 		SearchType:     SearchTypeHybrid, // Default to hybrid search
+		Multiline:      false,            // Default: match line by line
+		MaxEdits:       DefaultMaxEdits,  // Default fuzzy edit distance
+		CaseSensitive:  false,            // Default: case follows the search type
+		SmartCase:      false,            // Default: no smart case
+		WholeWord:      false,            // Default: match anywhere in a word
 		Options:        make(map[string]string),
 		CreatedAt:      time.Now(),
 	}
@@ -58,14 +94,22 @@ func (sq *SearchQuery) Validate() error {
 		return fmt.Errorf("max results must be >= 1, got %d", sq.MaxResults)
 	}
 
-	if sq.MaxResults > 1000 {
-		return fmt.Errorf("max results cannot exceed 1000, got %d", sq.MaxResults)
+	if sq.MaxResults > MaxResultsLimit {
+		return fmt.Errorf("max results cannot exceed %d, got %d", MaxResultsLimit, sq.MaxResults)
+	}
+
+	if sq.Offset < 0 {
+		return fmt.Errorf("offset must be >= 0, got %d", sq.Offset)
 	}
 
 	if sq.Threshold < 0 || sq.Threshold > 1 {
 		return fmt.Errorf("threshold must be between 0 and 1, got %f", sq.Threshold)
 	}
 
+	if sq.ContextBefore < 0 || sq.ContextAfter < 0 {
+		return fmt.Errorf("context lines must be >= 0, got %d before and %d after", sq.ContextBefore, sq.ContextAfter)
+	}
+
 	// Validate search type
 	validTypes := map[SearchType]bool{
 		SearchTypeSemantic: true,
@@ -80,6 +124,21 @@ func (sq *SearchQuery) Validate() error {
 		return fmt.Errorf("invalid search type: %s", sq.SearchType)
 	}
 
+	if sq.MaxEdits < 0 || sq.MaxEdits > 5 {
+		return fmt.Errorf("max edits must be between 0 and 5, got %d", sq.MaxEdits)
+	}
+
+	switch sq.SortOrder {
+	case "", "score", "path", "mtime":
+	default:
+		return fmt.Errorf("invalid sort order: %s (supported: score, path, mtime)", sq.SortOrder)
+	}
+
+	// Multiline matching only applies to regular expressions
+	if sq.Multiline && sq.SearchType != SearchTypeRegex {
+		return fmt.Errorf("multiline mode requires regex search, got %s", sq.SearchType)
+	}
+
 	// Validate regex if search type is regex
 	if sq.SearchType == SearchTypeRegex {
 		if _, err := regexp.Compile(sq.QueryText); err != nil {
@@ -98,6 +157,49 @@ func (sq *SearchQuery) Validate() error {
 	return nil
 }
 
+// ResultWindow returns how many ranked results are needed to serve the requested page
+func (sq *SearchQuery) ResultWindow() int {
+	return sq.Offset + sq.MaxResults
+}
+
+// CandidateWindow returns how many ranked results a search produces, or 0 when it
+// produces every match. Lexical searches are exhaustive; semantic and hybrid searches
+// only score the nearest vectors, ranking RankedWindow results unless the requested page
+// lies beyond it.
+func (sq *SearchQuery) CandidateWindow() int {
+	if sq.IsLexical() {
+		return 0
+	}
+	if window := sq.ResultWindow(); window > RankedWindow {
+		return window
+	}
+	return RankedWindow
+}
+
+// IsLexical reports whether the search matches text only, without embeddings
+func (sq *SearchQuery) IsLexical() bool {
+	return sq.SearchType != SearchTypeSemantic && sq.SearchType != SearchTypeHybrid
+}
+
+// Fingerprint identifies the ranked result list of a query. Queries that differ only in
+// page size, offset or context share a fingerprint.
+func (sq *SearchQuery) Fingerprint() string {
+	h := md5.New()
+
+	h.Write([]byte(sq.QueryText))
+	h.Write([]byte(sq.SearchType))
+	h.Write([]byte(fmt.Sprintf("%f", sq.Threshold)))
+	h.Write([]byte(fmt.Sprintf("%t", sq.Multiline)))
+	h.Write([]byte(fmt.Sprintf("%d", sq.MaxEdits)))
+	h.Write([]byte(fmt.Sprintf("%t%t%t%t", sq.CaseSensitive, sq.SmartCase, sq.WholeWord, sq.NoSynonyms)))
+	h.Write([]byte(sq.FileFilter))
+	h.Write([]byte(sq.RawQuery))
+	h.Write([]byte(sq.LanguageFilter))
+	h.Write([]byte(sq.SortOrder))
+
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
 // GetProcessedQuery returns the processed query text for searching
 func (sq *SearchQuery) GetProcessedQuery() string {
 	query := strings.TrimSpace(sq.QueryText)
@@ -111,11 +213,11 @@ func (sq *SearchQuery) GetProcessedQuery() string {
 		// Add fuzzy matching markers
 		return addFuzzyMarkers(query)
 	case SearchTypeSemantic, SearchTypeHybrid:
-		// Clean and normalize for semantic search
-		return normalizeQuery(query)
+		// Clean and normalize for semantic search, spelling out compound identifiers
+		return normalizeQuery(expandIdentifiers(query))
 	case SearchTypeText:
-		// Simple text normalization
-		return strings.ToLower(query)
+		// Lowercased code tokens, compound identifiers followed by their sub-words
+		return strings.Join(CodeTerms(query), " ")
 	case SearchTypeRegex:
 		// Return as-is (already validated)
 		return query
@@ -124,10 +226,94 @@ func (sq *SearchQuery) GetProcessedQuery() string {
 	}
 }
 
-// GetKeywords extracts keywords from the query
-func (sq *SearchQuery) GetKeywords() []string {
-	query := strings.ToLower(sq.QueryText)
+// TextTerms returns the terms a text search finds in each line. Hybrid queries are
+// normalised as GetProcessedQuery does for them; case-sensitive and whole-word searches
+// keep the case and leave out the sub-words of compound identifiers, which would not
+// match on their own.
+func (sq *SearchQuery) TextTerms() []string {
+	if sq.SearchType != SearchTypeHybrid {
+		return strings.Fields(sq.QueryText)
+	}
+
+	processed := sq.GetProcessedQuery()
+	if sq.IsCaseSensitive() || sq.WholeWord {
+		processed = cleanQuery(sq.QueryText)
+	}
+	if terms := strings.Fields(processed); len(terms) > 0 {
+		return terms
+	}
+	return strings.Fields(sq.QueryText)
+}
+
+// ContextRange returns how many lines of context to attach before and after each match.
+// Explicit before and after counts win; IncludeContext alone uses defaultLines on both sides.
+func (sq *SearchQuery) ContextRange(defaultLines int) (int, int) {
+	if sq.ContextBefore > 0 || sq.ContextAfter > 0 {
+		return sq.ContextBefore, sq.ContextAfter
+	}
+	if sq.IncludeContext {
+		return defaultLines, defaultLines
+	}
+	return 0, 0
+}
+
+// OriginalText returns the query as the user typed it, before structured parsing
+func (sq *SearchQuery) OriginalText() string {
+	if sq.RawQuery != "" {
+		return sq.RawQuery
+	}
+	return sq.QueryText
+}
+
+// IsCaseSensitive reports whether lexical matching should respect case. CaseSensitive
+// always wins; SmartCase is case-sensitive only when the query contains an uppercase
+// letter. Otherwise regex search is case-sensitive and the other lexical searches are not.
+func (sq *SearchQuery) IsCaseSensitive() bool {
+	if sq.CaseSensitive {
+		return true
+	}
+
+	if sq.SmartCase {
+		return hasUppercaseLiteral(sq.QueryText, sq.SearchType == SearchTypeRegex)
+	}
+
+	return sq.SearchType == SearchTypeRegex
+}
+
+// LiteralPattern compiles literal text into a pattern honouring the case and whole-word settings
+func (sq *SearchQuery) LiteralPattern(text string) (*regexp.Regexp, error) {
+	return sq.compilePattern(regexp.QuoteMeta(text))
+}
+
+// RegexPattern compiles the query text as a regular expression honouring the multiline,
+// case and whole-word settings
+func (sq *SearchQuery) RegexPattern() (*regexp.Regexp, error) {
+	return sq.compilePattern(sq.QueryText)
+}
+
+// compilePattern wraps a regex source with the flags implied by the query
+func (sq *SearchQuery) compilePattern(source string) (*regexp.Regexp, error) {
+	if sq.WholeWord {
+		source = wordBoundaries(source)
+	}
+
+	flags := ""
+	if !sq.IsCaseSensitive() {
+		flags += "i"
+	}
+	if sq.Multiline {
+		flags += "m"
+	}
+	if flags != "" {
+		source = "(?" + flags + ")" + source
+	}
 
+	return regexp.Compile(source)
+}
+
+// GetKeywords extracts keywords from the query. Compound identifiers are kept along with
+// their sub-words, so "parseConfig" yields "parseconfig", "parse" and "config".
+func (sq *SearchQuery) GetKeywords() []string {
 	// Remove common stop words
 	stopWords := map[string]bool{
 		"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true, "by": true,
@@ -138,19 +324,9 @@ func (sq *SearchQuery) GetKeywords() []string {
 		"who": true, "why": true, "how": true, "can": true, "could": true, "should": true, "would": true,
 	}
 
-	// Split query into words
-	words := strings.Fields(query)
-
 	var keywords []string
-	seen := make(map[string]bool)
-
-	for _, word := range words {
-		// Clean the word
-		word = strings.Trim(word, ".,!?;:\"'()[]{}")
-		if word == "" {
-			continue
-		}
 
+	for _, word := range CodeTerms(sq.QueryText) {
 		// Skip stop words
 		if stopWords[word] {
 			continue
@@ -161,11 +337,7 @@ func (sq *SearchQuery) GetKeywords() []string {
 			continue
 		}
 
-		// Add to keywords if not already seen
-		if !seen[word] {
-			keywords = append(keywords, word)
-			seen[word] = true
-		}
+		keywords = append(keywords, word)
 	}
 
 	return keywords
@@ -228,9 +400,76 @@ func (sq *SearchQuery) ShouldIncludeFile(filePath, language string) bool {
 		}
 	}
 
+	// Check path filters from structured queries
+	for _, filter := range sq.PathFilters {
+		if !sq.matchesPathFilter(filePath, filter) {
+			return false
+		}
+	}
+	for _, filter := range sq.ExcludePaths {
+		if sq.matchesPathFilter(filePath, filter) {
+			return false
+		}
+	}
+
 	return true
 }
 
+// ShouldIncludeRepository checks a repository label against the repo: filters. A
+// repository must match one of the filters, when there are any, and none of the
+// excluded ones. Filters match the whole label or its trailing path elements, ignoring
+// case, with glob characters allowed; "api" matches both "api" and "team/api".
+func (sq *SearchQuery) ShouldIncludeRepository(name string) bool {
+	matches := func(filter string) bool {
+		label := strings.ToLower(name)
+		for {
+			if matched, err := filepath.Match(strings.ToLower(filter), label); err == nil && matched {
+				return true
+			}
+			slash := strings.Index(label, "/")
+			if slash < 0 {
+				return false
+			}
+			label = label[slash+1:]
+		}
+	}
+
+	included := len(sq.RepoFilters) == 0
+	for _, filter := range sq.RepoFilters {
+		included = included || matches(filter)
+	}
+	for _, filter := range sq.ExcludeRepos {
+		included = included && !matches(filter)
+	}
+	return included
+}
+
+// matchesPathFilter checks a path: filter. Filters containing glob characters must match
+// the whole path or a trailing part of it; other filters match as substrings.
+func (sq *SearchQuery) matchesPathFilter(filePath, filter string) bool {
+	filePath = filepath.ToSlash(filePath)
+
+	if !strings.ContainsAny(filter, "*?") {
+		return strings.Contains(filePath, filter)
+	}
+
+	pattern, err := regexp.Compile(sq.globToRegex(filter))
+	if err != nil {
+		return false
+	}
+
+	for {
+		if pattern.MatchString(filePath) {
+			return true
+		}
+		slash := strings.Index(filePath, "/")
+		if slash < 0 {
+			return false
+		}
+		filePath = filePath[slash+1:]
+	}
+}
+
 // GetEstimatedComplexity returns an estimate of query complexity
 func (sq *SearchQuery) GetEstimatedComplexity() QueryComplexity {
 	complexity := QueryComplexity{
@@ -325,6 +564,11 @@ func (sq *SearchQuery) Clone() *SearchQuery {
 	for k, v := range sq.Options {
 		clone.Options[k] = v
 	}
+	clone.PathFilters = append([]string(nil), sq.PathFilters...)
+	clone.ExcludePaths = append([]string(nil), sq.ExcludePaths...)
+	clone.SymbolFilters = append([]string(nil), sq.SymbolFilters...)
+	clone.RepoFilters = append([]string(nil), sq.RepoFilters...)
+	clone.ExcludeRepos = append([]string(nil), sq.ExcludeRepos...)
 	return &clone
 }
 
@@ -364,6 +608,44 @@ func (sq *SearchQuery) GetSummary() string {
 		summary += fmt.Sprintf(" (language: %s)", sq.LanguageFilter)
 	}
 
+	if len(sq.PathFilters) > 0 || len(sq.ExcludePaths) > 0 {
+		summary += fmt.Sprintf(" (paths: %s", strings.Join(sq.PathFilters, ", "))
+		for _, exclude := range sq.ExcludePaths {
+			summary += " -" + exclude
+		}
+		summary += ")"
+	}
+
+	if len(sq.SymbolFilters) > 0 {
+		summary += fmt.Sprintf(" (symbols: %s)", strings.Join(sq.SymbolFilters, ", "))
+	}
+
+	if len(sq.RepoFilters) > 0 || len(sq.ExcludeRepos) > 0 {
+		summary += fmt.Sprintf(" (repositories: %s", strings.Join(sq.RepoFilters, ", "))
+		for _, exclude := range sq.ExcludeRepos {
+			summary += " -" + exclude
+		}
+		summary += ")"
+	}
+
+	if sq.SearchType == SearchTypeFuzzy && sq.MaxEdits != DefaultMaxEdits {
+		summary += fmt.Sprintf(" (max edits %d)", sq.MaxEdits)
+	}
+
+	if sq.Multiline {
+		summary += " (multiline)"
+	}
+
+	if sq.CaseSensitive {
+		summary += " (case-sensitive)"
+	} else if sq.SmartCase {
+		summary += " (smart case)"
+	}
+
+	if sq.WholeWord {
+		summary += " (whole word)"
+	}
+
 	if sq.IncludeContext {
 		summary += " (with context)"
 	}
@@ -382,9 +664,12 @@ type QueryComplexity struct {
 
 // normalizeQuery normalizes query text for semantic search
 func normalizeQuery(query string) string {
-	// Convert to lowercase
-	query = strings.ToLower(query)
+	return strings.ToLower(cleanQuery(query))
+}
 
+// cleanQuery collapses whitespace and removes special characters, keeping programming
+// symbols
+func cleanQuery(query string) string {
 	// Remove extra whitespace
 	query = regexp.MustCompile(`\s+`).ReplaceAllString(query, " ")
 
@@ -397,6 +682,152 @@ func normalizeQuery(query string) string {
 	return query
 }
 
+// hasUppercaseLiteral reports whether a query contains an uppercase letter. For regex
+// queries only literal characters count, so escapes such as \S or \W are ignored.
+func hasUppercaseLiteral(query string, isRegex bool) bool {
+	if !isRegex {
+		return strings.IndexFunc(query, unicode.IsUpper) >= 0
+	}
+
+	re, err := syntax.Parse(query, syntax.Perl)
+	if err != nil {
+		return strings.IndexFunc(query, unicode.IsUpper) >= 0
+	}
+
+	var walk func(re *syntax.Regexp) bool
+	walk = func(re *syntax.Regexp) bool {
+		if re.Op == syntax.OpLiteral {
+			for _, r := range re.Rune {
+				if unicode.IsUpper(r) {
+					return true
+				}
+			}
+		}
+		for _, sub := range re.Sub {
+			if walk(sub) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return walk(re)
+}
+
+// wordBoundaries wraps a regex source in \b on each side where every match starts or ends
+// with a word character. A \b beside a symbol such as ( or - would demand a word
+// character beyond it, so such sides are left open.
+func wordBoundaries(source string) string {
+	re, err := syntax.Parse(source, syntax.Perl)
+	if err != nil {
+		// Compiling the source reports the error
+		return source
+	}
+
+	pattern := `(?:` + source + `)`
+	if word, empty := wordEdge(re, true); word && !empty {
+		pattern = `\b` + pattern
+	}
+	if word, empty := wordEdge(re, false); word && !empty {
+		pattern += `\b`
+	}
+	return pattern
+}
+
+// wordEdge reports whether every match of re starts (or, when first is false, ends) with
+// a word character, and whether re can match the empty string, in which case the edge
+// belongs to whatever follows it
+func wordEdge(re *syntax.Regexp, first bool) (word bool, empty bool) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		if len(re.Rune) == 0 {
+			return true, true
+		}
+		r := re.Rune[0]
+		if !first {
+			r = re.Rune[len(re.Rune)-1]
+		}
+		return isWordRange(r, r), false
+	case syntax.OpCharClass:
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			if !isWordRange(re.Rune[i], re.Rune[i+1]) {
+				return false, false
+			}
+		}
+		return len(re.Rune) > 0, false
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText,
+		syntax.OpEndText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return true, true
+	case syntax.OpCapture, syntax.OpPlus:
+		return wordEdge(re.Sub[0], first)
+	case syntax.OpStar, syntax.OpQuest:
+		word, _ := wordEdge(re.Sub[0], first)
+		return word, true
+	case syntax.OpRepeat:
+		word, empty := wordEdge(re.Sub[0], first)
+		return word, empty || re.Min == 0
+	case syntax.OpConcat:
+		for i := range re.Sub {
+			sub := re.Sub[i]
+			if !first {
+				sub = re.Sub[len(re.Sub)-1-i]
+			}
+			word, empty := wordEdge(sub, first)
+			if !word || !empty {
+				return word, false
+			}
+		}
+		return true, true
+	case syntax.OpAlternate:
+		word, empty := true, false
+		for _, sub := range re.Sub {
+			subWord, subEmpty := wordEdge(sub, first)
+			word = word && subWord
+			empty = empty || subEmpty
+		}
+		return word, empty
+	default:
+		return false, false
+	}
+}
+
+// isWordRange reports whether every rune from lo to hi is a \w word character
+func isWordRange(lo, hi rune) bool {
+	for _, span := range [][2]rune{{'0', '9'}, {'A', 'Z'}, {'_', '_'}, {'a', 'z'}} {
+		if lo >= span[0] && hi <= span[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// expandIdentifiers follows each compound identifier in the query with its sub-words,
+// so "parseConfig" becomes "parseConfig parse Config"
+func expandIdentifiers(query string) string {
+	var builder strings.Builder
+	last := 0
+	var pending []string
+
+	for _, token := range TokenizeCode(query) {
+		if token.Part {
+			pending = append(pending, token.Text)
+			continue
+		}
+		if len(pending) > 0 {
+			builder.WriteString(" " + strings.Join(pending, " "))
+			pending = nil
+		}
+		builder.WriteString(query[last:token.End])
+		last = token.End
+	}
+	if len(pending) > 0 {
+		builder.WriteString(" " + strings.Join(pending, " "))
+	}
+	builder.WriteString(query[last:])
+
+	return builder.String()
+}
+
 // addFuzzyMarkers adds markers for fuzzy matching
 func addFuzzyMarkers(query string) string {
 	// This is a simplified implementation
@@ -11,15 +11,21 @@ import (
 // SearchResult represents a single match found during search
 type SearchResult struct {
 	FilePath       string                 `json:"file_path"`
+	Repository     string                 `json:"repository,omitempty"` // Label of the repository, in multi-repository searches
 	StartLine      int                    `json:"start_line"`
 	EndLine        int                    `json:"end_line"`
+	StartColumn    int                    `json:"start_column,omitempty"`
+	EndColumn      int                    `json:"end_column,omitempty"`
 	Content        string                 `json:"content"`
-	Context        string                 `json:"context"`
+	Context        string                 `json:"context,omitempty"`
+	ContextBefore  []ContextLine          `json:"context_before,omitempty"` // Lines preceding StartLine
+	ContextAfter   []ContextLine          `json:"context_after,omitempty"`  // Lines following EndLine
 	RelevanceScore float64                `json:"relevance_score"`
 	VectorDistance float64                `json:"vector_distance"`
 	Language       string                 `json:"language"`
 	MatchType      MatchType              `json:"match_type"`
 	Highlights     []string               `json:"highlights"`
+	Spans          []MatchSpan            `json:"spans,omitempty"`
 	Metadata       map[string]interface{} `json:"metadata"`
 	Rank           int                    `json:"rank"`
 	FoundAt        time.Time              `json:"found_at"`
@@ -30,22 +36,60 @@ type SearchResult struct {
 type MatchType string
 
 const (
-	MatchTypeExact    MatchType = "exact"    // Exact string match
-	MatchTypeSemantic MatchType = "semantic" // Vector similarity match
-	MatchTypeFuzzy    MatchType = "fuzzy"    // Fuzzy string match
-	MatchTypeRegex    MatchType = "regex"    // Regular expression match
-	MatchTypePartial  MatchType = "partial"  // Partial word match
-	MatchTypeSynonym  MatchType = "synonym"  // Synonym match
-	MatchTypeHybrid   MatchType = "hybrid"   // Combination of multiple types
+	MatchTypeExact     MatchType = "exact"     // Exact string match
+	MatchTypeSemantic  MatchType = "semantic"  // Vector similarity match
+	MatchTypeFuzzy     MatchType = "fuzzy"     // Fuzzy string match
+	MatchTypeRegex     MatchType = "regex"     // Regular expression match
+	MatchTypePartial   MatchType = "partial"   // Partial word match
+	MatchTypeSynonym   MatchType = "synonym"   // Synonym match
+	MatchTypeHybrid    MatchType = "hybrid"    // Combination of multiple types
+	MatchTypeReference MatchType = "reference" // Resolved identifier reference
 )
 
-// NewSearchResult creates a new SearchResult with default values
+// MatchSpan records the exact location of a matched region within a file.
+// Lines are 1-based; columns are 1-based byte offsets within their line and
+// EndColumn is exclusive.
+type MatchSpan struct {
+	StartLine   int    `json:"start_line"`
+	StartColumn int    `json:"start_column"`
+	EndLine     int    `json:"end_line"`
+	EndColumn   int    `json:"end_column"`
+	Text        string `json:"text"`
+}
+
+// ContextLine is a numbered line of source surrounding a search result
+type ContextLine struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// ContextFromLines returns up to before lines preceding startLine and after lines
+// following endLine from the lines of a file. Line numbers are 1-based.
+func ContextFromLines(lines []string, startLine, endLine, before, after int) ([]ContextLine, []ContextLine) {
+	var beforeLines, afterLines []ContextLine
+
+	for line := startLine - before; line < startLine; line++ {
+		if line >= 1 && line <= len(lines) {
+			beforeLines = append(beforeLines, ContextLine{Line: line, Text: lines[line-1]})
+		}
+	}
+	for line := endLine + 1; line <= endLine+after && line <= len(lines); line++ {
+		if line >= 1 {
+			afterLines = append(afterLines, ContextLine{Line: line, Text: lines[line-1]})
+		}
+	}
+
+	return beforeLines, afterLines
+}
+
+// NewSearchResult creates a new SearchResult with default values. The content keeps its
+// indentation, so span columns index into its lines; only trailing whitespace is dropped.
 func NewSearchResult(filePath string, startLine, endLine int, content string) *SearchResult {
 	return &SearchResult{
 		FilePath:       filePath,
 		StartLine:      startLine,
 		EndLine:        endLine,
-		Content:        strings.TrimSpace(content),
+		Content:        strings.TrimRight(content, " \t\r\n"),
 		Context:        "",               // Will be populated separately
 		RelevanceScore: 0.0,              // Will be calculated
 		VectorDistance: 0.0,              // Will be calculated for semantic matches
@@ -104,13 +148,14 @@ func (sr *SearchResult) Validate() error {
 
 	// Validate match type
 	validTypes := map[MatchType]bool{
-		MatchTypeExact:    true,
-		MatchTypeSemantic: true,
-		MatchTypeFuzzy:    true,
-		MatchTypeRegex:    true,
-		MatchTypePartial:  true,
-		MatchTypeSynonym:  true,
-		MatchTypeHybrid:   true,
+		MatchTypeExact:     true,
+		MatchTypeSemantic:  true,
+		MatchTypeFuzzy:     true,
+		MatchTypeRegex:     true,
+		MatchTypePartial:   true,
+		MatchTypeSynonym:   true,
+		MatchTypeHybrid:    true,
+		MatchTypeReference: true,
 	}
 
 	if !validTypes[sr.MatchType] {
@@ -155,6 +200,18 @@ func (sr *SearchResult) AddHighlight(highlight string) {
 // ClearHighlights clears all highlights
 func (sr *SearchResult) ClearHighlights() {
 	sr.Highlights = make([]string, 0)
+	sr.Spans = nil
+}
+
+// AddSpan records a matched span and adds its text as a highlight.
+// The first span added also sets the result's start and end columns.
+func (sr *SearchResult) AddSpan(span MatchSpan) {
+	if len(sr.Spans) == 0 {
+		sr.StartColumn = span.StartColumn
+		sr.EndColumn = span.EndColumn
+	}
+	sr.Spans = append(sr.Spans, span)
+	sr.AddHighlight(span.Text)
 }
 
 // GetHighlightedContent returns the content with highlights applied
@@ -300,6 +357,17 @@ func (sr *SearchResult) SetContext(context string) {
 	sr.Context = context
 }
 
+// SetContextLines sets the lines surrounding the result
+func (sr *SearchResult) SetContextLines(before, after []ContextLine) {
+	sr.ContextBefore = before
+	sr.ContextAfter = after
+}
+
+// HasContextLines reports whether surrounding lines are attached to the result
+func (sr *SearchResult) HasContextLines() bool {
+	return len(sr.ContextBefore) > 0 || len(sr.ContextAfter) > 0
+}
+
 // GetDisplayFormat returns the result formatted for display
 func (sr *SearchResult) GetDisplayFormat() string {
 	lines := []string{
@@ -3,11 +3,20 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 	"time"
 )
 
+// Metadata keys recording where results were found. A search of one index records the
+// repository directory it was built from; a search of several records each repository's
+// directory by label.
+const (
+	MetadataRepositoryPath  = "repository_path"
+	MetadataRepositoryPaths = "repository_paths"
+)
+
 // SearchResults represents a collection of search results
 type SearchResults struct {
 	Query         *SearchQuery           `json:"query"`
@@ -15,6 +24,8 @@ type SearchResults struct {
 	TotalResults  int                    `json:"total_results"`
 	ExecutionTime time.Duration          `json:"execution_time"`
 	HasMore       bool                   `json:"has_more"`
+	Offset        int                    `json:"offset"`                // Rank of the first result, minus one
+	NextCursor    string                 `json:"next_cursor,omitempty"` // Cursor of the next page, when there is one
 	SearchedFiles int                    `json:"searched_files"`
 	Metadata      map[string]interface{} `json:"metadata"`
 	CreatedAt     time.Time              `json:"created_at"`
@@ -138,6 +149,96 @@ func (sr *SearchResults) GetResultsByLanguage() map[string][]*SearchResult {
 	return resultsByLanguage
 }
 
+// ResultGroup is a set of results sharing a file path or language
+type ResultGroup struct {
+	Key     string          `json:"key"`
+	Count   int             `json:"count"`
+	Results []*SearchResult `json:"results"`
+}
+
+// GroupBy groups results by "file" or "language". Groups appear in the order of their
+// first result, so they follow the current sort order.
+func (sr *SearchResults) GroupBy(field string) ([]ResultGroup, error) {
+	var grouped map[string][]*SearchResult
+	var keyOf func(*SearchResult) string
+
+	switch field {
+	case "file":
+		grouped = sr.GetResultsByFile()
+		keyOf = func(result *SearchResult) string { return result.FilePath }
+	case "language":
+		grouped = sr.GetResultsByLanguage()
+		keyOf = func(result *SearchResult) string {
+			if result.Language == "" {
+				return "Unknown"
+			}
+			return result.Language
+		}
+	default:
+		return nil, fmt.Errorf("invalid group field: %s (supported: file, language)", field)
+	}
+
+	var groups []ResultGroup
+	seen := make(map[string]bool)
+	for _, result := range sr.Results {
+		key := keyOf(result)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		groups = append(groups, ResultGroup{Key: key, Count: len(grouped[key]), Results: grouped[key]})
+	}
+
+	return groups, nil
+}
+
+// SortBy orders the results by relevance ("score"), by file path and line ("path"), or by
+// file modification time, most recently modified first ("mtime"). Ranks are unchanged.
+func (sr *SearchResults) SortBy(order string) error {
+	switch order {
+	case "", "score":
+		sort.SliceStable(sr.Results, func(i, j int) bool {
+			return sr.Results[i].IsBetterThan(sr.Results[j])
+		})
+
+	case "path":
+		sort.SliceStable(sr.Results, func(i, j int) bool {
+			a, b := sr.Results[i], sr.Results[j]
+			if a.FilePath != b.FilePath {
+				return a.FilePath < b.FilePath
+			}
+			return a.StartLine < b.StartLine
+		})
+
+	case "mtime":
+		modTimes := make(map[string]time.Time)
+		for _, result := range sr.Results {
+			if _, exists := modTimes[result.FilePath]; !exists {
+				if info, err := os.Stat(result.FilePath); err == nil {
+					modTimes[result.FilePath] = info.ModTime()
+				} else {
+					modTimes[result.FilePath] = time.Time{}
+				}
+			}
+		}
+		sort.SliceStable(sr.Results, func(i, j int) bool {
+			a, b := sr.Results[i], sr.Results[j]
+			if !modTimes[a.FilePath].Equal(modTimes[b.FilePath]) {
+				return modTimes[a.FilePath].After(modTimes[b.FilePath])
+			}
+			if a.FilePath != b.FilePath {
+				return a.FilePath < b.FilePath
+			}
+			return a.StartLine < b.StartLine
+		})
+
+	default:
+		return fmt.Errorf("invalid sort order: %s (supported: score, path, mtime)", order)
+	}
+
+	return nil
+}
+
 // GetResultsByMatchType returns results grouped by match type
 func (sr *SearchResults) GetResultsByMatchType() map[MatchType][]*SearchResult {
 	resultsByType := make(map[MatchType][]*SearchResult)
@@ -160,6 +261,35 @@ func (sr *SearchResults) LimitResults(maxResults int) {
 	}
 }
 
+// Page returns a copy holding limit results starting at offset, with a cursor for the
+// next page when more results follow. The results are copied, so the page can be
+// changed without affecting the full list.
+func (sr *SearchResults) Page(offset, limit int) *SearchResults {
+	page := NewSearchResults(sr.Query)
+	page.TotalResults = sr.TotalResults
+	page.SearchedFiles = sr.SearchedFiles
+	page.Offset = offset
+	for k, v := range sr.Metadata {
+		page.Metadata[k] = v
+	}
+
+	end := offset + limit
+	if end > len(sr.Results) {
+		end = len(sr.Results)
+	}
+	for i := offset; i < end; i++ {
+		resultCopy := *sr.Results[i]
+		page.Results = append(page.Results, &resultCopy)
+	}
+
+	if end < len(sr.Results) {
+		page.HasMore = true
+		page.NextCursor = EncodeCursor(sr.Query, end)
+	}
+
+	return page
+}
+
 // GetTopResults returns the top N results
 func (sr *SearchResults) GetTopResults(n int) []*SearchResult {
 	if n <= 0 {
@@ -305,6 +435,16 @@ func (sr *SearchResults) AddMetadata(key string, value interface{}) {
 	sr.Metadata[key] = value
 }
 
+// RepositoryPath returns the directory of the repository a result was found in, or an
+// empty string when the search did not record it
+func (sr *SearchResults) RepositoryPath(result *SearchResult) string {
+	if paths, ok := sr.Metadata[MetadataRepositoryPaths].(map[string]string); ok && result.Repository != "" {
+		return paths[result.Repository]
+	}
+	path, _ := sr.Metadata[MetadataRepositoryPath].(string)
+	return path
+}
+
 // GetMetadata retrieves metadata value
 func (sr *SearchResults) GetMetadata(key string) (interface{}, bool) {
 	if sr.Metadata == nil {
@@ -356,7 +496,7 @@ func (sr *SearchResults) ToTableFormat() string {
 // ToJSONFormat returns the results formatted as JSON
 func (sr *SearchResults) ToJSONFormat() (string, error) {
 	data, err := json.Marshal(map[string]interface{}{
-		"query":          sr.Query.QueryText,
+		"query":          sr.Query.OriginalText(),
 		"results":        sr.Results,
 		"total_results":  sr.TotalResults,
 		"execution_time": sr.ExecutionTime.String(),
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SymbolKind represents the kind of a code symbol
+type SymbolKind string
+
+const (
+	SymbolKindFunc   SymbolKind = "func"   // Free function
+	SymbolKindMethod SymbolKind = "method" // Function with a receiver or enclosing class
+	SymbolKindType   SymbolKind = "type"   // Type, interface, struct, enum or trait
+	SymbolKindConst  SymbolKind = "const"  // Constant
+	SymbolKindVar    SymbolKind = "var"    // Variable
+	SymbolKindClass  SymbolKind = "class"  // Class
+)
+
+// Symbol represents a named definition found at index time
+type Symbol struct {
+	Name      string     `json:"name"`
+	Kind      SymbolKind `json:"kind"`
+	Container string     `json:"container,omitempty"` // Receiver type or enclosing class
+	FilePath  string     `json:"file_path"`
+	StartLine int        `json:"start_line"`
+	EndLine   int        `json:"end_line"`
+	Signature string     `json:"signature"`
+	Language  string     `json:"language"`
+}
+
+// ParseSymbolKind parses a symbol kind name
+func ParseSymbolKind(kind string) (SymbolKind, error) {
+	switch SymbolKind(strings.ToLower(kind)) {
+	case SymbolKindFunc, SymbolKindMethod, SymbolKindType, SymbolKindConst, SymbolKindVar, SymbolKindClass:
+		return SymbolKind(strings.ToLower(kind)), nil
+	default:
+		return "", fmt.Errorf("invalid symbol kind: %s (supported: func, method, type, const, var, class)", kind)
+	}
+}
+
+// QualifiedName returns the name prefixed by its container, e.g. "Server.Start"
+func (s *Symbol) QualifiedName() string {
+	if s.Container == "" {
+		return s.Name
+	}
+	return s.Container + "." + s.Name
+}
+
+// ContainsLine checks if a line falls within the symbol's definition
+func (s *Symbol) ContainsLine(line int) bool {
+	return line >= s.StartLine && line <= s.EndLine
+}
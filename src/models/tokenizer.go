@@ -0,0 +1,149 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CodeToken is a word of code or query text produced by TokenizeCode
+type CodeToken struct {
+	Text  string `json:"text"`
+	Start int    `json:"start"` // Byte offset of the token in the tokenized text
+	End   int    `json:"end"`   // Byte offset just past the token
+	Part  bool   `json:"part"`  // Sub-word of a compound identifier rather than the word itself
+}
+
+// TokenizeCode splits text into words of letters, digits and underscores, joining
+// kebab-case words across hyphens. Each word is returned followed by its sub-words when
+// SplitIdentifier splits it, so both "parseConfig" and "parse", "Config" are tokens.
+func TokenizeCode(text string) []CodeToken {
+	var tokens []CodeToken
+
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !isWordRune(r) {
+			i += size
+			continue
+		}
+
+		start := i
+		for i < len(text) {
+			r, size = utf8.DecodeRuneInString(text[i:])
+			if isWordRune(r) {
+				i += size
+				continue
+			}
+			// A hyphen joins kebab-case words: it must sit between a letter or digit and a letter
+			if r == '-' && i+1 < len(text) {
+				prev, _ := utf8.DecodeLastRuneInString(text[:i])
+				next, _ := utf8.DecodeRuneInString(text[i+1:])
+				if (unicode.IsLetter(prev) || unicode.IsDigit(prev)) && unicode.IsLetter(next) {
+					i += size
+					continue
+				}
+			}
+			break
+		}
+
+		word := text[start:i]
+		tokens = append(tokens, CodeToken{Text: word, Start: start, End: i})
+
+		parts := identifierParts(word)
+		if len(parts) == 1 && parts[0][0] == 0 && parts[0][1] == len(word) {
+			continue
+		}
+		for _, part := range parts {
+			tokens = append(tokens, CodeToken{
+				Text:  word[part[0]:part[1]],
+				Start: start + part[0],
+				End:   start + part[1],
+				Part:  true,
+			})
+		}
+	}
+
+	return tokens
+}
+
+// SplitIdentifier splits an identifier on camelCase, PascalCase, snake_case, kebab-case
+// and letter-digit boundaries, e.g. "HTTPServer2_config" into "HTTP", "Server", "2", "config"
+func SplitIdentifier(identifier string) []string {
+	var parts []string
+	for _, part := range identifierParts(identifier) {
+		parts = append(parts, identifier[part[0]:part[1]])
+	}
+	return parts
+}
+
+// CodeTerms returns the distinct lowercased tokens of text, words before their sub-words
+func CodeTerms(text string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+
+	for _, token := range TokenizeCode(text) {
+		term := strings.ToLower(token.Text)
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	return terms
+}
+
+// identifierParts returns the byte ranges of the sub-words of an identifier
+func identifierParts(identifier string) [][2]int {
+	var parts [][2]int
+	start := -1
+	var prev rune
+
+	for i, r := range identifier {
+		if r == '_' || r == '-' {
+			if start >= 0 {
+				parts = append(parts, [2]int{start, i})
+				start = -1
+			}
+			prev = r
+			continue
+		}
+
+		if start >= 0 && isIdentifierBoundary(prev, r, identifier[i+utf8.RuneLen(r):]) {
+			parts = append(parts, [2]int{start, i})
+			start = i
+		}
+		if start < 0 {
+			start = i
+		}
+		prev = r
+	}
+
+	if start >= 0 {
+		parts = append(parts, [2]int{start, len(identifier)})
+	}
+
+	return parts
+}
+
+// isIdentifierBoundary reports whether a new sub-word starts at current, given the
+// previous rune and the text after current
+func isIdentifierBoundary(prev, current rune, rest string) bool {
+	switch {
+	case unicode.IsDigit(prev) != unicode.IsDigit(current):
+		// Letter-digit transitions: "utf8" is "utf", "8"
+		return true
+	case unicode.IsLower(prev) && unicode.IsUpper(current):
+		// camelCase hump: "parseConfig"
+		return true
+	case unicode.IsUpper(prev) && unicode.IsUpper(current):
+		// End of an acronym: "HTTPServer" splits before the "S" of "Server"
+		next, _ := utf8.DecodeRuneInString(rest)
+		return unicode.IsLower(next)
+	}
+	return false
+}
+
+// isWordRune reports whether r can appear in an identifier
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
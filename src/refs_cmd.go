@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"code-search/src/lib"
+	"code-search/src/models"
+	"code-search/src/services"
+)
+
+// ReferencesCommand implements the refs and callers commands
+type ReferencesCommand struct {
+	referenceService *services.ReferenceService
+	callers          bool
+}
+
+// NewReferencesCommand creates a refs command, or a callers command when callers is set
+func NewReferencesCommand(callers bool) *ReferencesCommand {
+	return &ReferencesCommand{
+		referenceService: services.NewReferenceService(
+			lib.NewInMemoryVectorStore(""),
+			&services.SilentLogger{},
+		),
+		callers: callers,
+	}
+}
+
+// ReferencesOptions contains refs and callers command options
+type ReferencesOptions struct {
+	directory  string
+	format     string
+	color      lib.ColorMode
+	maxResults int
+}
+
+// Execute executes the command with the given arguments
+func (cmd *ReferencesCommand) Execute(args []string) error {
+	if len(args) < 1 {
+		return NewInvalidArgumentError(fmt.Sprintf("%s is required", cmd.argumentName()), nil)
+	}
+	if args[0] == "--help" || args[0] == "-h" {
+		cmd.printReferencesHelp()
+		return nil
+	}
+
+	name := args[0]
+	options, err := cmd.parseReferencesOptions(args[1:])
+	if err != nil {
+		return NewInvalidArgumentError(fmt.Sprintf("invalid %s options", cmd.name()), err)
+	}
+
+	indexPath, err := ResolveIndexPath(options.directory)
+	if err != nil {
+		return NewInvalidArgumentError("failed to resolve index location", err)
+	}
+
+	query := models.NewSearchQuery(name)
+	query.MaxResults = options.maxResults
+
+	var results *models.SearchResults
+	if cmd.callers {
+		results, err = cmd.referenceService.FindCallers(indexPath, query)
+	} else {
+		results, err = cmd.referenceService.FindReferences(indexPath, query)
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			return NewNotFoundError(fmt.Sprintf("%s lookup failed", cmd.name()), err)
+		}
+		return NewGeneralError(fmt.Sprintf("%s lookup failed", cmd.name()), err)
+	}
+
+	// Results are rendered in the search command's formats
+	if options.format == "table" && results.IsEmpty() {
+		fmt.Println("No references found.")
+		fmt.Println("References are resolved for Go files at index time; run 'code-search index' to update an older index.")
+		return nil
+	}
+	formatter, _ := newResultFormatter(options.format, FormatterOptions{
+		Color: lib.NewColorizer(lib.UseColor(options.color, os.Stdout)),
+	})
+	return formatter.Format(os.Stdout, results)
+}
+
+// parseReferencesOptions parses command line options for refs and callers
+func (cmd *ReferencesCommand) parseReferencesOptions(args []string) (ReferencesOptions, error) {
+	options := ReferencesOptions{
+		format:     "table",
+		color:      lib.ColorAuto,
+		maxResults: 100,
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch arg {
+		case "--dir", "-d":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--dir requires a directory path", nil)
+			}
+			options.directory = args[i+1]
+			i++
+
+		case "--format":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--format requires a value", nil)
+			}
+			format := strings.ToLower(args[i+1])
+			if _, exists := formatterRegistry[format]; !exists {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid format: %s (supported: %s)", format, strings.Join(formatterNames(), ", ")), nil)
+			}
+			options.format = format
+			i++
+
+		case "--color":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--color requires a value", nil)
+			}
+			color, err := lib.ParseColorMode(args[i+1])
+			if err != nil {
+				return options, NewInvalidArgumentError(err.Error(), nil)
+			}
+			options.color = color
+			i++
+
+		case "--max-results", "-m":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--max-results requires a value", nil)
+			}
+			var maxResults int
+			if _, err := fmt.Sscanf(args[i+1], "%d", &maxResults); err != nil || maxResults < 1 {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid max-results value: %s", args[i+1]), nil)
+			}
+			options.maxResults = maxResults
+			i++
+
+		case "--help", "-h":
+			cmd.printReferencesHelp()
+			os.Exit(0)
+
+		default:
+			return options, NewInvalidArgumentError(fmt.Sprintf("unknown option: %s", arg), nil)
+		}
+	}
+
+	return options, nil
+}
+
+// name returns the command name
+func (cmd *ReferencesCommand) name() string {
+	if cmd.callers {
+		return "callers"
+	}
+	return "refs"
+}
+
+// argumentName returns the description of the command's argument
+func (cmd *ReferencesCommand) argumentName() string {
+	if cmd.callers {
+		return "function name"
+	}
+	return "identifier"
+}
+
+// printReferencesHelp prints help for the refs or callers command
+func (cmd *ReferencesCommand) printReferencesHelp() {
+	if cmd.callers {
+		fmt.Printf(`Usage: code-search callers <function> [options]
+
+Arguments:
+  <function>               Function or method name, optionally qualified (e.g. "Server.Start")
+
+Lists every call site of the function, with the calling function in the
+"caller" metadata of JSON output.
+`)
+	} else {
+		fmt.Printf(`Usage: code-search refs <identifier> [options]
+
+Arguments:
+  <identifier>             Identifier name, optionally qualified (e.g. "Server.Start", "models.SearchQuery")
+
+Lists the definition of the identifier followed by every reference to it.
+`)
+	}
+
+	fmt.Printf(`
+Options:
+  -d, --dir <directory>    Specify indexed directory (default: current directory)
+      --format <fmt>       Output format: table, json, raw, ndjson, sarif, vimgrep, csv (default: table)
+      --color <when>       Color table output: auto, always, never (default: auto)
+  -m, --max-results <n>    Maximum number of results to return (default: 100)
+  -h, --help               Show this help message
+
+References are resolved by type-checking the indexed Go packages when the
+index is built. Imports are loaded from the repository, GOROOT and the local
+module cache; nothing is downloaded.
+`)
+}
+
+// GetHelp returns help text for the command
+func (cmd *ReferencesCommand) GetHelp() string {
+	if cmd.callers {
+		return `callers <function> [options] - Find call sites of a Go function or method
+
+Use 'code-search callers --help' for detailed usage information.`
+	}
+	return `refs <identifier> [options] - Find definitions and references of a Go identifier
+
+Use 'code-search refs --help' for detailed usage information.`
+}
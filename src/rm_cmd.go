@@ -0,0 +1,220 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"code-search/src/lib"
+	"code-search/src/services"
+)
+
+// RemoveCommand implements the rm command
+type RemoveCommand struct {
+	indexingService *services.IndexingService
+	fileUtils       *lib.FileUtilities
+}
+
+// NewRemoveCommand creates a new rm command
+func NewRemoveCommand() *RemoveCommand {
+	return &RemoveCommand{
+		indexingService: newInspectionService(services.DefaultConfig()),
+		fileUtils:       lib.NewFileUtilities(),
+	}
+}
+
+// RemoveOptions contains rm command options
+type RemoveOptions struct {
+	directory string
+	allStale  bool
+	dryRun    bool
+}
+
+// Execute executes the rm command with the given arguments
+func (cmd *RemoveCommand) Execute(args []string) error {
+	options, err := cmd.parseRemoveOptions(args)
+	if err != nil {
+		return NewInvalidArgumentError("invalid rm options", err)
+	}
+
+	registry := lib.NewIndexRegistry(lib.DefaultRegistryPath())
+	if options.allStale {
+		return cmd.removeStale(registry, options.dryRun)
+	}
+	return cmd.removeDirectory(registry, options.directory, options.dryRun)
+}
+
+// parseRemoveOptions parses command line options for rm
+func (cmd *RemoveCommand) parseRemoveOptions(args []string) (RemoveOptions, error) {
+	options := RemoveOptions{}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch arg {
+		case "--dir", "-d":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--dir requires a directory path", nil)
+			}
+			options.directory = args[i+1]
+			i++
+
+		case "--all-stale":
+			options.allStale = true
+
+		case "--dry-run", "-n":
+			options.dryRun = true
+
+		case "--help", "-h":
+			cmd.printRemoveHelp()
+			os.Exit(0)
+
+		default:
+			return options, NewInvalidArgumentError(fmt.Sprintf("unknown option: %s", arg), nil)
+		}
+	}
+
+	if (options.directory == "") == !options.allStale {
+		return options, NewInvalidArgumentError("either --dir or --all-stale is required", nil)
+	}
+	return options, nil
+}
+
+// removeDirectory deletes the indexes of one directory and forgets them
+func (cmd *RemoveCommand) removeDirectory(registry *lib.IndexRegistry, directory string, dryRun bool) error {
+	resolved, err := cmd.fileUtils.ResolvePath(directory)
+	if err != nil {
+		return NewInvalidArgumentError("failed to resolve directory path", err)
+	}
+
+	entries, err := registry.Entries()
+	if err != nil {
+		return NewGeneralError("failed to read the index registry", err)
+	}
+	var registered []string
+	for _, entry := range entries {
+		if entry.Directory == resolved {
+			registered = append(registered, entry.IndexPath)
+		}
+	}
+
+	onDisk := cmd.fileUtils.DirectoryExists(cmd.fileUtils.CreateIndexLocation(resolved).IndexDir)
+	for _, name := range services.SingleFileIndexNames {
+		onDisk = onDisk || cmd.fileUtils.FileExists(filepath.Join(resolved, name))
+	}
+	if !onDisk && len(registered) == 0 {
+		return NewIndexNotFoundError(resolved)
+	}
+
+	if dryRun {
+		fmt.Printf("Would remove the index of %s\n", resolved)
+		return nil
+	}
+	if err := cmd.indexingService.DeleteDirectoryIndex(resolved); err != nil {
+		if errors.Is(err, services.ErrIndexLocked) {
+			return NewGeneralError("index is in use", err)
+		}
+		return NewGeneralError("failed to remove index", err)
+	}
+	if err := registry.Unregister(registered...); err != nil {
+		return NewGeneralError("failed to update the index registry", err)
+	}
+
+	fmt.Printf("Removed the index of %s\n", resolved)
+	return nil
+}
+
+// removeStale deletes every stale, missing or orphaned index and forgets it. Locked
+// indexes are left alone.
+func (cmd *RemoveCommand) removeStale(registry *lib.IndexRegistry, dryRun bool) error {
+	indexes, err := registeredIndexes(registry, cmd.fileUtils)
+	if err != nil {
+		return NewGeneralError("failed to read the index registry", err)
+	}
+
+	var removed []string
+	deleted := make(map[string]bool)
+	locked, failed := 0, 0
+	for _, index := range indexes {
+		if !index.Stale() {
+			continue
+		}
+		if index.Locked {
+			fmt.Printf("Skipped %s: the index is locked by a running index\n", index.Directory)
+			locked++
+			continue
+		}
+		if dryRun {
+			fmt.Printf("Would remove %s: %s\n", index.Directory, describeIndexState(index))
+			continue
+		}
+
+		// Orphaned and missing indexes have nothing left to delete
+		if index.State == indexStateStale {
+			if err := cmd.indexingService.DeleteDirectoryIndex(index.Directory); err != nil {
+				fmt.Printf("Failed to remove %s: %v\n", index.Directory, err)
+				if errors.Is(err, services.ErrIndexLocked) {
+					locked++
+				} else {
+					failed++
+				}
+				continue
+			}
+			deleted[index.Directory] = true
+		}
+		removed = append(removed, index.IndexPath)
+		fmt.Printf("Removed %s: %s\n", index.Directory, describeIndexState(index))
+	}
+
+	// Deleting a directory's index deletes every index registered for it
+	for _, index := range indexes {
+		if deleted[index.Directory] && !index.Stale() {
+			removed = append(removed, index.IndexPath)
+		}
+	}
+	if err := registry.Unregister(removed...); err != nil {
+		return NewGeneralError("failed to update the index registry", err)
+	}
+	if !dryRun && len(removed) == 0 && locked == 0 && failed == 0 {
+		fmt.Println("No stale indexes")
+	}
+
+	switch {
+	case failed > 0:
+		return NewGeneralError(fmt.Sprintf("%d indexes could not be removed", failed), nil)
+	case locked > 0:
+		return NewGeneralError(fmt.Sprintf("%d locked indexes were skipped", locked), nil)
+	}
+	return nil
+}
+
+// printRemoveHelp prints help for the rm command
+func (cmd *RemoveCommand) printRemoveHelp() {
+	fmt.Printf(`Usage: code-search rm (--dir <directory> | --all-stale) [options]
+
+Deletes indexes and removes them from the registry that 'code-search list' shows. Only
+index files are deleted: the directory's .clindex and the .code-search-index files that
+'code-search index' writes without --dir. Indexes locked by a running index are never
+deleted.
+
+Options:
+  -d, --dir <directory>    Remove the index of this directory
+      --all-stale          Remove every index 'code-search list' shows as stale, missing
+                           or orphaned
+  -n, --dry-run            Show what would be removed without removing it
+  -h, --help               Show this help message
+
+Examples:
+  code-search rm --dir ~/old-project
+  code-search rm --all-stale --dry-run
+  code-search rm --all-stale
+`)
+}
+
+// GetHelp returns help text for the command
+func (cmd *RemoveCommand) GetHelp() string {
+	return `rm (--dir <directory> | --all-stale) - Delete indexes
+
+Use 'code-search rm --help' for detailed usage information.`
+}
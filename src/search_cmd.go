@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -46,13 +47,40 @@ func NewSearchCommand() *SearchCommand {
 
 // Execute executes the search command with the given arguments
 func (cmd *SearchCommand) Execute(args []string) error {
+	var options SearchOptions
+	err := cmd.search(args, &options)
+	if err != nil && options.grepExitCodes {
+		return NewGrepError(err)
+	}
+	return err
+}
+
+// search runs the search and displays its results. The parsed options are stored in
+// options, even when parsing fails part way.
+func (cmd *SearchCommand) search(args []string, options *SearchOptions) error {
 	if len(args) < 1 {
 		return NewInvalidArgumentError("search query is required", nil)
 	}
 
+	// Configuration files and the environment supply defaults that flags override
+	config, err := loadConfig(argValue(args[1:], "--dir", "-d"))
+	if err != nil {
+		return err
+	}
+	if _, exists := formatterRegistry[strings.ToLower(config.Format)]; !exists {
+		_, origin := config.Source("search.format")
+		return NewInvalidArgumentError(fmt.Sprintf("invalid format in %s: %s (supported: %s)", origin, config.Format, strings.Join(formatterNames(), ", ")), nil)
+	}
+	cmd.searchService = services.NewSearchService(
+		lib.NewSimpleCodeParser(),
+		lib.NewInMemoryVectorStore(""),
+		cmd.logger,
+		config.SearchOptions(),
+	)
+
 	// Parse arguments
 	queryText := args[0]
-	options, err := cmd.parseSearchOptions(args[1:])
+	*options, err = cmd.parseSearchOptions(args[1:], config)
 	if err != nil {
 		return NewInvalidArgumentError("invalid search options", err)
 	}
@@ -60,7 +88,11 @@ func (cmd *SearchCommand) Execute(args []string) error {
 	// Create search query
 	query := models.NewSearchQuery(queryText)
 	query.MaxResults = options.maxResults
-	query.IncludeContext = options.withContext
+	query.SortOrder = options.sort
+	// Explicit context counts, even 0, replace the default lines of --with-context
+	query.IncludeContext = options.withContext && !options.contextSet
+	query.ContextBefore = options.contextBefore
+	query.ContextAfter = options.contextAfter
 	query.FileFilter = options.filePattern
 	query.Threshold = options.threshold
 
@@ -71,19 +103,59 @@ func (cmd *SearchCommand) Execute(args []string) error {
 		query.SearchType = models.SearchTypeExact
 	} else if options.fuzzy {
 		query.SearchType = models.SearchTypeFuzzy
+	} else if options.regex || options.multiline {
+		query.SearchType = models.SearchTypeRegex
+	} else if options.all {
+		// Exhaustive searches stream, which needs a lexical search type, so the
+		// default hybrid search runs as a text search instead
+		query.SearchType = models.SearchTypeText
+		fmt.Fprintln(os.Stderr, "Note: --all runs a text search; hybrid ranking is not available with --all")
+	}
+	query.Multiline = options.multiline
+	query.MaxEdits = options.maxEdits
+	query.CaseSensitive = options.caseSensitive
+	query.SmartCase = options.smartCase
+	query.WholeWord = options.wholeWord
+	query.NoSynonyms = options.noSynonyms
+
+	// Parse structured syntax such as lang:go, path:, sym:, quoted phrases, OR and negation
+	if err := query.ParseStructuredQuery(); err != nil {
+		var parseErr *models.QueryParseError
+		if errors.As(err, &parseErr) {
+			return NewQueryParseError(parseErr.Query, parseErr.Column, parseErr.Message)
+		}
+		return NewInvalidArgumentError("invalid search query", err)
+	}
+
+	// A cursor is tied to the parsed query, so it is decoded after parsing
+	query.Offset = options.offset
+	if options.cursor != "" {
+		offset, err := models.DecodeCursor(query, options.cursor)
+		if err != nil {
+			return NewInvalidArgumentError("invalid cursor", err)
+		}
+		query.Offset = offset
+	}
+
+	// Listing files or counting matches covers every match, not the top 10. Lexical
+	// searches find every match exhaustively; semantic and hybrid searches rank as many
+	// candidates as a query may ask for.
+	exhaustive := options.all
+	if (options.filesWithMatches || options.count) && !options.maxResultsSet {
+		if query.IsLexical() {
+			exhaustive = true
+		} else {
+			query.MaxResults = 1000
+		}
 	}
 
 	// Create embedding config if semantic search is enabled
 	var searchService SearchServiceInterface = cmd.searchService
 	if options.semantic || query.SearchType == models.SearchTypeSemantic || query.SearchType == models.SearchTypeHybrid {
-		embeddingConfig := lib.EmbeddingConfig{
-			ModelName:        options.modelName,
-			MaxBatchSize:     32,
-			CacheSize:        options.cacheSize,
-			MemoryLimit:      options.memoryLimit,
-			SemanticWeight:   0.7,
-			TextWeight:       0.3,
-		}
+		embeddingConfig := config.EmbeddingConfig()
+		embeddingConfig.ModelName = options.modelName
+		embeddingConfig.CacheSize = options.cacheSize
+		embeddingConfig.MemoryLimit = options.memoryLimit
 
 		// Create enhanced search service with embedding support
 		enhancedService, err := services.NewEnhancedSearchServiceWithConfig(cmd.searchService, embeddingConfig)
@@ -93,36 +165,60 @@ func (cmd *SearchCommand) Execute(args []string) error {
 		searchService = enhancedService
 	}
 
-	// Determine target directory for locking
-	targetDir := options.directory
-	if targetDir == "" {
-		targetDir, err = os.Getwd()
-		if err != nil {
-			return NewGeneralError("failed to get current directory", err)
+	// Search the current directory (backward compatibility) unless repositories are given
+	indexPath := cmd.getIndexPath(options.force)
+	repositories, err := cmd.resolveRepositories(*options)
+	if err != nil {
+		return err
+	}
+	multiRepository := len(repositories) > 1
+	if len(repositories) == 0 && (len(query.RepoFilters) > 0 || len(query.ExcludeRepos) > 0) {
+		return NewInvalidArgumentError("repo: filters need repositories given with --dir or --workspace", nil)
+	}
+	if len(repositories) > 0 {
+		names := make([]string, len(repositories))
+		for i, repository := range repositories {
+			names[i] = repository.Name
+		}
+		if repositories, err = services.FilterRepositories(query, repositories); err != nil {
+			return NewInvalidArgumentError(fmt.Sprintf("invalid search query (repositories: %s)", strings.Join(names, ", ")), err)
 		}
+		indexPath = repositories[0].IndexPath
 	}
 
 	// Note: File locking disabled temporarily to resolve search issues
-	// lockFile, err := cmd.fileUtils.AcquireSharedLock(targetDir)
+	// lockFile, err := cmd.fileUtils.AcquireSharedLock(repositories[0].Directory)
 	// if err != nil {
 	// 	return NewGeneralError("failed to acquire search lock", err)
 	// }
 	// defer cmd.fileUtils.ReleaseLock(lockFile)
 
-	// Perform search based on whether directory is specified
-	start := time.Now()
 	var results *models.SearchResults
 
-	if options.directory != "" {
-		// Search in specified directory - resolve index location
-		indexPath, err := cmd.fileUtils.GetIndexLocation(options.directory)
-		if err != nil {
-			return NewInvalidArgumentError("failed to resolve index location", err)
+	// Exhaustive searches print results as they are found when the output format can
+	formatter, _ := newResultFormatter(options.format, FormatterOptions{
+		Color: lib.NewColorizer(lib.UseColor(options.color, os.Stdout)),
+	})
+	streamer, streaming := formatter.(StreamFormatter)
+	streaming = streaming && exhaustive && canStream(*options)
+	var emit func(*models.SearchResult) error
+	if streaming {
+		if err := streamer.Begin(os.Stdout, query); err != nil {
+			return NewGeneralError("failed to write results", err)
 		}
-		results, err = searchService.Search(query, indexPath)
-	} else {
-		// Search current directory (backward compatibility)
-		indexPath := cmd.getIndexPath(options.force)
+		emit = func(result *models.SearchResult) error {
+			return streamer.Write(os.Stdout, result)
+		}
+	}
+
+	switch {
+	case multiRepository && exhaustive:
+		results, err = cmd.searchRepositoriesAll(query, repositories, emit)
+	case multiRepository:
+		results, err = services.SearchRepositories(searchService, query, repositories)
+	case exhaustive:
+		results, err = cmd.searchAll(query, indexPath, emit)
+	default:
 		results, err = searchService.Search(query, indexPath)
 	}
 
@@ -134,48 +230,218 @@ func (cmd *SearchCommand) Execute(args []string) error {
 		return NewGeneralError("search failed", err)
 	}
 
-	// Display results based on output format
-	switch options.format {
-	case "json":
-		return cmd.displayJSONResults(results)
-	case "raw":
-		return cmd.displayRawResults(results)
-	default:
-		return cmd.displayTableResults(results, start)
+	if streaming {
+		if err := streamer.End(os.Stdout, results); err != nil {
+			return NewGeneralError("failed to write results", err)
+		}
+	} else {
+		// Ranked searches are sorted before paging; exhaustive ones find matches in path order
+		if exhaustive && options.sort != "" {
+			if err := results.SortBy(options.sort); err != nil {
+				return NewInvalidArgumentError("invalid sort order", err)
+			}
+		}
+
+		if err := cmd.displayResults(results, *options, formatter); err != nil {
+			return err
+		}
 	}
+
+	if options.grepExitCodes && results.TotalResults == 0 {
+		return NewNoMatchesError()
+	}
+	return nil
+}
+
+// canStream reports whether results can be printed as they are found, which needs an
+// output mode that neither regroups, reorders nor summarizes the whole result set
+func canStream(options SearchOptions) bool {
+	return options.groupBy == "" && options.sort == "" && !options.filesWithMatches && !options.count
+}
+
+// searchAll runs an exhaustive lexical search. With an emit function, each result is
+// passed to it as soon as it is found and the returned summary holds no results;
+// otherwise the results are collected for display.
+func (cmd *SearchCommand) searchAll(query *models.SearchQuery, indexPath string, emit func(*models.SearchResult) error) (*models.SearchResults, error) {
+	if emit != nil {
+		return cmd.searchService.Stream(query, indexPath, emit)
+	}
+
+	collected := models.NewSearchResults(query)
+	summary, err := cmd.searchService.Stream(query, indexPath, func(result *models.SearchResult) error {
+		collected.Results = append(collected.Results, result)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	collected.TotalResults = summary.TotalResults
+	collected.SearchedFiles = summary.SearchedFiles
+	collected.ExecutionTime = summary.ExecutionTime
+	collected.Metadata = summary.Metadata
+	return collected, nil
+}
+
+// searchRepositoriesAll runs an exhaustive lexical search of each repository in turn,
+// labelling every result with its repository and numbering results across repositories
+func (cmd *SearchCommand) searchRepositoriesAll(query *models.SearchQuery, repositories []services.Repository, emit func(*models.SearchResult) error) (*models.SearchResults, error) {
+	start := time.Now()
+	combined := models.NewSearchResults(query)
+	paths := make(map[string]string, len(repositories))
+
+	for _, repository := range repositories {
+		summary, err := cmd.searchService.Stream(query, repository.IndexPath, func(result *models.SearchResult) error {
+			result.Repository = repository.Name
+			result.Rank += combined.TotalResults
+			if emit != nil {
+				return emit(result)
+			}
+			combined.Results = append(combined.Results, result)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", repository.Name, err)
+		}
+		combined.TotalResults += summary.TotalResults
+		combined.SearchedFiles += summary.SearchedFiles
+		paths[repository.Name], _ = summary.Metadata[models.MetadataRepositoryPath].(string)
+	}
+	combined.AddMetadata(models.MetadataRepositoryPaths, paths)
+
+	combined.SetExecutionTime(time.Since(start))
+	return combined, nil
+}
+
+// resolveRepositories returns the repositories given with --workspace and --dir, each
+// once, labelled and with the index file in its .clindex
+func (cmd *SearchCommand) resolveRepositories(options SearchOptions) ([]services.Repository, error) {
+	var directories []string
+	if options.workspace != "" {
+		workspace, err := services.LoadWorkspace(options.workspace)
+		if err != nil {
+			return nil, NewInvalidArgumentError("invalid workspace", err)
+		}
+		directories = append(directories, workspace.Repositories...)
+	}
+	for _, directory := range options.directories {
+		resolved, err := cmd.fileUtils.ResolvePath(directory)
+		if err != nil {
+			return nil, NewInvalidArgumentError("failed to resolve directory path", err)
+		}
+		directories = append(directories, resolved)
+	}
+
+	var unique []string
+	seen := make(map[string]bool)
+	for _, directory := range directories {
+		if !seen[directory] {
+			seen[directory] = true
+			unique = append(unique, directory)
+		}
+	}
+
+	var repositories []services.Repository
+	for i, label := range services.RepositoryLabels(unique) {
+		repositories = append(repositories, services.Repository{
+			Name:      label,
+			Directory: unique[i],
+			IndexPath: cmd.fileUtils.CreateIndexLocation(unique[i]).DataFile,
+		})
+	}
+	return repositories, nil
+}
+
+// displayResults displays search results in the requested mode and output format
+func (cmd *SearchCommand) displayResults(results *models.SearchResults, options SearchOptions, formatter ResultFormatter) error {
+	switch {
+	case options.filesWithMatches:
+		return cmd.displayFilesWithMatches(results, options.format)
+	case options.count:
+		return cmd.displayCounts(results, options.format)
+	case options.groupBy != "":
+		groups, err := results.GroupBy(options.groupBy)
+		if err != nil {
+			return NewInvalidArgumentError("invalid group field", err)
+		}
+		if grouper, ok := formatter.(GroupFormatter); ok {
+			return grouper.FormatGroups(os.Stdout, results, groups)
+		}
+
+		// Other formats carry the path of each result, so grouping only changes their order
+		var ordered []*models.SearchResult
+		for _, group := range groups {
+			ordered = append(ordered, group.Results...)
+		}
+		flattened := *results
+		flattened.Results = ordered
+		return formatter.Format(os.Stdout, &flattened)
+	}
+
+	return formatter.Format(os.Stdout, results)
 }
 
 // SearchOptions contains search command options
 type SearchOptions struct {
-	maxResults    int
-	filePattern   string
-	withContext   bool
-	force         bool
-	format        string
-	threshold     float64
-	semantic      bool
-	exact         bool
-	fuzzy         bool
-	directory     string
-	modelName     string
-	embeddingPath string
-	cacheSize     int
-	memoryLimit   int64
+	maxResults       int
+	maxResultsSet    bool
+	offset           int
+	cursor           string
+	all              bool
+	filesWithMatches bool
+	filePattern      string
+	withContext      bool
+	contextBefore    int
+	contextAfter     int
+	contextSet       bool
+	force            bool
+	format           string
+	color            lib.ColorMode
+	threshold        float64
+	semantic         bool
+	exact            bool
+	fuzzy            bool
+	regex            bool
+	multiline        bool
+	maxEdits         int
+	caseSensitive    bool
+	smartCase        bool
+	wholeWord        bool
+	noSynonyms       bool
+	groupBy          string
+	sort             string
+	count            bool
+	grepExitCodes    bool
+	directories      []string
+	workspace        string
+	modelName        string
+	embeddingPath    string
+	cacheSize        int
+	memoryLimit      int64
 }
 
-// parseSearchOptions parses command line options for search
-func (cmd *SearchCommand) parseSearchOptions(args []string) (SearchOptions, error) {
+// parseSearchOptions parses command line options for search, starting from the
+// configured defaults
+func (cmd *SearchCommand) parseSearchOptions(args []string, config *services.Config) (SearchOptions, error) {
 	options := SearchOptions{
 		maxResults:    10,
 		filePattern:   "",
 		withContext:   false,
 		force:         false,
-		format:        "table",
+		format:        strings.ToLower(config.Format),
+		color:         lib.ColorAuto,
 		threshold:     0.7,
 		semantic:      false,
 		exact:         false,
 		fuzzy:         false,
-		modelName:     "all-MiniLM-L6-v2",
+		regex:         false,
+		multiline:     false,
+		maxEdits:      models.DefaultMaxEdits,
+		caseSensitive: false,
+		smartCase:     false,
+		wholeWord:     false,
+		noSynonyms:    false,
+		modelName:     config.Model,
 		embeddingPath: "",
 		cacheSize:     1000,
 		memoryLimit:   200, // MB
@@ -189,13 +455,35 @@ func (cmd *SearchCommand) parseSearchOptions(args []string) (SearchOptions, erro
 			if i+1 >= len(args) {
 				return options, NewInvalidArgumentError("--max-results requires a value", nil)
 			}
-			var maxResults int
-			if _, err := fmt.Sscanf(args[i+1], "%d", &maxResults); err != nil || maxResults < 1 {
-				return options, NewInvalidArgumentError(fmt.Sprintf("invalid max-results value: %s", args[i+1]), nil)
+			maxResults, err := parseMaxResults(args[i+1])
+			if err != nil {
+				return options, err
 			}
 			options.maxResults = maxResults
+			options.maxResultsSet = true
+			i++
+
+		case "--offset":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--offset requires a value", nil)
+			}
+			var offset int
+			if _, err := fmt.Sscanf(args[i+1], "%d", &offset); err != nil || offset < 0 {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid offset value: %s (must be 0 or greater)", args[i+1]), nil)
+			}
+			options.offset = offset
 			i++
 
+		case "--cursor":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--cursor requires a value", nil)
+			}
+			options.cursor = args[i+1]
+			i++
+
+		case "--all":
+			options.all = true
+
 		case "--file-pattern", "-f":
 			if i+1 >= len(args) {
 				return options, NewInvalidArgumentError("--file-pattern requires a value", nil)
@@ -206,6 +494,26 @@ func (cmd *SearchCommand) parseSearchOptions(args []string) (SearchOptions, erro
 		case "--with-context", "-c":
 			options.withContext = true
 
+		case "--after-context", "-A", "--before-context", "-B", "--context", "-C":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError(fmt.Sprintf("%s requires a number of lines", arg), nil)
+			}
+			var lines int
+			if _, err := fmt.Sscanf(args[i+1], "%d", &lines); err != nil || lines < 0 {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid context value: %s (must be 0 or greater)", args[i+1]), nil)
+			}
+			switch arg {
+			case "--after-context", "-A":
+				options.contextAfter = lines
+			case "--before-context", "-B":
+				options.contextBefore = lines
+			default:
+				options.contextBefore = lines
+				options.contextAfter = lines
+			}
+			options.contextSet = true
+			i++
+
 		case "--force", "-F":
 			options.force = true
 
@@ -214,12 +522,23 @@ func (cmd *SearchCommand) parseSearchOptions(args []string) (SearchOptions, erro
 				return options, NewInvalidArgumentError("--format requires a value", nil)
 			}
 			format := strings.ToLower(args[i+1])
-			if format != "table" && format != "json" && format != "raw" {
-				return options, NewInvalidArgumentError(fmt.Sprintf("invalid format: %s (supported: table, json, raw)", format), nil)
+			if _, exists := formatterRegistry[format]; !exists {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid format: %s (supported: %s)", format, strings.Join(formatterNames(), ", ")), nil)
 			}
 			options.format = format
 			i++
 
+		case "--color":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--color requires a value", nil)
+			}
+			color, err := lib.ParseColorMode(args[i+1])
+			if err != nil {
+				return options, NewInvalidArgumentError(err.Error(), nil)
+			}
+			options.color = color
+			i++
+
 		case "--threshold", "-t":
 			if i+1 >= len(args) {
 				return options, NewInvalidArgumentError("--threshold requires a value", nil)
@@ -240,11 +559,78 @@ func (cmd *SearchCommand) parseSearchOptions(args []string) (SearchOptions, erro
 		case "--fuzzy", "-z":
 			options.fuzzy = true
 
+		case "--regex", "-r":
+			options.regex = true
+
+		case "--multiline", "-U":
+			options.multiline = true
+
+		case "--case-sensitive":
+			options.caseSensitive = true
+
+		case "--smart-case", "-S":
+			options.smartCase = true
+
+		case "--word", "-w":
+			options.wholeWord = true
+
+		case "--no-synonyms":
+			options.noSynonyms = true
+
+		case "--group-by":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--group-by requires a value", nil)
+			}
+			groupBy := strings.ToLower(args[i+1])
+			if groupBy != "file" && groupBy != "language" {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid group-by value: %s (supported: file, language)", groupBy), nil)
+			}
+			options.groupBy = groupBy
+			i++
+
+		case "--sort":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--sort requires a value", nil)
+			}
+			order := strings.ToLower(args[i+1])
+			if order != "score" && order != "path" && order != "mtime" {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid sort value: %s (supported: score, path, mtime)", order), nil)
+			}
+			options.sort = order
+			i++
+
+		case "--files-with-matches", "-l":
+			options.filesWithMatches = true
+
+		case "--count":
+			options.count = true
+
+		case "--grep-exit-codes":
+			options.grepExitCodes = true
+
+		case "--max-edits":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--max-edits requires a value", nil)
+			}
+			var maxEdits int
+			if _, err := fmt.Sscanf(args[i+1], "%d", &maxEdits); err != nil || maxEdits < 0 || maxEdits > 5 {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid max-edits value: %s (must be between 0 and 5)", args[i+1]), nil)
+			}
+			options.maxEdits = maxEdits
+			i++
+
 		case "--dir", "-d":
 			if i+1 >= len(args) {
 				return options, NewInvalidArgumentError("--dir requires a directory path", nil)
 			}
-			options.directory = args[i+1]
+			options.directories = append(options.directories, args[i+1])
+			i++
+
+		case "--workspace":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--workspace requires a workspace name or file", nil)
+			}
+			options.workspace = args[i+1]
 			i++
 
 		case "--model", "-M":
@@ -294,9 +680,29 @@ func (cmd *SearchCommand) parseSearchOptions(args []string) (SearchOptions, erro
 		}
 	}
 
+	if options.offset > 0 && options.cursor != "" {
+		return options, NewInvalidArgumentError("--offset and --cursor cannot be used together", nil)
+	}
+	if options.all && (options.offset > 0 || options.cursor != "") {
+		return options, NewInvalidArgumentError("--all returns every result and cannot be paged", nil)
+	}
+	if options.all && options.semantic {
+		return options, NewInvalidArgumentError("--all requires a lexical search and cannot be used with --semantic", nil)
+	}
+
 	return options, nil
 }
 
+// parseMaxResults parses a --max-results value, which must lie between 1 and the
+// largest page a query may ask for
+func parseMaxResults(value string) (int, error) {
+	var maxResults int
+	if _, err := fmt.Sscanf(value, "%d", &maxResults); err != nil || maxResults < 1 || maxResults > models.MaxResultsLimit {
+		return 0, NewInvalidArgumentError(fmt.Sprintf("invalid max-results value: %s (must be between 1 and %d)", value, models.MaxResultsLimit), nil)
+	}
+	return maxResults, nil
+}
+
 // getIndexPath returns the path to the index file
 func (cmd *SearchCommand) getIndexPath(force bool) string {
 	// Default index path
@@ -310,87 +716,64 @@ func (cmd *SearchCommand) getIndexPath(force bool) string {
 	return indexPath
 }
 
-// displayTableResults displays search results in table format
-func (cmd *SearchCommand) displayTableResults(results *models.SearchResults, start time.Time) error {
-	if results == nil || results.Results == nil {
-		fmt.Println("No results found.")
-		return nil
-	}
-
-	if results.IsEmpty() {
-		fmt.Println("No results found.")
-		return nil
-	}
-
-	fmt.Printf("Found %d results:\n\n", len(results.Results))
-
+// displayFilesWithMatches prints each file with at least one match once, in result order
+func (cmd *SearchCommand) displayFilesWithMatches(results *models.SearchResults, format string) error {
+	files := []string{}
+	seen := make(map[string]bool)
 	for _, result := range results.Results {
-		fmt.Printf("%d. %s:%d-%d\n", result.Rank, result.FilePath, result.StartLine, result.EndLine)
-
-		// Display content with syntax highlighting (simplified)
-		content := result.Content
-		if len(content) > 100 {
-			content = content[:97] + "..."
-		}
-		fmt.Printf("   %s\n", content)
-
-		// Display highlights if any
-		if len(result.Highlights) > 0 {
-			highlights := strings.Join(result.Highlights, "; ")
-			if len(highlights) > 80 {
-				highlights = highlights[:77] + "..."
-			}
-			fmt.Printf("   Highlights: %s\n", highlights)
+		if !seen[result.FilePath] {
+			seen[result.FilePath] = true
+			files = append(files, result.FilePath)
 		}
+	}
 
-		// Display context if available
-		if result.Context != "" && len(result.Context) < 200 {
-			contextLines := strings.Split(result.Context, "\n")
-			for _, line := range contextLines {
-				if strings.TrimSpace(line) != "" {
-					fmt.Printf("   %s\n", line)
-				}
-			}
+	if format == "json" {
+		jsonData, err := json.MarshalIndent(map[string]interface{}{"files": files}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to generate JSON output: %w", err)
 		}
-
-		fmt.Printf("   Score: %.3f | Type: %s\n", result.RelevanceScore, result.MatchType)
-		fmt.Println()
+		fmt.Println(string(jsonData))
+		return nil
 	}
 
-	// Display summary
-	fmt.Printf("Search completed in %v\n", results.ExecutionTime)
-	if len(results.Results) != results.TotalResults {
-		fmt.Printf("Showing %d of %d results\n", len(results.Results), results.TotalResults)
+	for _, file := range files {
+		fmt.Println(file)
 	}
-
 	return nil
 }
 
-// displayJSONResults displays search results in JSON format
-func (cmd *SearchCommand) displayJSONResults(results *models.SearchResults) error {
-	// Create a clean JSON structure for output
-	output := map[string]interface{}{
-		"query":          results.Query.QueryText,
-		"totalResults":   results.TotalResults,
-		"displayed":      len(results.Results),
-		"executionTime":  results.ExecutionTime.String(),
-		"has_more":       results.HasMore,
-		"results":        results.Results,
-	}
+// FileCount is the number of matches in one file
+type FileCount struct {
+	File  string `json:"file"`
+	Count int    `json:"count"`
+}
 
-	jsonData, err := json.MarshalIndent(output, "", "  ")
+// displayCounts prints the number of matches per file as path:count, in result order
+func (cmd *SearchCommand) displayCounts(results *models.SearchResults, format string) error {
+	groups, err := results.GroupBy("file")
 	if err != nil {
-		return fmt.Errorf("failed to generate JSON output: %w", err)
+		return err
 	}
 
-	fmt.Println(string(jsonData))
-	return nil
-}
+	counts := []FileCount{}
+	for _, group := range groups {
+		counts = append(counts, FileCount{File: group.Key, Count: group.Count})
+	}
 
-// displayRawResults displays search results in raw format
-func (cmd *SearchCommand) displayRawResults(results *models.SearchResults) error {
-	for _, result := range results.Results {
-		fmt.Printf("%s:%d:%d:%s\n", result.FilePath, result.StartLine, result.EndLine, result.Content)
+	if format == "json" {
+		jsonData, err := json.MarshalIndent(map[string]interface{}{
+			"counts": counts,
+			"total":  len(results.Results),
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to generate JSON output: %w", err)
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	for _, count := range counts {
+		fmt.Printf("%s:%d\n", count.File, count.Count)
 	}
 	return nil
 }
@@ -403,16 +786,39 @@ Arguments:
   <query>                  The search query text
 
 Options:
-  -m, --max-results <n>    Maximum number of results to return (default: 10)
+  -m, --max-results <n>    Maximum number of results to return (default: 10, at most 1000)
+      --offset <n>         Skip the first n ranked results, to fetch a later page
+      --cursor <c>         Fetch the page a previous search returned as next_cursor
+      --all                Print every match as it is found, in path order (lexical searches
+                           only; runs a text search unless --exact, --regex or --fuzzy is given)
   -f, --file-pattern <p>   Filter results by file pattern (e.g., "*.go")
-  -c, --with-context       Include code context in results
+  -c, --with-context       Include 3 lines of context around each result
+  -A, --after-context <n>  Include n lines of context after each result
+  -B, --before-context <n> Include n lines of context before each result
+  -C, --context <n>        Include n lines of context before and after each result
   -F, --force              Force search (use test index)
-  -d, --dir <directory>     Specify directory to search (default: current directory)
-      --format <fmt>       Output format: table, json, raw (default: table)
+  -d, --dir <directory>     Specify directory to search (default: current directory); repeat
+                            to search several repositories
+      --workspace <name>   Search the repositories listed in a workspace, by name or file
+      --format <fmt>       Output format: table, json, raw, ndjson, sarif, vimgrep, csv (default: table)
+      --color <when>       Color table output: auto, always, never (default: auto)
   -t, --threshold <t>      Similarity threshold (0.0-1.0, default: 0.7)
   -s, --semantic          Use semantic search
   -e, --exact             Use exact matching
   -z, --fuzzy             Use fuzzy matching
+      --max-edits <n>      Maximum edit distance for fuzzy matching (0-5, default: 2)
+      --case-sensitive     Match case exactly in text, exact, fuzzy and regex search
+  -S, --smart-case         Match case only when the query contains uppercase letters
+  -w, --word               Only match whole words; an edge that is a symbol, as in "foo(",
+                           needs no word boundary
+      --no-synonyms        Do not expand terms with synonyms and abbreviations (e.g. cfg, config)
+      --group-by <field>   Group results by file or language
+      --sort <order>       Order results by score, path or mtime (default: score)
+  -l, --files-with-matches Print only the paths of files with matches
+      --count              Print the number of matches in each file as path:count
+      --grep-exit-codes    Exit 1 when nothing matched and 2 on any error, like grep
+  -r, --regex             Use regular expression matching
+  -U, --multiline         Match the regex against whole files so it can span lines
   -M, --model <name>       Embedding model name (default: all-MiniLM-L6-v2)
       --embedding-path     Path to external embedding model file
       --cache-size <n>     Embedding cache size (default: 1000)
@@ -425,21 +831,56 @@ Examples:
   code-search search "database query" --with-context --format json
   code-search search "function.*error" --semantic --threshold 0.8
   code-search search "TODO" --dir /path/to/my-project
+  code-search search 'func \w+\(ctx[^)]*\)\s*\{\s*return nil' --multiline
+  code-search search "UsreAuth" --fuzzy --max-edits 1
+  code-search search "Err" --exact --word --case-sensitive
+  code-search search 'lang:go -path:_test.go (retry OR backoff) -deprecated' --threshold 0.3
   code-search search "class.*Controller" --dir ../sibling-project --format json
   code-search search "import.*react" --dir ~/frontend --max-results 10
+  code-search search "retry policy" --dir ~/src/api --dir ~/src/web
+  code-search search "TODO -repo:web" --exact --workspace product
   code-search search "user login" --semantic --model all-MiniLM-L6-v2
   code-search search "api endpoint" --model custom-model --embedding-path /path/to/model.onnx
   code-search search "memory leak" --cache-size 2000 --memory-limit 500
+  code-search search "TODO" --exact --group-by file --sort path
+  code-search search "handler" --max-results 50 --offset 50
+  code-search search "TODO" --exact --all --format raw
+  code-search search "eval(" --exact --all --format sarif > findings.sarif
+  code-search search "deprecated" --exact -l --grep-exit-codes && echo "found"
+  code-search search "TODO" --exact --color always | less -R
+
+Query Syntax:
+  lang:<language>          Restrict results to one language
+  path:<text>, -path:<text>  Keep or exclude files whose path contains text (globs allowed)
+  sym:<name>               Keep matches on lines defining a symbol containing name
+  repo:<name>, -repo:<name>  Search or skip repositories of a multi-repository search
+  "exact phrase"           Match a phrase exactly
+  (a OR b), -term          Group alternatives and exclude terms
 
 Output Formats:
   table    Human-readable table format (default)
   json     Machine-readable JSON format
   raw      Simple file:line:line:content format
+  ndjson   One JSON event per line, shaped like ripgrep --json: begin, match, context, summary
+  sarif    SARIF 2.1.0 log with a finding per result, for code scanning uploads
+  vimgrep  path:line:column:text lines for the Vim/Neovim quickfix list and Emacs grep-mode
+  csv      A header row and one row per result, for spreadsheets
+
+Multiple Repositories:
+  Repeating --dir or naming a workspace searches each repository's index concurrently and
+  merges the results by score; every search type scores on the same 0-1 scale in each index.
+  Results are labelled with the repository's directory name. Workspaces are YAML files in
+  $XDG_CONFIG_HOME/code-search/workspaces/<name>.yaml listing directories:
+
+    repositories:
+      - ~/src/api
+      - ~/src/web
 
 Search Types:
   semantic Vector-based semantic search (default for combined search)
   exact    Exact phrase matching
-  fuzzy    Fuzzy string matching
+  fuzzy    Fuzzy identifier matching (edit distance and subsequence scoring)
+  regex    Regular expression matching (line by line, or whole file with --multiline)
 
 Embedding Models:
   all-MiniLM-L6-v2   Default multilingual model (384 dimensions)
@@ -454,8 +895,10 @@ Embedding Options:
 Exit Codes:
   0        Search completed successfully
   1        Error during search
-  2        Invalid arguments
+  2        Invalid arguments or query syntax error
   3        Index not found (run 'code-search index' first)
+
+  With --grep-exit-codes: 0 when results were found, 1 when nothing matched, 2 on any error
 `)
 }
 
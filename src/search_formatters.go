@@ -0,0 +1,452 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"code-search/src/lib"
+	"code-search/src/models"
+)
+
+// ResultFormatter writes search results in one output format
+type ResultFormatter interface {
+	// Format writes a complete set of results
+	Format(out io.Writer, results *models.SearchResults) error
+}
+
+// StreamFormatter is a ResultFormatter that can also write results one at a time, as
+// they are found
+type StreamFormatter interface {
+	ResultFormatter
+	// Begin writes whatever precedes the first result
+	Begin(out io.Writer, query *models.SearchQuery) error
+	// Write writes one result
+	Write(out io.Writer, result *models.SearchResult) error
+	// End writes whatever follows the last result, given the totals of the search
+	End(out io.Writer, results *models.SearchResults) error
+}
+
+// GroupFormatter is a ResultFormatter with its own layout for grouped results. Formats
+// that are not group formatters show grouped results in group order.
+type GroupFormatter interface {
+	ResultFormatter
+	// FormatGroups writes results under their groups
+	FormatGroups(out io.Writer, results *models.SearchResults, groups []models.ResultGroup) error
+}
+
+// FormatterOptions are the display settings passed to every formatter
+type FormatterOptions struct {
+	// Color styles terminal output; formats that are read by other tools ignore it
+	Color *lib.Colorizer
+}
+
+// formatterRegistry maps --format names to functions creating their formatters. Each
+// search creates its own formatter, so formatters may keep state between results.
+var formatterRegistry = make(map[string]func(FormatterOptions) ResultFormatter)
+
+// RegisterFormatter makes an output format available to --format
+func RegisterFormatter(name string, factory func(FormatterOptions) ResultFormatter) {
+	formatterRegistry[name] = factory
+}
+
+// newResultFormatter creates the formatter of an output format
+func newResultFormatter(name string, options FormatterOptions) (ResultFormatter, bool) {
+	factory, exists := formatterRegistry[name]
+	if !exists {
+		return nil, false
+	}
+	return factory(options), true
+}
+
+// formatterNames returns the registered output formats in sorted order
+func formatterNames() []string {
+	names := make([]string, 0, len(formatterRegistry))
+	for name := range formatterRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterFormatter("table", func(options FormatterOptions) ResultFormatter {
+		return &tableFormatter{color: options.Color}
+	})
+	RegisterFormatter("json", func(FormatterOptions) ResultFormatter { return &jsonFormatter{} })
+	RegisterFormatter("raw", func(FormatterOptions) ResultFormatter { return &rawFormatter{} })
+	RegisterFormatter("ndjson", func(FormatterOptions) ResultFormatter { return &ndjsonFormatter{} })
+	RegisterFormatter("sarif", func(FormatterOptions) ResultFormatter { return &sarifFormatter{} })
+	RegisterFormatter("vimgrep", func(FormatterOptions) ResultFormatter { return &vimgrepFormatter{} })
+	RegisterFormatter("csv", func(FormatterOptions) ResultFormatter { return &csvFormatter{} })
+}
+
+// formatAll writes a complete set of results through a stream formatter
+func formatAll(formatter StreamFormatter, out io.Writer, results *models.SearchResults) error {
+	if err := formatter.Begin(out, results.Query); err != nil {
+		return err
+	}
+	for _, result := range results.Results {
+		if err := formatter.Write(out, result); err != nil {
+			return err
+		}
+	}
+	return formatter.End(out, results)
+}
+
+// snippetWidth is the number of bytes of a matched line shown in table output
+const snippetWidth = 100
+
+// tableFormatter writes human-readable results, colored when color is enabled
+type tableFormatter struct {
+	color *lib.Colorizer
+}
+
+// Format writes the results with a header and a summary
+func (f *tableFormatter) Format(out io.Writer, results *models.SearchResults) error {
+	if results == nil || results.IsEmpty() {
+		fmt.Fprintln(out, "No results found.")
+		return nil
+	}
+
+	fmt.Fprintf(out, "Found %d results:\n\n", len(results.Results))
+
+	for _, result := range results.Results {
+		f.writeResult(out, result, "")
+	}
+
+	writeTableSummary(out, results)
+	return nil
+}
+
+// FormatGroups writes the results under a header per group
+func (f *tableFormatter) FormatGroups(out io.Writer, results *models.SearchResults, groups []models.ResultGroup) error {
+	if results.IsEmpty() {
+		fmt.Fprintln(out, "No results found.")
+		return nil
+	}
+
+	fmt.Fprintf(out, "Found %d results in %d groups:\n\n", len(results.Results), len(groups))
+
+	for _, group := range groups {
+		fmt.Fprintf(out, "%s (%d)\n", f.color.Heading(group.Key), group.Count)
+		for _, result := range group.Results {
+			f.writeResult(out, result, "  ")
+		}
+	}
+
+	writeTableSummary(out, results)
+	return nil
+}
+
+// Begin writes nothing, since the number of results is not known yet
+func (f *tableFormatter) Begin(out io.Writer, query *models.SearchQuery) error {
+	return nil
+}
+
+// Write writes one result
+func (f *tableFormatter) Write(out io.Writer, result *models.SearchResult) error {
+	f.writeResult(out, result, "")
+	return nil
+}
+
+// End writes the totals of results that were written as they were found
+func (f *tableFormatter) End(out io.Writer, results *models.SearchResults) error {
+	if results.TotalResults == 0 {
+		fmt.Fprintln(out, "No results found.")
+		return nil
+	}
+	fmt.Fprintf(out, "Found %d results in %d files searched\n", results.TotalResults, results.SearchedFiles)
+	fmt.Fprintf(out, "Search completed in %v\n", results.ExecutionTime)
+	return nil
+}
+
+// writeResult writes one result in table format, indented by indent
+func (f *tableFormatter) writeResult(out io.Writer, result *models.SearchResult, indent string) {
+	location := fmt.Sprintf("%d-%d", result.StartLine, result.EndLine)
+	repository := ""
+	if result.Repository != "" {
+		repository = fmt.Sprintf("[%s] ", f.color.Heading(result.Repository))
+	}
+	fmt.Fprintf(out, "%s%d. %s%s:%s\n", indent, result.Rank, repository, f.color.Path(result.FilePath), f.color.LineNumber(location))
+
+	// Display the matched line, shortened around the match
+	line, matches := resultSnippet(result)
+	line, matches = lib.TruncateAround(line, matches, snippetWidth)
+	fmt.Fprintf(out, "%s   %s\n", indent, f.color.Snippet(line, result.Language, matches))
+
+	// Without color, list the highlights since they are not marked inline
+	if !f.color.Enabled() && len(result.Highlights) > 0 {
+		highlights := strings.Join(result.Highlights, "; ")
+		if len(highlights) > 80 {
+			highlights = highlights[:77] + "..."
+		}
+		fmt.Fprintf(out, "%s   Highlights: %s\n", indent, highlights)
+	}
+
+	// Display surrounding lines, or a context string from older producers
+	if result.HasContextLines() {
+		f.writeContext(out, result, indent)
+	} else if result.Context != "" && len(result.Context) < 200 {
+		contextLines := strings.Split(result.Context, "\n")
+		for _, line := range contextLines {
+			if strings.TrimSpace(line) != "" {
+				fmt.Fprintf(out, "%s   %s\n", indent, f.color.Dim(line))
+			}
+		}
+	}
+
+	fmt.Fprintf(out, "%s   %s\n", indent, f.color.Dim(fmt.Sprintf("Score: %.3f | Type: %s", result.RelevanceScore, result.MatchType)))
+	fmt.Fprintln(out)
+}
+
+// writeContext writes a result's surrounding lines with line numbers, marking the match
+func (f *tableFormatter) writeContext(out io.Writer, result *models.SearchResult, indent string) {
+	for _, line := range result.ContextBefore {
+		fmt.Fprintf(out, "%s   %s  %s\n", indent, f.color.LineNumber(fmt.Sprintf("%5d", line.Line)), f.color.Snippet(line.Text, result.Language, nil))
+	}
+	content := f.color.Snippet(result.Content, result.Language, columnRanges(result.Content, result.Spans, result.StartLine))
+	fmt.Fprintf(out, "%s   %s> %s\n", indent, f.color.LineNumber(fmt.Sprintf("%5d", result.StartLine)), content)
+	for _, line := range result.ContextAfter {
+		fmt.Fprintf(out, "%s   %s  %s\n", indent, f.color.LineNumber(fmt.Sprintf("%5d", line.Line)), f.color.Snippet(line.Text, result.Language, nil))
+	}
+}
+
+// writeTableSummary writes the search time, which results were shown and how to get the next page
+func writeTableSummary(out io.Writer, results *models.SearchResults) {
+	fmt.Fprintf(out, "Search completed in %v\n", results.ExecutionTime)
+	if len(results.Results) != results.TotalResults {
+		fmt.Fprintf(out, "Showing results %d-%d of %d\n", results.Offset+1, results.Offset+len(results.Results), results.TotalResults)
+	}
+	if results.HasMore {
+		fmt.Fprintf(out, "Next page: --offset %d or --cursor %s\n", results.Offset+len(results.Results), results.NextCursor)
+	}
+}
+
+// resultSnippet returns the line of a result to display, which is the line of its first
+// match without its indentation, with the byte ranges of the matches on that line
+func resultSnippet(result *models.SearchResult) (string, [][2]int) {
+	lines := strings.Split(result.Content, "\n")
+	index := 0
+	for _, span := range result.Spans {
+		if offset := span.StartLine - result.StartLine; offset >= 0 && offset < len(lines) {
+			index = offset
+			break
+		}
+	}
+	line := strings.TrimLeft(lines[index], " \t")
+
+	if len(result.Spans) > 0 {
+		indent := len(lines[index]) - len(line)
+		var ranges [][2]int
+		for _, r := range columnRanges(lines[index], result.Spans, result.StartLine+index) {
+			ranges = append(ranges, [2]int{max(r[0]-indent, 0), max(r[1]-indent, 0)})
+		}
+		return line, ranges
+	}
+
+	// Producers without spans only report the highlighted text
+	var ranges [][2]int
+	for _, highlight := range result.Highlights {
+		if start := strings.Index(line, highlight); highlight != "" && start >= 0 {
+			ranges = append(ranges, [2]int{start, start + len(highlight)})
+		}
+	}
+	return line, ranges
+}
+
+// columnRanges returns the byte ranges within line of the single-line spans of lineNumber.
+// Span columns count from the start of the file line, as result content does.
+func columnRanges(line string, spans []models.MatchSpan, lineNumber int) [][2]int {
+	var ranges [][2]int
+	for _, span := range spans {
+		start, end := span.StartColumn-1, span.EndColumn-1
+		if span.StartLine != lineNumber || span.EndLine != lineNumber || start < 0 || end <= start || end > len(line) {
+			continue
+		}
+		ranges = append(ranges, [2]int{start, end})
+	}
+	return ranges
+}
+
+// jsonFormatter writes the results as one JSON object
+type jsonFormatter struct{}
+
+// Format writes the results and their totals
+func (f *jsonFormatter) Format(out io.Writer, results *models.SearchResults) error {
+	// Create a clean JSON structure for output
+	output := map[string]interface{}{
+		"query":         results.Query.OriginalText(),
+		"totalResults":  results.TotalResults,
+		"displayed":     len(results.Results),
+		"executionTime": results.ExecutionTime.String(),
+		"has_more":      results.HasMore,
+		"offset":        results.Offset,
+		"results":       results.Results,
+	}
+	if results.NextCursor != "" {
+		output["next_cursor"] = results.NextCursor
+	}
+
+	jsonData, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to generate JSON output: %w", err)
+	}
+
+	fmt.Fprintln(out, string(jsonData))
+	return nil
+}
+
+// FormatGroups writes the results under their groups
+func (f *jsonFormatter) FormatGroups(out io.Writer, results *models.SearchResults, groups []models.ResultGroup) error {
+	if groups == nil {
+		groups = []models.ResultGroup{}
+	}
+	output := map[string]interface{}{
+		"query":         results.Query.OriginalText(),
+		"totalResults":  results.TotalResults,
+		"displayed":     len(results.Results),
+		"executionTime": results.ExecutionTime.String(),
+		"has_more":      results.HasMore,
+		"groups":        groups,
+	}
+
+	jsonData, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to generate JSON output: %w", err)
+	}
+
+	fmt.Fprintln(out, string(jsonData))
+	return nil
+}
+
+// rawFormatter writes results as path:start:end:content lines. As in grep, context lines
+// use "-" instead of ":" after the path and line, and "--" separates non-adjacent groups.
+type rawFormatter struct {
+	lastFile string
+	lastLine int
+}
+
+// Format writes every result
+func (f *rawFormatter) Format(out io.Writer, results *models.SearchResults) error {
+	return formatAll(f, out, results)
+}
+
+// Begin writes nothing
+func (f *rawFormatter) Begin(out io.Writer, query *models.SearchQuery) error {
+	return nil
+}
+
+// Write writes one result with its context lines
+func (f *rawFormatter) Write(out io.Writer, result *models.SearchResult) error {
+	if result.HasContextLines() {
+		firstLine := result.StartLine
+		if len(result.ContextBefore) > 0 {
+			firstLine = result.ContextBefore[0].Line
+		}
+		if f.lastFile != "" && (result.FilePath != f.lastFile || firstLine != f.lastLine+1) {
+			fmt.Fprintln(out, "--")
+		}
+		for _, line := range result.ContextBefore {
+			fmt.Fprintf(out, "%s-%d-%s\n", result.FilePath, line.Line, line.Text)
+		}
+	}
+
+	fmt.Fprintf(out, "%s:%d:%d:%s\n", result.FilePath, result.StartLine, result.EndLine, result.Content)
+
+	if result.HasContextLines() {
+		for _, line := range result.ContextAfter {
+			fmt.Fprintf(out, "%s-%d-%s\n", result.FilePath, line.Line, line.Text)
+		}
+		f.lastFile, f.lastLine = result.FilePath, result.EndLine
+		if len(result.ContextAfter) > 0 {
+			f.lastLine = result.ContextAfter[len(result.ContextAfter)-1].Line
+		}
+	}
+	return nil
+}
+
+// End writes nothing
+func (f *rawFormatter) End(out io.Writer, results *models.SearchResults) error {
+	return nil
+}
+
+// vimgrepFormatter writes results as path:line:column:text lines, which Vim and Neovim
+// read into the quickfix list and Emacs reads in grep-mode
+type vimgrepFormatter struct{}
+
+// Format writes every result
+func (f *vimgrepFormatter) Format(out io.Writer, results *models.SearchResults) error {
+	return formatAll(f, out, results)
+}
+
+// Begin writes nothing
+func (f *vimgrepFormatter) Begin(out io.Writer, query *models.SearchQuery) error {
+	return nil
+}
+
+// Write writes the first line of a result at the column of its first match
+func (f *vimgrepFormatter) Write(out io.Writer, result *models.SearchResult) error {
+	column := result.StartColumn
+	if column < 1 {
+		column = 1
+	}
+	_, err := fmt.Fprintf(out, "%s:%d:%d:%s\n", result.FilePath, result.StartLine, column, firstLine(result.Content))
+	return err
+}
+
+// End writes nothing
+func (f *vimgrepFormatter) End(out io.Writer, results *models.SearchResults) error {
+	return nil
+}
+
+// csvHeader names the columns of CSV output
+var csvHeader = []string{"rank", "path", "start_line", "end_line", "start_column", "score", "match_type", "language", "content"}
+
+// csvFormatter writes a header row and one row per result
+type csvFormatter struct {
+	writer *csv.Writer
+}
+
+// Format writes every result
+func (f *csvFormatter) Format(out io.Writer, results *models.SearchResults) error {
+	return formatAll(f, out, results)
+}
+
+// Begin writes the header row
+func (f *csvFormatter) Begin(out io.Writer, query *models.SearchQuery) error {
+	f.writer = csv.NewWriter(out)
+	return f.flush(f.writer.Write(csvHeader))
+}
+
+// Write writes one result as a row
+func (f *csvFormatter) Write(out io.Writer, result *models.SearchResult) error {
+	return f.flush(f.writer.Write([]string{
+		fmt.Sprintf("%d", result.Rank),
+		result.FilePath,
+		fmt.Sprintf("%d", result.StartLine),
+		fmt.Sprintf("%d", result.EndLine),
+		fmt.Sprintf("%d", result.StartColumn),
+		fmt.Sprintf("%.3f", result.RelevanceScore),
+		string(result.MatchType),
+		result.Language,
+		result.Content,
+	}))
+}
+
+// End writes nothing after the rows
+func (f *csvFormatter) End(out io.Writer, results *models.SearchResults) error {
+	return nil
+}
+
+// flush writes buffered rows out, so rows appear as results are found
+func (f *csvFormatter) flush(err error) error {
+	if err != nil {
+		return fmt.Errorf("failed to write CSV row: %w", err)
+	}
+	f.writer.Flush()
+	return f.writer.Error()
+}
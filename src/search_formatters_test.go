@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"code-search/src/models"
+)
+
+// formatterTestResults returns one result whose match sits on an indented line, found
+// in a repository at /work/repo
+func formatterTestResults() *models.SearchResults {
+	query := models.NewSearchQuery("retry")
+	result := models.NewSearchResult("/work/repo/pkg/client.go", 12, 12, "\t\treturn retry(ctx)")
+	result.Rank = 1
+	result.RelevanceScore = 0.75
+	result.MatchType = models.MatchTypeExact
+	result.Language = "go"
+	result.AddSpan(models.MatchSpan{StartLine: 12, StartColumn: 10, EndLine: 12, EndColumn: 15, Text: "retry"})
+
+	results := models.NewSearchResults(query)
+	results.Results = []*models.SearchResult{result}
+	results.TotalResults = 1
+	results.SearchedFiles = 3
+	results.AddMetadata(models.MetadataRepositoryPath, "/work/repo")
+	return results
+}
+
+// formatTestResults runs a registered formatter over the test results
+func formatTestResults(t *testing.T, name string) string {
+	t.Helper()
+	formatter, ok := newResultFormatter(name, FormatterOptions{})
+	if !ok {
+		t.Fatalf("Formatter %s is not registered", name)
+	}
+	var out bytes.Buffer
+	if err := formatter.Format(&out, formatterTestResults()); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	return out.String()
+}
+
+// TestNDJSONFormatter_Write tests the match events of NDJSON output
+func TestNDJSONFormatter_Write(t *testing.T) {
+	t.Run("Submatches index the match lines at their columns", func(t *testing.T) {
+		output := formatTestResults(t, "ndjson")
+		var match struct {
+			Data ndjsonMatch `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(strings.Split(output, "\n")[1]), &match); err != nil {
+			t.Fatalf("Invalid match event: %v", err)
+		}
+
+		lines := match.Data.Lines.Text
+		if len(match.Data.Submatches) != 1 {
+			t.Fatalf("Expected one submatch, got %+v", match.Data.Submatches)
+		}
+		submatch := match.Data.Submatches[0]
+		if submatch.Start != 9 || lines[submatch.Start:submatch.End] != "retry" || submatch.Match.Text != "retry" {
+			t.Errorf("Submatch %d-%d does not cover retry in %q", submatch.Start, submatch.End, lines)
+		}
+	})
+
+	t.Run("Submatches span lines", func(t *testing.T) {
+		results := formatterTestResults()
+		result := models.NewSearchResult("/work/repo/pkg/client.go", 12, 13, "\tif err != nil {\n\t\treturn retry(ctx)")
+		result.AddSpan(models.MatchSpan{StartLine: 12, StartColumn: 5, EndLine: 13, EndColumn: 9, Text: "err != nil {\n\t\treturn"})
+		result.AddSpan(models.MatchSpan{StartLine: 13, StartColumn: 10, EndLine: 14, EndColumn: 1, Text: "retry(ctx)\n"})
+		results.Results = []*models.SearchResult{result}
+
+		var out bytes.Buffer
+		if err := (&ndjsonFormatter{}).Format(&out, results); err != nil {
+			t.Fatalf("Format failed: %v", err)
+		}
+		var match struct {
+			Data ndjsonMatch `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(strings.Split(out.String(), "\n")[1]), &match); err != nil {
+			t.Fatalf("Invalid match event: %v", err)
+		}
+
+		lines := match.Data.Lines.Text
+		want := []string{"err != nil {\n\t\treturn", "retry(ctx)\n"}
+		if len(match.Data.Submatches) != len(want) {
+			t.Fatalf("Expected %d submatches, got %+v", len(want), match.Data.Submatches)
+		}
+		for i, submatch := range match.Data.Submatches {
+			if lines[submatch.Start:submatch.End] != want[i] || submatch.Match.Text != want[i] {
+				t.Errorf("Submatch %d-%d does not cover %q in %q", submatch.Start, submatch.End, want[i], lines)
+			}
+		}
+	})
+}
+
+// goldenSARIF is the SARIF log of the test results, located relative to their repository
+const goldenSARIF = `{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "code-search",
+          "rules": [
+            {
+              "id": "code-search/match",
+              "shortDescription": {
+                "text": "Code matching a saved search"
+              }
+            }
+          ]
+        }
+      },
+      "results": [
+        {
+          "ruleId": "code-search/match",
+          "level": "note",
+          "message": {
+            "text": "Match for \"retry\": return retry(ctx)"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "pkg/client.go",
+                  "uriBaseId": "%SRCROOT%"
+                },
+                "region": {
+                  "startLine": 12,
+                  "startColumn": 10,
+                  "endLine": 12,
+                  "endColumn": 15,
+                  "snippet": {
+                    "text": "\t\treturn retry(ctx)"
+                  }
+                }
+              }
+            }
+          ],
+          "properties": {
+            "matchType": "exact",
+            "rank": 1,
+            "score": 0.75
+          }
+        }
+      ],
+      "properties": {
+        "query": "retry",
+        "totalResults": 1
+      }
+    }
+  ]
+}
+`
+
+// TestFormatters_Golden tests the exact output of the formats read by other tools
+func TestFormatters_Golden(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"sarif", goldenSARIF},
+		{"vimgrep", "/work/repo/pkg/client.go:12:10:\t\treturn retry(ctx)\n"},
+		{"csv", "rank,path,start_line,end_line,start_column,score,match_type,language,content\n" +
+			"1,/work/repo/pkg/client.go,12,12,10,0.750,exact,go,\"\t\treturn retry(ctx)\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if output := formatTestResults(t, tt.format); output != tt.expected {
+				t.Errorf("Unexpected %s output:\n%s\nexpected:\n%s", tt.format, output, tt.expected)
+			}
+		})
+	}
+
+	t.Run("SARIF paths of several repositories", func(t *testing.T) {
+		results := formatterTestResults()
+		other := *results.Results[0]
+		other.FilePath = "/work/web/app.go"
+		other.Repository = "web"
+		results.Results[0].Repository = "repo"
+		results.Results = append(results.Results, &other)
+		results.AddMetadata(models.MetadataRepositoryPaths, map[string]string{"repo": "/work/repo", "web": "/work/web"})
+
+		var out bytes.Buffer
+		if err := (&sarifFormatter{}).Format(&out, results); err != nil {
+			t.Fatalf("Format failed: %v", err)
+		}
+		var log sarifLog
+		if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+			t.Fatalf("Invalid SARIF: %v", err)
+		}
+		var uris []string
+		for _, finding := range log.Runs[0].Results {
+			uris = append(uris, finding.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		}
+		if strings.Join(uris, " ") != "pkg/client.go app.go" {
+			t.Errorf("Expected paths relative to each repository, got %v", uris)
+		}
+	})
+}
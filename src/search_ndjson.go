@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"code-search/src/models"
+)
+
+// ndjsonEvent is one line of NDJSON output. Its shape follows ripgrep's --json output:
+// a type and the data of that type.
+type ndjsonEvent struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// ndjsonText holds text the way ripgrep does, as {"text": "..."}
+type ndjsonText struct {
+	Text string `json:"text"`
+}
+
+// ndjsonBegin is the data of the begin event that opens the output of a search
+type ndjsonBegin struct {
+	Query      string `json:"query"`
+	SearchType string `json:"search_type"`
+	Offset     int    `json:"offset"`
+}
+
+// ndjsonSubmatch is a matched region of a result. Start and end are 0-based byte offsets
+// within the lines text of the match event, as in ripgrep.
+type ndjsonSubmatch struct {
+	Match ndjsonText `json:"match"`
+	Start int        `json:"start"`
+	End   int        `json:"end"`
+}
+
+// ndjsonMatch is the data of a match event
+type ndjsonMatch struct {
+	Path          ndjsonText         `json:"path"`
+	Lines         ndjsonText         `json:"lines"`
+	LineNumber    int                `json:"line_number"`
+	EndLineNumber int                `json:"end_line_number"`
+	Submatches    []ndjsonSubmatch   `json:"submatches"`
+	Spans         []models.MatchSpan `json:"spans"`
+	Rank          int                `json:"rank"`
+	Score         float64            `json:"score"`
+	MatchType     models.MatchType   `json:"match_type"`
+	Language      string             `json:"language,omitempty"`
+	Highlights    []string           `json:"highlights"`
+}
+
+// ndjsonContext is the data of a context event, one per line surrounding a match
+type ndjsonContext struct {
+	Path       ndjsonText `json:"path"`
+	Lines      ndjsonText `json:"lines"`
+	LineNumber int        `json:"line_number"`
+}
+
+// ndjsonElapsed is a duration in ripgrep's format
+type ndjsonElapsed struct {
+	Secs  int64  `json:"secs"`
+	Nanos int64  `json:"nanos"`
+	Human string `json:"human"`
+}
+
+// ndjsonStats are the statistics of the summary event
+type ndjsonStats struct {
+	Elapsed           ndjsonElapsed `json:"elapsed"`
+	Searches          int           `json:"searches"`
+	SearchesWithMatch int           `json:"searches_with_match"`
+	MatchedLines      int           `json:"matched_lines"`
+	Matches           int           `json:"matches"`
+	TotalResults      int           `json:"total_results"`
+	HasMore           bool          `json:"has_more"`
+	NextCursor        string        `json:"next_cursor,omitempty"`
+}
+
+// ndjsonSummary is the data of the summary event that closes the output of a search
+type ndjsonSummary struct {
+	ElapsedTotal ndjsonElapsed `json:"elapsed_total"`
+	Stats        ndjsonStats   `json:"stats"`
+}
+
+// ndjsonFormatter writes search results as newline-delimited JSON events: one begin
+// event, a match event per result with context events around it, and a summary event
+type ndjsonFormatter struct {
+	encoder      *json.Encoder
+	files        map[string]bool
+	matchedLines int
+	matches      int
+}
+
+// Format writes the events of a complete set of results
+func (f *ndjsonFormatter) Format(out io.Writer, results *models.SearchResults) error {
+	return formatAll(f, out, results)
+}
+
+// Begin writes the event that opens the output of a search
+func (f *ndjsonFormatter) Begin(out io.Writer, query *models.SearchQuery) error {
+	f.encoder = json.NewEncoder(out)
+	f.encoder.SetEscapeHTML(false)
+	f.files = make(map[string]bool)
+
+	return f.write("begin", ndjsonBegin{
+		Query:      query.OriginalText(),
+		SearchType: string(query.SearchType),
+		Offset:     query.Offset,
+	})
+}
+
+// Write writes a result and its context lines
+func (f *ndjsonFormatter) Write(out io.Writer, result *models.SearchResult) error {
+	for _, line := range result.ContextBefore {
+		if err := f.context(result.FilePath, line); err != nil {
+			return err
+		}
+	}
+
+	spans := result.Spans
+	if spans == nil {
+		spans = []models.MatchSpan{}
+	}
+	highlights := result.Highlights
+	if highlights == nil {
+		highlights = []string{}
+	}
+
+	err := f.write("match", ndjsonMatch{
+		Path:          ndjsonText{Text: result.FilePath},
+		Lines:         ndjsonText{Text: result.Content + "\n"},
+		LineNumber:    result.StartLine,
+		EndLineNumber: result.EndLine,
+		Submatches:    ndjsonSubmatches(result),
+		Spans:         spans,
+		Rank:          result.Rank,
+		Score:         result.RelevanceScore,
+		MatchType:     result.MatchType,
+		Language:      result.Language,
+		Highlights:    highlights,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, line := range result.ContextAfter {
+		if err := f.context(result.FilePath, line); err != nil {
+			return err
+		}
+	}
+
+	f.files[result.FilePath] = true
+	f.matchedLines += result.EndLine - result.StartLine + 1
+	f.matches++
+	return nil
+}
+
+// context writes one line surrounding a match
+func (f *ndjsonFormatter) context(path string, line models.ContextLine) error {
+	return f.write("context", ndjsonContext{
+		Path:       ndjsonText{Text: path},
+		Lines:      ndjsonText{Text: line.Text + "\n"},
+		LineNumber: line.Line,
+	})
+}
+
+// End writes the summary event that closes the output of a search
+func (f *ndjsonFormatter) End(out io.Writer, results *models.SearchResults) error {
+	elapsed := ndjsonDuration(results.ExecutionTime)
+	return f.write("summary", ndjsonSummary{
+		ElapsedTotal: elapsed,
+		Stats: ndjsonStats{
+			Elapsed:           elapsed,
+			Searches:          results.SearchedFiles,
+			SearchesWithMatch: len(f.files),
+			MatchedLines:      f.matchedLines,
+			Matches:           f.matches,
+			TotalResults:      results.TotalResults,
+			HasMore:           results.HasMore,
+			NextCursor:        results.NextCursor,
+		},
+	})
+}
+
+// write encodes one event as a line
+func (f *ndjsonFormatter) write(eventType string, data interface{}) error {
+	if err := f.encoder.Encode(ndjsonEvent{Type: eventType, Data: data}); err != nil {
+		return fmt.Errorf("failed to write %s event: %w", eventType, err)
+	}
+	return nil
+}
+
+// ndjsonSubmatches converts the spans of a result to ripgrep submatches, at byte offsets
+// within the lines text, which is the content followed by a newline. Spans that cross
+// lines cover the newlines between them.
+func ndjsonSubmatches(result *models.SearchResult) []ndjsonSubmatch {
+	text := result.Content + "\n"
+	lineStarts := []int{0}
+	for i := 0; i < len(result.Content); i++ {
+		if result.Content[i] == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	// A span that ends with a newline ends at the start of the line after the content
+	lineStarts = append(lineStarts, len(text))
+	offset := func(line, column int) int {
+		index := line - result.StartLine
+		if index < 0 || index >= len(lineStarts) || column < 1 {
+			return -1
+		}
+		return lineStarts[index] + column - 1
+	}
+
+	submatches := []ndjsonSubmatch{}
+	for _, span := range result.Spans {
+		start, end := offset(span.StartLine, span.StartColumn), offset(span.EndLine, span.EndColumn)
+		if start < 0 || end <= start || end > len(text) {
+			continue
+		}
+		submatches = append(submatches, ndjsonSubmatch{
+			Match: ndjsonText{Text: text[start:end]},
+			Start: start,
+			End:   end,
+		})
+	}
+	sort.SliceStable(submatches, func(i, j int) bool {
+		return submatches[i].Start < submatches[j].Start
+	})
+	return submatches
+}
+
+// ndjsonDuration converts a duration to ripgrep's elapsed format
+func ndjsonDuration(d time.Duration) ndjsonElapsed {
+	return ndjsonElapsed{
+		Secs:  int64(d / time.Second),
+		Nanos: int64(d % time.Second),
+		Human: fmt.Sprintf("%.6fs", d.Seconds()),
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"code-search/src/models"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifRuleID  = "code-search/match"
+	// sarifSourceRoot is the base of relative result paths, resolved by the consumer to
+	// the root of the checkout
+	sarifSourceRoot = "%SRCROOT%"
+)
+
+// sarifLog is the root of a SARIF document
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+// sarifRun is the output of one run of the tool
+type sarifRun struct {
+	Tool       sarifTool              `json:"tool"`
+	Results    []sarifResult          `json:"results"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// sarifTool describes the tool that produced a run
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+// sarifDriver names the tool and the rules its results refer to
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+// sarifRule describes the kind of finding a result reports
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+// sarifMessage is a plain text message
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+// sarifResult is one finding
+type sarifResult struct {
+	RuleID     string                 `json:"ruleId"`
+	Level      string                 `json:"level"`
+	Message    sarifMessage           `json:"message"`
+	Locations  []sarifLocation        `json:"locations"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// sarifLocation is where a finding is
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+// sarifPhysicalLocation is a region of a file
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+// sarifArtifactLocation identifies a file, relative to uriBaseId when one is set
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// sarifRegion is a range of lines and columns, with the text it covers
+type sarifRegion struct {
+	StartLine   int          `json:"startLine"`
+	StartColumn int          `json:"startColumn,omitempty"`
+	EndLine     int          `json:"endLine"`
+	EndColumn   int          `json:"endColumn,omitempty"`
+	Snippet     sarifMessage `json:"snippet"`
+}
+
+// sarifFormatter writes the results as a SARIF 2.1.0 log with one note-level finding per
+// result, so saved searches can be uploaded to code scanning
+type sarifFormatter struct{}
+
+// Format writes the SARIF log of a set of results
+func (f *sarifFormatter) Format(out io.Writer, results *models.SearchResults) error {
+	query := ""
+	if results.Query != nil {
+		query = results.Query.OriginalText()
+	}
+
+	// Paths are relative to the repository each result was found in
+	findings := make([]sarifResult, 0, len(results.Results))
+	for _, result := range results.Results {
+		findings = append(findings, newSARIFResult(result, query, results.RepositoryPath(result)))
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name: "code-search",
+				Rules: []sarifRule{{
+					ID:               sarifRuleID,
+					ShortDescription: sarifMessage{Text: "Code matching a saved search"},
+				}},
+			}},
+			Results: findings,
+			Properties: map[string]interface{}{
+				"query":        query,
+				"totalResults": results.TotalResults,
+			},
+		}},
+	}
+
+	jsonData, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to generate SARIF output: %w", err)
+	}
+
+	fmt.Fprintln(out, string(jsonData))
+	return nil
+}
+
+// newSARIFResult converts a search result to a finding located relative to root
+func newSARIFResult(result *models.SearchResult, query, root string) sarifResult {
+	region := sarifRegion{
+		StartLine: result.StartLine,
+		EndLine:   result.EndLine,
+		Snippet:   sarifMessage{Text: result.Content},
+	}
+	if len(result.Spans) > 0 {
+		span := result.Spans[0]
+		region.StartLine, region.StartColumn = span.StartLine, span.StartColumn
+		region.EndLine, region.EndColumn = span.EndLine, span.EndColumn
+	}
+
+	return sarifResult{
+		RuleID:  sarifRuleID,
+		Level:   "note",
+		Message: sarifMessage{Text: fmt.Sprintf("Match for %q: %s", query, strings.TrimSpace(firstLine(result.Content)))},
+		Locations: []sarifLocation{{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactURI(result.FilePath, root),
+				Region:           region,
+			},
+		}},
+		Properties: map[string]interface{}{
+			"rank":      result.Rank,
+			"score":     result.RelevanceScore,
+			"matchType": result.MatchType,
+		},
+	}
+}
+
+// sarifArtifactURI returns a path relative to the source root when the file is inside
+// it, and an absolute file URI otherwise
+func sarifArtifactURI(path, root string) sarifArtifactLocation {
+	if root != "" {
+		if relative, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(relative, "..") {
+			return sarifArtifactLocation{
+				URI:       (&url.URL{Path: filepath.ToSlash(relative)}).String(),
+				URIBaseID: sarifSourceRoot,
+			}
+		}
+	}
+
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		absolute = path
+	}
+	return sarifArtifactLocation{URI: (&url.URL{Scheme: "file", Path: filepath.ToSlash(absolute)}).String()}
+}
+
+// firstLine returns text up to its first newline
+func firstLine(text string) string {
+	if newline := strings.IndexByte(text, '\n'); newline >= 0 {
+		return text[:newline]
+	}
+	return text
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"code-search/src/lib"
+	"code-search/src/services"
+)
+
+// ServeCommand implements the serve command
+type ServeCommand struct {
+	fileUtils *lib.FileUtilities
+}
+
+// NewServeCommand creates a new serve command
+func NewServeCommand() *ServeCommand {
+	return &ServeCommand{
+		fileUtils: lib.NewFileUtilities(),
+	}
+}
+
+// ServeOptions contains serve command options
+type ServeOptions struct {
+	addr      string
+	directory string
+	token     string
+	timeout   time.Duration
+}
+
+// Execute executes the serve command with the given arguments
+func (cmd *ServeCommand) Execute(args []string) error {
+	options, err := cmd.parseServeOptions(args)
+	if err != nil {
+		return NewInvalidArgumentError("invalid serve options", err)
+	}
+
+	logger := &services.DefaultLogger{}
+	server, err := newSearchServer(cmd.fileUtils, options.directory, options.timeout, logger)
+	if err != nil {
+		return err
+	}
+	if err := server.Load(); err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			return NewNotFoundError("no index to serve; run 'code-search index' first", err)
+		}
+		return NewGeneralError("failed to load index", err)
+	}
+	defer server.Close()
+	if options.token != "" {
+		server.RequireToken(options.token)
+	}
+
+	listener, err := net.Listen("tcp", options.addr)
+	if err != nil {
+		return NewGeneralError("failed to listen", err)
+	}
+	if host, _, err := net.SplitHostPort(options.addr); err == nil {
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			// Requests naming the address the server was given are accepted too
+			if ip == nil || !ip.IsUnspecified() {
+				server.AllowHost(host)
+			}
+			if options.token == "" {
+				logger.Warn("Listening on %s, which is reachable from other machines; set --token to require authentication", options.addr)
+			}
+		}
+	}
+
+	httpServer := &http.Server{
+		Handler:           server,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      options.timeout + 10*time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+
+	// Shut down cleanly on Ctrl-C or SIGTERM
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+	go func() {
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(ctx)
+	}()
+
+	fmt.Printf("Serving %s on http://%s (OpenAPI description at /openapi.json)\n", server.Root(), listener.Addr())
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return NewGeneralError("server failed", err)
+	}
+	return nil
+}
+
+// newSearchServer creates a search server for an indexed directory, without loading its
+// index. The serve and mcp commands share it.
+func newSearchServer(fileUtils *lib.FileUtilities, directory string, timeout time.Duration, logger services.Logger) (*services.SearchServer, error) {
+	root, err := fileUtils.ResolvePath(directory)
+	if err != nil {
+		return nil, NewInvalidArgumentError("failed to resolve directory", err)
+	}
+	indexPath, err := ResolveIndexPath(directory)
+	if err != nil {
+		return nil, NewInvalidArgumentError("failed to resolve index location", err)
+	}
+
+	config, err := loadConfig(root)
+	if err != nil {
+		return nil, err
+	}
+
+	searchOptions := config.SearchOptions()
+	searchOptions.Timeout = timeout
+	// Rankings are not cached, since the loaded index is replaced on reindex
+	searchOptions.CacheResults = false
+	searchService := services.NewSearchService(
+		lib.NewSimpleCodeParser(),
+		lib.NewInMemoryVectorStore(""),
+		&services.SilentLogger{},
+		searchOptions,
+	)
+
+	return services.NewSearchServer(root, indexPath, searchService, reindexFunc(root, indexPath, directory != "", config), logger), nil
+}
+
+// reindexFunc returns the function that incrementally reindexes the served directory,
+// the same way the index command would, or only the given files
+func reindexFunc(root, indexPath string, directory bool, config *services.Config) services.ReindexFunc {
+	return func(files []string) (*services.IndexingResult, error) {
+		indexingService := services.NewIndexingService(
+			lib.NewFileSystemScanner(),
+			config.CodeParser(),
+			lib.NewInMemoryVectorStore(""),
+			&services.SilentLogger{},
+			config.IndexingOptions(),
+		)
+		if len(files) > 0 {
+			return indexingService.IndexFiles(root, indexPath, files)
+		}
+		if directory {
+			return indexingService.IndexDirectory(root, false, nil)
+		}
+		return indexingService.IndexRepository(root, indexPath, false, nil)
+	}
+}
+
+// parseServeOptions parses command line options for serve
+func (cmd *ServeCommand) parseServeOptions(args []string) (ServeOptions, error) {
+	options := ServeOptions{
+		addr:    "127.0.0.1:7420",
+		timeout: services.DefaultSearchOptions().Timeout,
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch arg {
+		case "--addr", "-a":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--addr requires a host:port value", nil)
+			}
+			if _, _, err := net.SplitHostPort(args[i+1]); err != nil {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid address: %s (expected host:port)", args[i+1]), nil)
+			}
+			options.addr = args[i+1]
+			i++
+
+		case "--dir", "-d":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--dir requires a directory path", nil)
+			}
+			options.directory = args[i+1]
+			i++
+
+		case "--token":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return options, NewInvalidArgumentError("--token requires a value", nil)
+			}
+			options.token = args[i+1]
+			i++
+
+		case "--timeout":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--timeout requires a duration", nil)
+			}
+			timeout, err := time.ParseDuration(args[i+1])
+			if err != nil || timeout <= 0 {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid timeout: %s (e.g. 10s, 1m)", args[i+1]), nil)
+			}
+			options.timeout = timeout
+			i++
+
+		case "--help", "-h":
+			cmd.printServeHelp()
+			os.Exit(0)
+
+		default:
+			return options, NewInvalidArgumentError(fmt.Sprintf("unknown option: %s", arg), nil)
+		}
+	}
+
+	return options, nil
+}
+
+// printServeHelp prints help for the serve command
+func (cmd *ServeCommand) printServeHelp() {
+	fmt.Printf(`Usage: code-search serve [options]
+
+Serves searches of an indexed directory over HTTP with JSON bodies. The index is
+loaded once and kept in memory.
+
+Options:
+  -a, --addr <host:port>   Address to listen on (default: 127.0.0.1:7420)
+  -d, --dir <directory>    Specify indexed directory (default: current directory)
+      --token <token>      Require "Authorization: Bearer <token>" on every request
+      --timeout <d>        Maximum time a search may take (default: 30s)
+  -h, --help               Show this help message
+
+Endpoints:
+  GET  /health             Whether the server is up and an index is loaded
+  GET  /v1/search?q=...    Search; also POST with a JSON body
+  GET  /v1/status          Indexed files and the last reindex (?check=true compares with disk)
+  POST /v1/reindex         Incrementally reindex and load the new index (?wait=true to block)
+  GET  /openapi.json       OpenAPI description of the endpoints
+
+Requests must name a loopback host, or the host given to --addr, in their Host header,
+and POST requests must have Content-Type application/json.
+
+Examples:
+  code-search serve --dir ~/src/api
+  curl 'http://127.0.0.1:7420/v1/search?q=retry&type=text&max_results=5'
+  curl -X POST http://127.0.0.1:7420/v1/search -H 'Content-Type: application/json' \
+       -d '{"query": "lang:go handler", "search_type": "text"}'
+`)
+}
+
+// GetHelp returns help text for the command
+func (cmd *ServeCommand) GetHelp() string {
+	return `serve [options] - Serve searches of the index over local HTTP
+
+Use 'code-search serve --help' for detailed usage information.`
+}
@@ -0,0 +1,451 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code-search/src/lib"
+	"code-search/src/models"
+)
+
+// ProjectConfigNames are the file names looked up from the target directory upwards
+var ProjectConfigNames = []string{".code-search.yaml", ".code-search.yml"}
+
+// ConfigSource identifies where a configuration value came from
+type ConfigSource string
+
+const (
+	ConfigSourceDefault ConfigSource = "default"
+	ConfigSourceUser    ConfigSource = "user"
+	ConfigSourceProject ConfigSource = "project"
+	ConfigSourceEnv     ConfigSource = "env"
+)
+
+// Config holds settings read from configuration files and the environment. Command line
+// flags are applied on top by each command.
+type Config struct {
+	Include        []string
+	Exclude        []string
+	FileTypes      []string
+	MaxFileSize    int64
+	Chunker        string
+	Model          string
+	SemanticWeight float64
+	TextWeight     float64
+	Format         string
+	ContextLines   int
+
+	files   []string
+	origins map[string]configOrigin
+}
+
+// ConfigSetting is the effective value of one setting and where it was set
+type ConfigSetting struct {
+	Key    string       `json:"key"`
+	Value  interface{}  `json:"value"`
+	Source ConfigSource `json:"source"`
+	Origin string       `json:"origin,omitempty"`
+	Env    string       `json:"env"`
+}
+
+// configOrigin records the source of a value and the file position or variable that set it
+type configOrigin struct {
+	source ConfigSource
+	origin string
+}
+
+// configSetting describes a setting, its environment variable and its field
+type configSetting struct {
+	key   string
+	env   string
+	field func(c *Config) interface{}
+	check func(c *Config) error
+}
+
+// configSettings lists every setting in display order
+var configSettings = []configSetting{
+	{key: "index.include", env: "CODE_SEARCH_INCLUDE", field: func(c *Config) interface{} { return &c.Include }},
+	{key: "index.exclude", env: "CODE_SEARCH_EXCLUDE", field: func(c *Config) interface{} { return &c.Exclude }},
+	{key: "index.file_types", env: "CODE_SEARCH_FILE_TYPES", field: func(c *Config) interface{} { return &c.FileTypes },
+		check: func(c *Config) error {
+			if len(c.FileTypes) == 0 {
+				return fmt.Errorf("at least one file type is required (use \"*\" for all)")
+			}
+			return nil
+		}},
+	{key: "index.max_file_size", env: "CODE_SEARCH_MAX_FILE_SIZE", field: func(c *Config) interface{} { return &c.MaxFileSize },
+		check: func(c *Config) error {
+			if c.MaxFileSize <= 0 {
+				return fmt.Errorf("must be greater than 0")
+			}
+			return nil
+		}},
+	{key: "index.chunker", env: "CODE_SEARCH_CHUNKER", field: func(c *Config) interface{} { return &c.Chunker },
+		check: func(c *Config) error {
+			if c.Chunker != "simple" && c.Chunker != "ast" {
+				return fmt.Errorf("unknown chunker %q (supported: simple, ast)", c.Chunker)
+			}
+			return nil
+		}},
+	{key: "embedding.model", env: "CODE_SEARCH_MODEL", field: func(c *Config) interface{} { return &c.Model },
+		check: func(c *Config) error {
+			if c.Model == "" {
+				return fmt.Errorf("cannot be empty")
+			}
+			return nil
+		}},
+	{key: "search.semantic_weight", env: "CODE_SEARCH_SEMANTIC_WEIGHT", field: func(c *Config) interface{} { return &c.SemanticWeight },
+		check: func(c *Config) error { return checkWeight(c.SemanticWeight) }},
+	{key: "search.text_weight", env: "CODE_SEARCH_TEXT_WEIGHT", field: func(c *Config) interface{} { return &c.TextWeight },
+		check: func(c *Config) error { return checkWeight(c.TextWeight) }},
+	{key: "search.format", env: "CODE_SEARCH_FORMAT", field: func(c *Config) interface{} { return &c.Format },
+		check: func(c *Config) error {
+			if c.Format == "" {
+				return fmt.Errorf("cannot be empty")
+			}
+			return nil
+		}},
+	{key: "search.context_lines", env: "CODE_SEARCH_CONTEXT_LINES", field: func(c *Config) interface{} { return &c.ContextLines },
+		check: func(c *Config) error {
+			if c.ContextLines < 0 {
+				return fmt.Errorf("must be 0 or greater")
+			}
+			return nil
+		}},
+}
+
+// checkWeight validates a hybrid search weight
+func checkWeight(weight float64) error {
+	if weight < 0 || weight > 1 {
+		return fmt.Errorf("must be between 0 and 1")
+	}
+	return nil
+}
+
+// DefaultConfig returns the configuration used when nothing is set
+func DefaultConfig() *Config {
+	indexing := DefaultIndexingOptions()
+	search := DefaultSearchOptions()
+
+	return &Config{
+		Include:        []string{},
+		Exclude:        append([]string{}, indexing.ExcludePatterns...),
+		FileTypes:      append([]string{}, indexing.FileTypes...),
+		MaxFileSize:    indexing.MaxFileSize,
+		Chunker:        "simple",
+		Model:          lib.DefaultEmbeddingConfig().ModelName,
+		SemanticWeight: search.SemanticWeight,
+		TextWeight:     search.TextWeight,
+		Format:         "table",
+		ContextLines:   search.ContextLines,
+		origins:        make(map[string]configOrigin),
+	}
+}
+
+// LoadConfig builds the effective configuration for a target directory. Later layers win:
+// defaults, the user file, the nearest project file, then environment variables.
+func LoadConfig(targetDir string) (*Config, error) {
+	config := DefaultConfig()
+
+	if path := UserConfigPath(); path != "" {
+		if err := config.loadFileIfExists(path, ConfigSourceUser); err != nil {
+			return nil, err
+		}
+	}
+
+	if path, err := FindProjectConfig(targetDir); err != nil {
+		return nil, err
+	} else if path != "" {
+		if err := config.loadFileIfExists(path, ConfigSourceProject); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := config.loadEnv(os.Getenv); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// UserConfigPath returns the user-level configuration file, in $XDG_CONFIG_HOME or
+// ~/.config when it is not set
+func UserConfigPath() string {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "code-search", "config.yaml")
+}
+
+// FindProjectConfig returns the nearest project configuration file in the target
+// directory or one of its parents, or "" when there is none
+func FindProjectConfig(targetDir string) (string, error) {
+	dir, err := filepath.Abs(targetDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", targetDir, err)
+	}
+
+	for {
+		for _, name := range ProjectConfigNames {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path, nil
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// LoadFile applies a configuration file on top of the current values
+func (c *Config) LoadFile(path string, source ConfigSource) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	values, err := lib.ParseSimpleYAML(data)
+	if err != nil {
+		return fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	// Apply settings in table order so errors are reported consistently
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if findConfigSetting(key) == nil {
+			return fmt.Errorf("invalid config %s:%d: unknown setting %s", path, values[key].Line, key)
+		}
+	}
+
+	for _, setting := range configSettings {
+		value, ok := values[setting.key]
+		if !ok {
+			continue
+		}
+		origin := fmt.Sprintf("%s:%d", path, value.Line)
+		if err := c.apply(setting, value, configOrigin{source: source, origin: origin}); err != nil {
+			return fmt.Errorf("invalid config %s: %s: %w", origin, setting.key, err)
+		}
+	}
+
+	c.files = append(c.files, path)
+	return nil
+}
+
+// loadFileIfExists applies a configuration file when it exists
+func (c *Config) loadFileIfExists(path string, source ConfigSource) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	return c.LoadFile(path, source)
+}
+
+// loadEnv applies CODE_SEARCH_* environment variables; lists are comma-separated
+func (c *Config) loadEnv(getenv func(string) string) error {
+	for _, setting := range configSettings {
+		raw := getenv(setting.env)
+		if raw == "" {
+			continue
+		}
+		if err := c.apply(setting, lib.YAMLValue{Scalar: raw}, configOrigin{source: ConfigSourceEnv, origin: setting.env}); err != nil {
+			return fmt.Errorf("invalid %s: %w", setting.env, err)
+		}
+	}
+	return nil
+}
+
+// apply sets one value, validates it and records where it came from
+func (c *Config) apply(setting configSetting, value lib.YAMLValue, origin configOrigin) error {
+	switch field := setting.field(c).(type) {
+	case *[]string:
+		if value.IsList {
+			*field = append([]string{}, value.List...)
+		} else {
+			*field = splitConfigList(value.Scalar)
+		}
+	case *string:
+		if value.IsList {
+			return fmt.Errorf("expected a single value, got a list")
+		}
+		*field = value.Scalar
+	case *int:
+		parsed, err := strconv.Atoi(value.Scalar)
+		if err != nil || value.IsList {
+			return fmt.Errorf("expected a whole number, got %q", value.Scalar)
+		}
+		*field = parsed
+	case *int64:
+		parsed, err := ParseByteSize(value.Scalar)
+		if err != nil || value.IsList {
+			return fmt.Errorf("expected a size such as 1048576, 512KB or 2MB, got %q", value.Scalar)
+		}
+		*field = parsed
+	case *float64:
+		parsed, err := strconv.ParseFloat(value.Scalar, 64)
+		if err != nil || value.IsList {
+			return fmt.Errorf("expected a number, got %q", value.Scalar)
+		}
+		*field = parsed
+	}
+
+	if setting.check != nil {
+		if err := setting.check(c); err != nil {
+			return err
+		}
+	}
+	c.origins[setting.key] = origin
+	return nil
+}
+
+// splitConfigList splits a comma-separated list, dropping empty items
+func splitConfigList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ParseByteSize parses a size in bytes with an optional KB, MB or GB suffix
+func ParseByteSize(value string) (int64, error) {
+	text := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(text, unit.suffix) {
+			text = strings.TrimSpace(strings.TrimSuffix(text, unit.suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+
+	size, err := strconv.ParseInt(text, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size: %s", value)
+	}
+	return size * multiplier, nil
+}
+
+// findConfigSetting returns the setting with the given key
+func findConfigSetting(key string) *configSetting {
+	for i := range configSettings {
+		if configSettings[i].key == key {
+			return &configSettings[i]
+		}
+	}
+	return nil
+}
+
+// Files returns the configuration files that were applied, user file first
+func (c *Config) Files() []string {
+	return c.files
+}
+
+// Source returns where a setting's effective value came from, and the file position or
+// environment variable that set it
+func (c *Config) Source(key string) (ConfigSource, string) {
+	if origin, ok := c.origins[key]; ok {
+		return origin.source, origin.origin
+	}
+	return ConfigSourceDefault, ""
+}
+
+// Settings returns every setting with its effective value and source
+func (c *Config) Settings() []ConfigSetting {
+	settings := make([]ConfigSetting, 0, len(configSettings))
+	for _, setting := range configSettings {
+		var value interface{}
+		switch field := setting.field(c).(type) {
+		case *[]string:
+			value = *field
+		case *string:
+			value = *field
+		case *int:
+			value = *field
+		case *int64:
+			value = *field
+		case *float64:
+			value = *field
+		}
+
+		source, origin := c.Source(setting.key)
+		settings = append(settings, ConfigSetting{
+			Key:    setting.key,
+			Value:  value,
+			Source: source,
+			Origin: origin,
+			Env:    setting.env,
+		})
+	}
+	return settings
+}
+
+// IndexingOptions returns the default indexing options with the configured filters.
+// Filters set by a file or the environment override those stored with an index.
+func (c *Config) IndexingOptions() models.IndexingOptions {
+	options := DefaultIndexingOptions()
+	options.IncludePatterns = append([]string{}, c.Include...)
+	options.ExcludePatterns = append([]string{}, c.Exclude...)
+	options.FileTypes = append([]string{}, c.FileTypes...)
+	options.MaxFileSize = c.MaxFileSize
+	options.EmbeddingModel = c.Model
+
+	for key, filter := range map[string]string{
+		"index.include":       "include_patterns",
+		"index.exclude":       "exclude_patterns",
+		"index.file_types":    "file_types",
+		"index.max_file_size": "max_file_size",
+	} {
+		if source, _ := c.Source(key); source != ConfigSourceDefault {
+			options.FilterOverrides = append(options.FilterOverrides, filter)
+		}
+	}
+	sort.Strings(options.FilterOverrides)
+	return options
+}
+
+// SearchOptions returns the default search options with the configured context and weights
+func (c *Config) SearchOptions() SearchOptions {
+	options := DefaultSearchOptions()
+	options.ContextLines = c.ContextLines
+	options.SemanticWeight = c.SemanticWeight
+	options.TextWeight = c.TextWeight
+	return options
+}
+
+// EmbeddingConfig returns the default embedding configuration with the configured model
+// and weights
+func (c *Config) EmbeddingConfig() lib.EmbeddingConfig {
+	config := lib.DefaultEmbeddingConfig()
+	config.ModelName = c.Model
+	config.SemanticWeight = c.SemanticWeight
+	config.TextWeight = c.TextWeight
+	return config
+}
+
+// CodeParser returns the configured chunker
+func (c *Config) CodeParser() CodeParser {
+	if c.Chunker == "ast" {
+		return lib.NewASTCodeParser(lib.DefaultChunkingConfig())
+	}
+	return lib.NewSimpleCodeParser()
+}
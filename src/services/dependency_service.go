@@ -0,0 +1,96 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"code-search/src/lib"
+)
+
+// DependencyQuery describes a traversal of the file dependency graph
+type DependencyQuery struct {
+	File    string `json:"file"`    // Path relative to the repository root
+	Reverse bool   `json:"reverse"` // Follow importers instead of imports
+	Depth   int    `json:"depth"`   // Maximum depth, 0 for unlimited
+}
+
+// DependencyResult contains the files reached from a dependency query
+type DependencyResult struct {
+	File    string               `json:"file"`
+	Reverse bool                 `json:"reverse"`
+	Depth   int                  `json:"depth"`
+	Files   []DependencyFile     `json:"files"`   // Reached files in breadth-first order
+	Edges   []lib.DependencyEdge `json:"edges"`   // Importer to imported file
+	Imports []string             `json:"imports"` // Import specifiers of File as written
+}
+
+// DependencyFile is a file reached from a dependency query
+type DependencyFile struct {
+	Path  string `json:"path"`
+	Depth int    `json:"depth"`
+}
+
+// DependencyService answers queries about which files import which
+type DependencyService struct {
+	logger Logger
+}
+
+// NewDependencyService creates a new dependency service
+func NewDependencyService(logger Logger) *DependencyService {
+	return &DependencyService{
+		logger: logger,
+	}
+}
+
+// GetDependencies loads the dependency graph saved for a repository and walks it from the query file
+func (ds *DependencyService) GetDependencies(repositoryPath string, query DependencyQuery) (*DependencyResult, error) {
+	graphPath := filepath.Join(lib.NewFileUtilities().CreateIndexLocation(repositoryPath).IndexDir, "dependencies.json")
+	if _, err := os.Stat(graphPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("dependency graph does not exist: %s", graphPath)
+	}
+
+	graph := newDependencyGraph(repositoryPath)
+	if err := graph.LoadMetadata(); err != nil {
+		return nil, fmt.Errorf("failed to load dependency graph: %w", err)
+	}
+
+	file := filepath.ToSlash(filepath.Clean(query.File))
+	deps, exists := graph.GetDependencies(file)
+	if !exists {
+		return nil, fmt.Errorf("file not in dependency graph: %s", file)
+	}
+
+	result := &DependencyResult{
+		File:    file,
+		Reverse: query.Reverse,
+		Depth:   query.Depth,
+		Files:   []DependencyFile{},
+		Edges:   graph.TraverseDependencies(file, query.Reverse, query.Depth),
+		Imports: importSpecifiers(deps),
+	}
+
+	seen := map[string]bool{file: true}
+	for _, edge := range result.Edges {
+		reached := edge.To
+		if query.Reverse {
+			reached = edge.From
+		}
+		if !seen[reached] {
+			seen[reached] = true
+			result.Files = append(result.Files, DependencyFile{Path: reached, Depth: edge.Depth})
+		}
+	}
+
+	ds.logger.Debug("Found %d files from %s", len(result.Files), file)
+	return result, nil
+}
+
+// importSpecifiers returns all imports, requires and includes of a file
+func importSpecifiers(deps *lib.FileDependencies) []string {
+	specs := []string{}
+	specs = append(specs, deps.Imports...)
+	specs = append(specs, deps.Requires...)
+	specs = append(specs, deps.Includes...)
+	return specs
+}
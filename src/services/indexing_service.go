@@ -1,10 +1,12 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -13,6 +15,14 @@ import (
 	"code-search/src/lib"
 )
 
+// ErrIndexLocked is returned when an index cannot be changed because an index run holds
+// its lock
+var ErrIndexLocked = errors.New("the index is locked by a running index")
+
+// SingleFileIndexNames are the index files 'index' writes in the indexed directory itself
+// when run without --dir; --force writes the .test one
+var SingleFileIndexNames = []string{".code-search-index", ".code-search-index.test"}
+
 // IndexingService handles codebase indexing operations
 type IndexingService struct {
 	fileScanner  FileScanner
@@ -21,6 +31,7 @@ type IndexingService struct {
 	logger       Logger
 	indexOptions models.IndexingOptions
 	workerPool   *lib.WorkerPool
+	goReferences *lib.GoReferenceAnalyzer
 	mu           sync.RWMutex
 }
 
@@ -50,6 +61,7 @@ type Logger interface {
 // FileScanner interface for scanning files
 type FileScanner interface {
 	ScanFiles(rootPath string, options models.IndexingOptions) ([]string, error)
+	ShouldSkipFile(rootPath, filePath string, options models.IndexingOptions) (bool, string)
 	GetFileStats(filePath string) (models.FileStats, error)
 }
 
@@ -60,6 +72,12 @@ type CodeParser interface {
 	GetSupportedFileTypes() []string
 }
 
+// SymbolParser is a CodeParser that also builds the symbol table of each file it parses,
+// from the same read of the file
+type SymbolParser interface {
+	ParseFileWithSymbols(filePath string) ([]models.CodeChunk, []models.Symbol, error)
+}
+
 
 // ProgressCallback is called during indexing to report progress
 type ProgressCallback func(current, total int, filePath string)
@@ -112,6 +130,7 @@ func NewIndexingService(
 		logger:       logger,
 		indexOptions: options,
 		workerPool:   workerPool,
+		goReferences: lib.NewGoReferenceAnalyzer(),
 	}
 }
 
@@ -160,6 +179,20 @@ func (is *IndexingService) IndexRepository(
 		result.Errors = append(result.Errors, fmt.Sprintf("Failed to create index: %v", err))
 		return result, err
 	}
+	if codeIndex != existingIndex {
+		existingIndex = nil
+	}
+
+	// An update uses the filters the index was built with, unless this run overrides them
+	defer is.useIndexingOptions(is.filteredOptions(existingIndex))()
+	filters := is.indexOptions.Filters()
+	codeIndex.Filters = &filters
+	if is.indexOptions.EmbeddingModel != "" {
+		codeIndex.Model = &models.IndexModel{
+			ModelName:    is.indexOptions.EmbeddingModel,
+			ModelVersion: lib.DefaultModelVersion,
+		}
+	}
 
 	// Scan files
 	files, err := is.fileScanner.ScanFiles(repositoryPath, is.indexOptions)
@@ -170,6 +203,13 @@ func (is *IndexingService) IndexRepository(
 
 	is.logger.Info("Found %d files to process", len(files))
 
+	// Re-index files whose imports changed since the last run
+	dependencies := newDependencyGraph(repositoryPath)
+	if existingIndex != nil {
+		is.removeUnscannedFiles(codeIndex, files)
+		is.invalidateDependents(codeIndex, dependencies, files)
+	}
+
 	// Process files using BatchProcessor for memory efficiency
 	totalFiles := len(files)
 	processedFiles := 0
@@ -213,6 +253,15 @@ func (is *IndexingService) IndexRepository(
 		return result, err
 	}
 
+	// Resolve Go identifiers across packages for refs and callers
+	is.buildGoReferences(codeIndex)
+
+	// Record which files import which, for deps and incremental re-indexing
+	dependencies.BuildDependencyGraph(repositoryPath, files)
+	if err := dependencies.SaveMetadata(); err != nil {
+		is.logger.Warn("Failed to save dependency graph: %v", err)
+	}
+
 	// Save the index
 	if err := codeIndex.Save(indexPath); err != nil {
 		result.Errors = append(result.Errors, fmt.Sprintf("Failed to save index: %v", err))
@@ -228,6 +277,187 @@ func (is *IndexingService) IndexRepository(
 	return result, nil
 }
 
+// IndexFiles reindexes only the given files of a repository that is already indexed, such
+// as a file just saved in an editor. Files that no longer exist are removed from the index.
+func (is *IndexingService) IndexFiles(repositoryPath string, indexPath string, files []string) (*IndexingResult, error) {
+	start := time.Now()
+
+	result := &IndexingResult{
+		Errors:         make([]string, 0),
+		RepositoryPath: repositoryPath,
+		IndexPath:      indexPath,
+	}
+
+	codeIndex, err := is.loadExistingIndex(indexPath)
+	if err != nil {
+		return result, fmt.Errorf("failed to load index: %w", err)
+	}
+	if codeIndex.RepositoryPath != repositoryPath {
+		return result, fmt.Errorf("index at %s is for %s, not %s", indexPath, codeIndex.RepositoryPath, repositoryPath)
+	}
+	defer is.useIndexingOptions(is.filteredOptions(codeIndex))()
+
+	for _, filePath := range files {
+		// The old entry is removed first, so the file is processed even when its
+		// modification time has not moved on
+		if err := codeIndex.RemoveFileEntry(filePath); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", filePath, err))
+			continue
+		}
+		if _, err := os.Stat(filePath); os.IsNotExist(err) {
+			continue
+		}
+
+		processed := is.processFile(filePath, codeIndex)
+		switch {
+		case processed.Error != nil:
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", filePath, processed.Error))
+		case processed.Skipped:
+			result.FilesSkipped++
+		default:
+			result.FilesIndexed++
+			result.ChunksCreated += processed.ChunkCount
+		}
+	}
+
+	// References into the changed files may have moved, so Go references are resolved again
+	is.buildGoReferences(codeIndex)
+
+	dependencies := newDependencyGraph(repositoryPath)
+	var indexed []string
+	for _, entry := range codeIndex.GetAllFiles() {
+		indexed = append(indexed, entry.FilePath)
+	}
+	dependencies.BuildDependencyGraph(repositoryPath, indexed)
+	if err := dependencies.SaveMetadata(); err != nil {
+		is.logger.Warn("Failed to save dependency graph: %v", err)
+	}
+
+	if err := codeIndex.Save(indexPath); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("Failed to save index: %v", err))
+		return result, err
+	}
+
+	result.Duration = time.Since(start)
+	result.Success = true
+	is.logger.Info("Reindexed %d of %d files in %v", result.FilesIndexed, len(files), result.Duration)
+	return result, nil
+}
+
+// filteredOptions returns the indexing options for a run over an existing index. Filters
+// this run does not override are the ones the index was built with, and a warning lists
+// any that changed.
+func (is *IndexingService) filteredOptions(existingIndex *models.CodeIndex) models.IndexingOptions {
+	options := is.indexOptions
+	if existingIndex == nil || existingIndex.Filters == nil {
+		return options
+	}
+
+	options = options.WithStoredFilters(*existingIndex.Filters)
+	if changes := existingIndex.Filters.Changes(options.Filters()); len(changes) > 0 {
+		is.logger.Warn("Index filters differ from the last run (%s); files that no longer match are removed",
+			strings.Join(changes, "; "))
+	}
+	return options
+}
+
+// useIndexingOptions replaces the indexing options for one run and returns the function
+// that restores them
+func (is *IndexingService) useIndexingOptions(options models.IndexingOptions) func() {
+	previous := is.indexOptions
+	is.indexOptions = options
+	return func() {
+		is.indexOptions = previous
+	}
+}
+
+// removeUnscannedFiles removes the entries of files that were deleted or no longer match
+// the filters
+func (is *IndexingService) removeUnscannedFiles(codeIndex *models.CodeIndex, files []string) {
+	scanned := make(map[string]bool, len(files))
+	for _, filePath := range files {
+		scanned[filePath] = true
+	}
+
+	for _, entry := range codeIndex.GetAllFiles() {
+		if scanned[entry.FilePath] {
+			continue
+		}
+		if err := codeIndex.RemoveFileEntry(entry.FilePath); err != nil {
+			is.logger.Warn("Failed to remove %s from the index: %v", entry.FilePath, err)
+		}
+	}
+}
+
+// invalidateDependents removes the index entries of files that import a changed file,
+// newDependencyGraph returns the dependency graph of a repository, stored in its index
+// directory where the deps command reads it
+func newDependencyGraph(repositoryPath string) *lib.IncrementalIndexer {
+	indexDir := lib.NewFileUtilities().CreateIndexLocation(repositoryPath).IndexDir
+	return lib.NewIncrementalIndexer(indexDir, lib.DefaultIncrementalOptions())
+}
+
+// so they are processed again along with the files they depend on
+func (is *IndexingService) invalidateDependents(codeIndex *models.CodeIndex, dependencies *lib.IncrementalIndexer, files []string) {
+	if err := dependencies.LoadMetadata(); err != nil {
+		is.logger.Debug("No dependency graph loaded: %v", err)
+		return
+	}
+
+	var changed []string
+	for _, filePath := range files {
+		if skip, _ := is.shouldSkipFile(filePath, codeIndex); skip {
+			continue
+		}
+		if rel, err := filepath.Rel(codeIndex.RepositoryPath, filePath); err == nil {
+			changed = append(changed, filepath.ToSlash(rel))
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	dependents := dependencies.GetDependents(changed)
+	for _, rel := range dependents {
+		if err := codeIndex.RemoveFileEntry(filepath.Join(codeIndex.RepositoryPath, filepath.FromSlash(rel))); err != nil {
+			is.logger.Warn("Failed to invalidate dependent %s: %v", rel, err)
+		}
+	}
+
+	is.logger.Debug("Re-indexing %d dependents of %d changed files", len(dependents), len(changed))
+}
+
+// buildGoReferences type-checks the indexed Go packages and stores the resolved
+// references on their file entries
+func (is *IndexingService) buildGoReferences(codeIndex *models.CodeIndex) {
+	entries := make(map[string]*models.FileEntry)
+	var goFiles []string
+	for _, entry := range codeIndex.GetAllFiles() {
+		if entry.Language == "Go" {
+			entries[entry.FilePath] = entry
+			goFiles = append(goFiles, entry.FilePath)
+		}
+	}
+	if len(goFiles) == 0 {
+		return
+	}
+
+	start := time.Now()
+	references, err := is.goReferences.Analyze(codeIndex.RepositoryPath, goFiles)
+	if err != nil {
+		is.logger.Warn("Go reference analysis failed: %v", err)
+		return
+	}
+
+	total := 0
+	for filePath, entry := range entries {
+		entry.References = references[filePath]
+		total += len(entry.References)
+	}
+
+	is.logger.Debug("Resolved %d Go references in %v", total, time.Since(start))
+}
+
 // FileProcessingResult contains the result of processing a single file
 type FileProcessingResult struct {
 	FilePath   string
@@ -331,8 +561,15 @@ func (is *IndexingService) processFile(filePath string, codeIndex *models.CodeIn
 		return result
 	}
 
-	// Parse file into chunks
-	chunks, err := is.codeParser.ParseFile(filePath)
+	// Parse file into chunks, and symbols when the parser builds them
+	var chunks []models.CodeChunk
+	var symbols []models.Symbol
+	var err error
+	if parser, ok := is.codeParser.(SymbolParser); ok {
+		chunks, symbols, err = parser.ParseFileWithSymbols(filePath)
+	} else {
+		chunks, err = is.codeParser.ParseFile(filePath)
+	}
 	if err != nil {
 		result.Error = fmt.Errorf("failed to parse file: %w", err)
 		return result
@@ -370,6 +607,8 @@ func (is *IndexingService) processFile(filePath string, codeIndex *models.CodeIn
 		fileEntry.AddChunk(chunk)
 	}
 
+	fileEntry.Symbols = symbols
+
 	// Add file entry to index
 	if err := codeIndex.AddFileEntry(fileEntry); err != nil {
 		result.Error = fmt.Errorf("failed to add file entry to index: %w", err)
@@ -393,6 +632,13 @@ func (is *IndexingService) shouldSkipFile(filePath string, codeIndex *models.Cod
 		}
 	}
 
+	// Check hidden, exclude and include filters
+	if codeIndex != nil {
+		if skip, reason := is.fileScanner.ShouldSkipFile(codeIndex.RepositoryPath, filePath, is.indexOptions); skip {
+			return true, reason
+		}
+	}
+
 	// Check file size
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
@@ -427,7 +673,7 @@ func (is *IndexingService) isFileTypeSupported(filePath string) bool {
 
 	// Specific file types
 	for _, fileType := range is.indexOptions.FileTypes {
-		if strings.HasSuffix(strings.ToLower(filePath), strings.ToLower(fileType)) {
+		if lib.MatchesFileType(filePath, fileType) {
 			return true
 		}
 	}
@@ -521,6 +767,108 @@ func (is *IndexingService) GetIndexingStatus(indexPath string) (*IndexingStatus,
 	}, nil
 }
 
+// GetIndexHealth describes an index and compares it with the files on disk, using the
+// filters the next index run would use
+func (is *IndexingService) GetIndexHealth(indexPath string) (*IndexHealth, error) {
+	info, err := os.Stat(indexPath)
+	if os.IsNotExist(err) {
+		return &IndexHealth{IndexPath: indexPath}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat index: %w", err)
+	}
+
+	format, err := lib.DetectIndexFormat(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+	if format != "json" {
+		return nil, fmt.Errorf("cannot inspect a %s index; run 'code-search index --force' to rebuild it", format)
+	}
+
+	index, err := is.loadExistingIndex(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load index for status: %w", err)
+	}
+
+	health := &IndexHealth{
+		Exists:         true,
+		IndexPath:      indexPath,
+		Format:         format,
+		Version:        index.Version,
+		Size:           info.Size(),
+		RepositoryPath: index.RepositoryPath,
+		LastIndexed:    index.LastModified,
+		Filters:        index.Filters,
+		Model:          index.Model,
+		Languages:      make(map[string]LanguageCount),
+	}
+	for _, entry := range index.GetAllFiles() {
+		language := entry.Language
+		if language == "" {
+			language = "unknown"
+		}
+		count := health.Languages[language]
+		count.Files++
+		count.Chunks += len(entry.Chunks)
+		health.Languages[language] = count
+
+		health.FileCount++
+		health.ChunkCount += len(entry.Chunks)
+	}
+
+	health.StaleFiles, health.DeletedFiles, err = index.ChangedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	// Files the next run would index, or remove because they no longer match the filters
+	options := is.indexOptions
+	if index.Filters != nil {
+		options = options.WithStoredFilters(*index.Filters)
+	}
+	defer is.useIndexingOptions(options)()
+
+	files, err := is.fileScanner.ScanFiles(index.RepositoryPath, is.indexOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan files: %w", err)
+	}
+	scanned := make(map[string]bool, len(files))
+	for _, filePath := range files {
+		scanned[filePath] = true
+		if _, err := index.GetFileEntry(filePath); err == nil {
+			continue
+		}
+		if is.wouldIndex(filePath, index) {
+			health.NewFiles = append(health.NewFiles, filePath)
+		}
+	}
+
+	deleted := make(map[string]bool, len(health.DeletedFiles))
+	for _, filePath := range health.DeletedFiles {
+		deleted[filePath] = true
+	}
+	for _, entry := range index.GetAllFiles() {
+		if !scanned[entry.FilePath] && !deleted[entry.FilePath] {
+			health.DeletedFiles = append(health.DeletedFiles, entry.FilePath)
+		}
+	}
+
+	sort.Strings(health.NewFiles)
+	sort.Strings(health.DeletedFiles)
+	return health, nil
+}
+
+// wouldIndex reports whether a file missing from the index would be added by the next
+// run; files that are skipped or produce no chunks are never stored
+func (is *IndexingService) wouldIndex(filePath string, index *models.CodeIndex) bool {
+	if skip, _ := is.shouldSkipFile(filePath, index); skip {
+		return false
+	}
+	chunks, err := is.codeParser.ParseFile(filePath)
+	return err == nil && len(chunks) > 0
+}
+
 // IndexDirectory indexes a specific directory with validation
 func (is *IndexingService) IndexDirectory(
 	directoryPath string,
@@ -565,8 +913,14 @@ func (is *IndexingService) IndexDirectory(
 
 	is.logger.Info("Starting directory indexing for: %s", config.Path)
 
-	// Update directory metadata
+	// Update directory metadata, recording the embedding model the index is built with
 	config.Metadata.MarkIndexed()
+	if is.indexOptions.EmbeddingModel != "" {
+		config.Metadata.Model = &models.IndexModel{
+			ModelName:    is.indexOptions.EmbeddingModel,
+			ModelVersion: lib.DefaultModelVersion,
+		}
+	}
 
 	// Save directory metadata
 	metadataBytes, err := config.Metadata.ToJSON()
@@ -664,13 +1018,13 @@ func (is *IndexingService) GetDirectoryIndexStatus(directoryPath string) (*Direc
 	}
 
 	return &DirectoryIndexStatus{
-		Exists:         true,
-		Directory:      resolvedPath,
-		IndexLocation:  indexLocation,
-		IndexStatus:    indexStatus,
-		DirectoryMeta:  *metadata,
-		Locked:         false,
-		Message:        "Index found and accessible",
+		Exists:        true,
+		Directory:     resolvedPath,
+		IndexLocation: indexLocation,
+		IndexStatus:   indexStatus,
+		DirectoryMeta: *metadata,
+		Locked:        false,
+		Message:       "Index found and accessible",
 	}, nil
 }
 
@@ -688,7 +1042,9 @@ func (is *IndexingService) DeleteIndex(indexPath string) error {
 	return nil
 }
 
-// DeleteDirectoryIndex removes a directory's index
+// DeleteDirectoryIndex removes a directory's .clindex and the single-file indexes that
+// 'index' writes without --dir. It fails with ErrIndexLocked while an index run holds the
+// directory's lock.
 func (is *IndexingService) DeleteDirectoryIndex(directoryPath string) error {
 	fileUtils := lib.NewFileUtilities()
 
@@ -701,10 +1057,20 @@ func (is *IndexingService) DeleteDirectoryIndex(directoryPath string) error {
 	// Create index location
 	indexLocation := fileUtils.CreateIndexLocation(resolvedPath)
 
-	// Note: File locking disabled temporarily to resolve indexing issues
-	// if fileUtils.IsLocked(resolvedPath) {
-	// 	return fmt.Errorf("cannot delete index: directory '%s' is currently being indexed", resolvedPath)
-	// }
+	// Hold the lock while deleting, so no index run starts halfway
+	if fileUtils.DirectoryExists(indexLocation.IndexDir) {
+		lockFile, err := fileUtils.AcquireLock(resolvedPath)
+		if err != nil {
+			return fmt.Errorf("cannot delete the index of '%s': %w", resolvedPath, ErrIndexLocked)
+		}
+		defer fileUtils.ReleaseLock(lockFile)
+	}
+
+	for _, name := range SingleFileIndexNames {
+		if err := is.DeleteIndex(filepath.Join(resolvedPath, name)); err != nil {
+			return err
+		}
+	}
 
 	// Remove index directory
 	if err := fileUtils.CleanupIndexFiles(indexLocation); err != nil {
@@ -744,6 +1110,36 @@ type IndexingStatus struct {
 	Message        string    `json:"message"`
 }
 
+// IndexHealth describes an index and how far it has drifted from the files on disk
+type IndexHealth struct {
+	Exists         bool                     `json:"exists"`
+	IndexPath      string                   `json:"index_path"`
+	Format         string                   `json:"format,omitempty"`
+	Version        string                   `json:"version,omitempty"`
+	Size           int64                    `json:"size"`
+	RepositoryPath string                   `json:"repository_path,omitempty"`
+	FileCount      int                      `json:"file_count"`
+	ChunkCount     int                      `json:"chunk_count"`
+	Languages      map[string]LanguageCount `json:"languages,omitempty"`
+	LastIndexed    time.Time                `json:"last_indexed"`
+	Filters        *models.IndexFilters     `json:"filters,omitempty"`
+	Model          *models.IndexModel       `json:"-"`             // Reported by the status command
+	StaleFiles     []string                 `json:"stale_files"`   // Indexed files whose content changed
+	NewFiles       []string                 `json:"new_files"`     // Files the next run would add
+	DeletedFiles   []string                 `json:"deleted_files"` // Indexed files that are gone or no longer match the filters
+}
+
+// LanguageCount counts the indexed files and chunks of one language
+type LanguageCount struct {
+	Files  int `json:"files"`
+	Chunks int `json:"chunks"`
+}
+
+// NeedsReindex reports whether the index is missing or out of date
+func (h *IndexHealth) NeedsReindex() bool {
+	return !h.Exists || len(h.StaleFiles) > 0 || len(h.NewFiles) > 0 || len(h.DeletedFiles) > 0
+}
+
 // DefaultLogger provides a simple console logger
 type DefaultLogger struct{}
 
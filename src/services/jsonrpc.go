@@ -0,0 +1,49 @@
+package services
+
+import "encoding/json"
+
+// JSON-RPC error codes, shared by the MCP and LSP servers
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+// rpcNull is a JSON-RPC result of null, which a nil Result would omit
+var rpcNull = json.RawMessage("null")
+
+// rpcRequest is a JSON-RPC 2.0 request, or a notification when it has no id
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is a JSON-RPC error
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// newRPCResult builds a successful response
+func newRPCResult(id json.RawMessage, result interface{}) *rpcResponse {
+	return &rpcResponse{JSONRPC: "2.0", ID: id, Result: result}
+}
+
+// newRPCError builds an error response. Errors about unreadable requests have a null id.
+func newRPCError(id json.RawMessage, code int, message string) *rpcResponse {
+	if len(id) == 0 {
+		id = rpcNull
+	}
+	return &rpcResponse{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: message}}
+}
@@ -0,0 +1,608 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+
+	"code-search/src/models"
+)
+
+// LSP error codes beyond the JSON-RPC ones
+const (
+	lspServerNotInitialized = -32002
+	lspRequestFailed        = -32803
+)
+
+// LSP symbol kinds used for index symbols
+const (
+	lspSymbolClass     = 5
+	lspSymbolMethod    = 6
+	lspSymbolInterface = 11
+	lspSymbolFunction  = 12
+	lspSymbolVariable  = 13
+	lspSymbolConstant  = 14
+	lspSymbolStruct    = 23
+)
+
+// LSP message types for window/logMessage
+const (
+	lspMessageError = 1
+	lspMessageLog   = 4
+)
+
+// maxWorkspaceSymbols bounds the symbols returned for workspace/symbol
+const maxWorkspaceSymbols = 200
+
+// reindexRetryDelay is how long saved files wait while another reindex runs
+const reindexRetryDelay = 500 * time.Millisecond
+
+// WorkspaceOpener creates the search server for the workspace an editor opens, given the
+// root it names in initialize
+type WorkspaceOpener func(root string) (*SearchServer, error)
+
+// LSPServer speaks the Language Server Protocol over a pair of streams, so editors can
+// search the index without a plugin. It answers workspace/symbol from the symbol index,
+// answers the custom codeSearch/search request with search results, and reindexes files
+// as they are saved. Columns are counted in UTF-16 code units, as LSP requires.
+type LSPServer struct {
+	open          WorkspaceOpener
+	symbolService *SymbolService
+	version       string
+	logger        Logger
+
+	search      *SearchServer
+	initialized bool
+	shutdown    bool
+
+	writeMu sync.Mutex
+	out     io.Writer
+
+	saveMu    sync.Mutex
+	saved     map[string]bool
+	reindexer bool
+	reindexWG sync.WaitGroup
+}
+
+// LSPPosition is a zero-based line and UTF-16 column
+type LSPPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// LSPRange is a range between two positions, the end being exclusive
+type LSPRange struct {
+	Start LSPPosition `json:"start"`
+	End   LSPPosition `json:"end"`
+}
+
+// LSPLocation is a range in a file
+type LSPLocation struct {
+	URI   string   `json:"uri"`
+	Range LSPRange `json:"range"`
+}
+
+// LSPSymbolInformation is a workspace/symbol result
+type LSPSymbolInformation struct {
+	Name          string      `json:"name"`
+	Kind          int         `json:"kind"`
+	Location      LSPLocation `json:"location"`
+	ContainerName string      `json:"containerName,omitempty"`
+}
+
+// LSPSearchParams are the params of codeSearch/search, the search fields in LSP's casing
+type LSPSearchParams struct {
+	Query         string  `json:"query"`
+	SearchType    string  `json:"searchType,omitempty"`
+	MaxResults    int     `json:"maxResults,omitempty"`
+	Offset        int     `json:"offset,omitempty"`
+	Cursor        string  `json:"cursor,omitempty"`
+	Threshold     float64 `json:"threshold,omitempty"`
+	FilePattern   string  `json:"filePattern,omitempty"`
+	Dir           string  `json:"dir,omitempty"`
+	CaseSensitive bool    `json:"caseSensitive,omitempty"`
+	SmartCase     bool    `json:"smartCase,omitempty"`
+	WholeWord     bool    `json:"wholeWord,omitempty"`
+	NoSynonyms    bool    `json:"noSynonyms,omitempty"`
+	Multiline     bool    `json:"multiline,omitempty"`
+	MaxEdits      *int    `json:"maxEdits,omitempty"`
+}
+
+// LSPSearchResult is one result of codeSearch/search
+type LSPSearchResult struct {
+	Location  LSPLocation `json:"location"`
+	Score     float64     `json:"score"`
+	MatchType string      `json:"matchType"`
+	Content   string      `json:"content"`
+}
+
+// LSPSearchResponse is the result of codeSearch/search
+type LSPSearchResponse struct {
+	Query        string            `json:"query"`
+	TotalResults int               `json:"totalResults"`
+	HasMore      bool              `json:"hasMore"`
+	NextCursor   string            `json:"nextCursor,omitempty"`
+	Results      []LSPSearchResult `json:"results"`
+}
+
+// NewLSPServer creates a language server that opens its workspace with open
+func NewLSPServer(open WorkspaceOpener, symbolService *SymbolService, version string, logger Logger) *LSPServer {
+	return &LSPServer{
+		open:          open,
+		symbolService: symbolService,
+		version:       version,
+		logger:        logger,
+		saved:         make(map[string]bool),
+	}
+}
+
+// Serve answers the messages read from in until the client sends exit or in is exhausted.
+// It returns an error if the client exits without shutting the server down first.
+func (l *LSPServer) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	l.out = out
+	reader := bufio.NewReader(in)
+	defer l.reindexWG.Wait()
+
+	for ctx.Err() == nil {
+		message, err := readLSPMessage(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var request rpcRequest
+		if err := json.Unmarshal(message, &request); err != nil {
+			l.write(newRPCError(nil, rpcParseError, fmt.Sprintf("invalid JSON: %v", err)))
+			continue
+		}
+		if request.Method == "exit" {
+			if !l.shutdown {
+				return fmt.Errorf("client exited without shutting down the server")
+			}
+			return nil
+		}
+
+		if response := l.handle(ctx, request); response != nil {
+			l.write(response)
+		}
+	}
+	return ctx.Err()
+}
+
+// handle answers one message, returning nil for notifications
+func (l *LSPServer) handle(ctx context.Context, request rpcRequest) *rpcResponse {
+	notification := len(request.ID) == 0
+
+	switch {
+	case request.Method == "initialize":
+		result, err := l.initialize(request.Params)
+		if err != nil {
+			return newRPCError(request.ID, lspRequestFailed, err.Error())
+		}
+		return newRPCResult(request.ID, result)
+	case !l.initialized:
+		if notification {
+			return nil
+		}
+		return newRPCError(request.ID, lspServerNotInitialized, "the server has not been initialized")
+	case l.shutdown && !notification:
+		return newRPCError(request.ID, rpcInvalidRequest, "the server is shutting down")
+	}
+
+	if notification {
+		if request.Method == "textDocument/didSave" {
+			l.didSave(request.Params)
+		}
+		// Other notifications, such as didOpen, didChange and $/cancelRequest, are not needed
+		return nil
+	}
+
+	switch request.Method {
+	case "shutdown":
+		l.shutdown = true
+		return newRPCResult(request.ID, rpcNull)
+	case "workspace/symbol":
+		var params struct {
+			Query string `json:"query"`
+		}
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return newRPCError(request.ID, rpcInvalidParams, fmt.Sprintf("invalid params: %v", err))
+		}
+		symbols, err := l.workspaceSymbols(params.Query)
+		if err != nil {
+			return newRPCError(request.ID, lspRequestFailed, err.Error())
+		}
+		return newRPCResult(request.ID, symbols)
+	case "codeSearch/search":
+		var params LSPSearchParams
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return newRPCError(request.ID, rpcInvalidParams, fmt.Sprintf("invalid params: %v", err))
+		}
+		return l.codeSearch(ctx, request.ID, params)
+	default:
+		return newRPCError(request.ID, rpcMethodNotFound, fmt.Sprintf("method not found: %s", request.Method))
+	}
+}
+
+// initialize opens the workspace the client names and describes the server's capabilities
+func (l *LSPServer) initialize(params json.RawMessage) (interface{}, error) {
+	var request struct {
+		RootURI          string `json:"rootUri"`
+		RootPath         string `json:"rootPath"`
+		WorkspaceFolders []struct {
+			URI string `json:"uri"`
+		} `json:"workspaceFolders"`
+	}
+	json.Unmarshal(params, &request)
+
+	// The first workspace folder wins when the client sends several
+	root := request.RootPath
+	if len(request.WorkspaceFolders) > 0 {
+		root = uriToPath(request.WorkspaceFolders[0].URI)
+	} else if request.RootURI != "" {
+		root = uriToPath(request.RootURI)
+	}
+
+	search, err := l.open(root)
+	if err != nil {
+		return nil, err
+	}
+	if err := search.Load(); err != nil {
+		l.logger.Warn("No index loaded for %s: %v", search.Root(), err)
+	}
+	l.search = search
+	l.initialized = true
+
+	return map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			"positionEncoding":        "utf-16",
+			"textDocumentSync":        map[string]interface{}{"openClose": false, "change": 0, "save": map[string]bool{"includeText": false}},
+			"workspaceSymbolProvider": true,
+			"experimental":            map[string]interface{}{"codeSearch": map[string]bool{"search": true}},
+		},
+		"serverInfo": map[string]string{"name": "code-search", "version": l.version},
+	}, nil
+}
+
+// workspaceSymbols answers workspace/symbol. An empty query lists every symbol, up to
+// the limit.
+func (l *LSPServer) workspaceSymbols(query string) ([]LSPSymbolInformation, error) {
+	index := l.search.currentIndex()
+	if index == nil {
+		return nil, fmt.Errorf("%w; run 'code-search index' in %s", errNoIndex, l.search.Root())
+	}
+	if strings.TrimSpace(query) == "" {
+		query = "*"
+	}
+
+	matches, err := l.symbolService.FindSymbolsInIndex(index, SymbolQuery{Pattern: query, MaxResults: maxWorkspaceSymbols})
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(lspFileLines)
+	symbols := make([]LSPSymbolInformation, 0, len(matches))
+	for _, match := range matches {
+		symbols = append(symbols, LSPSymbolInformation{
+			Name:          match.Name,
+			Kind:          lspSymbolKind(&match.Symbol),
+			Location:      files.symbolLocation(&match.Symbol),
+			ContainerName: match.Container,
+		})
+	}
+	return symbols, nil
+}
+
+// codeSearch answers codeSearch/search
+func (l *LSPServer) codeSearch(ctx context.Context, id json.RawMessage, params LSPSearchParams) *rpcResponse {
+	response, err := l.search.Search(ctx, SearchRequest{
+		Query:         params.Query,
+		SearchType:    params.SearchType,
+		MaxResults:    params.MaxResults,
+		Offset:        params.Offset,
+		Cursor:        params.Cursor,
+		Threshold:     params.Threshold,
+		FilePattern:   params.FilePattern,
+		Dir:           params.Dir,
+		CaseSensitive: params.CaseSensitive,
+		SmartCase:     params.SmartCase,
+		WholeWord:     params.WholeWord,
+		NoSynonyms:    params.NoSynonyms,
+		Multiline:     params.Multiline,
+		MaxEdits:      params.MaxEdits,
+	})
+	if err != nil {
+		var parseErr *models.QueryParseError
+		var pathErr *serverPathError
+		switch {
+		case errors.As(err, &parseErr):
+			return newRPCError(id, rpcInvalidParams, fmt.Sprintf("query syntax error at column %d: %s", parseErr.Column, parseErr.Message))
+		case errors.As(err, &pathErr), strings.Contains(err.Error(), "invalid"):
+			return newRPCError(id, rpcInvalidParams, err.Error())
+		default:
+			return newRPCError(id, lspRequestFailed, err.Error())
+		}
+	}
+
+	files := make(lspFileLines)
+	results := make([]LSPSearchResult, 0, len(response.Results))
+	for _, result := range response.Results {
+		results = append(results, LSPSearchResult{
+			Location:  files.resultLocation(result),
+			Score:     result.RelevanceScore,
+			MatchType: string(result.MatchType),
+			Content:   result.Content,
+		})
+	}
+
+	return newRPCResult(id, LSPSearchResponse{
+		Query:        response.Query,
+		TotalResults: response.TotalResults,
+		HasMore:      response.HasMore,
+		NextCursor:   response.NextCursor,
+		Results:      results,
+	})
+}
+
+// didSave queues a saved file for reindexing. Saves arriving while a reindex runs are
+// collected and reindexed together afterwards.
+func (l *LSPServer) didSave(params json.RawMessage) {
+	var request struct {
+		TextDocument struct {
+			URI string `json:"uri"`
+		} `json:"textDocument"`
+	}
+	if err := json.Unmarshal(params, &request); err != nil {
+		return
+	}
+	path := uriToPath(request.TextDocument.URI)
+	if path == "" || l.search.fileUtils.ValidatePathSecurity(path, []string{l.search.Root()}) != nil {
+		return
+	}
+
+	l.saveMu.Lock()
+	defer l.saveMu.Unlock()
+	l.saved[filepath.Clean(path)] = true
+	if !l.reindexer {
+		l.reindexer = true
+		l.reindexWG.Add(1)
+		go l.reindexSaved()
+	}
+}
+
+// reindexSaved reindexes saved files until none are waiting. Files whose reindex fails
+// are queued again: they wait for another reindex to finish, and otherwise go with the
+// next save rather than retrying a failing reindex at once.
+func (l *LSPServer) reindexSaved() {
+	defer l.reindexWG.Done()
+
+	for {
+		l.saveMu.Lock()
+		if len(l.saved) == 0 {
+			l.reindexer = false
+			l.saveMu.Unlock()
+			return
+		}
+		pending := make([]string, 0, len(l.saved))
+		for path := range l.saved {
+			pending = append(pending, path)
+		}
+		sort.Strings(pending)
+		l.saved = make(map[string]bool)
+		l.saveMu.Unlock()
+		files := pending
+
+		// Files the index does not have yet may be excluded from indexing, so those
+		// trigger an incremental reindex of the whole workspace, which applies the rules
+		index := l.search.currentIndex()
+		for _, path := range files {
+			if index == nil {
+				files = nil
+				break
+			}
+			if _, err := index.GetFileEntry(path); err != nil {
+				files = nil
+				break
+			}
+		}
+
+		result, err := l.search.Reindex(files)
+		if errors.Is(err, errReindexRunning) {
+			l.requeue(pending, true)
+			time.Sleep(reindexRetryDelay)
+			continue
+		}
+		if err != nil {
+			l.notify("window/logMessage", map[string]interface{}{"type": lspMessageError, "message": fmt.Sprintf("code-search: reindex failed: %v", err)})
+			if !l.requeue(pending, false) {
+				return
+			}
+			continue
+		}
+		l.notify("window/logMessage", map[string]interface{}{"type": lspMessageLog, "message": fmt.Sprintf("code-search: reindexed %d files in %v", result.FilesIndexed, result.Duration)})
+	}
+}
+
+// requeue queues files again after a failed reindex and reports whether the reindexer
+// keeps running. Unless it is to wait or other files were saved meanwhile, it stops until
+// the next save.
+func (l *LSPServer) requeue(files []string, wait bool) bool {
+	l.saveMu.Lock()
+	defer l.saveMu.Unlock()
+	running := wait || len(l.saved) > 0
+	for _, path := range files {
+		l.saved[path] = true
+	}
+	if !running {
+		l.reindexer = false
+	}
+	return running
+}
+
+// notify sends a notification to the client
+func (l *LSPServer) notify(method string, params interface{}) {
+	l.write(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+// write sends a message with its Content-Length header
+func (l *LSPServer) write(message interface{}) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		l.logger.Error("Failed to encode LSP message: %v", err)
+		return
+	}
+
+	l.writeMu.Lock()
+	defer l.writeMu.Unlock()
+	if _, err := fmt.Fprintf(l.out, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		l.logger.Error("Failed to write LSP message: %v", err)
+	}
+}
+
+// readLSPMessage reads one message body, framed by headers as LSP's base protocol does
+func readLSPMessage(reader *bufio.Reader) ([]byte, error) {
+	headers, err := textproto.NewReader(reader).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF && len(headers) == 0 {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to read message headers: %w", err)
+	}
+
+	length, err := strconv.Atoi(headers.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length header: %q", headers.Get("Content-Length"))
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return nil, fmt.Errorf("failed to read message body: %w", err)
+	}
+	return body, nil
+}
+
+// lspSymbolKind maps an index symbol kind to an LSP symbol kind
+func lspSymbolKind(symbol *models.Symbol) int {
+	switch symbol.Kind {
+	case models.SymbolKindFunc:
+		return lspSymbolFunction
+	case models.SymbolKindMethod:
+		return lspSymbolMethod
+	case models.SymbolKindClass:
+		return lspSymbolClass
+	case models.SymbolKindConst:
+		return lspSymbolConstant
+	case models.SymbolKindVar:
+		return lspSymbolVariable
+	}
+	if strings.Contains(symbol.Signature, "interface") || strings.Contains(symbol.Signature, "trait") {
+		return lspSymbolInterface
+	}
+	return lspSymbolStruct
+}
+
+// lspFileLines caches the lines of files read to convert byte columns to UTF-16 ones
+type lspFileLines map[string][]string
+
+// line returns a 1-based line of a file, or "" when it cannot be read
+func (f lspFileLines) line(path string, number int) string {
+	lines, ok := f[path]
+	if !ok {
+		if data, err := os.ReadFile(path); err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+		f[path] = lines
+	}
+	if number < 1 || number > len(lines) {
+		return ""
+	}
+	return strings.TrimSuffix(lines[number-1], "\r")
+}
+
+// position converts a 1-based line and 0-based byte column to an LSP position
+func (f lspFileLines) position(path string, line, byteColumn int) LSPPosition {
+	return LSPPosition{Line: line - 1, Character: utf16Column(f.line(path, line), byteColumn)}
+}
+
+// lineEnd returns the position at the end of a 1-based line
+func (f lspFileLines) lineEnd(path string, line int) LSPPosition {
+	text := f.line(path, line)
+	return LSPPosition{Line: line - 1, Character: utf16Column(text, len(text))}
+}
+
+// symbolLocation returns the location of a symbol, starting at its name
+func (f lspFileLines) symbolLocation(symbol *models.Symbol) LSPLocation {
+	start := LSPPosition{Line: symbol.StartLine - 1}
+	if column := strings.Index(f.line(symbol.FilePath, symbol.StartLine), symbol.Name); column >= 0 {
+		start = f.position(symbol.FilePath, symbol.StartLine, column)
+	}
+	return LSPLocation{
+		URI:   pathToURI(symbol.FilePath),
+		Range: LSPRange{Start: start, End: f.lineEnd(symbol.FilePath, symbol.EndLine)},
+	}
+}
+
+// resultLocation returns the location of a search result's first match, or of its whole
+// lines when it has no exact match
+func (f lspFileLines) resultLocation(result *models.SearchResult) LSPLocation {
+	location := LSPLocation{URI: pathToURI(result.FilePath)}
+	if len(result.Spans) > 0 {
+		span := result.Spans[0]
+		location.Range = LSPRange{
+			Start: f.position(result.FilePath, span.StartLine, span.StartColumn-1),
+			End:   f.position(result.FilePath, span.EndLine, span.EndColumn-1),
+		}
+		return location
+	}
+	location.Range = LSPRange{
+		Start: LSPPosition{Line: result.StartLine - 1},
+		End:   f.lineEnd(result.FilePath, result.EndLine),
+	}
+	return location
+}
+
+// utf16Column converts a byte offset in a line to a count of UTF-16 code units
+func utf16Column(line string, byteColumn int) int {
+	if byteColumn > len(line) {
+		byteColumn = len(line)
+	}
+	column := 0
+	for _, r := range line[:max(byteColumn, 0)] {
+		if n := utf16.RuneLen(r); n > 0 {
+			column += n
+		} else {
+			// Invalid UTF-8 is decoded as U+FFFD, a single code unit
+			column++
+		}
+	}
+	return column
+}
+
+// uriToPath converts a file URI to a path, returning "" for other schemes
+func uriToPath(uri string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme != "file" {
+		return ""
+	}
+	return filepath.FromSlash(parsed.Path)
+}
+
+// pathToURI converts an absolute path to a file URI
+func pathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
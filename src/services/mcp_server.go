@@ -0,0 +1,470 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code-search/src/models"
+)
+
+// mcpProtocolVersions are the Model Context Protocol revisions the server speaks, newest first
+var mcpProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// maxSnippetLines bounds the lines get_file_snippet returns at once
+const maxSnippetLines = 500
+
+// MCPServer exposes searches of one indexed directory as Model Context Protocol tools. It
+// reads JSON-RPC messages from one stream and writes responses to another, one message per
+// line, as MCP's stdio transport does.
+type MCPServer struct {
+	search        *SearchServer
+	symbolService *SymbolService
+	version       string
+	logger        Logger
+	tools         []mcpTool
+}
+
+// mcpTool is a tool advertised by tools/list
+type mcpTool struct {
+	Name        string          `json:"name"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+
+	handler func(ctx context.Context, arguments json.RawMessage) (interface{}, error)
+}
+
+// mcpContent is a block of a tool result
+type mcpContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// mcpToolResult is the result of tools/call
+type mcpToolResult struct {
+	Content           []mcpContent `json:"content"`
+	StructuredContent interface{}  `json:"structuredContent,omitempty"`
+	IsError           bool         `json:"isError,omitempty"`
+}
+
+// FileSnippet is the result of the get_file_snippet tool
+type FileSnippet struct {
+	Path       string `json:"path"`
+	StartLine  int    `json:"start_line"`
+	EndLine    int    `json:"end_line"`
+	TotalLines int    `json:"total_lines"`
+	Content    string `json:"content"`
+}
+
+// NewMCPServer creates an MCP server answering tool calls from the index served by search
+func NewMCPServer(search *SearchServer, symbolService *SymbolService, version string, logger Logger) *MCPServer {
+	server := &MCPServer{
+		search:        search,
+		symbolService: symbolService,
+		version:       version,
+		logger:        logger,
+	}
+
+	server.tools = []mcpTool{
+		{
+			Name:        "search_code",
+			Title:       "Search code",
+			Description: "Search the indexed directory. Queries use the code-search syntax: words, \"phrases\", OR, -exclusions and filters such as lang:go, path:src/ and sym:Handler. Returns ranked results with file paths, line ranges and content; pass next_cursor back as cursor for the next page.",
+			InputSchema: json.RawMessage(mcpSearchSchema),
+			handler:     server.callSearch,
+		},
+		{
+			Name:        "get_file_snippet",
+			Title:       "Read file lines",
+			Description: fmt.Sprintf("Read a range of lines from a file inside the indexed directory, at most %d lines at a time.", maxSnippetLines),
+			InputSchema: json.RawMessage(mcpSnippetSchema),
+			handler:     server.callFileSnippet,
+		},
+		{
+			Name:        "list_symbols",
+			Title:       "List symbols",
+			Description: "Find function, method, type, class, constant and variable definitions by name, prefix, abbreviation or glob.",
+			InputSchema: json.RawMessage(mcpSymbolsSchema),
+			handler:     server.callListSymbols,
+		},
+		{
+			Name:        "index_status",
+			Title:       "Index status",
+			Description: "Describe the loaded index: indexed files and chunks, languages, when it was built and the last reindex. With check it also reports whether files changed since.",
+			InputSchema: json.RawMessage(`{"type": "object", "properties": {"check": {"type": "boolean", "description": "Compare the index with the files on disk"}}, "additionalProperties": false}`),
+			handler:     server.callIndexStatus,
+		},
+		{
+			Name:        "reindex",
+			Title:       "Reindex",
+			Description: "Incrementally reindex the directory, so that changed files are searchable, and load the new index.",
+			InputSchema: json.RawMessage(`{"type": "object", "properties": {}, "additionalProperties": false}`),
+			handler:     server.callReindex,
+		},
+	}
+	return server
+}
+
+// Serve answers the messages read from in until it is exhausted or ctx is done
+func (m *MCPServer) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	encoder := json.NewEncoder(out)
+
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			if response := m.handleMessage(ctx, line); response != nil {
+				if err := encoder.Encode(response); err != nil {
+					return fmt.Errorf("failed to write response: %w", err)
+				}
+			}
+		}
+
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return fmt.Errorf("failed to read request: %w", readErr)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// handleMessage answers one message, returning nil for notifications
+func (m *MCPServer) handleMessage(ctx context.Context, message []byte) *rpcResponse {
+	if message[0] == '[' {
+		return newRPCError(nil, rpcInvalidRequest, "batched requests are not supported")
+	}
+
+	var request rpcRequest
+	if err := json.Unmarshal(message, &request); err != nil {
+		return newRPCError(nil, rpcParseError, fmt.Sprintf("invalid JSON: %v", err))
+	}
+	if request.JSONRPC != "2.0" || request.Method == "" {
+		return newRPCError(request.ID, rpcInvalidRequest, "expected a JSON-RPC 2.0 request")
+	}
+
+	// Notifications, such as notifications/initialized, need no response
+	if len(request.ID) == 0 {
+		return nil
+	}
+
+	switch request.Method {
+	case "initialize":
+		return newRPCResult(request.ID, m.initialize(request.Params))
+	case "ping":
+		return newRPCResult(request.ID, struct{}{})
+	case "tools/list":
+		return newRPCResult(request.ID, map[string]interface{}{"tools": m.tools})
+	case "tools/call":
+		return m.callTool(ctx, request)
+	default:
+		return newRPCError(request.ID, rpcMethodNotFound, fmt.Sprintf("method not found: %s", request.Method))
+	}
+}
+
+// initialize agrees on a protocol version and describes the server
+func (m *MCPServer) initialize(params json.RawMessage) interface{} {
+	var request struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	json.Unmarshal(params, &request)
+
+	// Answer with the client's version when it is supported, and otherwise the newest
+	version := mcpProtocolVersions[0]
+	for _, supported := range mcpProtocolVersions {
+		if request.ProtocolVersion == supported {
+			version = supported
+		}
+	}
+
+	return map[string]interface{}{
+		"protocolVersion": version,
+		"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+		"serverInfo":      map[string]string{"name": "code-search", "version": m.version},
+		"instructions":    fmt.Sprintf("Searches the code in %s. Use search_code to find code, list_symbols to find definitions and get_file_snippet to read around a result.", m.search.root),
+	}
+}
+
+// callTool runs a tool. Failures of the tool itself are reported in the result, so the
+// model can see them, rather than as protocol errors.
+func (m *MCPServer) callTool(ctx context.Context, request rpcRequest) *rpcResponse {
+	var params struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(request.Params, &params); err != nil {
+		return newRPCError(request.ID, rpcInvalidParams, fmt.Sprintf("invalid params: %v", err))
+	}
+
+	for _, tool := range m.tools {
+		if tool.Name != params.Name {
+			continue
+		}
+
+		result, err := tool.handler(ctx, params.Arguments)
+		if err != nil {
+			return newRPCResult(request.ID, mcpToolResult{
+				Content: []mcpContent{{Type: "text", Text: err.Error()}},
+				IsError: true,
+			})
+		}
+
+		text, err := json.Marshal(result)
+		if err != nil {
+			return newRPCResult(request.ID, mcpToolResult{
+				Content: []mcpContent{{Type: "text", Text: fmt.Sprintf("failed to encode result: %v", err)}},
+				IsError: true,
+			})
+		}
+		return newRPCResult(request.ID, mcpToolResult{
+			Content:           []mcpContent{{Type: "text", Text: string(text)}},
+			StructuredContent: result,
+		})
+	}
+
+	return newRPCError(request.ID, rpcInvalidParams, fmt.Sprintf("unknown tool: %s", params.Name))
+}
+
+// callSearch runs the search_code tool
+func (m *MCPServer) callSearch(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+	var request SearchRequest
+	if err := decodeToolArguments(arguments, &request); err != nil {
+		return nil, err
+	}
+
+	response, err := m.search.Search(ctx, request)
+	if err != nil {
+		var parseErr *models.QueryParseError
+		if errors.As(err, &parseErr) {
+			return nil, fmt.Errorf("query syntax error at column %d: %s", parseErr.Column, parseErr.Message)
+		}
+		return nil, m.explain(err)
+	}
+	return response, nil
+}
+
+// callFileSnippet runs the get_file_snippet tool
+func (m *MCPServer) callFileSnippet(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+	var args struct {
+		Path      string `json:"path"`
+		StartLine int    `json:"start_line"`
+		EndLine   int    `json:"end_line"`
+	}
+	if err := decodeToolArguments(arguments, &args); err != nil {
+		return nil, err
+	}
+	if args.Path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	return m.fileSnippet(args.Path, args.StartLine, args.EndLine)
+}
+
+// callListSymbols runs the list_symbols tool
+func (m *MCPServer) callListSymbols(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+	var args struct {
+		Pattern    string `json:"pattern"`
+		Kind       string `json:"kind"`
+		MaxResults int    `json:"max_results"`
+	}
+	if err := decodeToolArguments(arguments, &args); err != nil {
+		return nil, err
+	}
+
+	query := SymbolQuery{Pattern: args.Pattern, MaxResults: args.MaxResults}
+	if query.MaxResults <= 0 {
+		query.MaxResults = 50
+	}
+	if args.Kind != "" {
+		kind, err := models.ParseSymbolKind(args.Kind)
+		if err != nil {
+			return nil, err
+		}
+		query.Kind = kind
+	}
+
+	index := m.search.currentIndex()
+	if index == nil {
+		return nil, m.explain(errNoIndex)
+	}
+	matches, err := m.symbolService.FindSymbolsInIndex(index, query)
+	if err != nil {
+		return nil, err
+	}
+	if matches == nil {
+		matches = []SymbolMatch{}
+	}
+
+	return map[string]interface{}{
+		"pattern": args.Pattern,
+		"total":   len(matches),
+		"symbols": matches,
+	}, nil
+}
+
+// callIndexStatus runs the index_status tool
+func (m *MCPServer) callIndexStatus(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+	var args struct {
+		Check bool `json:"check"`
+	}
+	if err := decodeToolArguments(arguments, &args); err != nil {
+		return nil, err
+	}
+
+	status, err := m.search.Status(args.Check)
+	if err != nil {
+		return nil, m.explain(err)
+	}
+	return status, nil
+}
+
+// callReindex runs the reindex tool
+func (m *MCPServer) callReindex(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+	if err := decodeToolArguments(arguments, &struct{}{}); err != nil {
+		return nil, err
+	}
+	return m.search.Reindex(nil)
+}
+
+// fileSnippet reads lines startLine to endLine of a file inside the served directory
+func (m *MCPServer) fileSnippet(path string, startLine, endLine int) (*FileSnippet, error) {
+	root := m.search.root
+	if !filepath.IsAbs(path) {
+		// Joined without cleaning, so that ".." is still seen and rejected
+		path = root + string(filepath.Separator) + path
+	}
+	if err := m.search.fileUtils.ValidatePathSecurity(path, []string{root}); err != nil {
+		return nil, err
+	}
+
+	// Symbolic links must not lead outside the directory either
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if resolvedRoot, err := filepath.EvalSymlinks(root); err == nil {
+		if err := m.search.fileUtils.ValidatePathSecurity(resolved, []string{resolvedRoot}); err != nil {
+			return nil, fmt.Errorf("path '%s' links outside the indexed directory", path)
+		}
+	}
+
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return nil, fmt.Errorf("%s is a binary file", path)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if startLine < 1 {
+		startLine = 1
+	}
+	if endLine < startLine {
+		endLine = startLine + 99
+	}
+	if endLine-startLine+1 > maxSnippetLines {
+		endLine = startLine + maxSnippetLines - 1
+	}
+	if endLine > len(lines) {
+		endLine = len(lines)
+	}
+	if startLine > len(lines) {
+		return nil, fmt.Errorf("start_line %d is past the end of %s, which has %d lines", startLine, path, len(lines))
+	}
+
+	relative, err := filepath.Rel(root, filepath.Clean(path))
+	if err != nil {
+		relative = path
+	}
+	return &FileSnippet{
+		Path:       filepath.ToSlash(relative),
+		StartLine:  startLine,
+		EndLine:    endLine,
+		TotalLines: len(lines),
+		Content:    strings.Join(lines[startLine-1:endLine], "\n"),
+	}, nil
+}
+
+// explain adds what to do next to errors the model can act on
+func (m *MCPServer) explain(err error) error {
+	switch {
+	case errors.Is(err, errNoIndex):
+		return fmt.Errorf("%w; call the reindex tool to build it", err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%w; narrow the query or add filters", err)
+	}
+	return err
+}
+
+// decodeToolArguments decodes tool arguments, rejecting unknown ones
+func decodeToolArguments(arguments json.RawMessage, target interface{}) error {
+	if len(arguments) == 0 || string(arguments) == "null" {
+		arguments = json.RawMessage("{}")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(arguments))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+// Input schemas of the larger tools
+const (
+	mcpSearchSchema = `{
+  "type": "object",
+  "required": ["query"],
+  "additionalProperties": false,
+  "properties": {
+    "query": {"type": "string", "description": "Search query, e.g. 'retry lang:go -path:vendor/'"},
+    "search_type": {"type": "string", "enum": ["hybrid", "text", "semantic", "regex", "exact", "fuzzy"], "description": "How to match (default: hybrid)"},
+    "max_results": {"type": "integer", "minimum": 1, "maximum": 1000, "description": "Results per page (default: 10)"},
+    "offset": {"type": "integer", "minimum": 0, "description": "Ranked results to skip"},
+    "cursor": {"type": "string", "description": "next_cursor of a previous page"},
+    "threshold": {"type": "number", "minimum": 0, "maximum": 1, "description": "Minimum similarity for semantic and hybrid search (default: 0.7)"},
+    "file_pattern": {"type": "string", "description": "Glob the file name must match, e.g. *.go"},
+    "dir": {"type": "string", "description": "Directory inside the indexed one to search"},
+    "case_sensitive": {"type": "boolean"},
+    "smart_case": {"type": "boolean", "description": "Match case only when the query has uppercase letters"},
+    "whole_word": {"type": "boolean"},
+    "no_synonyms": {"type": "boolean", "description": "Do not expand terms with synonyms and abbreviations"},
+    "multiline": {"type": "boolean", "description": "Let regex matches span lines"},
+    "max_edits": {"type": "integer", "minimum": 0, "maximum": 5, "description": "Edit distance for fuzzy search (default: 2)"},
+    "context_before": {"type": "integer", "minimum": 0, "description": "Lines of context before each result"},
+    "context_after": {"type": "integer", "minimum": 0, "description": "Lines of context after each result"}
+  }
+}`
+
+	mcpSnippetSchema = `{
+  "type": "object",
+  "required": ["path"],
+  "additionalProperties": false,
+  "properties": {
+    "path": {"type": "string", "description": "File path, relative to the indexed directory or absolute"},
+    "start_line": {"type": "integer", "minimum": 1, "description": "First line, starting at 1 (default: 1)"},
+    "end_line": {"type": "integer", "minimum": 1, "description": "Last line, inclusive (default: start_line + 99)"}
+  }
+}`
+
+	mcpSymbolsSchema = `{
+  "type": "object",
+  "required": ["pattern"],
+  "additionalProperties": false,
+  "properties": {
+    "pattern": {"type": "string", "description": "Symbol name, prefix, abbreviation or glob"},
+    "kind": {"type": "string", "enum": ["func", "method", "type", "const", "var", "class"]},
+    "max_results": {"type": "integer", "minimum": 1, "description": "Maximum symbols to return (default: 50)"}
+  }
+}`
+)
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"code-search/src/lib"
+	"code-search/src/models"
+)
+
+// Relevance of each kind of reference, so definitions are listed before uses
+var referenceKindScores = map[models.ReferenceKind]float64{
+	models.ReferenceKindDefinition: 1.0,
+	models.ReferenceKindCall:       0.9,
+	models.ReferenceKindUse:        0.8,
+}
+
+// ReferenceService answers cross-reference queries from the references stored in an index
+type ReferenceService struct {
+	vectorStore models.VectorStore
+	logger      Logger
+}
+
+// NewReferenceService creates a new reference service
+func NewReferenceService(vectorStore models.VectorStore, logger Logger) *ReferenceService {
+	return &ReferenceService{
+		vectorStore: vectorStore,
+		logger:      logger,
+	}
+}
+
+// FindReferences returns the definitions and uses of the identifier named by the query text
+func (rs *ReferenceService) FindReferences(indexPath string, query *models.SearchQuery) (*models.SearchResults, error) {
+	return rs.find(indexPath, query, func(ref *models.Reference) bool {
+		return true
+	})
+}
+
+// FindCallers returns the call sites of the function or method named by the query text
+func (rs *ReferenceService) FindCallers(indexPath string, query *models.SearchQuery) (*models.SearchResults, error) {
+	return rs.find(indexPath, query, func(ref *models.Reference) bool {
+		return ref.Kind == models.ReferenceKindCall
+	})
+}
+
+// find collects the references matching the query name that pass include
+func (rs *ReferenceService) find(
+	indexPath string,
+	query *models.SearchQuery,
+	include func(ref *models.Reference) bool,
+) (*models.SearchResults, error) {
+	start := time.Now()
+
+	name := strings.TrimSpace(query.QueryText)
+	if name == "" {
+		return nil, fmt.Errorf("identifier cannot be empty")
+	}
+
+	if _, err := os.Stat(indexPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("index file does not exist: %s", indexPath)
+	}
+
+	index, err := models.LoadCodeIndex(indexPath, rs.vectorStore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load index: %w", err)
+	}
+	defer index.Close()
+
+	results := models.NewSearchResults(query)
+	results.SetSearchedFiles(len(index.GetAllFiles()))
+
+	var matches []*models.SearchResult
+	for _, entry := range index.GetAllFiles() {
+		var lines *lib.LineIndex
+
+		for i := range entry.References {
+			ref := &entry.References[i]
+			if !ref.MatchesName(name) || !include(ref) {
+				continue
+			}
+
+			// Read each file once, and only if it has a match
+			if lines == nil {
+				data, err := os.ReadFile(entry.FilePath)
+				if err != nil {
+					rs.logger.Warn("Failed to read %s: %v", entry.FilePath, err)
+					break
+				}
+				lines = lib.NewLineIndex(string(data))
+			}
+
+			if result := rs.newReferenceResult(ref, lines.LineText(ref.Line), entry.Language); result != nil {
+				matches = append(matches, result)
+			}
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].RelevanceScore != matches[j].RelevanceScore {
+			return matches[i].RelevanceScore > matches[j].RelevanceScore
+		}
+		if matches[i].FilePath != matches[j].FilePath {
+			return matches[i].FilePath < matches[j].FilePath
+		}
+		if matches[i].StartLine != matches[j].StartLine {
+			return matches[i].StartLine < matches[j].StartLine
+		}
+		return matches[i].StartColumn < matches[j].StartColumn
+	})
+
+	for _, match := range matches {
+		if err := results.AddResult(match); err != nil {
+			rs.logger.Warn("Failed to add reference result: %v", err)
+		}
+	}
+	for i, result := range results.Results {
+		result.Rank = i + 1
+	}
+
+	results.LimitResults(query.MaxResults)
+	results.SetExecutionTime(time.Since(start))
+
+	rs.logger.Debug("Found %d references to %s in %v", results.TotalResults, name, results.ExecutionTime)
+	return results, nil
+}
+
+// newReferenceResult converts a stored reference into a search result
+func (rs *ReferenceService) newReferenceResult(ref *models.Reference, lineText, language string) *models.SearchResult {
+	if strings.TrimSpace(lineText) == "" {
+		return nil
+	}
+
+	result := models.NewSearchResult(ref.FilePath, ref.Line, ref.Line, lineText)
+	result.MatchType = models.MatchTypeReference
+	result.Language = language
+	result.RelevanceScore = referenceKindScores[ref.Kind]
+	result.AddSpan(models.MatchSpan{
+		StartLine:   ref.Line,
+		StartColumn: ref.Column,
+		EndLine:     ref.Line,
+		EndColumn:   ref.EndColumn,
+		Text:        ref.Name,
+	})
+	result.AddMetadata("reference_kind", string(ref.Kind))
+	result.AddMetadata("target", ref.Target)
+	if ref.Caller != "" {
+		result.AddMetadata("caller", ref.Caller)
+	}
+
+	return result
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"code-search/src/models"
+)
+
+// ErrNoRepositories is returned when the repo: filters exclude every repository
+var ErrNoRepositories = errors.New("no repository matches the repo: filters")
+
+// maxRepositoryWindow is how many ranked results are requested from each repository for
+// every page, the largest page a query may ask for
+const maxRepositoryWindow = 1000
+
+// Repository is one indexed repository of a multi-repository search
+type Repository struct {
+	Name      string `json:"name"`
+	Directory string `json:"directory"`
+	IndexPath string `json:"index_path"`
+}
+
+// IndexSearcher searches a single index file
+type IndexSearcher interface {
+	Search(query *models.SearchQuery, indexPath string) (*models.SearchResults, error)
+}
+
+// SearchRepositories searches the indexes of several repositories concurrently and
+// merges their results into one ranking. Raw scores of different indexes fall in
+// different ranges, so each repository's scores are min-max normalised before the
+// rankings are merged. Each result is labelled with its repository, and repositories excluded by the
+// query's repo: filters are not searched. Every repository ranks the same
+// maxRepositoryWindow results for every page, so normalised scores and the merged order
+// do not change between pages; later pages of the merged ranking are not served.
+func SearchRepositories(
+	searcher IndexSearcher,
+	query *models.SearchQuery,
+	repositories []Repository,
+) (*models.SearchResults, error) {
+	start := time.Now()
+
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid search query: %w", err)
+	}
+
+	selected, err := FilterRepositories(query, repositories)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([]*models.SearchResults, len(selected))
+	errs := make([]error, len(selected))
+	var wg sync.WaitGroup
+	for i, repository := range selected {
+		wg.Add(1)
+		go func(i int, repository Repository) {
+			defer wg.Done()
+			repositoryQuery := query.Clone()
+			repositoryQuery.Offset = 0
+			repositoryQuery.MaxResults = maxRepositoryWindow
+			found[i], errs[i] = searcher.Search(repositoryQuery, repository.IndexPath)
+		}(i, repository)
+	}
+	wg.Wait()
+
+	merged := models.NewSearchResults(query)
+	names := make([]string, len(selected))
+	paths := make(map[string]string, len(selected))
+	for i, repository := range selected {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("%s: %w", repository.Name, errs[i])
+		}
+		names[i] = repository.Name
+		paths[repository.Name], _ = found[i].Metadata[models.MetadataRepositoryPath].(string)
+		// Only the first maxRepositoryWindow results of a repository can ever be served
+		merged.TotalResults += min(found[i].TotalResults, maxRepositoryWindow)
+		merged.SearchedFiles += found[i].SearchedFiles
+		merged.Results = append(merged.Results, normaliseResults(found[i].Results, repository.Name)...)
+	}
+	// Ties go to the earlier repository, then to the better rank within it
+	sort.SliceStable(merged.Results, func(i, j int) bool {
+		return merged.Results[i].RelevanceScore > merged.Results[j].RelevanceScore
+	})
+	for i, result := range merged.Results {
+		result.Rank = i + 1
+	}
+	if merged, err = sortRanking(merged, query.SortOrder); err != nil {
+		return nil, err
+	}
+	merged.AddMetadata("repositories", names)
+	merged.AddMetadata(models.MetadataRepositoryPaths, paths)
+
+	results := merged.Page(query.Offset, query.MaxResults)
+	results.SetExecutionTime(time.Since(start))
+	return results, nil
+}
+
+// FilterRepositories returns the repositories the query's repo: filters keep, or
+// ErrNoRepositories when they keep none
+func FilterRepositories(query *models.SearchQuery, repositories []Repository) ([]Repository, error) {
+	var selected []Repository
+	for _, repository := range repositories {
+		if query.ShouldIncludeRepository(repository.Name) {
+			selected = append(selected, repository)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, ErrNoRepositories
+	}
+	return selected, nil
+}
+
+// normaliseResults labels the results of one repository and rescales their scores so
+// that its best result scores 1 and its worst 0. Results of a repository whose scores
+// are all equal score 1.
+func normaliseResults(results []*models.SearchResult, repository string) []*models.SearchResult {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, result := range results {
+		low = math.Min(low, result.RelevanceScore)
+		high = math.Max(high, result.RelevanceScore)
+	}
+	for _, result := range results {
+		result.Repository = repository
+		if high > low {
+			result.UpdateScore((result.RelevanceScore - low) / (high - low))
+		} else {
+			result.UpdateScore(1)
+		}
+	}
+	return results
+}
@@ -0,0 +1,591 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"code-search/src/lib"
+	"code-search/src/models"
+)
+
+var (
+	errNoIndex        = errors.New("no index is loaded")
+	errReindexRunning = errors.New("a reindex is already running")
+)
+
+// ReindexFunc incrementally reindexes the served directory, or only the given files
+type ReindexFunc func(files []string) (*IndexingResult, error)
+
+// SearchServer serves searches of one indexed directory over HTTP with JSON bodies. The
+// index stays loaded between requests and is replaced after each reindex.
+type SearchServer struct {
+	root          string
+	indexPath     string
+	searchService *SearchService
+	reindex       ReindexFunc
+	fileUtils     *lib.FileUtilities
+	logger        Logger
+	mux           *http.ServeMux
+	started       time.Time
+	hosts         map[string]bool
+	token         string
+
+	mu         sync.RWMutex
+	index      *models.CodeIndex
+	loadedAt   time.Time
+	reindexing bool
+	lastResult *IndexingResult
+	lastError  string
+}
+
+// NewSearchServer creates a server for the directory root, whose index is at indexPath
+func NewSearchServer(root, indexPath string, searchService *SearchService, reindex ReindexFunc, logger Logger) *SearchServer {
+	server := &SearchServer{
+		root:          filepath.Clean(root),
+		indexPath:     indexPath,
+		searchService: searchService,
+		reindex:       reindex,
+		fileUtils:     lib.NewFileUtilities(),
+		logger:        logger,
+		mux:           http.NewServeMux(),
+		started:       time.Now(),
+		hosts:         map[string]bool{"localhost": true, "127.0.0.1": true, "::1": true},
+	}
+
+	server.mux.HandleFunc("/health", server.handleHealth)
+	server.mux.HandleFunc("/openapi.json", server.handleOpenAPI)
+	server.mux.HandleFunc("/v1/search", server.handleSearch)
+	server.mux.HandleFunc("/v1/status", server.handleStatus)
+	server.mux.HandleFunc("/v1/reindex", server.handleReindex)
+	return server
+}
+
+// Load loads the index from disk, replacing the one being served
+func (s *SearchServer) Load() error {
+	index, err := s.searchService.LoadIndex(s.indexPath)
+	if err != nil {
+		return fmt.Errorf("failed to load index: %w", err)
+	}
+
+	s.mu.Lock()
+	// Searches still running keep the previous index until they finish, so it is left to
+	// the garbage collector instead of being closed here
+	s.index = index
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// Root returns the directory being served
+func (s *SearchServer) Root() string {
+	return s.root
+}
+
+// Close releases the index being served
+func (s *SearchServer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.index == nil {
+		return nil
+	}
+	err := s.index.Close()
+	s.index = nil
+	return err
+}
+
+// AllowHost accepts requests naming host in their Host header. Only loopback hosts are
+// accepted otherwise, so that other sites cannot reach the server by DNS rebinding.
+func (s *SearchServer) AllowHost(host string) {
+	s.hosts[strings.ToLower(strings.Trim(host, "[]"))] = true
+}
+
+// RequireToken makes every request present the token as "Authorization: Bearer <token>"
+func (s *SearchServer) RequireToken(token string) {
+	s.token = token
+}
+
+// ServeHTTP checks the Host header, token and content type of a request and routes it
+// to its endpoint. POST requests must send JSON, which browsers cannot do across sites
+// without the server's consent.
+func (s *SearchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	if !s.hosts[strings.ToLower(strings.Trim(host, "[]"))] {
+		s.writeError(w, http.StatusForbidden, fmt.Errorf("host %s is not allowed", r.Host))
+		return
+	}
+
+	if s.token != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			s.writeError(w, http.StatusUnauthorized, errors.New("missing or invalid token"))
+			return
+		}
+	}
+
+	if r.Method == http.MethodPost {
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+			s.writeError(w, http.StatusUnsupportedMediaType, errors.New("POST requests must have Content-Type application/json"))
+			return
+		}
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// SearchRequest is the body of POST /v1/search. GET requests pass the same fields as query
+// parameters.
+type SearchRequest struct {
+	Query         string  `json:"query"`
+	SearchType    string  `json:"search_type,omitempty"`
+	MaxResults    int     `json:"max_results,omitempty"`
+	Offset        int     `json:"offset,omitempty"`
+	Cursor        string  `json:"cursor,omitempty"`
+	Threshold     float64 `json:"threshold,omitempty"`
+	FilePattern   string  `json:"file_pattern,omitempty"`
+	Dir           string  `json:"dir,omitempty"`
+	CaseSensitive bool    `json:"case_sensitive,omitempty"`
+	SmartCase     bool    `json:"smart_case,omitempty"`
+	WholeWord     bool    `json:"whole_word,omitempty"`
+	NoSynonyms    bool    `json:"no_synonyms,omitempty"`
+	Multiline     bool    `json:"multiline,omitempty"`
+	MaxEdits      *int    `json:"max_edits,omitempty"`
+	ContextBefore int     `json:"context_before,omitempty"`
+	ContextAfter  int     `json:"context_after,omitempty"`
+}
+
+// SearchResponse is the body of a successful search
+type SearchResponse struct {
+	Query         string                 `json:"query"`
+	TotalResults  int                    `json:"totalResults"`
+	Displayed     int                    `json:"displayed"`
+	ExecutionTime string                 `json:"executionTime"`
+	HasMore       bool                   `json:"has_more"`
+	Offset        int                    `json:"offset"`
+	NextCursor    string                 `json:"next_cursor,omitempty"`
+	Results       []*models.SearchResult `json:"results"`
+}
+
+// ServerStatus is the body of GET /v1/status
+type ServerStatus struct {
+	RepositoryPath string          `json:"repository_path"`
+	IndexPath      string          `json:"index_path"`
+	FileCount      int             `json:"file_count"`
+	ChunkCount     int             `json:"chunk_count"`
+	FileTypes      map[string]int  `json:"file_types"`
+	LastModified   time.Time       `json:"last_modified"`
+	LoadedAt       time.Time       `json:"loaded_at"`
+	Reindexing     bool            `json:"reindexing"`
+	LastReindex    *IndexingResult `json:"last_reindex,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	Stale          *bool           `json:"stale,omitempty"`
+}
+
+// serverError is the body of every failed request
+type serverError struct {
+	Error  string `json:"error"`
+	Column int    `json:"column,omitempty"`
+}
+
+// handleHealth reports that the server is up and whether an index is loaded
+func (s *SearchServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if !s.allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":       "ok",
+		"index_loaded": s.currentIndex() != nil,
+		"uptime":       time.Since(s.started).Round(time.Second).String(),
+	})
+}
+
+// handleOpenAPI serves the OpenAPI description of the endpoints
+func (s *SearchServer) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if !s.allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(SearchServerOpenAPI))
+}
+
+// handleSearch runs a search against the loaded index
+func (s *SearchServer) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if !s.allowMethods(w, r, http.MethodGet, http.MethodPost) {
+		return
+	}
+
+	var request SearchRequest
+	if r.Method == http.MethodPost {
+		decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&request); err != nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+	} else {
+		var err error
+		if request, err = searchRequestFromParams(r.URL.Query()); err != nil {
+			s.writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	response, err := s.Search(r.Context(), request)
+	if err != nil {
+		var parseErr *models.QueryParseError
+		var pathErr *serverPathError
+		switch {
+		case errors.As(err, &parseErr):
+			s.writeJSON(w, http.StatusBadRequest, serverError{Error: parseErr.Message, Column: parseErr.Column})
+		case errors.As(err, &pathErr):
+			s.writeError(w, http.StatusForbidden, err)
+		case errors.Is(err, errNoIndex):
+			s.writeError(w, http.StatusServiceUnavailable, err)
+		case errors.Is(err, context.DeadlineExceeded):
+			s.writeError(w, http.StatusGatewayTimeout, err)
+		case strings.Contains(err.Error(), "invalid search query"):
+			s.writeError(w, http.StatusBadRequest, err)
+		default:
+			s.writeError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// Search runs a search request against the loaded index. Results outside the served
+// directory are dropped before the ranking is paged.
+func (s *SearchServer) Search(ctx context.Context, request SearchRequest) (*SearchResponse, error) {
+	query, err := s.newQuery(request)
+	if err != nil {
+		return nil, err
+	}
+
+	index := s.currentIndex()
+	if index == nil {
+		return nil, errNoIndex
+	}
+
+	results, err := s.searchService.searchIndexContext(ctx, query, index, s.inRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := results.Results
+	if visible == nil {
+		visible = []*models.SearchResult{}
+	}
+	return &SearchResponse{
+		Query:         query.OriginalText(),
+		TotalResults:  results.TotalResults,
+		Displayed:     len(visible),
+		ExecutionTime: results.ExecutionTime.String(),
+		HasMore:       results.HasMore,
+		Offset:        results.Offset,
+		NextCursor:    results.NextCursor,
+		Results:       visible,
+	}, nil
+}
+
+// inRoot reports whether a result lies inside the served directory
+func (s *SearchServer) inRoot(result *models.SearchResult) bool {
+	if err := s.fileUtils.ValidatePathSecurity(result.FilePath, []string{s.root}); err != nil {
+		s.logger.Warn("Dropping result outside the served directory: %s", result.FilePath)
+		return false
+	}
+	return true
+}
+
+// handleStatus reports what is indexed and the state of reindexing
+func (s *SearchServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if !s.allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	status, err := s.Status(r.URL.Query().Get("check") == "true")
+	if err != nil {
+		if errors.Is(err, errNoIndex) {
+			s.writeError(w, http.StatusServiceUnavailable, err)
+		} else {
+			s.writeError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	s.writeJSON(w, http.StatusOK, status)
+}
+
+// Status describes the loaded index and the state of reindexing. With check it also
+// compares the index with the files on disk, which reads every indexed file.
+func (s *SearchServer) Status(check bool) (*ServerStatus, error) {
+	index := s.currentIndex()
+	if index == nil {
+		return nil, errNoIndex
+	}
+
+	stats := index.GetStats()
+	s.mu.RLock()
+	status := &ServerStatus{
+		RepositoryPath: stats.RepositoryPath,
+		IndexPath:      s.indexPath,
+		FileCount:      stats.TotalFiles,
+		ChunkCount:     stats.TotalChunks,
+		FileTypes:      stats.FileTypes,
+		LastModified:   stats.LastModified,
+		LoadedAt:       s.loadedAt,
+		Reindexing:     s.reindexing,
+		LastReindex:    s.lastResult,
+		LastError:      s.lastError,
+	}
+	s.mu.RUnlock()
+
+	if check {
+		stale, err := index.ShouldReindex()
+		if err != nil {
+			return nil, err
+		}
+		status.Stale = &stale
+	}
+	return status, nil
+}
+
+// handleReindex starts an incremental reindex in the background and loads the new index
+// when it completes. With ?wait=true it responds after the reindex instead.
+func (s *SearchServer) handleReindex(w http.ResponseWriter, r *http.Request) {
+	if !s.allowMethods(w, r, http.MethodPost) {
+		return
+	}
+
+	if err := s.beginReindex(); err != nil {
+		s.writeError(w, http.StatusConflict, err)
+		return
+	}
+
+	if r.URL.Query().Get("wait") != "true" {
+		go s.runReindex(nil)
+		s.writeJSON(w, http.StatusAccepted, map[string]string{"status": "started"})
+		return
+	}
+
+	result, err := s.runReindex(nil)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// Reindex incrementally reindexes the directory, or only the given files, and loads the
+// new index, unless a reindex is already running
+func (s *SearchServer) Reindex(files []string) (*IndexingResult, error) {
+	if err := s.beginReindex(); err != nil {
+		return nil, err
+	}
+	return s.runReindex(files)
+}
+
+// beginReindex marks a reindex as running, failing if one already is
+func (s *SearchServer) beginReindex() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.reindexing {
+		return errReindexRunning
+	}
+	s.reindexing = true
+	return nil
+}
+
+// runReindex reindexes the directory or files, reloads the index and records the outcome
+func (s *SearchServer) runReindex(files []string) (*IndexingResult, error) {
+	result, err := s.reindex(files)
+	if err == nil {
+		err = s.Load()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reindexing = false
+	if err != nil {
+		s.logger.Error("Reindex failed: %v", err)
+		s.lastError = err.Error()
+		return nil, err
+	}
+	s.lastResult, s.lastError = result, ""
+	return result, nil
+}
+
+// currentIndex returns the index being served
+func (s *SearchServer) currentIndex() *models.CodeIndex {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.index
+}
+
+// serverPathError reports a requested directory outside the served one
+type serverPathError struct {
+	err error
+}
+
+func (e *serverPathError) Error() string {
+	return e.err.Error()
+}
+
+// newQuery converts a request to a parsed search query
+func (s *SearchServer) newQuery(request SearchRequest) (*models.SearchQuery, error) {
+	query := models.NewSearchQuery(request.Query)
+	if request.SearchType != "" {
+		query.SearchType = models.SearchType(strings.ToLower(request.SearchType))
+	}
+	if request.MaxResults != 0 {
+		query.MaxResults = request.MaxResults
+	}
+	if request.Threshold != 0 {
+		query.Threshold = request.Threshold
+	}
+	if request.MaxEdits != nil {
+		query.MaxEdits = *request.MaxEdits
+	}
+	query.FileFilter = request.FilePattern
+	query.CaseSensitive = request.CaseSensitive
+	query.SmartCase = request.SmartCase
+	query.WholeWord = request.WholeWord
+	query.NoSynonyms = request.NoSynonyms
+	query.Multiline = request.Multiline
+	query.ContextBefore = request.ContextBefore
+	query.ContextAfter = request.ContextAfter
+
+	if err := query.ParseStructuredQuery(); err != nil {
+		return nil, err
+	}
+
+	// Restrict the search to a directory inside the served one
+	if request.Dir != "" {
+		dir := request.Dir
+		if !filepath.IsAbs(dir) {
+			// Joined without cleaning, so that ".." is still seen and rejected
+			dir = s.root + string(filepath.Separator) + dir
+		}
+		if err := s.fileUtils.ValidatePathSecurity(dir, []string{s.root}); err != nil {
+			return nil, &serverPathError{err}
+		}
+		query.PathFilters = append(query.PathFilters, filepath.ToSlash(filepath.Clean(dir))+"/")
+	}
+
+	// A cursor is tied to the parsed query, so it is decoded after parsing
+	query.Offset = request.Offset
+	if request.Cursor != "" {
+		offset, err := models.DecodeCursor(query, request.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %w", err)
+		}
+		query.Offset = offset
+	}
+
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid search query: %w", err)
+	}
+	return query, nil
+}
+
+// searchRequestFromParams reads a search request from URL query parameters
+func searchRequestFromParams(params url.Values) (SearchRequest, error) {
+	request := SearchRequest{
+		Query:       params.Get("q"),
+		SearchType:  params.Get("type"),
+		Cursor:      params.Get("cursor"),
+		FilePattern: params.Get("file_pattern"),
+		Dir:         params.Get("dir"),
+	}
+	if request.Query == "" {
+		request.Query = params.Get("query")
+	}
+
+	ints := map[string]*int{
+		"max_results":    &request.MaxResults,
+		"offset":         &request.Offset,
+		"context_before": &request.ContextBefore,
+		"context_after":  &request.ContextAfter,
+	}
+	for name, target := range ints {
+		if value := params.Get(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return request, fmt.Errorf("invalid %s: %s", name, value)
+			}
+			*target = parsed
+		}
+	}
+	if value := params.Get("max_edits"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return request, fmt.Errorf("invalid max_edits: %s", value)
+		}
+		request.MaxEdits = &parsed
+	}
+	if value := params.Get("threshold"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return request, fmt.Errorf("invalid threshold: %s", value)
+		}
+		request.Threshold = parsed
+	}
+
+	bools := map[string]*bool{
+		"case_sensitive": &request.CaseSensitive,
+		"smart_case":     &request.SmartCase,
+		"whole_word":     &request.WholeWord,
+		"no_synonyms":    &request.NoSynonyms,
+		"multiline":      &request.Multiline,
+	}
+	for name, target := range bools {
+		if value := params.Get(name); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return request, fmt.Errorf("invalid %s: %s", name, value)
+			}
+			*target = parsed
+		}
+	}
+
+	return request, nil
+}
+
+// allowMethods rejects requests with other methods and reports whether to continue
+func (s *SearchServer) allowMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, method := range methods {
+		if r.Method == method {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	s.writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	return false
+}
+
+// writeJSON writes a JSON response
+func (s *SearchServer) writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		s.logger.Warn("Failed to write response: %v", err)
+	}
+}
+
+// writeError writes an error response
+func (s *SearchServer) writeError(w http.ResponseWriter, status int, err error) {
+	s.writeJSON(w, status, serverError{Error: err.Error()})
+}
@@ -0,0 +1,205 @@
+package services
+
+// SearchServerOpenAPI is the OpenAPI 3.0 description of the SearchServer endpoints,
+// served at /openapi.json
+const SearchServerOpenAPI = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "code-search server",
+    "version": "1.0.0",
+    "description": "Searches one indexed directory. The index stays loaded in memory between requests. Requests must name a loopback host or the listening host, POST requests must send application/json, and a server started with --token requires it as a bearer token."
+  },
+  "security": [{}, {"bearerToken": []}],
+  "paths": {
+    "/health": {
+      "get": {
+        "summary": "Report that the server is up",
+        "responses": {
+          "200": {
+            "description": "The server is up",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Health"}}}
+          }
+        }
+      }
+    },
+    "/v1/search": {
+      "get": {
+        "summary": "Search with query parameters",
+        "parameters": [
+          {"name": "q", "in": "query", "required": true, "schema": {"type": "string"}, "description": "Query, with the same syntax as the search command"},
+          {"name": "type", "in": "query", "schema": {"$ref": "#/components/schemas/SearchType"}},
+          {"name": "max_results", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 1000}},
+          {"name": "offset", "in": "query", "schema": {"type": "integer", "minimum": 0}},
+          {"name": "cursor", "in": "query", "schema": {"type": "string"}},
+          {"name": "threshold", "in": "query", "schema": {"type": "number", "minimum": 0, "maximum": 1}},
+          {"name": "file_pattern", "in": "query", "schema": {"type": "string"}},
+          {"name": "dir", "in": "query", "schema": {"type": "string"}, "description": "Directory inside the served one to search"},
+          {"name": "case_sensitive", "in": "query", "schema": {"type": "boolean"}},
+          {"name": "smart_case", "in": "query", "schema": {"type": "boolean"}},
+          {"name": "whole_word", "in": "query", "schema": {"type": "boolean"}},
+          {"name": "no_synonyms", "in": "query", "schema": {"type": "boolean"}},
+          {"name": "multiline", "in": "query", "schema": {"type": "boolean"}},
+          {"name": "max_edits", "in": "query", "schema": {"type": "integer", "minimum": 0, "maximum": 5}},
+          {"name": "context_before", "in": "query", "schema": {"type": "integer", "minimum": 0}},
+          {"name": "context_after", "in": "query", "schema": {"type": "integer", "minimum": 0}}
+        ],
+        "responses": {
+          "200": {"$ref": "#/components/responses/SearchResults"},
+          "400": {"$ref": "#/components/responses/Error"},
+          "403": {"$ref": "#/components/responses/Error"},
+          "503": {"$ref": "#/components/responses/Error"},
+          "504": {"$ref": "#/components/responses/Error"}
+        }
+      },
+      "post": {
+        "summary": "Search with a JSON body",
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/SearchRequest"}}}
+        },
+        "responses": {
+          "200": {"$ref": "#/components/responses/SearchResults"},
+          "400": {"$ref": "#/components/responses/Error"},
+          "403": {"$ref": "#/components/responses/Error"},
+          "503": {"$ref": "#/components/responses/Error"},
+          "504": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/v1/status": {
+      "get": {
+        "summary": "Describe the loaded index and the last reindex",
+        "parameters": [
+          {"name": "check", "in": "query", "schema": {"type": "boolean"}, "description": "Also compare the index with the files on disk"}
+        ],
+        "responses": {
+          "200": {
+            "description": "Index status",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Status"}}}
+          },
+          "503": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/v1/reindex": {
+      "post": {
+        "summary": "Incrementally reindex the directory and load the new index",
+        "parameters": [
+          {"name": "wait", "in": "query", "schema": {"type": "boolean"}, "description": "Respond when the reindex has finished"}
+        ],
+        "responses": {
+          "200": {
+            "description": "The reindex finished",
+            "content": {"application/json": {"schema": {"type": "object", "additionalProperties": true}}}
+          },
+          "202": {"description": "The reindex started"},
+          "409": {"$ref": "#/components/responses/Error"},
+          "500": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "SearchType": {
+        "type": "string",
+        "enum": ["hybrid", "text", "semantic", "regex", "exact", "fuzzy"],
+        "default": "hybrid"
+      },
+      "SearchRequest": {
+        "type": "object",
+        "required": ["query"],
+        "additionalProperties": false,
+        "properties": {
+          "query": {"type": "string"},
+          "search_type": {"$ref": "#/components/schemas/SearchType"},
+          "max_results": {"type": "integer", "minimum": 1, "maximum": 1000, "default": 10},
+          "offset": {"type": "integer", "minimum": 0},
+          "cursor": {"type": "string"},
+          "threshold": {"type": "number", "minimum": 0, "maximum": 1, "default": 0.7},
+          "file_pattern": {"type": "string"},
+          "dir": {"type": "string"},
+          "case_sensitive": {"type": "boolean"},
+          "smart_case": {"type": "boolean"},
+          "whole_word": {"type": "boolean"},
+          "no_synonyms": {"type": "boolean"},
+          "multiline": {"type": "boolean"},
+          "max_edits": {"type": "integer", "minimum": 0, "maximum": 5, "default": 2},
+          "context_before": {"type": "integer", "minimum": 0},
+          "context_after": {"type": "integer", "minimum": 0}
+        }
+      },
+      "SearchResults": {
+        "type": "object",
+        "properties": {
+          "query": {"type": "string"},
+          "totalResults": {"type": "integer"},
+          "displayed": {"type": "integer"},
+          "executionTime": {"type": "string"},
+          "has_more": {"type": "boolean"},
+          "offset": {"type": "integer"},
+          "next_cursor": {"type": "string"},
+          "results": {"type": "array", "items": {"$ref": "#/components/schemas/SearchResult"}}
+        }
+      },
+      "SearchResult": {
+        "type": "object",
+        "additionalProperties": true,
+        "properties": {
+          "file_path": {"type": "string"},
+          "start_line": {"type": "integer"},
+          "end_line": {"type": "integer"},
+          "content": {"type": "string"},
+          "relevance_score": {"type": "number"},
+          "match_type": {"type": "string"},
+          "rank": {"type": "integer"}
+        }
+      },
+      "Status": {
+        "type": "object",
+        "properties": {
+          "repository_path": {"type": "string"},
+          "index_path": {"type": "string"},
+          "file_count": {"type": "integer"},
+          "chunk_count": {"type": "integer"},
+          "file_types": {"type": "object", "additionalProperties": {"type": "integer"}},
+          "last_modified": {"type": "string", "format": "date-time"},
+          "loaded_at": {"type": "string", "format": "date-time"},
+          "reindexing": {"type": "boolean"},
+          "last_reindex": {"type": "object", "additionalProperties": true},
+          "last_error": {"type": "string"},
+          "stale": {"type": "boolean"}
+        }
+      },
+      "Health": {
+        "type": "object",
+        "properties": {
+          "status": {"type": "string"},
+          "index_loaded": {"type": "boolean"},
+          "uptime": {"type": "string"}
+        }
+      },
+      "Error": {
+        "type": "object",
+        "properties": {
+          "error": {"type": "string"},
+          "column": {"type": "integer", "description": "Column of a query syntax error"}
+        }
+      }
+    },
+    "responses": {
+      "SearchResults": {
+        "description": "A page of ranked results",
+        "content": {"application/json": {"schema": {"$ref": "#/components/schemas/SearchResults"}}}
+      },
+      "Error": {
+        "description": "The request failed",
+        "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}
+      }
+    },
+    "securitySchemes": {
+      "bearerToken": {"type": "http", "scheme": "bearer", "description": "The token given to serve --token"}
+    }
+  }
+}
+`
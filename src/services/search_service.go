@@ -1,9 +1,11 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
@@ -13,6 +15,12 @@ import (
 	"code-search/src/lib"
 )
 
+// symbolDefinitionBoost is added to the relevance of results on the definition line of a sym: filter match
+const symbolDefinitionBoost = 0.2
+
+// synonymScoreWeight scales the score contribution of terms matched only through a synonym
+const synonymScoreWeight = 0.8
+
 // SearchService handles search operations on indexed codebases
 type SearchService struct {
 	codeParser    CodeParser
@@ -30,6 +38,8 @@ type SearchOptions struct {
 	EnableFuzzy       bool          `json:"enable_fuzzy"`
 	EnableContext     bool          `json:"enable_context"`
 	ContextLines      int           `json:"context_lines"`
+	SemanticWeight    float64       `json:"semantic_weight"`
+	TextWeight        float64       `json:"text_weight"`
 	CacheResults      bool          `json:"cache_results"`
 	CacheSize         int           `json:"cache_size"`
 	CacheTTL          time.Duration `json:"cache_ttl"`
@@ -44,6 +54,8 @@ func DefaultSearchOptions() SearchOptions {
 		EnableFuzzy:       true,
 		EnableContext:     false,
 		ContextLines:      3,
+		SemanticWeight:    0.6,
+		TextWeight:        0.4,
 		CacheResults:      true,
 		CacheSize:         100,
 		CacheTTL:          10 * time.Minute,
@@ -88,7 +100,9 @@ func NewSearchService(
 	}
 }
 
-// Search performs a search operation on the indexed codebase
+// Search performs a search operation on the indexed codebase. It returns the page of
+// ranked results selected by the query's offset and maximum results. The full ranking is
+// cached, so later pages of the same query are served without searching again.
 func (ss *SearchService) Search(
 	query *models.SearchQuery,
 	indexPath string,
@@ -109,27 +123,201 @@ func (ss *SearchService) Search(
 	}
 
 	// Check cache first if enabled
+	var index *models.CodeIndex
+	var ranked *models.SearchResults
+	cacheScope := indexCacheScope(indexPath)
 	if ss.searchOptions.CacheResults && ss.queryCache != nil {
-		if cachedResults, found := ss.queryCache.Get(query); found {
+		if cachedResults, found := ss.queryCache.Get(cacheScope, query); found {
 			ss.logger.Debug("Cache hit for query: %s", query.GetSummary())
-			cachedResults.SetExecutionTime(time.Since(start))
-			return cachedResults, nil
+			ranked = cachedResults
+		} else {
+			ss.logger.Debug("Cache miss for query: %s", query.GetSummary())
 		}
-		ss.logger.Debug("Cache miss for query: %s", query.GetSummary())
 	}
 
-	ss.logger.Info("Performing search: %s", query.GetSummary())
+	if ranked == nil {
+		ss.logger.Info("Performing search: %s", query.GetSummary())
 
-	// Load index
-	index, err := ss.loadIndex(indexPath)
+		// Load index
+		var err error
+		index, err = ss.loadIndex(indexPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load index: %w", err)
+		}
+		defer index.Close()
+
+		ranked, err = ss.rankResults(query, index)
+		if err != nil {
+			return nil, err
+		}
+
+		// Cache the full ranking if enabled
+		if ss.searchOptions.CacheResults && ss.queryCache != nil {
+			ss.queryCache.Put(cacheScope, query, ranked)
+		}
+	}
+
+	ranked, err := sortRanking(ranked, query.SortOrder)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load index: %w", err)
+		return nil, err
+	}
+
+	// Select the requested page
+	results := ranked.Page(query.Offset, query.MaxResults)
+	results.Query = query
+
+	// Attach surrounding lines to the results that are returned
+	if before, after := query.ContextRange(ss.searchOptions.ContextLines); before > 0 || after > 0 {
+		if index == nil {
+			var err error
+			index, err = ss.loadIndex(indexPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load index: %w", err)
+			}
+			defer index.Close()
+		}
+		ss.attachContext(query, index, results.Results)
+	}
+
+	// Set execution time
+	results.SetExecutionTime(time.Since(start))
+
+	ss.logger.Info("Search completed: %d results found in %v", results.TotalResults, results.ExecutionTime)
+
+	return results, nil
+}
+
+// sortRanking returns a ranking in the given sort order, leaving the ranking itself, which
+// may be cached, in relevance order. Results keep their relevance ranks.
+func sortRanking(ranked *models.SearchResults, order string) (*models.SearchResults, error) {
+	if order == "" || order == "score" {
+		return ranked, nil
+	}
+	sorted := *ranked
+	sorted.Results = append([]*models.SearchResult(nil), ranked.Results...)
+	if err := sorted.SortBy(order); err != nil {
+		return nil, err
+	}
+	return &sorted, nil
+}
+
+// indexCacheScope identifies an index file and its version, so cached rankings are
+// neither shared between indexes nor served after the index is rebuilt
+func indexCacheScope(indexPath string) string {
+	if absPath, err := filepath.Abs(indexPath); err == nil {
+		indexPath = absPath
+	}
+	info, err := os.Stat(indexPath)
+	if err != nil {
+		return indexPath
+	}
+	return fmt.Sprintf("%s:%d:%d", indexPath, info.Size(), info.ModTime().UnixNano())
+}
+
+// SearchIndex searches an index that is already loaded, for callers that run many queries
+// against one index. Rankings are not cached, since the in-memory index may be newer or
+// older than the one on disk.
+func (ss *SearchService) SearchIndex(
+	query *models.SearchQuery,
+	index *models.CodeIndex,
+) (*models.SearchResults, error) {
+	return ss.searchIndex(query, index, nil)
+}
+
+// searchIndex searches a loaded index. Results that keep rejects are dropped from the
+// ranking before it is paged, so totals and ranks only count the results kept.
+func (ss *SearchService) searchIndex(
+	query *models.SearchQuery,
+	index *models.CodeIndex,
+	keep func(*models.SearchResult) bool,
+) (*models.SearchResults, error) {
+	start := time.Now()
+
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid search query: %w", err)
+	}
+	if query.MaxResults == 0 {
+		query.MaxResults = ss.searchOptions.DefaultMaxResults
+	}
+	if query.Threshold == 0 {
+		query.Threshold = ss.searchOptions.DefaultThreshold
+	}
+
+	ranked, err := ss.rankResults(query, index)
+	if err != nil {
+		return nil, err
+	}
+	if keep != nil {
+		ranked.Results = ranked.FilterResults(keep)
+		ranked.TotalResults = len(ranked.Results)
+		for i, result := range ranked.Results {
+			result.Rank = i + 1
+		}
+	}
+	if ranked, err = sortRanking(ranked, query.SortOrder); err != nil {
+		return nil, err
 	}
-	defer index.Close()
 
+	results := ranked.Page(query.Offset, query.MaxResults)
+	results.Query = query
+	ss.attachContext(query, index, results.Results)
+	results.SetExecutionTime(time.Since(start))
+
+	return results, nil
+}
+
+// SearchIndexContext runs SearchIndex until the context is done or SearchOptions.Timeout
+// has passed. The search types do not check for cancellation, so a search that runs out
+// of time finishes in the background and its results are dropped.
+func (ss *SearchService) SearchIndexContext(
+	ctx context.Context,
+	query *models.SearchQuery,
+	index *models.CodeIndex,
+) (*models.SearchResults, error) {
+	return ss.searchIndexContext(ctx, query, index, nil)
+}
+
+// searchIndexContext runs searchIndex until the context is done or the timeout has passed
+func (ss *SearchService) searchIndexContext(
+	ctx context.Context,
+	query *models.SearchQuery,
+	index *models.CodeIndex,
+	keep func(*models.SearchResult) bool,
+) (*models.SearchResults, error) {
+	if ss.searchOptions.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ss.searchOptions.Timeout)
+		defer cancel()
+	}
+
+	type outcome struct {
+		results *models.SearchResults
+		err     error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		results, err := ss.searchIndex(query, index, keep)
+		done <- outcome{results, err}
+	}()
+
+	select {
+	case result := <-done:
+		return result.results, result.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("search stopped: %w", ctx.Err())
+	}
+}
+
+// rankResults finds every result of a query, or the candidate window for semantic
+// searches, and ranks them by relevance
+func (ss *SearchService) rankResults(
+	query *models.SearchQuery,
+	index *models.CodeIndex,
+) (*models.SearchResults, error) {
 	// Create search results container
 	results := models.NewSearchResults(query)
 	results.SetSearchedFiles(len(index.GetAllFiles()))
+	results.AddMetadata(models.MetadataRepositoryPath, index.RepositoryPath)
 
 	// Perform search based on query type
 	searchResults, err := ss.performSearch(query, index)
@@ -137,6 +325,12 @@ func (ss *SearchService) Search(
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
 
+	// Apply structured query filters that individual search types do not enforce
+	searchResults, err = ss.applyStructuredFilters(query, index, searchResults)
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+
 	// Add results to container
 	for _, result := range searchResults {
 		if err := results.AddResult(result); err != nil {
@@ -147,20 +341,75 @@ func (ss *SearchService) Search(
 	// Sort results by relevance
 	results.SortResults()
 
-	// Apply limits
-	results.LimitResults(query.MaxResults)
+	return results, nil
+}
 
-	// Set execution time
-	results.SetExecutionTime(time.Since(start))
+// Stream searches the index file by file and passes each result to emit as soon as its
+// file has been searched. Results are not limited, and are ordered by path and line
+// instead of by relevance. Only lexical searches stream, since semantic ranking needs
+// every candidate at once. The returned summary holds the totals but no results.
+func (ss *SearchService) Stream(
+	query *models.SearchQuery,
+	indexPath string,
+	emit func(*models.SearchResult) error,
+) (*models.SearchResults, error) {
+	start := time.Now()
 
-	// Cache the results if enabled
-	if ss.searchOptions.CacheResults && ss.queryCache != nil {
-		ss.queryCache.Put(query, results)
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid search query: %w", err)
+	}
+	if !query.IsLexical() {
+		return nil, fmt.Errorf("streaming requires a lexical search, got %s", query.SearchType)
 	}
 
-	ss.logger.Info("Search completed: %d results found in %v", results.TotalResults, results.ExecutionTime)
+	index, err := ss.loadIndex(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load index: %w", err)
+	}
+	defer index.Close()
 
-	return results, nil
+	files := index.GetAllFiles()
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].FilePath < files[j].FilePath
+	})
+
+	summary := models.NewSearchResults(query)
+	summary.SetSearchedFiles(len(files))
+	summary.AddMetadata(models.MetadataRepositoryPath, index.RepositoryPath)
+
+	for _, entry := range files {
+		if !query.ShouldIncludeFile(entry.FilePath, entry.Language) {
+			continue
+		}
+
+		fileResults, err := ss.performSearch(query, index.FileIndex(entry))
+		if err != nil {
+			return summary, fmt.Errorf("search failed: %w", err)
+		}
+		fileResults, err = ss.applyStructuredFilters(query, index, fileResults)
+		if err != nil {
+			return summary, fmt.Errorf("search failed: %w", err)
+		}
+
+		sort.SliceStable(fileResults, func(i, j int) bool {
+			if fileResults[i].StartLine != fileResults[j].StartLine {
+				return fileResults[i].StartLine < fileResults[j].StartLine
+			}
+			return fileResults[i].StartColumn < fileResults[j].StartColumn
+		})
+		ss.attachContext(query, index, fileResults)
+
+		for _, result := range fileResults {
+			summary.TotalResults++
+			result.Rank = summary.TotalResults
+			if err := emit(result); err != nil {
+				return summary, err
+			}
+		}
+	}
+
+	summary.SetExecutionTime(time.Since(start))
+	return summary, nil
 }
 
 // performSearch executes the actual search based on query type
@@ -198,7 +447,7 @@ func (ss *SearchService) performSemanticSearch(
 	}
 
 	// Perform vector search
-	vectorResults, err := index.Search(queryEmbedding, query.MaxResults*2) // Get more results for filtering
+	vectorResults, err := index.Search(queryEmbedding, query.CandidateWindow()*2) // Get more results for filtering
 	if err != nil {
 		return nil, fmt.Errorf("vector search failed: %w", err)
 	}
@@ -249,14 +498,6 @@ func (ss *SearchService) performSemanticSearch(
 
 		result.Language = language
 
-		// Add context if requested
-		if query.IncludeContext {
-			context, err := result.CalculateContext(ss.searchOptions.ContextLines)
-			if err == nil {
-				result.SetContext(context)
-			}
-		}
-
 		results = append(results, result)
 	}
 
@@ -269,7 +510,20 @@ func (ss *SearchService) performTextSearch(
 	index *models.CodeIndex,
 ) ([]*models.SearchResult, error) {
 	var results []*models.SearchResult
-	searchTerms := strings.Fields(strings.ToLower(query.GetProcessedQuery()))
+	synonyms := ss.loadSynonyms(query, index)
+	terms, err := ss.compileTerms(query, synonyms)
+	if err != nil {
+		return nil, err
+	}
+
+	// Structured queries are evaluated as a boolean expression per line
+	var expressionTerms map[string]*lexicalTerm
+	if query.Expression != nil {
+		expressionTerms, err = ss.compileExpressionTerms(query, synonyms)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// Get all file entries
 	fileEntries := index.GetAllFiles()
@@ -290,14 +544,31 @@ func (ss *SearchService) performTextSearch(
 		// Search for terms in content
 		lines := strings.Split(content, "\n")
 		for i, line := range lines {
-			lineLower := strings.ToLower(line)
-
-			// Check if all search terms are present in this line
+			// Check if all search terms, or synonyms of them, are present in this line
 			allTermsFound := true
-			for _, term := range searchTerms {
-				if !strings.Contains(lineLower, term) {
-					allTermsFound = false
-					break
+			viaSynonym := false
+			if query.Expression != nil {
+				allTermsFound = query.Expression.Matches(func(value string) bool {
+					term := expressionTerms[value]
+					if term.matches(line) {
+						return true
+					}
+					if term.matchesSynonym(line) {
+						viaSynonym = true
+						return true
+					}
+					return false
+				})
+			} else {
+				for _, term := range terms {
+					if term.matches(line) {
+						continue
+					}
+					if !term.matchesSynonym(line) {
+						allTermsFound = false
+						break
+					}
+					viaSynonym = true
 				}
 			}
 
@@ -306,18 +577,25 @@ func (ss *SearchService) performTextSearch(
 				result := models.NewSearchResult(
 					fileEntry.FilePath,
 					i+1, i+1, // Line numbers are 1-based
-					strings.TrimSpace(line),
+					line,
 				)
 
 				result.Language = fileEntry.Language
 				result.MatchType = models.MatchTypeExact
-				result.RelevanceScore = ss.calculateTextRelevanceScore(line, searchTerms)
-
-				// Add context if requested
-				if query.IncludeContext {
-					context := ss.getLineContext(lines, i, ss.searchOptions.ContextLines)
-					result.SetContext(context)
+				if viaSynonym {
+					result.MatchType = models.MatchTypeSynonym
+				}
+				result.RelevanceScore = ss.calculateTextRelevanceScore(line, terms)
+				var ranges [][]int
+				for _, term := range terms {
+					termRanges := term.find(line)
+					if len(termRanges) == 0 {
+						termRanges = term.findSynonyms(line)
+					}
+					ranges = append(ranges, termRanges...)
 				}
+				// Terms may match within each other, as "parse" does in "parseconfig"
+				addRangeSpans(result, line, i+1, mergeRanges(ranges))
 
 				results = append(results, result)
 			}
@@ -327,6 +605,364 @@ func (ss *SearchService) performTextSearch(
 	return results, nil
 }
 
+// lexicalTerm matches a query term in a line of text. The term is matched literally
+// first; a compound identifier such as parse_config or parseConfig also matches when its
+// sub-words appear consecutively within one identifier of the line, so it finds
+// parseConfig, parse_config and ParseConfigFile alike.
+type lexicalTerm struct {
+	pattern       *regexp.Regexp
+	parts         []string
+	caseSensitive bool
+	wholeWord     bool
+	exact         bool           // Only whole identifiers or sub-words match, ignoring case
+	synonyms      []*lexicalTerm // Expansions tried when the term itself does not match
+}
+
+// newLexicalTerm compiles a query term honouring the case and whole-word settings
+func newLexicalTerm(query *models.SearchQuery, term string) (*lexicalTerm, error) {
+	pattern, err := query.LiteralPattern(term)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search term %q: %w", term, err)
+	}
+
+	return &lexicalTerm{
+		pattern:       pattern,
+		parts:         models.SplitIdentifier(term),
+		caseSensitive: query.IsCaseSensitive(),
+		wholeWord:     query.WholeWord,
+	}, nil
+}
+
+// newSynonymTerm creates a term for a synonym. Synonyms are often short abbreviations,
+// so they only match whole identifiers or sub-words: "err" finds ErrClosed but not stderr.
+func newSynonymTerm(synonym string) *lexicalTerm {
+	return &lexicalTerm{
+		parts: models.SplitIdentifier(synonym),
+		exact: true,
+	}
+}
+
+// matches reports whether the term occurs in line
+func (lt *lexicalTerm) matches(line string) bool {
+	if !lt.exact && lt.pattern.MatchString(line) {
+		return true
+	}
+	return len(lt.identifierMatches(line)) > 0
+}
+
+// find returns the byte ranges of the term in line, literal matches taking precedence
+func (lt *lexicalTerm) find(line string) [][]int {
+	if !lt.exact {
+		if ranges := lt.pattern.FindAllStringIndex(line, -1); len(ranges) > 0 {
+			return ranges
+		}
+	}
+	return lt.identifierMatches(line)
+}
+
+// matchesSynonym reports whether any synonym of the term occurs in line
+func (lt *lexicalTerm) matchesSynonym(line string) bool {
+	for _, synonym := range lt.synonyms {
+		if synonym.matches(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// findSynonyms returns the byte ranges of all synonyms of the term in line
+func (lt *lexicalTerm) findSynonyms(line string) [][]int {
+	var ranges [][]int
+	for _, synonym := range lt.synonyms {
+		ranges = append(ranges, synonym.find(line)...)
+	}
+	return ranges
+}
+
+// identifierMatches finds runs of identifier sub-words matching the term's sub-words.
+// The last sub-word may be a prefix unless whole words are required.
+func (lt *lexicalTerm) identifierMatches(line string) [][]int {
+	if len(lt.parts) == 0 || (len(lt.parts) < 2 && !lt.exact) {
+		return nil
+	}
+
+	var ranges [][]int
+	tokens := models.TokenizeCode(line)
+	for i := 0; i < len(tokens); i++ {
+		// Collect the sub-words that follow each compound identifier
+		j := i + 1
+		for j < len(tokens) && tokens[j].Part {
+			j++
+		}
+		wordParts := tokens[i+1 : j]
+		if len(wordParts) == 0 {
+			wordParts = tokens[i : i+1]
+		}
+		i = j - 1
+
+		for start := 0; start+len(lt.parts) <= len(wordParts); start++ {
+			if lt.matchesParts(wordParts[start : start+len(lt.parts)]) {
+				ranges = append(ranges, []int{wordParts[start].Start, wordParts[start+len(lt.parts)-1].End})
+				break
+			}
+		}
+	}
+
+	return ranges
+}
+
+// matchesParts compares the term's sub-words with consecutive sub-words of an identifier
+func (lt *lexicalTerm) matchesParts(wordParts []models.CodeToken) bool {
+	for k, part := range lt.parts {
+		text := wordParts[k].Text
+		if !lt.wholeWord && !lt.exact && k == len(lt.parts)-1 && len(text) > len(part) {
+			text = text[:len(part)]
+		}
+		caseSensitive := lt.caseSensitive && !lt.exact
+		if (caseSensitive && text != part) || (!caseSensitive && !strings.EqualFold(text, part)) {
+			return false
+		}
+	}
+	return true
+}
+
+// compileTerms compiles each text term of the query, or each positive term and phrase
+// of a structured query, with its synonyms
+func (ss *SearchService) compileTerms(query *models.SearchQuery, synonyms *lib.SynonymDictionary) ([]*lexicalTerm, error) {
+	values := query.TextTerms()
+	if query.Expression != nil {
+		values = query.Expression.PositiveValues()
+	}
+
+	var terms []*lexicalTerm
+	for _, value := range values {
+		term, err := newLexicalTerm(query, value)
+		if err != nil {
+			return nil, err
+		}
+		term.synonyms = expandSynonyms(value, synonyms)
+		terms = append(terms, term)
+	}
+
+	return terms, nil
+}
+
+// compileExpressionTerms compiles every term and phrase of a structured query. Only
+// positive terms are expanded, so a negated term excludes exactly what it says.
+func (ss *SearchService) compileExpressionTerms(query *models.SearchQuery, synonyms *lib.SynonymDictionary) (map[string]*lexicalTerm, error) {
+	terms := make(map[string]*lexicalTerm)
+	negative := make(map[string]bool)
+	for _, value := range query.Expression.NegativeValues() {
+		negative[value] = true
+	}
+
+	values := append(query.Expression.PositiveValues(), query.Expression.NegativeValues()...)
+	for _, value := range values {
+		if _, exists := terms[value]; exists {
+			continue
+		}
+		term, err := newLexicalTerm(query, value)
+		if err != nil {
+			return nil, err
+		}
+		if !negative[value] {
+			term.synonyms = expandSynonyms(value, synonyms)
+		}
+		terms[value] = term
+	}
+
+	return terms, nil
+}
+
+// loadSynonyms returns the synonym dictionary of the indexed repository, or nil when the
+// query disables synonym expansion
+func (ss *SearchService) loadSynonyms(query *models.SearchQuery, index *models.CodeIndex) *lib.SynonymDictionary {
+	if query.NoSynonyms {
+		return nil
+	}
+
+	synonyms, err := lib.LoadSynonymDictionary(index.RepositoryPath)
+	if err != nil {
+		ss.logger.Warn("Using built-in synonyms only: %v", err)
+	}
+	return synonyms
+}
+
+// expandSynonyms returns terms for the synonyms of a query term. A compound term is also
+// expanded one sub-word at a time, so "parse_cfg" looks for "parse_config" as well.
+func expandSynonyms(value string, synonyms *lib.SynonymDictionary) []*lexicalTerm {
+	if synonyms == nil {
+		return nil
+	}
+
+	expansions := synonyms.Expand(value)
+	if parts := models.SplitIdentifier(value); len(parts) > 1 {
+		for i, part := range parts {
+			for _, synonym := range synonyms.Expand(part) {
+				variant := append([]string(nil), parts...)
+				variant[i] = synonym
+				expansions = append(expansions, strings.Join(variant, "_"))
+			}
+		}
+	}
+
+	var terms []*lexicalTerm
+	seen := make(map[string]bool)
+	for _, expansion := range expansions {
+		if !seen[expansion] {
+			seen[expansion] = true
+			terms = append(terms, newSynonymTerm(expansion))
+		}
+	}
+	return terms
+}
+
+// applyStructuredFilters enforces path and symbol filters on all results, and negated
+// terms on search types that do not evaluate the boolean expression themselves
+func (ss *SearchService) applyStructuredFilters(
+	query *models.SearchQuery,
+	index *models.CodeIndex,
+	results []*models.SearchResult,
+) ([]*models.SearchResult, error) {
+	if query.RawQuery == "" {
+		return results, nil
+	}
+
+	var excluded []*regexp.Regexp
+	if query.Expression != nil && query.SearchType != models.SearchTypeText {
+		for _, value := range query.Expression.NegativeValues() {
+			pattern, err := query.LiteralPattern(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid search term %q: %w", value, err)
+			}
+			excluded = append(excluded, pattern)
+		}
+	}
+
+	filtered := results[:0]
+	for _, result := range results {
+		if !query.ShouldIncludeFile(result.FilePath, result.Language) {
+			continue
+		}
+		if !ss.matchesSymbolFilters(index, result, query.SymbolFilters) {
+			continue
+		}
+
+		keep := true
+		for _, pattern := range excluded {
+			if pattern.MatchString(result.Content) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			filtered = append(filtered, result)
+		}
+	}
+
+	return filtered, nil
+}
+
+// matchesSymbolFilters checks that a result lies within a symbol whose name contains every
+// symbol filter, boosting results on the definition line itself. Files indexed without a
+// symbol table fall back to checking whether the line looks like a matching definition.
+func (ss *SearchService) matchesSymbolFilters(index *models.CodeIndex, result *models.SearchResult, filters []string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+
+	entry, err := index.GetFileEntry(result.FilePath)
+	if err != nil || len(entry.Symbols) == 0 {
+		return ss.matchesSymbolFiltersHeuristically(result.Content, filters)
+	}
+
+	isDefinition := true
+	for _, filter := range filters {
+		filter = strings.ToLower(filter)
+		found := false
+		for _, symbol := range entry.Symbols {
+			if !symbol.ContainsLine(result.StartLine) || !strings.Contains(strings.ToLower(symbol.Name), filter) {
+				continue
+			}
+			found = true
+			if symbol.StartLine != result.StartLine {
+				isDefinition = false
+			}
+			break
+		}
+		if !found {
+			return false
+		}
+	}
+
+	// Favour the definitions themselves over matches inside their bodies
+	if isDefinition {
+		result.RelevanceScore = math.Min(1.0, result.RelevanceScore+symbolDefinitionBoost)
+	}
+
+	return true
+}
+
+// matchesSymbolFiltersHeuristically checks that content defines an identifier containing every symbol filter
+func (ss *SearchService) matchesSymbolFiltersHeuristically(content string, symbols []string) bool {
+	if !ss.isDefinitionContext(content) {
+		return false
+	}
+
+	for _, symbol := range symbols {
+		found := false
+		for _, loc := range lib.ExtractIdentifiers(content) {
+			if strings.Contains(strings.ToLower(content[loc[0]:loc[1]]), strings.ToLower(symbol)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// addLiteralSpans records every match of pattern in line as a span on result
+func addLiteralSpans(result *models.SearchResult, line string, lineNumber int, pattern *regexp.Regexp) {
+	addRangeSpans(result, line, lineNumber, pattern.FindAllStringIndex(line, -1))
+}
+
+// mergeRanges sorts byte ranges and joins those that overlap, so each byte of a line
+// belongs to at most one span
+func mergeRanges(ranges [][]int) [][]int {
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i][0] < ranges[j][0]
+	})
+	var merged [][]int
+	for _, r := range ranges {
+		if last := len(merged) - 1; last >= 0 && r[0] < merged[last][1] {
+			merged[last][1] = max(merged[last][1], r[1])
+			continue
+		}
+		merged = append(merged, []int{r[0], r[1]})
+	}
+	return merged
+}
+
+// addRangeSpans records byte ranges of line as spans on result
+func addRangeSpans(result *models.SearchResult, line string, lineNumber int, ranges [][]int) {
+	for _, loc := range ranges {
+		if loc[1] <= loc[0] {
+			continue
+		}
+		result.AddSpan(models.MatchSpan{
+			StartLine:   lineNumber,
+			StartColumn: loc[0] + 1,
+			EndLine:     lineNumber,
+			EndColumn:   loc[1] + 1,
+			Text:        line[loc[0]:loc[1]],
+		})
+	}
+}
+
 // performHybridSearch performs a combination of semantic and text search in parallel
 func (ss *SearchService) performHybridSearch(
 	query *models.SearchQuery,
@@ -407,8 +1043,8 @@ func (ss *SearchService) performRegexSearch(
 	query *models.SearchQuery,
 	index *models.CodeIndex,
 ) ([]*models.SearchResult, error) {
-	// Compile regex pattern
-	pattern, err := regexp.Compile(query.QueryText)
+	// Compile regex pattern; in multiline mode ^ and $ still anchor at line boundaries
+	pattern, err := query.RegexPattern()
 	if err != nil {
 		return nil, fmt.Errorf("invalid regex pattern: %w", err)
 	}
@@ -429,43 +1065,130 @@ func (ss *SearchService) performRegexSearch(
 			continue
 		}
 
-		// Search for regex matches
+		if query.Multiline {
+			results = append(results, ss.findMultilineRegexMatches(fileEntry, content, pattern)...)
+			continue
+		}
+
+		// Search for regex matches line by line
 		lines := strings.Split(content, "\n")
 		for i, line := range lines {
-			matches := pattern.FindStringSubmatch(line)
-			if len(matches) > 0 {
-				// Create search result
-				result := models.NewSearchResult(
-					fileEntry.FilePath,
-					i+1, i+1,
-					strings.TrimSpace(line),
-				)
-
-				result.Language = fileEntry.Language
-				result.MatchType = models.MatchTypeRegex
-				result.RelevanceScore = ss.calculateRegexRelevanceScore(line, pattern)
+			matches := pattern.FindAllStringSubmatchIndex(line, -1)
+			if len(matches) == 0 {
+				continue
+			}
 
-				// Add highlights for matched groups
-				for _, match := range matches[1:] { // Skip full match
-					if match != "" {
-						result.AddHighlight(match)
-					}
+			// Create search result
+			result := models.NewSearchResult(
+				fileEntry.FilePath,
+				i+1, i+1,
+				line,
+			)
+
+			result.Language = fileEntry.Language
+			result.MatchType = models.MatchTypeRegex
+			result.RelevanceScore = ss.calculateRegexRelevanceScore(line, pattern)
+
+			// Add spans and highlights from submatch indices
+			for _, match := range matches {
+				for _, span := range regexMatchSpans(match) {
+					result.AddSpan(models.MatchSpan{
+						StartLine:   i + 1,
+						StartColumn: span[0] + 1,
+						EndLine:     i + 1,
+						EndColumn:   span[1] + 1,
+						Text:        line[span[0]:span[1]],
+					})
 				}
-
-				results = append(results, result)
 			}
+
+			results = append(results, result)
 		}
 	}
 
 	return results, nil
 }
 
+// findMultilineRegexMatches runs a pattern over whole-file content so matches may span lines
+func (ss *SearchService) findMultilineRegexMatches(
+	fileEntry *models.FileEntry,
+	content string,
+	pattern *regexp.Regexp,
+) []*models.SearchResult {
+	var results []*models.SearchResult
+	lineIndex := lib.NewLineIndex(content)
+
+	for _, match := range pattern.FindAllStringSubmatchIndex(content, -1) {
+		// Skip empty matches, which carry no useful location
+		if match[1] <= match[0] {
+			continue
+		}
+
+		startLine, _ := lineIndex.Position(match[0])
+		endLine, _ := lineIndex.Position(match[1] - 1)
+
+		result := models.NewSearchResult(
+			fileEntry.FilePath,
+			startLine, endLine,
+			lineIndex.LinesText(startLine, endLine),
+		)
+		if result.Content == "" {
+			continue
+		}
+
+		result.Language = fileEntry.Language
+		result.MatchType = models.MatchTypeRegex
+		result.RelevanceScore = ss.calculateMultilineRegexRelevanceScore(
+			match[1]-match[0],
+			lineIndex.LineStart(endLine+1)-lineIndex.LineStart(startLine),
+		)
+
+		// Add spans and highlights from submatch indices
+		for _, span := range regexMatchSpans(match) {
+			spanStartLine, spanStartColumn := lineIndex.Position(span[0])
+			spanEndLine, spanEndColumn := lineIndex.Position(span[1])
+			result.AddSpan(models.MatchSpan{
+				StartLine:   spanStartLine,
+				StartColumn: spanStartColumn,
+				EndLine:     spanEndLine,
+				EndColumn:   spanEndColumn,
+				Text:        content[span[0]:span[1]],
+			})
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// regexMatchSpans returns the byte ranges to highlight for a single match.
+// Capturing groups are preferred; the full match is used when no group participated.
+func regexMatchSpans(match []int) [][2]int {
+	var spans [][2]int
+	for g := 2; g+1 < len(match); g += 2 {
+		if match[g] >= 0 && match[g+1] > match[g] {
+			spans = append(spans, [2]int{match[g], match[g+1]})
+		}
+	}
+
+	if len(spans) == 0 && match[1] > match[0] {
+		spans = append(spans, [2]int{match[0], match[1]})
+	}
+
+	return spans
+}
+
 // performExactSearch performs exact phrase matching
 func (ss *SearchService) performExactSearch(
 	query *models.SearchQuery,
 	index *models.CodeIndex,
 ) ([]*models.SearchResult, error) {
-	searchPhrase := strings.ToLower(query.QueryText)
+	pattern, err := query.LiteralPattern(query.QueryText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search phrase: %w", err)
+	}
+
 	var results []*models.SearchResult
 	fileEntries := index.GetAllFiles()
 
@@ -485,18 +1208,18 @@ func (ss *SearchService) performExactSearch(
 		// Search for exact phrase
 		lines := strings.Split(content, "\n")
 		for i, line := range lines {
-			if strings.Contains(strings.ToLower(line), searchPhrase) {
+			if pattern.MatchString(line) {
 				// Create search result
 				result := models.NewSearchResult(
 					fileEntry.FilePath,
 					i+1, i+1,
-					strings.TrimSpace(line),
+					line,
 				)
 
 				result.Language = fileEntry.Language
 				result.MatchType = models.MatchTypeExact
-				result.RelevanceScore = ss.calculateExactRelevanceScore(line, searchPhrase)
-				result.AddHighlight(query.QueryText)
+				result.RelevanceScore = ss.calculateExactRelevanceScore(line, pattern)
+				addLiteralSpans(result, line, i+1, pattern)
 
 				results = append(results, result)
 			}
@@ -506,21 +1229,26 @@ func (ss *SearchService) performExactSearch(
 	return results, nil
 }
 
-// performFuzzySearch performs fuzzy string matching
+// performFuzzySearch matches query terms against the identifier vocabulary of the
+// index using Damerau-Levenshtein distance, falling back to subsequence scoring
 func (ss *SearchService) performFuzzySearch(
 	query *models.SearchQuery,
 	index *models.CodeIndex,
 ) ([]*models.SearchResult, error) {
-	// For now, implement a simple fuzzy search using text search with relaxed criteria
-	// In a production system, you'd use more sophisticated fuzzy matching algorithms
-
-	// Split query into terms and allow for some mismatches
-	searchTerms := strings.Fields(strings.ToLower(query.GetProcessedQuery()))
+	searchTerms := strings.Fields(query.QueryText)
+	if len(searchTerms) == 0 {
+		return nil, nil
+	}
 
-	var results []*models.SearchResult
-	fileEntries := index.GetAllFiles()
+	// Load candidate files and build the identifier vocabulary
+	type fileContent struct {
+		entry   *models.FileEntry
+		content string
+	}
+	var files []fileContent
+	vocabulary := make(map[string]bool)
 
-	for _, fileEntry := range fileEntries {
+	for _, fileEntry := range index.GetAllFiles() {
 		// Check file filter
 		if !query.ShouldIncludeFile(fileEntry.FilePath, fileEntry.Language) {
 			continue
@@ -533,33 +1261,184 @@ func (ss *SearchService) performFuzzySearch(
 			continue
 		}
 
-		// Search with fuzzy matching
-		lines := strings.Split(content, "\n")
+		files = append(files, fileContent{entry: fileEntry, content: content})
+		for _, loc := range codeTokenRanges(content) {
+			vocabulary[content[loc[0]:loc[1]]] = true
+		}
+	}
+
+	// Match each term against the vocabulary once
+	fuzzyOptions := lib.FuzzyOptions{
+		MaxEdits:      query.MaxEdits,
+		CaseSensitive: query.IsCaseSensitive(),
+		WholeWord:     query.WholeWord,
+	}
+	termMatches := make([]map[string]*lib.FuzzyMatch, len(searchTerms))
+	for t, term := range searchTerms {
+		termMatches[t] = make(map[string]*lib.FuzzyMatch)
+		for identifier := range vocabulary {
+			if match, ok := lib.FuzzyMatchTerm(term, identifier, fuzzyOptions); ok {
+				termMatches[t][identifier] = match
+			}
+		}
+	}
+
+	var results []*models.SearchResult
+	for _, file := range files {
+		lines := strings.Split(file.content, "\n")
 		for i, line := range lines {
-			lineLower := strings.ToLower(line)
+			lineMatch := ss.scoreFuzzyLine(line, searchTerms, termMatches, query.Expression)
+			if lineMatch == nil || lineMatch.score <= query.Threshold {
+				continue
+			}
 
-			// Calculate fuzzy match score
-			matchScore := ss.calculateFuzzyMatchScore(lineLower, searchTerms)
-			if matchScore > query.Threshold {
-				// Create search result
-				result := models.NewSearchResult(
-					fileEntry.FilePath,
-					i+1, i+1,
-					strings.TrimSpace(line),
-				)
+			searchResult := models.NewSearchResult(
+				file.entry.FilePath,
+				i+1, i+1,
+				line,
+			)
 
-				result.Language = fileEntry.Language
-				result.MatchType = models.MatchTypeFuzzy
-				result.RelevanceScore = matchScore
+			searchResult.Language = file.entry.Language
+			searchResult.MatchType = models.MatchTypeFuzzy
+			searchResult.RelevanceScore = lineMatch.score
 
-				results = append(results, result)
+			for _, span := range lineMatch.spans {
+				span.StartLine = i + 1
+				span.EndLine = i + 1
+				searchResult.AddSpan(span)
 			}
+
+			results = append(results, searchResult)
 		}
 	}
 
 	return results, nil
 }
 
+// codeTokenRanges returns the byte ranges of the identifiers in content and of their
+// sub-words, the vocabulary that fuzzy search matches terms against
+func codeTokenRanges(content string) [][]int {
+	var ranges [][]int
+	for _, token := range models.TokenizeCode(content) {
+		// Numbers are not identifiers, though digits split off an identifier are kept
+		if !token.Part && token.Text[0] >= '0' && token.Text[0] <= '9' {
+			continue
+		}
+		ranges = append(ranges, []int{token.Start, token.End})
+	}
+	return ranges
+}
+
+// fuzzyLineMatch holds the fuzzy score of a single line and its matched character ranges
+type fuzzyLineMatch struct {
+	score float64
+	spans []models.MatchSpan
+}
+
+// scoreFuzzyLine scores a line by the best fuzzy match of each term among its identifiers.
+// The score is the mean over all terms, or the score of the boolean expression when the
+// query has one; nil is returned when nothing matched.
+func (ss *SearchService) scoreFuzzyLine(
+	line string,
+	terms []string,
+	termMatches []map[string]*lib.FuzzyMatch,
+	expression *models.QueryNode,
+) *fuzzyLineMatch {
+	identifiers := codeTokenRanges(line)
+	if len(identifiers) == 0 {
+		return nil
+	}
+
+	lineMatch := &fuzzyLineMatch{}
+	termScores := make(map[string]float64, len(terms))
+	totalScore := 0.0
+
+	for t, matches := range termMatches {
+		var best *lib.FuzzyMatch
+		for _, loc := range identifiers {
+			if match, ok := matches[line[loc[0]:loc[1]]]; ok && (best == nil || match.Score > best.Score) {
+				best = match
+			}
+		}
+		if best == nil {
+			continue
+		}
+
+		totalScore += best.Score
+		termScores[terms[t]] = math.Max(termScores[terms[t]], best.Score)
+
+		// Highlight every occurrence of the best candidate on the line
+		for _, loc := range identifiers {
+			if line[loc[0]:loc[1]] != best.Candidate {
+				continue
+			}
+			for _, span := range lib.SpansFromPositions(best.Candidate, best.Positions) {
+				lineMatch.spans = append(lineMatch.spans, models.MatchSpan{
+					StartColumn: loc[0] + span[0] + 1,
+					EndColumn:   loc[0] + span[1] + 1,
+					Text:        best.Candidate[span[0]:span[1]],
+				})
+			}
+		}
+	}
+
+	if expression != nil {
+		lineMatch.score = fuzzyExpressionScore(expression, termScores)
+	} else {
+		lineMatch.score = totalScore / float64(len(termMatches))
+	}
+	if lineMatch.score == 0 {
+		return nil
+	}
+	return lineMatch
+}
+
+// fuzzyExpressionScore scores a boolean expression from the fuzzy scores of its terms:
+// phrases and AND take the mean of their parts, OR its best branch. Negated terms do not
+// score; lines containing them are dropped by the structured filters.
+func fuzzyExpressionScore(node *models.QueryNode, termScores map[string]float64) float64 {
+	switch node.Type {
+	case models.QueryNodeTerm, models.QueryNodePhrase:
+		words := strings.Fields(node.Value)
+		total := 0.0
+		for _, word := range words {
+			total += termScores[word]
+		}
+		if len(words) == 0 {
+			return 0
+		}
+		return total / float64(len(words))
+	case models.QueryNodeAnd:
+		total, count := 0.0, 0
+		for _, child := range node.Children {
+			if child.Type == models.QueryNodeNot {
+				continue
+			}
+			total += fuzzyExpressionScore(child, termScores)
+			count++
+		}
+		if count == 0 {
+			return 0
+		}
+		return total / float64(count)
+	case models.QueryNodeOr:
+		best := 0.0
+		for _, child := range node.Children {
+			if child.Type != models.QueryNodeNot {
+				best = math.Max(best, fuzzyExpressionScore(child, termScores))
+			}
+		}
+		return best
+	default:
+		return 0
+	}
+}
+
+// LoadIndex loads an index from disk for use with SearchIndex. The caller closes it.
+func (ss *SearchService) LoadIndex(indexPath string) (*models.CodeIndex, error) {
+	return ss.loadIndex(indexPath)
+}
+
 // Helper methods
 
 // loadIndex loads an index from disk
@@ -606,20 +1485,25 @@ func (ss *SearchService) mergeSearchResults(resultSets ...[]*models.SearchResult
 }
 
 // calculateTextRelevanceScore calculates relevance score for text search
-func (ss *SearchService) calculateTextRelevanceScore(line string, searchTerms []string) float64 {
-	lineLower := strings.ToLower(line)
+func (ss *SearchService) calculateTextRelevanceScore(line string, terms []*lexicalTerm) float64 {
 	score := 0.0
+	words := strings.Fields(line)
 
-	for _, term := range searchTerms {
-		if strings.Contains(lineLower, term) {
+	for _, term := range terms {
+		if term.pattern.MatchString(line) {
 			score += 0.5
 			// Boost score for exact word matches
-			words := strings.Fields(lineLower)
 			for _, word := range words {
-				if word == term {
+				if loc := term.pattern.FindStringIndex(word); loc != nil && loc[0] == 0 && loc[1] == len(word) {
 					score += 0.3
 				}
 			}
+		} else if len(term.identifierMatches(line)) > 0 {
+			// Sub-word matches inside an identifier rank below literal matches
+			score += 0.4
+		} else if term.matchesSynonym(line) {
+			// Synonym matches rank below matches of the term as typed
+			score += 0.5 * synonymScoreWeight
 		}
 	}
 
@@ -652,23 +1536,13 @@ func (ss *SearchService) calculateRegexRelevanceScore(line string, pattern *rege
 	return score
 }
 
-// calculateExactRelevanceScore calculates relevance score for exact phrase search
-func (ss *SearchService) calculateExactRelevanceScore(line, phrase string) float64 {
-	lineLower := strings.ToLower(line)
-	phraseLower := strings.ToLower(phrase)
-
-	if !strings.Contains(lineLower, phraseLower) {
+// calculateMultilineRegexRelevanceScore calculates relevance score for a match spanning lines
+func (ss *SearchService) calculateMultilineRegexRelevanceScore(matchLength, regionLength int) float64 {
+	if matchLength <= 0 || regionLength <= 0 {
 		return 0.0
 	}
 
-	// Score based on phrase length relative to line length
-	score := float64(len(phrase)) / float64(len(line))
-
-	// Boost score for exact matches
-	if strings.Contains(lineLower, phraseLower) {
-		score += 0.2
-	}
-
+	score := float64(matchLength) / float64(regionLength)
 	if score > 1.0 {
 		score = 1.0
 	}
@@ -676,68 +1550,44 @@ func (ss *SearchService) calculateExactRelevanceScore(line, phrase string) float
 	return score
 }
 
-// calculateFuzzyMatchScore calculates fuzzy match score
-func (ss *SearchService) calculateFuzzyMatchScore(line string, searchTerms []string) float64 {
-	if len(searchTerms) == 0 {
+// calculateExactRelevanceScore calculates relevance score for exact phrase search
+func (ss *SearchService) calculateExactRelevanceScore(line string, pattern *regexp.Regexp) float64 {
+	loc := pattern.FindStringIndex(line)
+	if loc == nil {
 		return 0.0
 	}
 
-	matchedTerms := 0.0
-	for _, term := range searchTerms {
-		if strings.Contains(line, term) {
-			matchedTerms++
-		} else {
-			// Check for partial matches (simplified Levenshtein distance)
-			if ss.isPartialMatch(line, term) {
-				matchedTerms += 0.5
-			}
-		}
-	}
-
-	return float64(matchedTerms) / float64(len(searchTerms))
-}
+	// Score based on phrase length relative to line length, boosted for the exact match
+	score := float64(loc[1]-loc[0])/float64(len(line)) + 0.2
 
-// isPartialMatch checks if term is a partial match of line (simplified)
-func (ss *SearchService) isPartialMatch(line, term string) bool {
-	// Simple implementation: check if any part of the term appears in the line
-	if len(term) <= 3 {
-		return strings.Contains(line, term)
-	}
-
-	// For longer terms, check if at least half the characters match
-	halflen := len(term) / 2
-	for i := 0; i <= len(term)-halflen; i++ {
-		substr := term[i : i+halflen]
-		if strings.Contains(line, substr) {
-			return true
-		}
+	if score > 1.0 {
+		score = 1.0
 	}
 
-	return false
+	return score
 }
 
-// getLineContext extracts context lines around a specific line
-func (ss *SearchService) getLineContext(lines []string, lineIndex, contextLines int) string {
-	start := lineIndex - contextLines
-	if start < 0 {
-		start = 0
+// attachContext adds the lines before and after each result requested by the query
+func (ss *SearchService) attachContext(query *models.SearchQuery, index *models.CodeIndex, results []*models.SearchResult) {
+	before, after := query.ContextRange(ss.searchOptions.ContextLines)
+	if before == 0 && after == 0 {
+		return
 	}
 
-	end := lineIndex + contextLines
-	if end >= len(lines) {
-		end = len(lines) - 1
-	}
+	for _, result := range results {
+		entry, err := index.GetFileEntry(result.FilePath)
+		if err != nil {
+			ss.logger.Debug("No index entry for context of %s: %v", result.FilePath, err)
+			continue
+		}
 
-	var contextLines_slice []string
-	for i := start; i <= end; i++ {
-		prefix := "   "
-		if i == lineIndex {
-			prefix = ">> "
+		beforeLines, afterLines, err := entry.GetContextLines(result.StartLine, result.EndLine, before, after)
+		if err != nil {
+			ss.logger.Warn("Failed to load context for %s: %v", result.FilePath, err)
+			continue
 		}
-		contextLines_slice = append(contextLines_slice, prefix+lines[i])
+		result.SetContextLines(beforeLines, afterLines)
 	}
-
-	return strings.Join(contextLines_slice, "\n")
 }
 
 // SearchInDirectory searches within a specific directory
@@ -921,7 +1771,7 @@ func (ss *SearchService) mergeAndRankResults(
 			SearchResult:    result,
 			SemanticScore:   result.RelevanceScore,
 			TextScore:       0.0,
-			CombinedScore:   result.RelevanceScore * 0.6, // Semantic gets 60% weight
+			CombinedScore:   result.RelevanceScore * ss.searchOptions.SemanticWeight,
 			SourceTypes:     []string{"semantic"},
 			MatchCount:      1,
 			FinalRelevance:  result.RelevanceScore,
@@ -936,7 +1786,7 @@ func (ss *SearchService) mergeAndRankResults(
 		if existing, found := resultMap[key]; found {
 			// Merge with existing result
 			existing.TextScore = result.RelevanceScore
-			existing.CombinedScore += result.RelevanceScore * 0.4 // Text gets 40% weight
+			existing.CombinedScore += result.RelevanceScore * ss.searchOptions.TextWeight
 			existing.SourceTypes = append(existing.SourceTypes, "text")
 			existing.MatchCount++
 
@@ -954,7 +1804,7 @@ func (ss *SearchService) mergeAndRankResults(
 				SearchResult:    result,
 				SemanticScore:   0.0,
 				TextScore:       result.RelevanceScore,
-				CombinedScore:   result.RelevanceScore * 0.4,
+				CombinedScore:   result.RelevanceScore * ss.searchOptions.TextWeight,
 				SourceTypes:     []string{"text"},
 				MatchCount:      1,
 				FinalRelevance:  result.RelevanceScore,
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"code-search/src/lib"
+	"code-search/src/models"
+)
+
+// SymbolQuery describes a lookup in the symbol index
+type SymbolQuery struct {
+	Pattern    string            `json:"pattern"`
+	Kind       models.SymbolKind `json:"kind,omitempty"`
+	MaxResults int               `json:"max_results"`
+}
+
+// SymbolMatch is a symbol matching a SymbolQuery
+type SymbolMatch struct {
+	models.Symbol
+	Score float64 `json:"score"`
+}
+
+// SymbolService looks up definitions in the symbol tables stored in an index
+type SymbolService struct {
+	vectorStore models.VectorStore
+	logger      Logger
+}
+
+// NewSymbolService creates a new symbol service
+func NewSymbolService(vectorStore models.VectorStore, logger Logger) *SymbolService {
+	return &SymbolService{
+		vectorStore: vectorStore,
+		logger:      logger,
+	}
+}
+
+// FindSymbols returns the symbols in the index matching the query, best matches first.
+// Patterns containing glob characters match names by glob; other patterns match exactly,
+// by prefix, or as a subsequence of the name.
+func (s *SymbolService) FindSymbols(indexPath string, query SymbolQuery) ([]SymbolMatch, error) {
+	if strings.TrimSpace(query.Pattern) == "" {
+		return nil, fmt.Errorf("symbol pattern cannot be empty")
+	}
+
+	if _, err := os.Stat(indexPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("index file does not exist: %s", indexPath)
+	}
+
+	index, err := models.LoadCodeIndex(indexPath, s.vectorStore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load index: %w", err)
+	}
+
+	return s.FindSymbolsInIndex(index, query)
+}
+
+// FindSymbolsInIndex returns the symbols in an already loaded index matching the query
+func (s *SymbolService) FindSymbolsInIndex(index *models.CodeIndex, query SymbolQuery) ([]SymbolMatch, error) {
+	if strings.TrimSpace(query.Pattern) == "" {
+		return nil, fmt.Errorf("symbol pattern cannot be empty")
+	}
+
+	var matches []SymbolMatch
+	for _, entry := range index.FileEntries {
+		for _, symbol := range entry.Symbols {
+			if query.Kind != "" && symbol.Kind != query.Kind {
+				continue
+			}
+
+			score, ok := scoreSymbol(query.Pattern, &symbol)
+			if !ok {
+				continue
+			}
+			matches = append(matches, SymbolMatch{Symbol: symbol, Score: score})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		if matches[i].Name != matches[j].Name {
+			return matches[i].Name < matches[j].Name
+		}
+		if matches[i].FilePath != matches[j].FilePath {
+			return matches[i].FilePath < matches[j].FilePath
+		}
+		return matches[i].StartLine < matches[j].StartLine
+	})
+
+	if query.MaxResults > 0 && len(matches) > query.MaxResults {
+		matches = matches[:query.MaxResults]
+	}
+
+	s.logger.Debug("Found %d symbols matching %q", len(matches), query.Pattern)
+	return matches, nil
+}
+
+// scoreSymbol scores how well a pattern matches a symbol's name or qualified name
+func scoreSymbol(pattern string, symbol *models.Symbol) (float64, bool) {
+	names := []string{symbol.Name}
+	if symbol.Container != "" {
+		names = append(names, symbol.QualifiedName())
+	}
+
+	if strings.ContainsAny(pattern, "*?[") {
+		for _, name := range names {
+			if ok, err := filepath.Match(strings.ToLower(pattern), strings.ToLower(name)); err == nil && ok {
+				return 1.0, true
+			}
+		}
+		return 0, false
+	}
+
+	best := 0.0
+	found := false
+	lowerPattern := strings.ToLower(pattern)
+	for _, name := range names {
+		lowerName := strings.ToLower(name)
+		var score float64
+		switch {
+		case name == pattern:
+			score = 1.0
+		case lowerName == lowerPattern:
+			score = 0.95
+		case strings.HasPrefix(lowerName, lowerPattern):
+			score = 0.8 + 0.1*float64(len(pattern))/float64(len(name))
+		default:
+			subsequence, _, ok := lib.SubsequenceScore(pattern, name, false)
+			if !ok {
+				continue
+			}
+			score = 0.7 * subsequence
+		}
+
+		if score > best {
+			best = score
+		}
+		found = true
+	}
+
+	return best, found
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"code-search/src/lib"
+)
+
+// Workspace is a named list of repositories that are searched together
+type Workspace struct {
+	Name         string   `json:"name"`
+	Path         string   `json:"path"`
+	Repositories []string `json:"repositories"` // Absolute repository directories
+}
+
+// WorkspacePath returns the file of a named workspace, in the workspaces directory next
+// to the user configuration file
+func WorkspacePath(name string) string {
+	configPath := UserConfigPath()
+	if configPath == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(configPath), "workspaces", name+".yaml")
+}
+
+// LoadWorkspace reads a workspace by name, or from a file when given a path. Workspace
+// files list repository directories under "repositories"; relative directories are
+// resolved from the file's directory.
+func LoadWorkspace(nameOrPath string) (*Workspace, error) {
+	path := nameOrPath
+	if !strings.ContainsRune(nameOrPath, filepath.Separator) && filepath.Ext(nameOrPath) == "" {
+		path = WorkspacePath(nameOrPath)
+		if path == "" {
+			return nil, fmt.Errorf("cannot locate workspace %s: no home directory", nameOrPath)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("workspace %s not found (looked for %s)", nameOrPath, path)
+		}
+		return nil, fmt.Errorf("failed to read workspace %s: %w", path, err)
+	}
+
+	values, err := lib.ParseSimpleYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid workspace %s: %w", path, err)
+	}
+	for key, value := range values {
+		if key != "repositories" {
+			return nil, fmt.Errorf("invalid workspace %s:%d: unknown setting %s", path, value.Line, key)
+		}
+	}
+
+	repositories, ok := values["repositories"]
+	if ok && !repositories.IsList {
+		return nil, fmt.Errorf("invalid workspace %s:%d: repositories must be a list", path, repositories.Line)
+	}
+	if len(repositories.List) == 0 {
+		return nil, fmt.Errorf("invalid workspace %s: repositories must list at least one directory", path)
+	}
+
+	workspace := &Workspace{
+		Name: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		Path: path,
+	}
+	fileUtils := lib.NewFileUtilities()
+	for _, directory := range repositories.List {
+		if !strings.HasPrefix(directory, "~/") && !filepath.IsAbs(directory) {
+			directory = filepath.Join(filepath.Dir(path), directory)
+		}
+		resolved, err := fileUtils.ResolvePath(directory)
+		if err != nil {
+			return nil, fmt.Errorf("invalid workspace %s: %w", path, err)
+		}
+		workspace.Repositories = append(workspace.Repositories, resolved)
+	}
+	return workspace, nil
+}
+
+// RepositoryLabels names repositories after their directories. Directories sharing a
+// name are told apart by as many parent directories as needed.
+func RepositoryLabels(directories []string) []string {
+	labels := make([]string, len(directories))
+	depth := make([]int, len(directories))
+	for i := range directories {
+		depth[i] = 1
+	}
+
+	for {
+		owners := make(map[string][]int)
+		for i, directory := range directories {
+			labels[i] = trailingPath(directory, depth[i])
+			owners[labels[i]] = append(owners[labels[i]], i)
+		}
+
+		conflicts := false
+		keys := make([]string, 0, len(owners))
+		for label := range owners {
+			keys = append(keys, label)
+		}
+		sort.Strings(keys)
+		for _, label := range keys {
+			indexes := owners[label]
+			if len(indexes) < 2 {
+				continue
+			}
+			for _, i := range indexes {
+				// A label covering the whole path cannot grow; identical paths keep it
+				if trailingPath(directories[i], depth[i]+1) != labels[i] {
+					depth[i]++
+					conflicts = true
+				}
+			}
+		}
+		if !conflicts {
+			return labels
+		}
+	}
+}
+
+// trailingPath returns the last count elements of a path, joined with slashes
+func trailingPath(path string, count int) string {
+	parts := strings.Split(strings.Trim(filepath.ToSlash(filepath.Clean(path)), "/"), "/")
+	if count > len(parts) {
+		count = len(parts)
+	}
+	return strings.Join(parts[len(parts)-count:], "/")
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"code-search/src/lib"
+	"code-search/src/models"
+	"code-search/src/services"
+)
+
+// statusListLimit is how many changed files of each kind the table lists
+const statusListLimit = 10
+
+// StatusCommand implements the status command
+type StatusCommand struct {
+	fileUtils *lib.FileUtilities
+}
+
+// NewStatusCommand creates a new status command
+func NewStatusCommand() *StatusCommand {
+	return &StatusCommand{
+		fileUtils: lib.NewFileUtilities(),
+	}
+}
+
+// StatusOptions contains status command options
+type StatusOptions struct {
+	directory string
+	format    string
+}
+
+// IndexStatusReport is the status of an index as shown by the status command. The
+// embedding model is the one the index was built with, and the model source is where the
+// configured model came from.
+type IndexStatusReport struct {
+	*services.IndexHealth
+	EmbeddingModel  string `json:"embedding_model"`
+	ModelVersion    string `json:"embedding_model_version,omitempty"`
+	ConfiguredModel string `json:"configured_model"`
+	ModelSource     string `json:"embedding_model_source"`
+	ModelChanged    bool   `json:"model_changed"`
+	Locked          bool   `json:"locked"`
+	NeedsReindex    bool   `json:"needs_reindex"`
+}
+
+// Execute executes the status command with the given arguments
+func (cmd *StatusCommand) Execute(args []string) error {
+	options, err := cmd.parseStatusOptions(args)
+	if err != nil {
+		return NewInvalidArgumentError("invalid status options", err)
+	}
+
+	config, err := loadConfig(options.directory)
+	if err != nil {
+		return err
+	}
+
+	indexPath, err := ResolveIndexPath(options.directory)
+	if err != nil {
+		return NewInvalidArgumentError("failed to resolve index location", err)
+	}
+
+	health, err := newInspectionService(config).GetIndexHealth(indexPath)
+	if err != nil {
+		return NewGeneralError("failed to read index status", err)
+	}
+
+	repositoryPath := health.RepositoryPath
+	if repositoryPath == "" {
+		directory := options.directory
+		if directory == "" {
+			directory = "."
+		}
+		if repositoryPath, err = cmd.fileUtils.ResolvePath(directory); err != nil {
+			return NewInvalidArgumentError("failed to resolve directory path", err)
+		}
+	}
+
+	report := IndexStatusReport{
+		IndexHealth:     health,
+		ConfiguredModel: config.Model,
+	}
+	source, _ := config.Source("embedding.model")
+	report.ModelSource = string(source)
+
+	// The model comes from the index itself, or from the directory metadata of indexes
+	// built before the index recorded it. Indexes with neither report none and are not
+	// held stale for it.
+	model := health.Model
+	if model == nil {
+		indexDir := cmd.fileUtils.CreateIndexLocation(repositoryPath).IndexDir
+		if metadata, err := lib.LoadMetadata(indexDir); err == nil && metadata.ModelName != "" {
+			model = &models.IndexModel{ModelName: metadata.ModelName, ModelVersion: metadata.ModelVersion}
+		}
+	}
+	if model != nil {
+		report.EmbeddingModel = model.ModelName
+		report.ModelVersion = model.ModelVersion
+		report.ModelChanged = health.Exists && model.ModelName != config.Model
+	}
+	report.NeedsReindex = health.NeedsReindex() || report.ModelChanged
+	report.Locked = cmd.fileUtils.IsLocked(repositoryPath)
+	cmd.relativizePaths(report.IndexHealth)
+
+	if options.format == "json" {
+		if err := cmd.displayJSONStatus(report); err != nil {
+			return err
+		}
+	} else if health.Exists {
+		cmd.displayTableStatus(report)
+	}
+
+	if !health.Exists {
+		return NewIndexNotFoundError(repositoryPath)
+	}
+	if report.NeedsReindex {
+		return NewIndexStaleError()
+	}
+	return nil
+}
+
+// newInspectionService creates an indexing service for reading and deleting indexes,
+// without a vector store file of its own
+func newInspectionService(config *services.Config) *services.IndexingService {
+	return services.NewIndexingService(
+		lib.NewFileSystemScanner(),
+		config.CodeParser(),
+		lib.NewInMemoryVectorStore(""),
+		&services.SilentLogger{},
+		config.IndexingOptions(),
+	)
+}
+
+// parseStatusOptions parses command line options for status
+func (cmd *StatusCommand) parseStatusOptions(args []string) (StatusOptions, error) {
+	options := StatusOptions{
+		format: "table",
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch arg {
+		case "--dir", "-d":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--dir requires a directory path", nil)
+			}
+			options.directory = args[i+1]
+			i++
+
+		case "--format":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--format requires a value", nil)
+			}
+			format := strings.ToLower(args[i+1])
+			if format != "table" && format != "json" {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid format: %s (supported: table, json)", format), nil)
+			}
+			options.format = format
+			i++
+
+		case "--help", "-h":
+			cmd.printStatusHelp()
+			os.Exit(0)
+
+		default:
+			return options, NewInvalidArgumentError(fmt.Sprintf("unknown option: %s", arg), nil)
+		}
+	}
+
+	return options, nil
+}
+
+// relativizePaths makes the changed files relative to the repository
+func (cmd *StatusCommand) relativizePaths(health *services.IndexHealth) {
+	for _, files := range [][]string{health.StaleFiles, health.NewFiles, health.DeletedFiles} {
+		for i, file := range files {
+			if rel, err := filepath.Rel(health.RepositoryPath, file); err == nil {
+				files[i] = filepath.ToSlash(rel)
+			}
+		}
+	}
+}
+
+// displayTableStatus prints the index status for people
+func (cmd *StatusCommand) displayTableStatus(report IndexStatusReport) {
+	indexPath, err := filepath.Abs(report.IndexPath)
+	if err != nil {
+		indexPath = report.IndexPath
+	}
+
+	fmt.Printf("Index:           %s\n", indexPath)
+	fmt.Printf("Format:          %s, version %s, %s\n", report.Format, report.Version, cmd.fileUtils.FormatBytes(report.Size))
+	fmt.Printf("Repository:      %s\n", report.RepositoryPath)
+	fmt.Printf("Last indexed:    %s (%s ago)\n", report.LastIndexed.Format("2006-01-02 15:04:05"), formatAge(time.Since(report.LastIndexed)))
+	switch {
+	case report.EmbeddingModel == "":
+		fmt.Printf("Embedding model: not recorded (configured: %s, %s)\n", report.ConfiguredModel, report.ModelSource)
+	case report.ModelChanged:
+		fmt.Printf("Embedding model: %s %s (configured: %s, %s)\n", report.EmbeddingModel, report.ModelVersion, report.ConfiguredModel, report.ModelSource)
+	default:
+		fmt.Printf("Embedding model: %s %s (%s)\n", report.EmbeddingModel, report.ModelVersion, report.ModelSource)
+	}
+	if report.Locked {
+		fmt.Printf("Lock:            locked (an index run is in progress)\n")
+	} else {
+		fmt.Printf("Lock:            unlocked\n")
+	}
+	fmt.Printf("Files:           %d files, %d chunks\n", report.FileCount, report.ChunkCount)
+
+	languages := make([]string, 0, len(report.Languages))
+	for language := range report.Languages {
+		languages = append(languages, language)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		a, b := report.Languages[languages[i]], report.Languages[languages[j]]
+		if a.Files != b.Files {
+			return a.Files > b.Files
+		}
+		return languages[i] < languages[j]
+	})
+	for _, language := range languages {
+		count := report.Languages[language]
+		fmt.Printf("  %-14s %6d files %8d chunks\n", language, count.Files, count.Chunks)
+	}
+
+	if report.ModelChanged {
+		fmt.Printf("Model changed:   index built with %s, configuration selects %s\n", report.EmbeddingModel, report.ConfiguredModel)
+	}
+	fmt.Printf("Changes:         %d stale, %d new, %d deleted\n", len(report.StaleFiles), len(report.NewFiles), len(report.DeletedFiles))
+	cmd.printChangedFiles("stale", report.StaleFiles)
+	cmd.printChangedFiles("new", report.NewFiles)
+	cmd.printChangedFiles("deleted", report.DeletedFiles)
+
+	if report.NeedsReindex {
+		fmt.Println("Status:          reindex needed (run 'code-search index')")
+	} else {
+		fmt.Println("Status:          up to date")
+	}
+}
+
+// printChangedFiles lists the first changed files of one kind
+func (cmd *StatusCommand) printChangedFiles(kind string, files []string) {
+	for i, file := range files {
+		if i == statusListLimit {
+			fmt.Printf("  %-8s ... and %d more\n", kind, len(files)-statusListLimit)
+			return
+		}
+		fmt.Printf("  %-8s %s\n", kind, file)
+	}
+}
+
+// displayJSONStatus prints the index status as JSON
+func (cmd *StatusCommand) displayJSONStatus(report IndexStatusReport) error {
+	for _, files := range []*[]string{&report.StaleFiles, &report.NewFiles, &report.DeletedFiles} {
+		if *files == nil {
+			*files = []string{}
+		}
+	}
+
+	jsonData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return NewGeneralError("failed to generate JSON output", err)
+	}
+	fmt.Println(string(jsonData))
+	return nil
+}
+
+// formatAge formats how long ago something happened, to the largest whole unit
+func formatAge(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return fmt.Sprintf("%ds", int(age.Seconds()))
+	case age < time.Hour:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(age.Hours()/24))
+}
+
+// printStatusHelp prints help for the status command
+func (cmd *StatusCommand) printStatusHelp() {
+	fmt.Printf(`Usage: code-search status [options]
+
+Shows the index location, format and size, file and chunk counts by language, the
+embedding model the index was built with, when the index was last built, whether an
+index run holds its lock, and which files changed on disk since then. An index built
+with a different model than the configured one needs reindexing.
+
+  stale    Indexed files whose content changed
+  new      Files the next index run would add
+  deleted  Indexed files that were removed or no longer match the index filters
+
+Options:
+  -d, --dir <directory>    Show the index of this directory (default: current directory)
+      --format <format>    Output format: table, json (default: table)
+  -h, --help               Show this help message
+
+Examples:
+  code-search status
+  code-search status --dir ~/project --format json
+
+Exit Codes:
+  0        The index is up to date
+  1        Error reading the index
+  2        Invalid arguments
+  3        No index found
+  4        The index needs reindexing (changed files or a changed model)
+`)
+}
+
+// GetHelp returns help text for the command
+func (cmd *StatusCommand) GetHelp() string {
+	return `status [options] - Show index health and whether it needs reindexing
+
+Use 'code-search status --help' for detailed usage information.`
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// captureStdout runs fn and returns what it printed to standard output
+func captureStdout(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(reader)
+		output <- data
+	}()
+	runErr := fn()
+	writer.Close()
+	return string(<-output), runErr
+}
+
+// TestStatusCommand_DefaultIndexModel tests that an index built at the default path
+// reports the embedding model it was built with
+func TestStatusCommand_DefaultIndexModel(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	repository := filepath.Join(dir, "repo")
+	if err := os.MkdirAll(repository, 0755); err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repository, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	t.Chdir(repository)
+
+	if _, err := captureStdout(t, func() error { return NewIndexCommand().Execute(nil) }); err != nil {
+		t.Fatalf("Index failed: %v", err)
+	}
+
+	status := func(t *testing.T) (IndexStatusReport, error) {
+		t.Helper()
+		output, err := captureStdout(t, func() error {
+			return NewStatusCommand().Execute([]string{"--format", "json"})
+		})
+		var report IndexStatusReport
+		if jsonErr := json.Unmarshal([]byte(output), &report); jsonErr != nil {
+			t.Fatalf("Failed to parse status output %q: %v", output, jsonErr)
+		}
+		return report, err
+	}
+
+	t.Run("Model is recorded", func(t *testing.T) {
+		report, err := status(t)
+		if err != nil {
+			t.Fatalf("Status failed: %v", err)
+		}
+		if report.EmbeddingModel == "" || report.EmbeddingModel != report.ConfiguredModel {
+			t.Errorf("Expected the configured model %q to be recorded, got %q", report.ConfiguredModel, report.EmbeddingModel)
+		}
+		if report.ModelChanged || report.NeedsReindex {
+			t.Errorf("Expected an up-to-date index, got changed %t, needs reindex %t", report.ModelChanged, report.NeedsReindex)
+		}
+	})
+
+	t.Run("Another configured model makes the index stale", func(t *testing.T) {
+		t.Setenv("CODE_SEARCH_MODEL", "another-model")
+		report, err := status(t)
+		if err == nil {
+			t.Error("Expected a stale index error")
+		}
+		if !report.ModelChanged || !report.NeedsReindex {
+			t.Errorf("Expected the model change to require a reindex, got changed %t, needs reindex %t", report.ModelChanged, report.NeedsReindex)
+		}
+	})
+}
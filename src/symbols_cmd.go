@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"code-search/src/lib"
+	"code-search/src/models"
+	"code-search/src/services"
+)
+
+// SymbolsCommand implements the symbols command
+type SymbolsCommand struct {
+	symbolService *services.SymbolService
+}
+
+// NewSymbolsCommand creates a new symbols command
+func NewSymbolsCommand() *SymbolsCommand {
+	return &SymbolsCommand{
+		symbolService: services.NewSymbolService(
+			lib.NewInMemoryVectorStore(""),
+			&services.SilentLogger{},
+		),
+	}
+}
+
+// SymbolsOptions contains symbols command options
+type SymbolsOptions struct {
+	kind       models.SymbolKind
+	directory  string
+	format     string
+	maxResults int
+}
+
+// Execute executes the symbols command with the given arguments
+func (cmd *SymbolsCommand) Execute(args []string) error {
+	if len(args) < 1 {
+		return NewInvalidArgumentError("symbol pattern is required", nil)
+	}
+	if args[0] == "--help" || args[0] == "-h" {
+		cmd.printSymbolsHelp()
+		return nil
+	}
+
+	pattern := args[0]
+	options, err := cmd.parseSymbolsOptions(args[1:])
+	if err != nil {
+		return NewInvalidArgumentError("invalid symbols options", err)
+	}
+
+	indexPath, err := ResolveIndexPath(options.directory)
+	if err != nil {
+		return NewInvalidArgumentError("failed to resolve index location", err)
+	}
+
+	start := time.Now()
+	matches, err := cmd.symbolService.FindSymbols(indexPath, services.SymbolQuery{
+		Pattern:    pattern,
+		Kind:       options.kind,
+		MaxResults: options.maxResults,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			return NewNotFoundError("symbol lookup failed", err)
+		}
+		return NewGeneralError("symbol lookup failed", err)
+	}
+
+	if options.format == "json" {
+		return cmd.displayJSONSymbols(pattern, matches)
+	}
+	return cmd.displayTableSymbols(matches, start)
+}
+
+// parseSymbolsOptions parses command line options for symbols
+func (cmd *SymbolsCommand) parseSymbolsOptions(args []string) (SymbolsOptions, error) {
+	options := SymbolsOptions{
+		format:     "table",
+		maxResults: 50,
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch arg {
+		case "--kind", "-k":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--kind requires a value", nil)
+			}
+			kind, err := models.ParseSymbolKind(args[i+1])
+			if err != nil {
+				return options, NewInvalidArgumentError(err.Error(), nil)
+			}
+			options.kind = kind
+			i++
+
+		case "--dir", "-d":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--dir requires a directory path", nil)
+			}
+			options.directory = args[i+1]
+			i++
+
+		case "--format":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--format requires a value", nil)
+			}
+			format := strings.ToLower(args[i+1])
+			if format != "table" && format != "json" {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid format: %s (supported: table, json)", format), nil)
+			}
+			options.format = format
+			i++
+
+		case "--max-results", "-m":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--max-results requires a value", nil)
+			}
+			var maxResults int
+			if _, err := fmt.Sscanf(args[i+1], "%d", &maxResults); err != nil || maxResults < 1 {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid max-results value: %s", args[i+1]), nil)
+			}
+			options.maxResults = maxResults
+			i++
+
+		case "--help", "-h":
+			cmd.printSymbolsHelp()
+			os.Exit(0)
+
+		default:
+			return options, NewInvalidArgumentError(fmt.Sprintf("unknown option: %s", arg), nil)
+		}
+	}
+
+	return options, nil
+}
+
+// displayTableSymbols displays symbols in table format
+func (cmd *SymbolsCommand) displayTableSymbols(matches []services.SymbolMatch, start time.Time) error {
+	if len(matches) == 0 {
+		fmt.Println("No symbols found.")
+		fmt.Println("Indexes built before symbol extraction have no symbol table; rebuild the index to add one.")
+		return nil
+	}
+
+	fmt.Printf("Found %d symbols:\n\n", len(matches))
+	for _, match := range matches {
+		fmt.Printf("%-7s %s  %s:%d-%d\n", match.Kind, match.QualifiedName(), match.FilePath, match.StartLine, match.EndLine)
+		if match.Signature != "" {
+			signature := match.Signature
+			if len(signature) > 100 {
+				signature = signature[:97] + "..."
+			}
+			fmt.Printf("        %s\n", signature)
+		}
+	}
+
+	fmt.Printf("\nLookup completed in %v\n", time.Since(start))
+	return nil
+}
+
+// displayJSONSymbols displays symbols in JSON format
+func (cmd *SymbolsCommand) displayJSONSymbols(pattern string, matches []services.SymbolMatch) error {
+	if matches == nil {
+		matches = []services.SymbolMatch{}
+	}
+
+	output := map[string]interface{}{
+		"pattern": pattern,
+		"total":   len(matches),
+		"symbols": matches,
+	}
+
+	jsonData, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to generate JSON output: %w", err)
+	}
+
+	fmt.Println(string(jsonData))
+	return nil
+}
+
+// printSymbolsHelp prints help for the symbols command
+func (cmd *SymbolsCommand) printSymbolsHelp() {
+	fmt.Printf(`Usage: code-search symbols <pattern> [options]
+
+Arguments:
+  <pattern>                Symbol name, prefix, abbreviation or glob (e.g. "Handle*")
+
+Options:
+  -k, --kind <kind>        Only show symbols of a kind: func, method, type, const, var, class
+  -d, --dir <directory>    Specify indexed directory (default: current directory)
+      --format <fmt>       Output format: table, json (default: table)
+  -m, --max-results <n>    Maximum number of symbols to return (default: 50)
+  -h, --help               Show this help message
+
+Examples:
+  code-search symbols NewServer
+  code-search symbols "Handle*" --kind method
+  code-search symbols Server.Start --format json
+  code-search symbols usrsvc --dir /path/to/project
+
+Symbols are extracted when indexing Go, Python, JavaScript, TypeScript, Java
+and Rust files. Indexes created by older versions have no symbol table; delete
+the index and run 'code-search index' again to build one.
+`)
+}
+
+// GetHelp returns help text for the symbols command
+func (cmd *SymbolsCommand) GetHelp() string {
+	return `symbols <pattern> [options] - Find definitions in the symbol index
+
+Use 'code-search symbols --help' for detailed usage information.`
+}
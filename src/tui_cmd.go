@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"code-search/src/lib"
+	"code-search/src/models"
+	"code-search/src/services"
+)
+
+// TUICommand implements the tui command
+type TUICommand struct {
+	searchService *services.SearchService
+}
+
+// NewTUICommand creates a new tui command
+func NewTUICommand() *TUICommand {
+	return &TUICommand{
+		searchService: services.NewSearchService(
+			lib.NewSimpleCodeParser(),
+			lib.NewInMemoryVectorStore(""),
+			&services.SilentLogger{},
+			services.DefaultSearchOptions(),
+		),
+	}
+}
+
+// TUIOptions contains tui command options
+type TUIOptions struct {
+	directory  string
+	query      string
+	searchType models.SearchType
+	maxResults int
+	threshold  float64
+}
+
+// Execute executes the tui command with the given arguments
+func (cmd *TUICommand) Execute(args []string) error {
+	options, err := cmd.parseTUIOptions(args)
+	if err != nil {
+		return NewInvalidArgumentError("invalid tui options", err)
+	}
+
+	if !lib.IsTerminal(os.Stdin) || !lib.IsTerminal(os.Stdout) {
+		return NewInvalidArgumentError("tui needs an interactive terminal; use 'code-search search' in scripts", nil)
+	}
+
+	indexPath, err := ResolveIndexPath(options.directory)
+	if err != nil {
+		return NewInvalidArgumentError("failed to resolve index location", err)
+	}
+
+	// The index is loaded once and searched in memory on every keystroke
+	index, err := cmd.searchService.LoadIndex(indexPath)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			return NewNotFoundError("failed to load index", err)
+		}
+		return NewGeneralError("failed to load index", err)
+	}
+	defer index.Close()
+
+	session := newTUISession(cmd.searchService, index, options)
+	if err := session.run(); err != nil {
+		return NewGeneralError("tui failed", err)
+	}
+	return nil
+}
+
+// parseTUIOptions parses command line options for tui
+func (cmd *TUICommand) parseTUIOptions(args []string) (TUIOptions, error) {
+	options := TUIOptions{
+		searchType: models.SearchTypeText,
+		maxResults: 100,
+		threshold:  0.3,
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch arg {
+		case "--dir", "-d":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--dir requires a directory path", nil)
+			}
+			options.directory = args[i+1]
+			i++
+
+		case "--type":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--type requires a value", nil)
+			}
+			searchType := models.SearchType(strings.ToLower(args[i+1]))
+			if tuiSearchTypeIndex(searchType) < 0 {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid search type: %s (supported: text, regex, semantic, hybrid)", args[i+1]), nil)
+			}
+			options.searchType = searchType
+			i++
+
+		case "--max-results", "-m":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--max-results requires a value", nil)
+			}
+			maxResults, err := parseMaxResults(args[i+1])
+			if err != nil {
+				return options, err
+			}
+			options.maxResults = maxResults
+			i++
+
+		case "--threshold", "-t":
+			if i+1 >= len(args) {
+				return options, NewInvalidArgumentError("--threshold requires a value", nil)
+			}
+			var threshold float64
+			if _, err := fmt.Sscanf(args[i+1], "%f", &threshold); err != nil || threshold < 0 || threshold > 1 {
+				return options, NewInvalidArgumentError(fmt.Sprintf("invalid threshold value: %s (must be between 0 and 1)", args[i+1]), nil)
+			}
+			options.threshold = threshold
+			i++
+
+		case "--help", "-h":
+			cmd.printTUIHelp()
+			os.Exit(0)
+
+		default:
+			if strings.HasPrefix(arg, "-") || options.query != "" {
+				return options, NewInvalidArgumentError(fmt.Sprintf("unknown option: %s", arg), nil)
+			}
+			options.query = arg
+		}
+	}
+
+	return options, nil
+}
+
+// printTUIHelp prints help for the tui command
+func (cmd *TUICommand) printTUIHelp() {
+	fmt.Printf(`Usage: code-search tui [query] [options]
+
+Opens a full-screen search of the index. The index is loaded once, and results
+update as you type.
+
+Arguments:
+  [query]                  Initial query
+
+Options:
+  -d, --dir <directory>    Specify indexed directory (default: current directory)
+      --type <type>        Initial search type: text, regex, semantic, hybrid (default: text)
+  -m, --max-results <n>    Maximum number of results to list (default: 100, at most 1000)
+  -t, --threshold <t>      Similarity threshold for semantic and hybrid search (default: 0.3)
+  -h, --help               Show this help message
+
+Keys:
+  typing                   Edit the query; structured syntax such as lang:go works
+  Up/Down, Ctrl-P/Ctrl-N   Select a result
+  PgUp/PgDn                Move the selection a page
+  Tab                      Switch search type: text, regex, semantic, hybrid
+  Enter                    Open the selected result in $VISUAL or $EDITOR at its line
+  Ctrl-U, Ctrl-W           Clear the query, or delete its last word
+  Esc, Ctrl-C              Quit
+`)
+}
+
+// GetHelp returns help text for the command
+func (cmd *TUICommand) GetHelp() string {
+	return `tui [query] [options] - Search interactively with results and a file preview
+
+Use 'code-search tui --help' for detailed usage information.`
+}
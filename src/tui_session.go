@@ -0,0 +1,487 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"code-search/src/lib"
+	"code-search/src/models"
+	"code-search/src/services"
+)
+
+// tuiSearchTypes are the search types Tab cycles through
+var tuiSearchTypes = []models.SearchType{
+	models.SearchTypeText,
+	models.SearchTypeRegex,
+	models.SearchTypeSemantic,
+	models.SearchTypeHybrid,
+}
+
+// tuiSearchDelay is how long typing must pause before the query is searched
+const tuiSearchDelay = 100 * time.Millisecond
+
+// Escape sequences for the alternate screen, which keeps the shell's scrollback intact
+const (
+	tuiEnterScreen = "\x1b[?1049h"
+	tuiLeaveScreen = "\x1b[?1049l"
+)
+
+// tuiSearchTypeIndex returns the position of a search type in tuiSearchTypes, or -1
+func tuiSearchTypeIndex(searchType models.SearchType) int {
+	for i, candidate := range tuiSearchTypes {
+		if candidate == searchType {
+			return i
+		}
+	}
+	return -1
+}
+
+// tuiSession is the state of one interactive search: the query being typed, its results,
+// the selected result and the screen they are drawn on
+type tuiSession struct {
+	searchService *services.SearchService
+	index         *models.CodeIndex
+	options       TUIOptions
+	color         *lib.Colorizer
+	root          string
+
+	query     []rune
+	typeIndex int
+	results   *models.SearchResults
+	searchErr error
+	selected  int
+	scroll    int
+	message   string
+
+	terminal *lib.TerminalState
+	width    int
+	height   int
+	files    map[string][]string
+}
+
+// newTUISession creates a session searching an index that is already loaded
+func newTUISession(searchService *services.SearchService, index *models.CodeIndex, options TUIOptions) *tuiSession {
+	root, _ := os.Getwd()
+	return &tuiSession{
+		searchService: searchService,
+		index:         index,
+		options:       options,
+		color:         lib.NewColorizer(os.Getenv("NO_COLOR") == ""),
+		root:          root,
+		query:         []rune(options.query),
+		typeIndex:     tuiSearchTypeIndex(options.searchType),
+		files:         make(map[string][]string),
+	}
+}
+
+// run takes over the terminal until the user quits
+func (s *tuiSession) run() error {
+	if err := s.enterScreen(); err != nil {
+		return err
+	}
+	defer s.leaveScreen()
+
+	// Keys are read one chunk at a time, and the reader waits for each chunk to be
+	// handled so that it does not take input meant for the editor
+	keys := make(chan []byte)
+	handled := make(chan struct{})
+	readErrs := make(chan error, 1)
+	go func() {
+		buffer := make([]byte, 256)
+		for {
+			n, err := os.Stdin.Read(buffer)
+			if err != nil {
+				readErrs <- err
+				return
+			}
+			keys <- append([]byte(nil), buffer[:n]...)
+			<-handled
+		}
+	}()
+
+	resized := make(chan os.Signal, 1)
+	signal.Notify(resized, syscall.SIGWINCH)
+	defer signal.Stop(resized)
+
+	s.search()
+	s.draw()
+
+	var pending <-chan time.Time
+	for {
+		select {
+		case key := <-keys:
+			quit, changed := s.handleKey(key)
+			if quit {
+				return nil
+			}
+			if changed {
+				pending = time.After(tuiSearchDelay)
+			}
+			s.draw()
+			handled <- struct{}{}
+
+		case <-pending:
+			pending = nil
+			s.search()
+			s.draw()
+
+		case <-resized:
+			s.draw()
+
+		case err := <-readErrs:
+			return fmt.Errorf("failed to read keys: %w", err)
+		}
+	}
+}
+
+// enterScreen puts the terminal in raw mode and switches to the alternate screen
+func (s *tuiSession) enterScreen() error {
+	state, err := lib.MakeRaw(os.Stdin)
+	if err != nil {
+		return err
+	}
+	s.terminal = state
+	fmt.Fprint(os.Stdout, tuiEnterScreen)
+	return nil
+}
+
+// leaveScreen restores the screen and terminal mode the session started with
+func (s *tuiSession) leaveScreen() {
+	fmt.Fprint(os.Stdout, tuiLeaveScreen)
+	if s.terminal != nil {
+		lib.RestoreTerminal(s.terminal)
+		s.terminal = nil
+	}
+}
+
+// handleKey applies one chunk of input. It reports whether to quit and whether the query
+// or search type changed.
+func (s *tuiSession) handleKey(key []byte) (bool, bool) {
+	s.message = ""
+
+	switch string(key) {
+	case "\x1b", "\x03":
+		return true, false
+	case "\x1b[A", "\x1bOA", "\x10":
+		s.moveSelection(-1)
+	case "\x1b[B", "\x1bOB", "\x0e":
+		s.moveSelection(1)
+	case "\x1b[5~":
+		s.moveSelection(-s.listHeight())
+	case "\x1b[6~":
+		s.moveSelection(s.listHeight())
+	case "\t":
+		s.typeIndex = (s.typeIndex + 1) % len(tuiSearchTypes)
+		return false, true
+	case "\r", "\n":
+		s.openSelected()
+	case "\x7f", "\x08":
+		if len(s.query) == 0 {
+			return false, false
+		}
+		s.query = s.query[:len(s.query)-1]
+		return false, true
+	case "\x15":
+		s.query = nil
+		return false, true
+	case "\x17":
+		trimmed := strings.TrimRightFunc(string(s.query), unicode.IsSpace)
+		cut := strings.LastIndexFunc(trimmed, unicode.IsSpace) + 1
+		s.query = []rune(trimmed[:cut])
+		return false, true
+	default:
+		// Other escape sequences are keys the session does not use
+		if key[0] == '\x1b' {
+			return false, false
+		}
+		changed := false
+		for _, r := range string(key) {
+			if unicode.IsPrint(r) {
+				s.query = append(s.query, r)
+				changed = true
+			}
+		}
+		return false, changed
+	}
+	return false, false
+}
+
+// search runs the current query against the loaded index
+func (s *tuiSession) search() {
+	s.results, s.searchErr = nil, nil
+	s.selected, s.scroll = 0, 0
+
+	text := strings.TrimSpace(string(s.query))
+	if text == "" {
+		return
+	}
+
+	query := models.NewSearchQuery(text)
+	query.SearchType = tuiSearchTypes[s.typeIndex]
+	query.MaxResults = s.options.maxResults
+	query.Threshold = s.options.threshold
+	query.SmartCase = true
+	if err := query.ParseStructuredQuery(); err != nil {
+		s.searchErr = err
+		return
+	}
+
+	s.results, s.searchErr = s.searchService.SearchIndex(query, s.index)
+}
+
+// moveSelection moves the selected result by delta, keeping it within the list
+func (s *tuiSession) moveSelection(delta int) {
+	if s.results == nil || len(s.results.Results) == 0 {
+		return
+	}
+	s.selected += delta
+	if s.selected < 0 {
+		s.selected = 0
+	}
+	if s.selected >= len(s.results.Results) {
+		s.selected = len(s.results.Results) - 1
+	}
+}
+
+// openSelected opens the selected result in the user's editor at its line
+func (s *tuiSession) openSelected() {
+	if s.results == nil || len(s.results.Results) == 0 {
+		return
+	}
+	result := s.results.Results[s.selected]
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	fields := strings.Fields(editor)
+	args := append(fields[1:], fmt.Sprintf("+%d", result.StartLine), result.FilePath)
+
+	s.leaveScreen()
+	command := exec.Command(fields[0], args...)
+	command.Stdin, command.Stdout, command.Stderr = os.Stdin, os.Stdout, os.Stderr
+	runErr := command.Run()
+	if err := s.enterScreen(); err != nil {
+		s.message = err.Error()
+		return
+	}
+
+	// The file may have changed, so its preview is read again
+	delete(s.files, result.FilePath)
+	if runErr != nil {
+		s.message = fmt.Sprintf("%s failed: %v", fields[0], runErr)
+	}
+}
+
+// listHeight returns the number of rows of the result list
+func (s *tuiSession) listHeight() int {
+	rows := (s.height - 4) * 2 / 5
+	if rows < 3 {
+		rows = 3
+	}
+	return rows
+}
+
+// draw redraws the whole screen: the query, a status line, the result list, a preview of
+// the selected result and the key help
+func (s *tuiSession) draw() {
+	s.width, s.height = 80, 24
+	if width, height, err := lib.TerminalSize(os.Stdout); err == nil && width > 0 && height > 0 {
+		s.width, s.height = width, height
+	}
+
+	rows := make([]string, 0, s.height)
+	prompt := fmt.Sprintf("Search [%s]> ", tuiSearchTypes[s.typeIndex])
+	rows = append(rows, s.color.Heading(prompt)+s.fit(string(s.query), s.width-len(prompt)))
+	rows = append(rows, s.statusLine())
+	rows = append(rows, s.color.Dim(strings.Repeat("─", s.width)))
+
+	listHeight := s.listHeight()
+	rows = append(rows, s.resultRows(listHeight)...)
+
+	previewHeight := s.height - len(rows) - 2
+	rows = append(rows, s.previewRows(previewHeight)...)
+	for len(rows) < s.height-1 {
+		rows = append(rows, "")
+	}
+	rows = append(rows, s.color.Dim(s.fit("↑/↓ select · Enter open · Tab search type · Ctrl-U clear · Esc quit", s.width)))
+
+	var frame strings.Builder
+	for i, row := range rows {
+		fmt.Fprintf(&frame, "\x1b[%d;1H%s\x1b[K", i+1, row)
+	}
+	// Leave the cursor at the end of the query
+	column := len(prompt) + utf8.RuneCountInString(string(s.query)) + 1
+	if column > s.width {
+		column = s.width
+	}
+	fmt.Fprintf(&frame, "\x1b[1;%dH", column)
+	fmt.Fprint(os.Stdout, frame.String())
+}
+
+// statusLine describes the results of the current query, or why there are none
+func (s *tuiSession) statusLine() string {
+	switch {
+	case s.message != "":
+		return s.color.Match(s.fit(s.message, s.width))
+	case s.searchErr != nil:
+		var parseErr *models.QueryParseError
+		if errors.As(s.searchErr, &parseErr) {
+			return s.color.Match(s.fit(fmt.Sprintf("column %d: %s", parseErr.Column, parseErr.Message), s.width))
+		}
+		return s.color.Match(s.fit(s.searchErr.Error(), s.width))
+	case s.results == nil:
+		return s.color.Dim(s.fit(fmt.Sprintf("Type to search %d indexed files", len(s.index.GetAllFiles())), s.width))
+	}
+
+	status := fmt.Sprintf("%d results in %v", s.results.TotalResults, s.results.ExecutionTime.Round(time.Microsecond))
+	if s.results.HasMore {
+		status = fmt.Sprintf("%d of %d results in %v", len(s.results.Results), s.results.TotalResults, s.results.ExecutionTime.Round(time.Microsecond))
+	}
+	return s.color.Dim(s.fit(status, s.width))
+}
+
+// resultRows renders the visible part of the result list, one result per row
+func (s *tuiSession) resultRows(height int) []string {
+	rows := make([]string, 0, height)
+	if s.results == nil {
+		return rows
+	}
+
+	// Scroll so that the selected result stays visible
+	if s.selected < s.scroll {
+		s.scroll = s.selected
+	}
+	if s.selected >= s.scroll+height {
+		s.scroll = s.selected - height + 1
+	}
+
+	for i := s.scroll; i < len(s.results.Results) && len(rows) < height; i++ {
+		result := s.results.Results[i]
+
+		marker := "  "
+		if i == s.selected {
+			marker = s.color.Match("> ")
+		}
+		location := fmt.Sprintf("%s:%d", s.relativePath(result.FilePath), result.StartLine)
+		location = s.fit(location, s.width/2)
+
+		line, matches := resultSnippet(result)
+		line, matches = expandTabs(line, matches)
+		line, matches = lib.TruncateAround(line, matches, s.width-len(location)-4)
+
+		pathStyle := s.color.Path
+		if i == s.selected {
+			pathStyle = s.color.Heading
+		}
+		rows = append(rows, fmt.Sprintf("%s%s  %s", marker, pathStyle(location), s.color.Snippet(line, result.Language, matches)))
+	}
+	return rows
+}
+
+// previewRows renders the file of the selected result around its lines
+func (s *tuiSession) previewRows(height int) []string {
+	rows := make([]string, 0, height)
+	if height < 2 || s.results == nil || len(s.results.Results) == 0 {
+		return rows
+	}
+	result := s.results.Results[s.selected]
+
+	header := "── " + s.fit(fmt.Sprintf("%s:%d-%d ", s.relativePath(result.FilePath), result.StartLine, result.EndLine), s.width-3)
+	if pad := s.width - utf8.RuneCountInString(header); pad > 0 {
+		header += strings.Repeat("─", pad)
+	}
+	rows = append(rows, s.color.Dim(header))
+
+	lines, err := s.fileLines(result.FilePath)
+	if err != nil {
+		return append(rows, s.color.Match(s.fit(err.Error(), s.width)))
+	}
+
+	// Show the match a third of the way down the pane
+	first := result.StartLine - (height-1)/3
+	if first < 1 {
+		first = 1
+	}
+	for number := first; number <= len(lines) && len(rows) < height; number++ {
+		marker := " "
+		if number >= result.StartLine && number <= result.EndLine {
+			marker = ">"
+		}
+		prefix := fmt.Sprintf("%5d%s ", number, marker)
+
+		line := lines[number-1]
+		line, matches := expandTabs(line, columnRanges(line, result.Spans, number))
+		line, matches = lib.TruncateAround(line, matches, s.width-len(prefix))
+		rows = append(rows, s.color.LineNumber(prefix)+s.color.Snippet(line, result.Language, matches))
+	}
+	return rows
+}
+
+// fileLines returns the lines of a file, read once per session
+func (s *tuiSession) fileLines(path string) ([]string, error) {
+	if lines, exists := s.files[path]; exists {
+		return lines, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	s.files[path] = lines
+	return lines, nil
+}
+
+// relativePath shortens a path inside the working directory to a relative one
+func (s *tuiSession) relativePath(path string) string {
+	if relative, err := filepath.Rel(s.root, path); err == nil && !strings.HasPrefix(relative, "..") {
+		return relative
+	}
+	return path
+}
+
+// fit cuts plain text to a width, keeping its start
+func (s *tuiSession) fit(text string, width int) string {
+	if width < 1 {
+		return ""
+	}
+	fitted, _ := lib.TruncateAround(text, nil, width)
+	return fitted
+}
+
+// expandTabs replaces tabs with four spaces, moving the byte ranges in matches with the text
+func expandTabs(line string, matches [][2]int) (string, [][2]int) {
+	if !strings.Contains(line, "\t") {
+		return line, matches
+	}
+
+	var b strings.Builder
+	offsets := make([]int, len(line)+1)
+	for i := 0; i < len(line); i++ {
+		offsets[i] = b.Len()
+		if line[i] == '\t' {
+			b.WriteString("    ")
+		} else {
+			b.WriteByte(line[i])
+		}
+	}
+	offsets[len(line)] = b.Len()
+
+	moved := make([][2]int, len(matches))
+	for i, match := range matches {
+		moved[i] = [2]int{offsets[match[0]], offsets[match[1]]}
+	}
+	return b.String(), moved
+}
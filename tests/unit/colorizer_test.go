@@ -0,0 +1,124 @@
+package unit
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"code-search/src/lib"
+)
+
+// TestParseColorMode tests parsing --color values
+func TestParseColorMode(t *testing.T) {
+	for _, value := range []string{"auto", "always", "never", "ALWAYS"} {
+		if _, err := lib.ParseColorMode(value); err != nil {
+			t.Errorf("Expected %q to parse, got %v", value, err)
+		}
+	}
+	if _, err := lib.ParseColorMode("sometimes"); err == nil {
+		t.Error("Expected an error for an unknown color mode")
+	}
+}
+
+// TestUseColor tests deciding whether to color output
+func TestUseColor(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer file.Close()
+
+	t.Run("Always and never are followed", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+		if !lib.UseColor(lib.ColorAlways, file) {
+			t.Error("Expected always to color even with NO_COLOR set")
+		}
+		if lib.UseColor(lib.ColorNever, os.Stdout) {
+			t.Error("Expected never not to color")
+		}
+	})
+
+	t.Run("Auto does not color files", func(t *testing.T) {
+		if lib.UseColor(lib.ColorAuto, file) {
+			t.Error("Expected no color when writing to a file")
+		}
+	})
+}
+
+// TestColorizer_Snippet tests highlighting matches and tokens in a line of code
+func TestColorizer_Snippet(t *testing.T) {
+	line := `	return fmt.Errorf("retry %d", 3) // give up`
+
+	t.Run("Disabled colorizer leaves text unchanged", func(t *testing.T) {
+		if got := lib.NewColorizer(false).Snippet(line, "Go", [][2]int{{8, 11}}); got != line {
+			t.Errorf("Expected unchanged line, got %q", got)
+		}
+	})
+
+	t.Run("Matches and tokens are styled", func(t *testing.T) {
+		start := strings.Index(line, "Errorf")
+		got := lib.NewColorizer(true).Snippet(line, "Go", [][2]int{{start, start + len("Errorf")}})
+
+		if !strings.Contains(got, "\x1b[1m\x1b[31mErrorf\x1b[0m") {
+			t.Errorf("Expected the match to be highlighted, got %q", got)
+		}
+		if !strings.Contains(got, "\x1b[34mreturn\x1b[0m") {
+			t.Errorf("Expected the keyword to be colored, got %q", got)
+		}
+		if !strings.Contains(got, "\x1b[33m\"retry %d\"\x1b[0m") {
+			t.Errorf("Expected the string to be colored, got %q", got)
+		}
+		if !strings.Contains(got, "\x1b[90m// give up\x1b[0m") {
+			t.Errorf("Expected the comment to be colored, got %q", got)
+		}
+		if stripped := stripANSI(got); stripped != line {
+			t.Errorf("Expected styling to keep the text, got %q", stripped)
+		}
+	})
+}
+
+// TestTruncateAround tests shortening long lines around their first match
+func TestTruncateAround(t *testing.T) {
+	t.Run("Short lines are kept", func(t *testing.T) {
+		line, matches := lib.TruncateAround("short line", [][2]int{{6, 10}}, 40)
+		if line != "short line" || matches[0] != [2]int{6, 10} {
+			t.Errorf("Expected the line unchanged, got %q %v", line, matches)
+		}
+	})
+
+	t.Run("Window is centered on the match", func(t *testing.T) {
+		text := strings.Repeat("a", 100) + "needle" + strings.Repeat("b", 100)
+		line, matches := lib.TruncateAround(text, [][2]int{{100, 106}}, 20)
+
+		if !strings.HasPrefix(line, "…") || !strings.HasSuffix(line, "…") {
+			t.Errorf("Expected both ends to be marked, got %q", line)
+		}
+		if len(matches) != 1 || line[matches[0][0]:matches[0][1]] != "needle" {
+			t.Errorf("Expected the match to move with the window, got %v in %q", matches, line)
+		}
+	})
+
+	t.Run("Cuts keep runes whole", func(t *testing.T) {
+		text := strings.Repeat("é", 50) + "needle"
+		line, _ := lib.TruncateAround(text, [][2]int{{100, 106}}, 15)
+		if !strings.HasSuffix(line, "needle") || !utf8.ValidString(line) {
+			t.Errorf("Expected a valid line ending in the match, got %q", line)
+		}
+	})
+}
+
+// stripANSI removes ANSI escape sequences from text
+func stripANSI(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\x1b' {
+			for i < len(text) && text[i] != 'm' {
+				i++
+			}
+			continue
+		}
+		b.WriteByte(text[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,174 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"code-search/src/lib"
+	"code-search/src/services"
+)
+
+// TestParseSimpleYAML tests reading the YAML subset used by configuration files
+func TestParseSimpleYAML(t *testing.T) {
+	t.Run("Mappings, lists and scalars", func(t *testing.T) {
+		values, err := lib.ParseSimpleYAML([]byte(`# comment
+index:
+  include: ["src/**", 'cmd/**']   # trailing comment
+  exclude:
+    - vendor/
+    - "a # b"
+  chunker: ast
+search:
+  context_lines: 5
+`))
+		if err != nil {
+			t.Fatalf("ParseSimpleYAML failed: %v", err)
+		}
+
+		if got := values["index.include"].List; !reflect.DeepEqual(got, []string{"src/**", "cmd/**"}) {
+			t.Errorf("Unexpected include %v", got)
+		}
+		if got := values["index.exclude"]; !reflect.DeepEqual(got.List, []string{"vendor/", "a # b"}) || got.Line != 5 {
+			t.Errorf("Unexpected exclude %+v", got)
+		}
+		if got := values["index.chunker"]; got.Scalar != "ast" || got.IsList || got.Line != 7 {
+			t.Errorf("Unexpected chunker %+v", got)
+		}
+		if got := values["search.context_lines"].Scalar; got != "5" {
+			t.Errorf("Unexpected context lines %q", got)
+		}
+	})
+
+	t.Run("Invalid documents", func(t *testing.T) {
+		for _, document := range []string{
+			"index\n",
+			"- item\n",
+			"format: \"json\nx: 1\n",
+			"format: json\nformat: table\n",
+			"include: [a, b\n",
+		} {
+			if _, err := lib.ParseSimpleYAML([]byte(document)); err == nil {
+				t.Errorf("Expected an error for %q", document)
+			}
+		}
+	})
+}
+
+// TestMatchGlob tests glob matching against repository-relative paths
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*.go", "src/a/main.go", true},
+		{"*.go", "main.py", false},
+		{"src/**", "src/a/main.go", true},
+		{"src/**", "lib/src/main.go", false},
+		{"src/**", "src", false},
+		{"**/*_test.go", "a/b/x_test.go", true},
+		{"**/*_test.go", "x_test.go", true},
+		{"vendor/", "vendor/x/y.go", true},
+		{"vendor/", "src/vendor/y.go", false},
+		{"src/*/main.go", "src/a/main.go", true},
+		{"src/*/main.go", "src/a/b/main.go", false},
+		{"/cmd/**/*.go", "cmd/tool/main.go", true},
+	}
+
+	for _, tt := range tests {
+		if got := lib.MatchGlob(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+// TestLoadConfig tests layering user, project and environment configuration
+func TestLoadConfig(t *testing.T) {
+	write := func(t *testing.T, path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	configHome := t.TempDir()
+	project := t.TempDir()
+	nested := filepath.Join(project, "src", "pkg")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("Failed to create %s: %v", nested, err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	write(t, filepath.Join(configHome, "code-search", "config.yaml"),
+		"search:\n  format: csv\n  context_lines: 1\nembedding:\n  model: user-model\n")
+	write(t, filepath.Join(project, ".code-search.yaml"),
+		"index:\n  include: [src/**]\n  max_file_size: 2MB\nsearch:\n  format: json\n")
+
+	t.Run("Defaults without files", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		config, err := services.LoadConfig(t.TempDir())
+		if err != nil {
+			t.Fatalf("LoadConfig failed: %v", err)
+		}
+		if config.Format != "table" || config.Chunker != "simple" || len(config.Files()) != 0 {
+			t.Errorf("Unexpected defaults %+v", config)
+		}
+		if source, _ := config.Source("search.format"); source != services.ConfigSourceDefault {
+			t.Errorf("Expected the default source, got %s", source)
+		}
+	})
+
+	t.Run("Project files override user files", func(t *testing.T) {
+		config, err := services.LoadConfig(nested)
+		if err != nil {
+			t.Fatalf("LoadConfig failed: %v", err)
+		}
+		if len(config.Files()) != 2 {
+			t.Fatalf("Expected the user and project files, got %v", config.Files())
+		}
+		if config.Format != "json" || config.ContextLines != 1 || config.Model != "user-model" {
+			t.Errorf("Unexpected layering %+v", config)
+		}
+		if config.MaxFileSize != 2<<20 || !reflect.DeepEqual(config.IndexingOptions().IncludePatterns, []string{"src/**"}) {
+			t.Errorf("Unexpected index settings %+v", config.IndexingOptions())
+		}
+		if source, origin := config.Source("search.format"); source != services.ConfigSourceProject || origin != filepath.Join(project, ".code-search.yaml")+":5" {
+			t.Errorf("Unexpected source %s (%s)", source, origin)
+		}
+	})
+
+	t.Run("Environment overrides files", func(t *testing.T) {
+		t.Setenv("CODE_SEARCH_FORMAT", "raw")
+		t.Setenv("CODE_SEARCH_EXCLUDE", "dist/, *.min.js")
+		config, err := services.LoadConfig(nested)
+		if err != nil {
+			t.Fatalf("LoadConfig failed: %v", err)
+		}
+		if config.Format != "raw" || !reflect.DeepEqual(config.Exclude, []string{"dist/", "*.min.js"}) {
+			t.Errorf("Unexpected environment layering %+v", config)
+		}
+		if source, origin := config.Source("index.exclude"); source != services.ConfigSourceEnv || origin != "CODE_SEARCH_EXCLUDE" {
+			t.Errorf("Unexpected source %s (%s)", source, origin)
+		}
+	})
+
+	t.Run("Invalid settings fail", func(t *testing.T) {
+		for _, content := range []string{
+			"index:\n  chunkr: ast\n",
+			"index:\n  chunker: tree\n",
+			"search:\n  semantic_weight: 2\n",
+			"index:\n  max_file_size: big\n",
+		} {
+			dir := t.TempDir()
+			write(t, filepath.Join(dir, ".code-search.yml"), content)
+			if _, err := services.LoadConfig(dir); err == nil {
+				t.Errorf("Expected an error for %q", content)
+			}
+		}
+	})
+}
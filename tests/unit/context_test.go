@@ -0,0 +1,116 @@
+package unit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code-search/src/models"
+)
+
+// TestFileEntry_GetContextLines tests context served from stored chunks and from the file
+func TestFileEntry_GetContextLines(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(filePath, []byte("package main\n\nfunc a() {\n\tb()\n}\n\nfunc b() {}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	entry, err := models.NewFileEntry(filePath)
+	if err != nil {
+		t.Fatalf("Failed to create file entry: %v", err)
+	}
+	// The stored chunk differs from the file, as if the file changed after indexing
+	entry.AddChunk(*models.NewCodeChunk("func a() {\n\tb(1)\n}", 3, 5, "Go"))
+
+	format := func(lines []models.ContextLine) string {
+		return fmt.Sprint(lines)
+	}
+
+	t.Run("Stored chunk content", func(t *testing.T) {
+		before, after, err := entry.GetContextLines(5, 5, 1, 0)
+		if err != nil {
+			t.Fatalf("GetContextLines failed: %v", err)
+		}
+		if format(before) != "[{4 \tb(1)}]" || len(after) != 0 {
+			t.Errorf("Expected the stored line 4, got %v and %v", before, after)
+		}
+	})
+
+	t.Run("File content outside stored chunks", func(t *testing.T) {
+		before, after, err := entry.GetContextLines(4, 4, 2, 3)
+		if err != nil {
+			t.Fatalf("GetContextLines failed: %v", err)
+		}
+		if format(before) != "[{2 } {3 func a() {}]" || format(after) != "[{5 }} {6 } {7 func b() {}}]" {
+			t.Errorf("Unexpected context %v and %v", before, after)
+		}
+	})
+
+	t.Run("Context is clipped at file boundaries", func(t *testing.T) {
+		before, _, err := entry.GetContextLines(1, 1, 3, 0)
+		if err != nil || len(before) != 0 {
+			t.Errorf("Expected no lines before line 1, got %v (%v)", before, err)
+		}
+	})
+}
+
+// TestSearchService_Context tests -A, -B and -C context on search results
+func TestSearchService_Context(t *testing.T) {
+	indexPath := createSearchTestIndex(t, map[string]string{
+		"main.go": "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n",
+	})
+	searchService := newTestSearchService()
+
+	search := func(t *testing.T, before, after int, withContext bool) *models.SearchResult {
+		t.Helper()
+		query := models.NewSearchQuery("Println")
+		query.SearchType = models.SearchTypeExact
+		query.ContextBefore = before
+		query.ContextAfter = after
+		query.IncludeContext = withContext
+
+		results, err := searchService.Search(query, indexPath)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(results.Results) != 1 {
+			t.Fatalf("Expected 1 result, got %d", len(results.Results))
+		}
+		return results.Results[0]
+	}
+
+	t.Run("Before and after counts", func(t *testing.T) {
+		result := search(t, 2, 1, false)
+		if len(result.ContextBefore) != 2 || result.ContextBefore[0].Line != 4 || result.ContextBefore[1].Text != "func main() {" {
+			t.Errorf("Unexpected context before: %+v", result.ContextBefore)
+		}
+		if len(result.ContextAfter) != 1 || result.ContextAfter[0].Line != 7 || result.ContextAfter[0].Text != "}" {
+			t.Errorf("Unexpected context after: %+v", result.ContextAfter)
+		}
+		if result.Context != "" {
+			t.Errorf("Expected no joined context string, got %q", result.Context)
+		}
+	})
+
+	t.Run("With context uses the default on both sides", func(t *testing.T) {
+		result := search(t, 0, 0, true)
+		if len(result.ContextBefore) != 3 || len(result.ContextAfter) != 1 {
+			t.Errorf("Expected 3 lines before and the 1 remaining line after, got %+v and %+v", result.ContextBefore, result.ContextAfter)
+		}
+	})
+
+	t.Run("No context by default", func(t *testing.T) {
+		if result := search(t, 0, 0, false); result.HasContextLines() {
+			t.Errorf("Expected no context, got %+v and %+v", result.ContextBefore, result.ContextAfter)
+		}
+	})
+
+	t.Run("Negative counts are rejected", func(t *testing.T) {
+		query := models.NewSearchQuery("Println")
+		query.ContextAfter = -1
+		if err := query.Validate(); err == nil {
+			t.Error("Expected a validation error")
+		}
+	})
+}
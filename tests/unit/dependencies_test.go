@@ -0,0 +1,148 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"code-search/src/lib"
+	"code-search/src/services"
+)
+
+// dependencyTestFiles imports across files in each supported language
+var dependencyTestFiles = map[string]string{
+	"go.mod":                 "module example.com/d\n",
+	"main.go":                "package main\n\nimport (\n\t\"fmt\"\n\t\"example.com/d/pkg/util\"\n)\n\nfunc main() { fmt.Println(util.X) }\n",
+	"pkg/util/util.go":       "package util\n\nimport \"example.com/d/pkg/base\"\n\nconst X = base.Y\n",
+	"pkg/base/base.go":       "package base\n\nconst Y = 1\n",
+	"web/index.ts":           "import { a } from './lib/a';\nconst b = require('./lib/b.js');\nimport React from 'react';\n",
+	"web/lib/a.ts":           "export const a = 1;\n",
+	"web/lib/b.js":           "module.exports = 2;\n",
+	"py/app/main.py":         "from . import helpers\nfrom app.models import User\nimport os\n",
+	"py/app/helpers.py":      "x = 1\n",
+	"py/app/models.py":       "class User: pass\n",
+	"java/com/x/A.java":      "package com.x;\n\nimport com.x.util.B;\n\nclass A {}\n",
+	"java/com/x/util/B.java": "package com.x.util;\n\nclass B {}\n",
+	"rs/src/main.rs":         "mod config;\nuse crate::config::Settings;\nuse std::io;\n\nfn main() {}\n",
+	"rs/src/config.rs":       "pub struct Settings;\n",
+	"c/main.c":               "#include <stdio.h>\n#include \"util.h\"\n",
+	"c/util.h":               "int util(void);\n",
+}
+
+// buildDependencyTestGraph writes the test files and builds their dependency graph
+func buildDependencyTestGraph(t *testing.T) (*lib.IncrementalIndexer, string) {
+	t.Helper()
+
+	rootDir := t.TempDir()
+	var files []string
+	for name, content := range dependencyTestFiles {
+		path := filepath.Join(rootDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		if name != "go.mod" {
+			files = append(files, path)
+		}
+	}
+
+	indexer := lib.NewIncrementalIndexer(filepath.Join(rootDir, ".clindex"), lib.DefaultIncrementalOptions())
+	indexer.BuildDependencyGraph(rootDir, files)
+	return indexer, rootDir
+}
+
+// TestIncrementalIndexer_BuildDependencyGraph tests import extraction and resolution per language
+func TestIncrementalIndexer_BuildDependencyGraph(t *testing.T) {
+	indexer, _ := buildDependencyTestGraph(t)
+
+	tests := []struct {
+		file     string
+		expected []string
+	}{
+		{"main.go", []string{"pkg/util/util.go"}},
+		{"web/index.ts", []string{"web/lib/a.ts", "web/lib/b.js"}},
+		{"py/app/main.py", []string{"py/app/helpers.py", "py/app/models.py"}},
+		{"java/com/x/A.java", []string{"java/com/x/util/B.java"}},
+		{"rs/src/main.rs", []string{"rs/src/config.rs"}},
+		{"c/main.c", []string{"c/util.h"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			deps, exists := indexer.GetDependencies(tt.file)
+			if !exists {
+				t.Fatalf("Expected %s in the dependency graph", tt.file)
+			}
+			if !reflect.DeepEqual(deps.Files, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, deps.Files)
+			}
+		})
+	}
+
+	t.Run("Importers", func(t *testing.T) {
+		deps, _ := indexer.GetDependencies("pkg/util/util.go")
+		if !reflect.DeepEqual(deps.ReferencedBy, []string{"main.go"}) {
+			t.Errorf("Expected util.go to be imported by main.go, got %v", deps.ReferencedBy)
+		}
+		if dependents := indexer.GetDependents([]string{"pkg/base/base.go"}); !reflect.DeepEqual(dependents, []string{"pkg/util/util.go"}) {
+			t.Errorf("Expected only the direct importer, got %v", dependents)
+		}
+	})
+}
+
+// TestIncrementalIndexer_TraverseDependencies tests depth-limited and reverse traversal
+func TestIncrementalIndexer_TraverseDependencies(t *testing.T) {
+	indexer, _ := buildDependencyTestGraph(t)
+
+	t.Run("Depth limit", func(t *testing.T) {
+		if edges := indexer.TraverseDependencies("main.go", false, 1); len(edges) != 1 {
+			t.Errorf("Expected 1 edge at depth 1, got %+v", edges)
+		}
+		edges := indexer.TraverseDependencies("main.go", false, 0)
+		if len(edges) != 2 || edges[1].From != "pkg/util/util.go" || edges[1].To != "pkg/base/base.go" || edges[1].Depth != 2 {
+			t.Errorf("Unexpected unlimited traversal: %+v", edges)
+		}
+	})
+
+	t.Run("Reverse keeps import direction", func(t *testing.T) {
+		edges := indexer.TraverseDependencies("pkg/base/base.go", true, 0)
+		if len(edges) != 2 || edges[0].From != "pkg/util/util.go" || edges[1].From != "main.go" || edges[1].Depth != 2 {
+			t.Errorf("Unexpected reverse traversal: %+v", edges)
+		}
+	})
+}
+
+// TestDependencyService_GetDependencies tests queries against a saved dependency graph
+func TestDependencyService_GetDependencies(t *testing.T) {
+	indexer, rootDir := buildDependencyTestGraph(t)
+	service := services.NewDependencyService(&services.SilentLogger{})
+
+	t.Run("Missing graph", func(t *testing.T) {
+		if _, err := service.GetDependencies(rootDir, services.DependencyQuery{File: "main.go"}); err == nil {
+			t.Error("Expected an error before the graph is saved")
+		}
+	})
+
+	if err := indexer.SaveMetadata(); err != nil {
+		t.Fatalf("Failed to save dependency graph: %v", err)
+	}
+
+	t.Run("Reverse query", func(t *testing.T) {
+		result, err := service.GetDependencies(rootDir, services.DependencyQuery{File: "pkg/base/base.go", Reverse: true, Depth: 1})
+		if err != nil {
+			t.Fatalf("GetDependencies failed: %v", err)
+		}
+		if len(result.Files) != 1 || result.Files[0].Path != "pkg/util/util.go" {
+			t.Errorf("Unexpected importers: %+v", result.Files)
+		}
+	})
+
+	t.Run("Unknown file", func(t *testing.T) {
+		if _, err := service.GetDependencies(rootDir, services.DependencyQuery{File: "missing.go"}); err == nil {
+			t.Error("Expected an error for a file outside the graph")
+		}
+	})
+}
@@ -144,6 +144,15 @@ func TestFileUtilities_ValidatePathSecurity(t *testing.T) {
 			t.Error("Expected error for path outside allowed base")
 		}
 	})
+
+	t.Run("Sibling sharing the base prefix fails", func(t *testing.T) {
+		tempDir := t.TempDir()
+		siblingPath := filepath.Join(tempDir+"-sibling", "file.go")
+
+		if err := fileUtils.ValidatePathSecurity(siblingPath, []string{tempDir}); err == nil {
+			t.Error("Expected error for a sibling directory sharing the base prefix")
+		}
+	})
 }
 
 // TestFileUtilities_DirectoryOperations tests directory-related operations
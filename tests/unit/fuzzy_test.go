@@ -0,0 +1,74 @@
+package unit
+
+import (
+	"testing"
+
+	"code-search/src/lib"
+)
+
+// TestDamerauLevenshtein tests edit distance with adjacent transpositions
+func TestDamerauLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"UserAuth", "UserAuth", 0},
+		{"UsreAuth", "UserAuth", 1},
+		{"userauth", "UserAuth", 2},
+		{"UserAth", "UserAuth", 1},
+		{"UsrAth", "UserAuth", 2},
+		{"", "abc", 3},
+	}
+
+	for _, tt := range tests {
+		if got := lib.DamerauLevenshtein(tt.a, tt.b, -1); got != tt.expected {
+			t.Errorf("DamerauLevenshtein(%q, %q) = %d, expected %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+
+	t.Run("Cutoff", func(t *testing.T) {
+		if got := lib.DamerauLevenshtein("abcdef", "uvwxyz", 2); got != 3 {
+			t.Errorf("Expected cutoff result 3, got %d", got)
+		}
+	})
+}
+
+// TestSubsequenceScore tests fzf-style subsequence scoring
+func TestSubsequenceScore(t *testing.T) {
+	t.Run("Positions", func(t *testing.T) {
+		_, positions, ok := lib.SubsequenceScore("usvc", "UserService", false)
+		if !ok {
+			t.Fatal("Expected subsequence match")
+		}
+
+		expected := []int{0, 1, 7, 9}
+		if len(positions) != len(expected) {
+			t.Fatalf("Expected positions %v, got %v", expected, positions)
+		}
+		for i := range expected {
+			if positions[i] != expected[i] {
+				t.Fatalf("Expected positions %v, got %v", expected, positions)
+			}
+		}
+	})
+
+	t.Run("Boundaries score higher", func(t *testing.T) {
+		camel, _, _ := lib.SubsequenceScore("ua", "UserAuth", false)
+		inner, _, _ := lib.SubsequenceScore("ua", "squash", false)
+		if camel <= inner {
+			t.Errorf("Expected camelCase match to outscore inner match: %f <= %f", camel, inner)
+		}
+	})
+
+	t.Run("No match", func(t *testing.T) {
+		if _, _, ok := lib.SubsequenceScore("xyz", "UserAuth", false); ok {
+			t.Error("Expected no match")
+		}
+	})
+
+	t.Run("Case sensitive", func(t *testing.T) {
+		if _, _, ok := lib.SubsequenceScore("usa", "UserAuth", true); ok {
+			t.Error("Expected no case-sensitive match")
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"code-search/src/lib"
+	"code-search/src/services"
+)
+
+// TestIgnoreRules tests .gitignore, .ignore and .codesearchignore handling
+func TestIgnoreRules(t *testing.T) {
+	write := func(t *testing.T, path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	// Keep the user's global excludes file out of the tests
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".git", "info"), 0755); err != nil {
+		t.Fatalf("Failed to create .git: %v", err)
+	}
+	write(t, filepath.Join(root, ".git", "info", "exclude"), "*.secret.go\n")
+	write(t, filepath.Join(root, ".gitignore"), "# build output\nbuild/\n*.gen.go\n!keep.gen.go\n/root_only.go\ndocs/**/draft.go\n")
+	write(t, filepath.Join(root, ".ignore"), "legacy.go\n")
+	write(t, filepath.Join(root, ".codesearchignore"), "!legacy.go\n")
+	write(t, filepath.Join(root, "pkg", ".gitignore"), "!*.gen.go\nlocal.go\n")
+
+	for _, file := range []string{
+		"main.go", "a.gen.go", "keep.gen.go", "root_only.go", "sub/root_only.go",
+		"build/out.go", "sub/build/out.go", "docs/a/b/draft.go", "docs/final.go",
+		"pkg/b.gen.go", "pkg/local.go", "local.go", "legacy.go", "x.secret.go",
+		"build.go",
+	} {
+		write(t, filepath.Join(root, file), "package x\n")
+	}
+
+	t.Run("Ignored paths", func(t *testing.T) {
+		rules := lib.NewIgnoreRules(root)
+		tests := []struct {
+			path string
+			want bool
+		}{
+			{"main.go", false},
+			{"a.gen.go", true},
+			{"keep.gen.go", false},
+			{"root_only.go", true},
+			{"sub/root_only.go", false},
+			{"build/out.go", true},
+			{"sub/build/out.go", true},
+			{"build.go", false},
+			{"docs/a/b/draft.go", true},
+			{"docs/final.go", false},
+			{"pkg/b.gen.go", false},
+			{"pkg/local.go", true},
+			{"local.go", false},
+			{"legacy.go", false},
+			{"x.secret.go", true},
+		}
+		for _, tt := range tests {
+			if got := rules.Ignored(filepath.Join(root, tt.path), false); got != tt.want {
+				t.Errorf("Ignored(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		}
+		if !rules.Ignored(filepath.Join(root, "build"), true) {
+			t.Error("Expected the build directory to be ignored")
+		}
+	})
+
+	t.Run("Negation cannot re-include files of an ignored directory", func(t *testing.T) {
+		dir := t.TempDir()
+		write(t, filepath.Join(dir, ".gitignore"), "out/\n!out/keep.go\n")
+		write(t, filepath.Join(dir, "out", "keep.go"), "package out\n")
+
+		if !lib.NewIgnoreRules(dir).Ignored(filepath.Join(dir, "out", "keep.go"), false) {
+			t.Error("Expected out/keep.go to stay ignored")
+		}
+	})
+
+	t.Run("Negation re-includes files matched by a trailing **", func(t *testing.T) {
+		dir := t.TempDir()
+		write(t, filepath.Join(dir, ".gitignore"), "gen/**\n!gen/keep.go\n")
+		write(t, filepath.Join(dir, "gen", "keep.go"), "package gen\n")
+		write(t, filepath.Join(dir, "gen", "drop.go"), "package gen\n")
+
+		rules := lib.NewIgnoreRules(dir)
+		if rules.Ignored(filepath.Join(dir, "gen"), true) {
+			t.Error("Expected the gen directory itself not to be ignored")
+		}
+		if rules.Ignored(filepath.Join(dir, "gen", "keep.go"), false) {
+			t.Error("Expected gen/keep.go to be re-included")
+		}
+		if !rules.Ignored(filepath.Join(dir, "gen", "drop.go"), false) {
+			t.Error("Expected gen/drop.go to stay ignored")
+		}
+	})
+
+	t.Run("Scanners skip ignored files", func(t *testing.T) {
+		options := services.DefaultIndexingOptions()
+		options.FileTypes = []string{".go"}
+
+		scanned := func(files []string) []string {
+			var rel []string
+			for _, file := range files {
+				path, _ := filepath.Rel(root, file)
+				rel = append(rel, filepath.ToSlash(path))
+			}
+			sort.Strings(rel)
+			return rel
+		}
+		want := []string{"build.go", "docs/final.go", "keep.gen.go", "legacy.go", "local.go", "main.go", "pkg/b.gen.go", "sub/root_only.go"}
+
+		for name, scanner := range map[string]services.FileScanner{
+			"basic":     lib.NewFileSystemScanner(),
+			"optimized": lib.NewOptimizedFileSystemScanner(),
+		} {
+			files, err := scanner.ScanFiles(root, options)
+			if err != nil {
+				t.Fatalf("%s: ScanFiles failed: %v", name, err)
+			}
+			if got := scanned(files); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: expected %v, got %v", name, want, got)
+			}
+		}
+
+		options.NoIgnore = true
+		files, err := lib.NewFileSystemScanner().ScanFiles(root, options)
+		if err != nil {
+			t.Fatalf("ScanFiles failed: %v", err)
+		}
+		if len(files) != 15 {
+			t.Errorf("Expected every file with --no-ignore, got %v", scanned(files))
+		}
+	})
+}
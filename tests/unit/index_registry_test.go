@@ -0,0 +1,124 @@
+package unit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code-search/src/lib"
+	"code-search/src/services"
+)
+
+// TestIndexRegistry tests recording and forgetting indexes
+func TestIndexRegistry(t *testing.T) {
+	t.Run("Default path follows XDG_DATA_HOME", func(t *testing.T) {
+		dataHome := t.TempDir()
+		t.Setenv("XDG_DATA_HOME", dataHome)
+		if got, want := lib.DefaultRegistryPath(), filepath.Join(dataHome, "code-search", "indexes.json"); got != want {
+			t.Errorf("Expected %s, got %s", want, got)
+		}
+	})
+
+	t.Run("Register, replace and unregister", func(t *testing.T) {
+		registry := lib.NewIndexRegistry(filepath.Join(t.TempDir(), "code-search", "indexes.json"))
+
+		entries, err := registry.Entries()
+		if err != nil || len(entries) != 0 {
+			t.Fatalf("Expected an empty registry, got %v: %v", entries, err)
+		}
+
+		for _, entry := range [][2]string{
+			{"/src/b", "/src/b/.clindex/data.index"},
+			{"/src/a", "/src/a/.code-search-index"},
+			{"/src/b", "/src/b/.clindex/data.index"},
+		} {
+			if err := registry.Register(entry[0], entry[1]); err != nil {
+				t.Fatalf("Register failed: %v", err)
+			}
+		}
+
+		entries, err = registry.Entries()
+		if err != nil {
+			t.Fatalf("Entries failed: %v", err)
+		}
+		if len(entries) != 2 || entries[0].Directory != "/src/a" || entries[1].Directory != "/src/b" {
+			t.Fatalf("Expected one entry per index ordered by directory, got %+v", entries)
+		}
+		if entries[1].LastIndexed.IsZero() {
+			t.Error("Expected the index time to be recorded")
+		}
+
+		if err := registry.Unregister("/src/a/.code-search-index"); err != nil {
+			t.Fatalf("Unregister failed: %v", err)
+		}
+		entries, _ = registry.Entries()
+		if len(entries) != 1 || entries[0].Directory != "/src/b" {
+			t.Errorf("Expected only /src/b to remain, got %+v", entries)
+		}
+	})
+
+	t.Run("Corrupt registry fails", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "indexes.json")
+		if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+			t.Fatalf("Failed to write registry: %v", err)
+		}
+		if _, err := lib.NewIndexRegistry(path).Entries(); err == nil {
+			t.Error("Expected an error for a corrupt registry")
+		}
+	})
+}
+
+// TestIndexingService_DeleteDirectoryIndex tests deleting indexes while respecting locks
+func TestIndexingService_DeleteDirectoryIndex(t *testing.T) {
+	repo := t.TempDir()
+	source := filepath.Join(repo, "main.go")
+	if err := os.WriteFile(source, []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", source, err)
+	}
+
+	indexingService := services.NewIndexingService(
+		lib.NewFileSystemScanner(),
+		lib.NewSimpleCodeParser(),
+		lib.NewMockVectorStore(),
+		&services.SilentLogger{},
+		services.DefaultIndexingOptions(),
+	)
+	if _, err := indexingService.IndexDirectory(repo, false, nil); err != nil {
+		t.Fatalf("IndexDirectory failed: %v", err)
+	}
+	if _, err := indexingService.IndexRepository(repo, filepath.Join(repo, ".code-search-index"), false, nil); err != nil {
+		t.Fatalf("IndexRepository failed: %v", err)
+	}
+
+	fileUtils := lib.NewFileUtilities()
+	t.Run("Locked indexes are kept", func(t *testing.T) {
+		lockFile, err := fileUtils.AcquireLock(repo)
+		if err != nil {
+			t.Fatalf("AcquireLock failed: %v", err)
+		}
+		defer fileUtils.ReleaseLock(lockFile)
+
+		// The lock is per open file, so a second descriptor conflicts even in one process
+		if err := indexingService.DeleteDirectoryIndex(repo); !errors.Is(err, services.ErrIndexLocked) {
+			t.Errorf("Expected ErrIndexLocked, got %v", err)
+		}
+		if !fileUtils.DirectoryExists(filepath.Join(repo, ".clindex")) {
+			t.Error("Expected the locked index to be kept")
+		}
+	})
+
+	t.Run("Index files are removed", func(t *testing.T) {
+		if err := indexingService.DeleteDirectoryIndex(repo); err != nil {
+			t.Fatalf("DeleteDirectoryIndex failed: %v", err)
+		}
+		for _, name := range []string{".clindex", ".code-search-index"} {
+			if _, err := os.Stat(filepath.Join(repo, name)); !os.IsNotExist(err) {
+				t.Errorf("Expected %s to be removed, got %v", name, err)
+			}
+		}
+		if _, err := os.Stat(source); err != nil {
+			t.Errorf("Expected sources to be kept: %v", err)
+		}
+	})
+}
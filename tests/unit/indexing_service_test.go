@@ -0,0 +1,297 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"code-search/src/lib"
+	"code-search/src/models"
+	"code-search/src/services"
+)
+
+// TestIndexingService_IndexFiles tests reindexing single files of an indexed repository
+func TestIndexingService_IndexFiles(t *testing.T) {
+	repo := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(repo, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	kept := write("kept.go", "package a\n\n// kept\n")
+	changed := write("changed.go", "package a\n\n// before\n")
+	indexPath := filepath.Join(repo, ".code-search-index")
+
+	indexingService := services.NewIndexingService(
+		lib.NewFileSystemScanner(),
+		lib.NewSimpleCodeParser(),
+		lib.NewMockVectorStore(),
+		&services.SilentLogger{},
+		services.DefaultIndexingOptions(),
+	)
+	if _, err := indexingService.IndexRepository(repo, indexPath, false, nil); err != nil {
+		t.Fatalf("IndexRepository failed: %v", err)
+	}
+
+	// contentHash returns the hash the index records for a file
+	contentHash := func(t *testing.T, path string) string {
+		t.Helper()
+		index, err := models.LoadCodeIndex(indexPath, lib.NewMockVectorStore())
+		if err != nil {
+			t.Fatalf("Failed to load index: %v", err)
+		}
+		defer index.Close()
+		entry, err := index.GetFileEntry(path)
+		if err != nil {
+			return ""
+		}
+		return entry.ContentHash
+	}
+
+	t.Run("Changed files are reindexed", func(t *testing.T) {
+		before := contentHash(t, changed)
+		write("changed.go", "package a\n\n// after\n")
+		result, err := indexingService.IndexFiles(repo, indexPath, []string{changed})
+		if err != nil || result.FilesIndexed != 1 {
+			t.Fatalf("Expected 1 file reindexed, got %+v: %v", result, err)
+		}
+
+		if after := contentHash(t, changed); after == "" || after == before {
+			t.Errorf("Expected a new hash for changed.go, got %q (was %q)", after, before)
+		}
+		if contentHash(t, kept) == "" {
+			t.Error("Expected kept.go to stay indexed")
+		}
+	})
+
+	t.Run("Deleted files are removed", func(t *testing.T) {
+		os.Remove(changed)
+		if _, err := indexingService.IndexFiles(repo, indexPath, []string{changed}); err != nil {
+			t.Fatalf("IndexFiles failed: %v", err)
+		}
+		if contentHash(t, changed) != "" {
+			t.Error("Expected changed.go to be removed from the index")
+		}
+	})
+
+	t.Run("Repositories without an index fail", func(t *testing.T) {
+		if _, err := indexingService.IndexFiles(repo, filepath.Join(repo, "missing"), []string{kept}); err == nil {
+			t.Error("Expected an error without an index")
+		}
+	})
+}
+
+// TestIndexingService_Filters tests that index filters are applied, stored and reused
+func TestIndexingService_Filters(t *testing.T) {
+	repo := t.TempDir()
+	for name, content := range map[string]string{
+		"main.go":         "package main\n\n// main\nfunc main() {}\n",
+		"util.py":         "# util\ndef util():\n    return 1\n",
+		"gen/types.go":    "package gen\n\n// types\ntype T struct{}\n",
+		".hidden/skip.go": "package hidden\n\n// skip\nfunc Skip() {}\n",
+	} {
+		path := filepath.Join(repo, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	indexPath := filepath.Join(repo, ".code-search-index")
+
+	index := func(t *testing.T, options models.IndexingOptions) *models.CodeIndex {
+		t.Helper()
+		indexingService := services.NewIndexingService(
+			lib.NewFileSystemScanner(),
+			lib.NewSimpleCodeParser(),
+			lib.NewMockVectorStore(),
+			&services.SilentLogger{},
+			options,
+		)
+		if _, err := indexingService.IndexRepository(repo, indexPath, false, nil); err != nil {
+			t.Fatalf("IndexRepository failed: %v", err)
+		}
+		codeIndex, err := models.LoadCodeIndex(indexPath, lib.NewMockVectorStore())
+		if err != nil {
+			t.Fatalf("Failed to load index: %v", err)
+		}
+		return codeIndex
+	}
+	indexed := func(codeIndex *models.CodeIndex) map[string]bool {
+		files := make(map[string]bool)
+		for _, entry := range codeIndex.GetAllFiles() {
+			rel, _ := filepath.Rel(repo, entry.FilePath)
+			files[filepath.ToSlash(rel)] = true
+		}
+		return files
+	}
+
+	t.Run("Filters are applied and stored", func(t *testing.T) {
+		options := services.DefaultIndexingOptions()
+		options.FileTypes = []string{"*.go"}
+		options.ExcludePatterns = []string{"gen/"}
+		options.FilterOverrides = []string{"exclude_patterns", "file_types"}
+
+		codeIndex := index(t, options)
+		files := indexed(codeIndex)
+		if len(files) != 1 || !files["main.go"] {
+			t.Errorf("Expected only main.go, got %v", files)
+		}
+		if codeIndex.Filters == nil || len(codeIndex.Filters.FileTypes) != 1 || codeIndex.Filters.FileTypes[0] != "*.go" {
+			t.Errorf("Expected the filters to be stored, got %+v", codeIndex.Filters)
+		}
+	})
+
+	t.Run("Later runs reuse stored filters", func(t *testing.T) {
+		codeIndex := index(t, services.DefaultIndexingOptions())
+		if files := indexed(codeIndex); len(files) != 1 || !files["main.go"] {
+			t.Errorf("Expected the stored filters to be reused, got %v", files)
+		}
+	})
+
+	t.Run("Overridden filters replace stored ones", func(t *testing.T) {
+		options := services.DefaultIndexingOptions()
+		options.FileTypes = []string{".py"}
+		options.FilterOverrides = []string{"file_types"}
+
+		codeIndex := index(t, options)
+		if files := indexed(codeIndex); len(files) != 1 || !files["util.py"] {
+			t.Errorf("Expected only util.py after overriding file types, got %v", files)
+		}
+		if excluded := codeIndex.Filters.ExcludePatterns; len(excluded) != 1 || excluded[0] != "gen/" {
+			t.Errorf("Expected the stored exclude patterns to be kept, got %v", excluded)
+		}
+	})
+
+	t.Run("Single files honor stored filters", func(t *testing.T) {
+		indexingService := services.NewIndexingService(
+			lib.NewFileSystemScanner(),
+			lib.NewSimpleCodeParser(),
+			lib.NewMockVectorStore(),
+			&services.SilentLogger{},
+			services.DefaultIndexingOptions(),
+		)
+		result, err := indexingService.IndexFiles(repo, indexPath, []string{filepath.Join(repo, "gen", "types.go")})
+		if err != nil || result.FilesIndexed != 0 || result.FilesSkipped != 1 {
+			t.Errorf("Expected the excluded file to be skipped, got %+v: %v", result, err)
+		}
+	})
+}
+
+// TestIndexingService_GetIndexHealth tests comparing an index with the files on disk
+func TestIndexingService_GetIndexHealth(t *testing.T) {
+	repo := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(repo, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	write("kept.go", "package a\n\nfunc Kept() {}\n")
+	touched := write("touched.go", "package a\n\nfunc Touched() {}\n")
+	changed := write("changed.go", "package a\n\nfunc Before() {}\n")
+	deleted := write("deleted.go", "package a\n\nfunc Deleted() {}\n")
+	indexPath := filepath.Join(repo, ".code-search-index")
+
+	indexingService := services.NewIndexingService(
+		lib.NewFileSystemScanner(),
+		lib.NewSimpleCodeParser(),
+		lib.NewMockVectorStore(),
+		&services.SilentLogger{},
+		services.DefaultIndexingOptions(),
+	)
+
+	t.Run("Missing index", func(t *testing.T) {
+		health, err := indexingService.GetIndexHealth(indexPath)
+		if err != nil {
+			t.Fatalf("GetIndexHealth failed: %v", err)
+		}
+		if health.Exists || !health.NeedsReindex() {
+			t.Errorf("Expected a missing index to need reindexing, got %+v", health)
+		}
+	})
+
+	if _, err := indexingService.IndexRepository(repo, indexPath, false, nil); err != nil {
+		t.Fatalf("IndexRepository failed: %v", err)
+	}
+
+	t.Run("Fresh index", func(t *testing.T) {
+		health, err := indexingService.GetIndexHealth(indexPath)
+		if err != nil {
+			t.Fatalf("GetIndexHealth failed: %v", err)
+		}
+		if !health.Exists || health.NeedsReindex() || health.Format != "json" {
+			t.Errorf("Expected a fresh JSON index, got %+v", health)
+		}
+		if health.FileCount != 4 || health.Languages["Go"].Files != 4 || health.ChunkCount != health.Languages["Go"].Chunks {
+			t.Errorf("Unexpected counts %+v", health)
+		}
+	})
+
+	t.Run("Changes on disk", func(t *testing.T) {
+		later := time.Now().Add(time.Hour)
+		if err := os.Chtimes(touched, later, later); err != nil {
+			t.Fatalf("Failed to touch %s: %v", touched, err)
+		}
+		write("changed.go", "package a\n\nfunc After() {}\n")
+		if err := os.Chtimes(changed, later, later); err != nil {
+			t.Fatalf("Failed to touch %s: %v", changed, err)
+		}
+		os.Remove(deleted)
+		added := write("added.go", "package a\n\nfunc Added() {}\n")
+		write("notes.tmp", "excluded by the default filters\n")
+
+		health, err := indexingService.GetIndexHealth(indexPath)
+		if err != nil {
+			t.Fatalf("GetIndexHealth failed: %v", err)
+		}
+		if !reflect.DeepEqual(health.StaleFiles, []string{changed}) {
+			t.Errorf("Expected only changed.go to be stale, got %v", health.StaleFiles)
+		}
+		if !reflect.DeepEqual(health.NewFiles, []string{added}) {
+			t.Errorf("Expected added.go to be new, got %v", health.NewFiles)
+		}
+		if !reflect.DeepEqual(health.DeletedFiles, []string{deleted}) {
+			t.Errorf("Expected deleted.go to be deleted, got %v", health.DeletedFiles)
+		}
+		if !health.NeedsReindex() {
+			t.Error("Expected the index to need reindexing")
+		}
+	})
+}
+
+// TestIndexingService_IndexDirectory tests the metadata recorded next to a directory index
+func TestIndexingService_IndexDirectory(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write main.go: %v", err)
+	}
+
+	t.Run("Records the embedding model", func(t *testing.T) {
+		options := services.DefaultIndexingOptions()
+		options.EmbeddingModel = "minilm"
+		indexingService := services.NewIndexingService(
+			lib.NewFileSystemScanner(),
+			lib.NewSimpleCodeParser(),
+			lib.NewMockVectorStore(),
+			&services.SilentLogger{},
+			options,
+		)
+		result, err := indexingService.IndexDirectory(repo, false, nil)
+		if err != nil {
+			t.Fatalf("IndexDirectory failed: %v", err)
+		}
+
+		metadata, err := lib.LoadMetadata(result.IndexPath)
+		if err != nil {
+			t.Fatalf("LoadMetadata failed: %v", err)
+		}
+		if metadata.ModelName != "minilm" || metadata.ModelVersion != lib.DefaultModelVersion {
+			t.Errorf("Expected model minilm %s, got %s %s", lib.DefaultModelVersion, metadata.ModelName, metadata.ModelVersion)
+		}
+	})
+}
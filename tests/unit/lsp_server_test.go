@@ -0,0 +1,237 @@
+package unit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"code-search/src/lib"
+	"code-search/src/services"
+)
+
+// lspFrame frames a message the way LSP clients send it
+func lspFrame(message string) string {
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(message), message)
+}
+
+// readLSPReplies reads every framed message the server wrote
+func readLSPReplies(t *testing.T, output string) []map[string]interface{} {
+	t.Helper()
+	reader := bufio.NewReader(strings.NewReader(output))
+	var replies []map[string]interface{}
+	for {
+		headers, err := textproto.NewReader(reader).ReadMIMEHeader()
+		if err == io.EOF {
+			return replies
+		}
+		if err != nil {
+			t.Fatalf("Invalid headers in %q: %v", output, err)
+		}
+		length, _ := strconv.Atoi(headers.Get("Content-Length"))
+		body := make([]byte, length)
+		if _, err := io.ReadFull(reader, body); err != nil {
+			t.Fatalf("Short body: %v", err)
+		}
+		var reply map[string]interface{}
+		if err := json.Unmarshal(body, &reply); err != nil {
+			t.Fatalf("Invalid JSON %q: %v", body, err)
+		}
+		replies = append(replies, reply)
+	}
+}
+
+// TestLSPServer tests the language server's requests and notifications
+func TestLSPServer(t *testing.T) {
+	indexPath := createSearchTestIndex(t, map[string]string{
+		"greet.go": "package greet\n\n// 日本 😀 TODO wave\nfunc Hello() {}\n",
+	})
+	root := filepath.Dir(filepath.Dir(indexPath))
+
+	var mu sync.Mutex
+	var reindexed [][]string
+	reindex := func(files []string) (*services.IndexingResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		reindexed = append(reindexed, files)
+		return &services.IndexingResult{Success: true, FilesIndexed: len(files)}, nil
+	}
+
+	var openedRoot string
+	open := func(workspace string) (*services.SearchServer, error) {
+		openedRoot = workspace
+		return services.NewSearchServer(root, indexPath, newTestSearchService(), reindex, &services.SilentLogger{}), nil
+	}
+	symbolService := services.NewSymbolService(lib.NewMockVectorStore(), &services.SilentLogger{})
+
+	messages := []string{
+		`{"jsonrpc": "2.0", "id": 1, "method": "workspace/symbol", "params": {"query": "Hello"}}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "initialize", "params": {"rootUri": "file://` + root + `"}}`,
+		`{"jsonrpc": "2.0", "method": "initialized", "params": {}}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "workspace/symbol", "params": {"query": "Hel"}}`,
+		`{"jsonrpc": "2.0", "id": 4, "method": "codeSearch/search", "params": {"query": "TODO", "searchType": "exact"}}`,
+		`{"jsonrpc": "2.0", "id": 5, "method": "codeSearch/search", "params": {"query": "(TODO OR FIXME", "searchType": "text"}}`,
+		`{"jsonrpc": "2.0", "method": "textDocument/didSave", "params": {"textDocument": {"uri": "file://` + root + `/greet.go"}}}`,
+		`{"jsonrpc": "2.0", "method": "textDocument/didSave", "params": {"textDocument": {"uri": "file:///etc/passwd"}}}`,
+		`{"jsonrpc": "2.0", "id": 6, "method": "textDocument/hover", "params": {}}`,
+		`{"jsonrpc": "2.0", "id": 7, "method": "shutdown"}`,
+		`{"jsonrpc": "2.0", "method": "exit"}`,
+	}
+	var input strings.Builder
+	for _, message := range messages {
+		input.WriteString(lspFrame(message))
+	}
+
+	var output strings.Builder
+	server := services.NewLSPServer(open, symbolService, "test", &services.SilentLogger{})
+	if err := server.Serve(context.Background(), strings.NewReader(input.String()), &output); err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+
+	replies := make(map[float64]map[string]interface{})
+	for _, reply := range readLSPReplies(t, output.String()) {
+		if id, ok := reply["id"].(float64); ok {
+			replies[id] = reply
+		}
+	}
+	errorCode := func(id float64) float64 {
+		if err, ok := replies[id]["error"].(map[string]interface{}); ok {
+			return err["code"].(float64)
+		}
+		return 0
+	}
+
+	t.Run("Requests before initialize fail", func(t *testing.T) {
+		if code := errorCode(1); code != -32002 {
+			t.Errorf("Expected ServerNotInitialized, got %v", replies[1])
+		}
+		if openedRoot != root {
+			t.Errorf("Expected the workspace %s to be opened, got %s", root, openedRoot)
+		}
+	})
+
+	t.Run("Workspace symbols have UTF-16 positions", func(t *testing.T) {
+		symbols, _ := replies[3]["result"].([]interface{})
+		if len(symbols) != 1 {
+			t.Fatalf("Expected Hello, got %v", replies[3])
+		}
+		symbol := symbols[0].(map[string]interface{})
+		start := symbol["location"].(map[string]interface{})["range"].(map[string]interface{})["start"].(map[string]interface{})
+		if symbol["name"] != "Hello" || symbol["kind"] != float64(12) || start["line"] != float64(3) || start["character"] != float64(5) {
+			t.Errorf("Unexpected symbol %v", symbol)
+		}
+	})
+
+	t.Run("Search results count columns in UTF-16 code units", func(t *testing.T) {
+		result := replies[4]["result"].(map[string]interface{})
+		results := result["results"].([]interface{})
+		if len(results) != 1 {
+			t.Fatalf("Expected one result, got %v", result)
+		}
+		location := results[0].(map[string]interface{})["location"].(map[string]interface{})
+		span := location["range"].(map[string]interface{})
+		start := span["start"].(map[string]interface{})
+		end := span["end"].(map[string]interface{})
+		// "// 日本 😀 " is 3 + 2 + 1 + 2 + 1 code units
+		if start["line"] != float64(2) || start["character"] != float64(9) || end["character"] != float64(13) {
+			t.Errorf("Unexpected range %v", span)
+		}
+		if location["uri"] != "file://"+root+"/greet.go" {
+			t.Errorf("Unexpected URI %v", location["uri"])
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		if code := errorCode(5); code != -32602 {
+			t.Errorf("Expected invalid params for a syntax error, got %v", replies[5])
+		}
+		if code := errorCode(6); code != -32601 {
+			t.Errorf("Expected method not found, got %v", replies[6])
+		}
+		if reply, ok := replies[7]; !ok || reply["result"] != nil || errorCode(7) != 0 {
+			t.Errorf("Expected a null shutdown result, got %v", reply)
+		}
+	})
+
+	t.Run("Saved files inside the workspace are reindexed", func(t *testing.T) {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(reindexed) != 1 || len(reindexed[0]) != 1 || reindexed[0][0] != filepath.Join(root, "greet.go") {
+			t.Errorf("Expected greet.go to be reindexed alone, got %v", reindexed)
+		}
+	})
+}
+
+// TestLSPServer_ExitWithoutShutdown tests that exiting without shutdown is an error
+func TestLSPServer_ExitWithoutShutdown(t *testing.T) {
+	server := services.NewLSPServer(nil, nil, "test", &services.SilentLogger{})
+	input := lspFrame(`{"jsonrpc": "2.0", "method": "exit"}`)
+	if err := server.Serve(context.Background(), strings.NewReader(input), io.Discard); err == nil {
+		t.Error("Expected an error when exiting without shutdown")
+	}
+}
+
+// TestLSPServer_FailedReindex tests that saved files survive a failed reindex
+func TestLSPServer_FailedReindex(t *testing.T) {
+	indexPath := createSearchTestIndex(t, map[string]string{
+		"a.go": "package a\n\nfunc A() {}\n",
+		"b.go": "package a\n\nfunc B() {}\n",
+	})
+	root := filepath.Dir(filepath.Dir(indexPath))
+
+	var mu sync.Mutex
+	var reindexed [][]string
+	failed := make(chan struct{})
+	reindex := func(files []string) (*services.IndexingResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		reindexed = append(reindexed, files)
+		if len(reindexed) == 1 {
+			defer close(failed)
+			return nil, errors.New("disk full")
+		}
+		return &services.IndexingResult{Success: true, FilesIndexed: len(files)}, nil
+	}
+	open := func(workspace string) (*services.SearchServer, error) {
+		return services.NewSearchServer(root, indexPath, newTestSearchService(), reindex, &services.SilentLogger{}), nil
+	}
+	save := func(name string) string {
+		return lspFrame(`{"jsonrpc": "2.0", "method": "textDocument/didSave", "params": {"textDocument": {"uri": "file://` + filepath.Join(root, name) + `"}}}`)
+	}
+
+	input, writer := io.Pipe()
+	server := services.NewLSPServer(open, nil, "test", &services.SilentLogger{})
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(context.Background(), input, io.Discard)
+	}()
+
+	io.WriteString(writer, lspFrame(`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"rootUri": "file://`+root+`"}}`))
+	io.WriteString(writer, save("a.go"))
+	<-failed
+	io.WriteString(writer, save("b.go"))
+	io.WriteString(writer, lspFrame(`{"jsonrpc": "2.0", "id": 2, "method": "shutdown"}`))
+	io.WriteString(writer, lspFrame(`{"jsonrpc": "2.0", "method": "exit"}`))
+	writer.Close()
+	if err := <-done; err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := [][]string{
+		{filepath.Join(root, "a.go")},
+		{filepath.Join(root, "a.go"), filepath.Join(root, "b.go")},
+	}
+	if fmt.Sprint(reindexed) != fmt.Sprint(expected) {
+		t.Errorf("Expected a.go to be reindexed again with b.go, got %v", reindexed)
+	}
+}
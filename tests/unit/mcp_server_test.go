@@ -0,0 +1,141 @@
+package unit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code-search/src/lib"
+	"code-search/src/services"
+)
+
+// mcpReply is a JSON-RPC response read back from the MCP server
+type mcpReply struct {
+	ID     json.RawMessage `json:"id"`
+	Result struct {
+		ProtocolVersion   string            `json:"protocolVersion"`
+		Tools             []json.RawMessage `json:"tools"`
+		Content           []struct{ Text string }
+		StructuredContent map[string]interface{} `json:"structuredContent"`
+		IsError           bool                   `json:"isError"`
+	} `json:"result"`
+	Error *struct {
+		Code int `json:"code"`
+	} `json:"error"`
+}
+
+// TestMCPServer tests the MCP protocol handling and tools
+func TestMCPServer(t *testing.T) {
+	indexPath := createSearchTestIndex(t, map[string]string{
+		"api/handler.go": "package api\n\n// TODO handle retries\nfunc HandleRequest() {}\n",
+		"store/store.go": "package store\n\n// TODO close the store\ntype Store struct{}\n",
+	})
+	root := filepath.Dir(filepath.Dir(indexPath))
+	if err := os.WriteFile(filepath.Join(filepath.Dir(root), "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatalf("Failed to write file outside the root: %v", err)
+	}
+
+	server := services.NewSearchServer(root, indexPath, newTestSearchService(), nil, &services.SilentLogger{})
+	if err := server.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	defer server.Close()
+	symbolService := services.NewSymbolService(lib.NewMockVectorStore(), &services.SilentLogger{})
+	mcpServer := services.NewMCPServer(server, symbolService, "test", &services.SilentLogger{})
+
+	// exchange sends messages, one per line, and returns the responses by id
+	exchange := func(t *testing.T, messages ...string) map[string]mcpReply {
+		t.Helper()
+		var out strings.Builder
+		if err := mcpServer.Serve(context.Background(), strings.NewReader(strings.Join(messages, "\n")), &out); err != nil {
+			t.Fatalf("Serve failed: %v", err)
+		}
+
+		replies := make(map[string]mcpReply)
+		scanner := bufio.NewScanner(strings.NewReader(out.String()))
+		for scanner.Scan() {
+			var reply mcpReply
+			if err := json.Unmarshal(scanner.Bytes(), &reply); err != nil {
+				t.Fatalf("Invalid response %q: %v", scanner.Text(), err)
+			}
+			replies[string(reply.ID)] = reply
+		}
+		return replies
+	}
+
+	t.Run("Initialize and list tools", func(t *testing.T) {
+		replies := exchange(t,
+			`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"protocolVersion": "2025-03-26"}}`,
+			`{"jsonrpc": "2.0", "method": "notifications/initialized"}`,
+			`{"jsonrpc": "2.0", "id": 2, "method": "tools/list"}`,
+		)
+		if len(replies) != 2 {
+			t.Fatalf("Expected no response to the notification, got %d responses", len(replies))
+		}
+		if version := replies["1"].Result.ProtocolVersion; version != "2025-03-26" {
+			t.Errorf("Expected the client's protocol version, got %q", version)
+		}
+		if tools := replies["2"].Result.Tools; len(tools) != 5 {
+			t.Errorf("Expected 5 tools, got %d", len(tools))
+		}
+	})
+
+	t.Run("Tools return structured results", func(t *testing.T) {
+		replies := exchange(t,
+			`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "search_code", "arguments": {"query": "TODO", "search_type": "exact", "dir": "store"}}}`,
+			`{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": {"name": "list_symbols", "arguments": {"pattern": "Handle*"}}}`,
+			`{"jsonrpc": "2.0", "id": 3, "method": "tools/call", "params": {"name": "get_file_snippet", "arguments": {"path": "store/store.go", "start_line": 3, "end_line": 4}}}`,
+			`{"jsonrpc": "2.0", "id": 4, "method": "tools/call", "params": {"name": "index_status"}}`,
+		)
+
+		if result := replies["1"].Result; result.IsError || result.StructuredContent["displayed"] != float64(1) {
+			t.Errorf("Expected one search result in store, got %+v", result)
+		}
+		if result := replies["2"].Result; result.IsError || result.StructuredContent["total"] != float64(1) {
+			t.Errorf("Expected HandleRequest, got %+v", result)
+		}
+		if content := replies["3"].Result.StructuredContent["content"]; content != "// TODO close the store\ntype Store struct{}" {
+			t.Errorf("Unexpected snippet %q", content)
+		}
+		if count := replies["4"].Result.StructuredContent["file_count"]; count != float64(2) {
+			t.Errorf("Expected 2 indexed files, got %v", count)
+		}
+		if text := replies["1"].Result.Content; len(text) != 1 || !strings.Contains(text[0].Text, "close the store") {
+			t.Errorf("Expected the result as JSON text too, got %+v", text)
+		}
+	})
+
+	t.Run("Tool failures are reported in the result", func(t *testing.T) {
+		replies := exchange(t,
+			`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "get_file_snippet", "arguments": {"path": "../secret.txt"}}}`,
+			`{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": {"name": "search_code", "arguments": {"query": "(TODO OR FIXME", "search_type": "text"}}}`,
+			`{"jsonrpc": "2.0", "id": 3, "method": "tools/call", "params": {"name": "search_code", "arguments": {"query": "TODO", "unknown": true}}}`,
+		)
+		for id, reply := range replies {
+			if !reply.Result.IsError || len(reply.Result.Content) == 0 {
+				t.Errorf("Expected a tool error for request %s, got %+v", id, reply)
+			}
+		}
+		if text := replies["2"].Result.Content[0].Text; !strings.Contains(text, "column 1") {
+			t.Errorf("Expected the error column, got %q", text)
+		}
+	})
+
+	t.Run("Protocol errors", func(t *testing.T) {
+		replies := exchange(t,
+			`not json`,
+			`{"jsonrpc": "2.0", "id": 1, "method": "resources/list"}`,
+			`{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": {"name": "missing"}}`,
+		)
+		expected := map[string]int{"null": -32700, "1": -32601, "2": -32602}
+		for id, code := range expected {
+			if reply := replies[id]; reply.Error == nil || reply.Error.Code != code {
+				t.Errorf("Expected error %d for request %s, got %+v", code, id, reply)
+			}
+		}
+	})
+}
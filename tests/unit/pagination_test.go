@@ -0,0 +1,301 @@
+package unit
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"code-search/src/lib"
+	"code-search/src/models"
+	"code-search/src/services"
+)
+
+// TestSearchCursor tests encoding and decoding page cursors
+func TestSearchCursor(t *testing.T) {
+	query := models.NewSearchQuery("handler")
+
+	t.Run("Round trip", func(t *testing.T) {
+		offset, err := models.DecodeCursor(query, models.EncodeCursor(query, 40))
+		if err != nil || offset != 40 {
+			t.Errorf("Expected offset 40, got %d (%v)", offset, err)
+		}
+	})
+
+	t.Run("Page size and offset do not change the query", func(t *testing.T) {
+		next := query.Clone()
+		next.MaxResults = 50
+		next.Offset = 10
+		if _, err := models.DecodeCursor(next, models.EncodeCursor(query, 40)); err != nil {
+			t.Errorf("Expected the cursor to apply, got %v", err)
+		}
+	})
+
+	t.Run("Cursor of another query", func(t *testing.T) {
+		other := models.NewSearchQuery("handler")
+		other.SearchType = models.SearchTypeExact
+		if _, err := models.DecodeCursor(other, models.EncodeCursor(query, 40)); err == nil {
+			t.Error("Expected an error for a cursor of another query")
+		}
+	})
+
+	t.Run("Malformed cursor", func(t *testing.T) {
+		if _, err := models.DecodeCursor(query, "not a cursor"); err == nil {
+			t.Error("Expected an error for a malformed cursor")
+		}
+	})
+}
+
+// TestSearchService_Pagination tests paging through results with offsets and cursors
+func TestSearchService_Pagination(t *testing.T) {
+	var content strings.Builder
+	content.WriteString("package p\n")
+	for i := 1; i <= 25; i++ {
+		fmt.Fprintf(&content, "\n// TODO item %d\n", i)
+	}
+	indexPath := createSearchTestIndex(t, map[string]string{"todo.go": content.String()})
+	searchService := newTestSearchService()
+
+	search := func(t *testing.T, offset int) *models.SearchResults {
+		t.Helper()
+		query := models.NewSearchQuery("TODO")
+		query.SearchType = models.SearchTypeExact
+		query.MaxResults = 10
+		query.Offset = offset
+
+		results, err := searchService.Search(query, indexPath)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		return results
+	}
+
+	t.Run("Pages cover every result once", func(t *testing.T) {
+		seen := make(map[int]bool)
+		offset := 0
+		for page := 0; page < 3; page++ {
+			results := search(t, offset)
+			if results.TotalResults != 25 || results.Offset != offset {
+				t.Fatalf("Unexpected page totals: %d results at offset %d", results.TotalResults, results.Offset)
+			}
+			for i, result := range results.Results {
+				if result.Rank != offset+i+1 {
+					t.Errorf("Expected rank %d, got %d", offset+i+1, result.Rank)
+				}
+				seen[result.StartLine] = true
+			}
+			if page < 2 {
+				if !results.HasMore {
+					t.Fatalf("Expected more results after page %d", page)
+				}
+				next, err := models.DecodeCursor(results.Query, results.NextCursor)
+				if err != nil {
+					t.Fatalf("DecodeCursor failed: %v", err)
+				}
+				offset = next
+			} else if results.HasMore || results.NextCursor != "" || len(results.Results) != 5 {
+				t.Errorf("Expected a last page of 5 results, got %d (has more %t)", len(results.Results), results.HasMore)
+			}
+		}
+		if len(seen) != 25 {
+			t.Errorf("Expected 25 distinct results, got %d", len(seen))
+		}
+	})
+
+	t.Run("Offset past the end", func(t *testing.T) {
+		results := search(t, 100)
+		if len(results.Results) != 0 || results.HasMore || results.TotalResults != 25 {
+			t.Errorf("Expected an empty last page, got %d results", len(results.Results))
+		}
+	})
+
+	t.Run("Sort orders the whole ranking before paging", func(t *testing.T) {
+		files := make(map[string]string)
+		for i := 1; i <= 12; i++ {
+			files[fmt.Sprintf("f%02d.go", i)] = fmt.Sprintf("package p\n// TODO item %d\n", i)
+		}
+		sortIndex := createSearchTestIndex(t, files)
+
+		query := models.NewSearchQuery("TODO")
+		query.SearchType = models.SearchTypeExact
+		query.MaxResults = 3
+		query.SortOrder = "path"
+		results, err := searchService.Search(query, sortIndex)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+
+		var got []string
+		for _, result := range results.Results {
+			got = append(got, filepath.Base(result.FilePath))
+		}
+		if want := []string{"f01.go", "f02.go", "f03.go"}; strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("Expected the first files by path %v, got %v", want, got)
+		}
+		if !results.HasMore || results.TotalResults != 12 {
+			t.Errorf("Expected more of 12 results, got %d (has more %t)", results.TotalResults, results.HasMore)
+		}
+	})
+
+	t.Run("Negative offsets are rejected", func(t *testing.T) {
+		query := models.NewSearchQuery("TODO")
+		query.Offset = -1
+		if err := query.Validate(); err == nil {
+			t.Error("Expected a validation error")
+		}
+	})
+}
+
+// TestSearchService_Stream tests exhaustive streaming of lexical results
+func TestSearchService_Stream(t *testing.T) {
+	indexPath := createSearchTestIndex(t, map[string]string{
+		"b.go": "package p\n\n// TODO b\n",
+		"a.go": "package p\n\n// TODO a1\n\n// TODO a2\n",
+	})
+	searchService := newTestSearchService()
+
+	t.Run("Results arrive in path and line order", func(t *testing.T) {
+		query := models.NewSearchQuery("TODO")
+		query.SearchType = models.SearchTypeExact
+		query.MaxResults = 1
+
+		var streamed []string
+		summary, err := searchService.Stream(query, indexPath, func(result *models.SearchResult) error {
+			streamed = append(streamed, result.Content)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Stream failed: %v", err)
+		}
+		if strings.Join(streamed, ",") != "// TODO a1,// TODO a2,// TODO b" {
+			t.Errorf("Unexpected stream order: %v", streamed)
+		}
+		if summary.TotalResults != 3 || len(summary.Results) != 0 {
+			t.Errorf("Expected a summary of 3 results without the results, got %d and %d", summary.TotalResults, len(summary.Results))
+		}
+	})
+
+	t.Run("Emit errors stop the stream", func(t *testing.T) {
+		query := models.NewSearchQuery("TODO")
+		query.SearchType = models.SearchTypeExact
+
+		calls := 0
+		_, err := searchService.Stream(query, indexPath, func(result *models.SearchResult) error {
+			calls++
+			return fmt.Errorf("closed")
+		})
+		if err == nil || calls != 1 {
+			t.Errorf("Expected the stream to stop after one result, got %d calls (%v)", calls, err)
+		}
+	})
+
+	t.Run("Semantic searches cannot stream", func(t *testing.T) {
+		query := models.NewSearchQuery("TODO")
+		query.SearchType = models.SearchTypeSemantic
+		if _, err := searchService.Stream(query, indexPath, func(*models.SearchResult) error { return nil }); err == nil {
+			t.Error("Expected an error for a semantic stream")
+		}
+	})
+}
+
+// TestQueryCache_Window tests that cached rankings only serve pages they cover
+func TestQueryCache_Window(t *testing.T) {
+	cache := lib.NewQueryCache(10, 10, time.Minute)
+	defer cache.Clear()
+
+	query := models.NewSearchQuery(fmt.Sprintf("cache window %d", time.Now().UnixNano()))
+	query.SearchType = models.SearchTypeSemantic
+	query.MaxResults = 10
+	cache.Put("index", query, models.NewSearchResults(query))
+
+	next := query.Clone()
+	next.Offset = 5
+	next.MaxResults = 5
+	if _, found := cache.Get("index", next); !found {
+		t.Error("Expected the cached ranking to serve a page inside its window")
+	}
+
+	next.Offset = 10
+	if _, found := cache.Get("index", next); !found {
+		t.Error("Expected a later page to be served from the ranked window")
+	}
+
+	next.Offset = models.RankedWindow
+	if _, found := cache.Get("index", next); found {
+		t.Error("Expected a page past the ranked window to miss the cache")
+	}
+
+	lexical := query.Clone()
+	lexical.SearchType = models.SearchTypeExact
+	cache.Put("index", lexical, models.NewSearchResults(lexical))
+	lexical.Offset = 500
+	if _, found := cache.Get("index", lexical); !found {
+		t.Error("Expected lexical rankings to serve every page")
+	}
+
+	if _, found := cache.Get("other index", lexical); found {
+		t.Error("Expected rankings of one index not to serve another")
+	}
+}
+
+// countingParser counts the query embeddings a search computes
+type countingParser struct {
+	*lib.SimpleCodeParser
+	embeddings int
+}
+
+func (p *countingParser) GetEmbedding(text string) ([]float64, error) {
+	p.embeddings++
+	return p.SimpleCodeParser.GetEmbedding(text)
+}
+
+// TestSearchService_CachedSemanticPages tests that later pages of a semantic search are
+// sliced from the cached ranking instead of being scored again
+func TestSearchService_CachedSemanticPages(t *testing.T) {
+	indexPath := createSearchTestIndex(t, map[string]string{"todo.go": "package p\n"})
+	store := lib.NewMockVectorStore()
+	for i := 1; i <= 15; i++ {
+		store.Insert(fmt.Sprintf("chunk-%d", i), []float64{1}, map[string]interface{}{
+			"file_path":  filepath.Join(filepath.Dir(filepath.Dir(indexPath)), "todo.go"),
+			"start_line": float64(i),
+			"end_line":   float64(i),
+			"content":    fmt.Sprintf("// TODO item %d", i),
+			"language":   "Go",
+		})
+	}
+	parser := &countingParser{SimpleCodeParser: lib.NewSimpleCodeParser()}
+	searchService := services.NewSearchService(parser, store, &services.SilentLogger{}, services.DefaultSearchOptions())
+
+	query := models.NewSearchQuery(fmt.Sprintf("pending work %d", time.Now().UnixNano()))
+	query.SearchType = models.SearchTypeSemantic
+	query.MaxResults = 10
+
+	first, err := searchService.Search(query, indexPath)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	next := query.Clone()
+	next.Offset = 10
+	second, err := searchService.Search(next, indexPath)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if parser.embeddings != 1 {
+		t.Errorf("Expected the second page to reuse the cached ranking, got %d embeddings", parser.embeddings)
+	}
+	if first.TotalResults != 15 || len(first.Results) != 10 || len(second.Results) != 5 {
+		t.Fatalf("Expected pages of 10 and 5 of 15 results, got %d and %d of %d", len(first.Results), len(second.Results), first.TotalResults)
+	}
+	seen := make(map[int]bool)
+	for _, result := range append(first.Results, second.Results...) {
+		seen[result.StartLine] = true
+	}
+	if len(seen) != 15 {
+		t.Errorf("Expected the pages to cover 15 distinct results, got %d", len(seen))
+	}
+	if second.Results[0].Rank != 11 {
+		t.Errorf("Expected the second page to start at rank 11, got %d", second.Results[0].Rank)
+	}
+}
@@ -0,0 +1,165 @@
+package unit
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"code-search/src/models"
+)
+
+// TestSearchQuery_ParseStructuredQuery tests field filters and boolean syntax
+func TestSearchQuery_ParseStructuredQuery(t *testing.T) {
+	t.Run("Filters and expression", func(t *testing.T) {
+		query := models.NewSearchQuery(`lang:go path:internal/ -path:_test.go sym:Handler "exact phrase" (retry OR backoff) -deprecated`)
+		if err := query.ParseStructuredQuery(); err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+
+		if query.LanguageFilter != "go" {
+			t.Errorf("Expected language filter 'go', got %q", query.LanguageFilter)
+		}
+		if !reflect.DeepEqual(query.PathFilters, []string{"internal/"}) || !reflect.DeepEqual(query.ExcludePaths, []string{"_test.go"}) {
+			t.Errorf("Unexpected path filters: %v, excludes: %v", query.PathFilters, query.ExcludePaths)
+		}
+		if !reflect.DeepEqual(query.SymbolFilters, []string{"Handler"}) {
+			t.Errorf("Unexpected symbol filters: %v", query.SymbolFilters)
+		}
+		if query.QueryText != "exact phrase retry backoff" {
+			t.Errorf("Unexpected query text: %q", query.QueryText)
+		}
+		if query.Expression == nil {
+			t.Fatal("Expected a boolean expression")
+		}
+
+		matches := func(words ...string) bool {
+			set := make(map[string]bool)
+			for _, word := range words {
+				set[word] = true
+			}
+			return query.Expression.Matches(func(value string) bool { return set[value] })
+		}
+		if !matches("exact phrase", "backoff") {
+			t.Error("Expected phrase with backoff to match")
+		}
+		if matches("exact phrase", "retry", "deprecated") {
+			t.Error("Expected negated term to exclude the match")
+		}
+		if matches("retry") {
+			t.Error("Expected missing phrase to fail the match")
+		}
+	})
+
+	t.Run("Plain words keep existing behaviour", func(t *testing.T) {
+		query := models.NewSearchQuery("user func(ctx) login")
+		if err := query.ParseStructuredQuery(); err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if query.Expression != nil || query.QueryText != "user func(ctx) login" {
+			t.Errorf("Expected plain query, got text %q and expression %+v", query.QueryText, query.Expression)
+		}
+	})
+
+	plainTests := []struct {
+		name  string
+		query string
+	}{
+		{"Lone minus", "1 - 2"},
+		{"Trailing minus", "total -"},
+		{"Minus before a number", "x = -1"},
+		{"Double minus", "run --verbose"},
+		{"Arrow", "a -> b"},
+		{"Parentheses around words", "if (err != nil)"},
+		{"Unbalanced parentheses", "retry) backoff"},
+		{"Unclosed parenthesis", "call(ctx, retry"},
+		{"Empty parentheses", "foo ()"},
+	}
+
+	for _, tt := range plainTests {
+		t.Run("Plain query: "+tt.name, func(t *testing.T) {
+			query := models.NewSearchQuery(tt.query)
+			if err := query.ParseStructuredQuery(); err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if query.Expression != nil || query.QueryText != tt.query {
+				t.Errorf("Expected plain query %q, got text %q and expression %+v", tt.query, query.QueryText, query.Expression)
+			}
+		})
+	}
+
+	t.Run("Negated group without OR", func(t *testing.T) {
+		query := models.NewSearchQuery("retry -(old backoff)")
+		if err := query.ParseStructuredQuery(); err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if query.Expression == nil || query.QueryText != "retry" {
+			t.Errorf("Expected a negated group, got text %q and expression %+v", query.QueryText, query.Expression)
+		}
+	})
+
+	t.Run("Regex keeps pattern verbatim", func(t *testing.T) {
+		query := models.NewSearchQuery(`lang:go func \w+\(ctx (a|b)`)
+		query.SearchType = models.SearchTypeRegex
+		if err := query.ParseStructuredQuery(); err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if query.QueryText != `func \w+\(ctx (a|b)` || query.LanguageFilter != "go" {
+			t.Errorf("Unexpected regex query: %q (language %q)", query.QueryText, query.LanguageFilter)
+		}
+	})
+
+	t.Run("Semantic search rejects OR", func(t *testing.T) {
+		query := models.NewSearchQuery("login (retry OR backoff)")
+		query.SearchType = models.SearchTypeSemantic
+		var parseErr *models.QueryParseError
+		if err := query.ParseStructuredQuery(); !errors.As(err, &parseErr) || parseErr.Column != 8 {
+			t.Errorf("Expected a parse error at column 8, got %v", err)
+		}
+	})
+
+	t.Run("Repository filters", func(t *testing.T) {
+		query := models.NewSearchQuery("repo:api* -repo:team/api login")
+		if err := query.ParseStructuredQuery(); err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if !reflect.DeepEqual(query.RepoFilters, []string{"api*"}) || !reflect.DeepEqual(query.ExcludeRepos, []string{"team/api"}) {
+			t.Errorf("Unexpected repository filters: %v, excludes: %v", query.RepoFilters, query.ExcludeRepos)
+		}
+
+		for name, want := range map[string]bool{"api": true, "API-gateway": true, "core/api": true, "team/api": false, "web": false} {
+			if got := query.ShouldIncludeRepository(name); got != want {
+				t.Errorf("ShouldIncludeRepository(%q) = %v, want %v", name, got, want)
+			}
+		}
+	})
+
+	errorTests := []struct {
+		name   string
+		query  string
+		column int
+	}{
+		{"Unterminated phrase", `retry "with backoff`, 7},
+		{"Unclosed group", `lang:go (retry OR backoff`, 9},
+		{"Unmatched close", `retry) OR backoff`, 6},
+		{"Missing OR operand", `retry OR`, 9},
+		{"Empty field value", `retry lang:`, 7},
+		{"Field inside group", `(retry OR lang:go)`, 11},
+		{"Negated language", `retry -lang:go`, 8},
+		{"Only negations", `-retry -backoff`, 1},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := models.NewSearchQuery(tt.query)
+			err := query.ParseStructuredQuery()
+
+			var parseErr *models.QueryParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Expected QueryParseError, got %v", err)
+			}
+			if parseErr.Column != tt.column {
+				t.Errorf("Expected column %d, got %d (%s)", tt.column, parseErr.Column, parseErr.Message)
+			}
+		})
+	}
+}
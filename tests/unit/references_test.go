@@ -0,0 +1,147 @@
+package unit
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"code-search/src/lib"
+	"code-search/src/models"
+	"code-search/src/services"
+)
+
+// referenceTestFiles is a two-package module with a cross-package method call
+var referenceTestFiles = map[string]string{
+	"go.mod":           "module example.com/app\n\ngo 1.21\n",
+	"server/server.go": "package server\n\nimport \"strings\"\n\ntype Server struct {\n\tName string\n}\n\nfunc (s *Server) Start() string {\n\treturn strings.ToUpper(s.Name)\n}\n\nfunc New(name string) *Server {\n\treturn &Server{Name: name}\n}\n",
+	"main.go":          "package main\n\nimport \"example.com/app/server\"\n\nfunc run() {\n\ts := server.New(\"api\")\n\ts.Start()\n\tprintln(s.Name)\n}\n\nfunc main() {\n\trun()\n\tvar start = (*server.Server).Start\n\t_ = start\n}\n",
+}
+
+// TestGoReferenceAnalyzer_Analyze tests type-checked definitions, references and calls
+func TestGoReferenceAnalyzer_Analyze(t *testing.T) {
+	indexPath := createSearchTestIndex(t, referenceTestFiles)
+	rootDir := filepath.Dir(filepath.Dir(indexPath))
+
+	files := []string{filepath.Join(rootDir, "main.go"), filepath.Join(rootDir, "server", "server.go")}
+	references, err := lib.NewGoReferenceAnalyzer().Analyze(rootDir, files)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	find := func(file, name string, kind models.ReferenceKind) []models.Reference {
+		var found []models.Reference
+		for _, ref := range references[file] {
+			if ref.Name == name && ref.Kind == kind {
+				found = append(found, ref)
+			}
+		}
+		return found
+	}
+
+	mainFile := filepath.Join(rootDir, "main.go")
+	serverFile := filepath.Join(rootDir, "server", "server.go")
+
+	t.Run("Method definition", func(t *testing.T) {
+		defs := find(serverFile, "Start", models.ReferenceKindDefinition)
+		if len(defs) != 1 || defs[0].Target != "example.com/app/server.Server.Start" || defs[0].Line != 9 || defs[0].Column != 18 {
+			t.Errorf("Unexpected definitions: %+v", defs)
+		}
+	})
+
+	t.Run("Cross-package call", func(t *testing.T) {
+		calls := find(mainFile, "Start", models.ReferenceKindCall)
+		if len(calls) != 1 || calls[0].Line != 7 || calls[0].Caller != "example.com/app.run" {
+			t.Errorf("Unexpected calls: %+v", calls)
+		}
+
+		uses := find(mainFile, "Start", models.ReferenceKindUse)
+		if len(uses) != 1 || uses[0].Line != 13 || uses[0].Caller != "example.com/app.main" {
+			t.Errorf("Expected the method value to be a plain reference, got %+v", uses)
+		}
+	})
+
+	t.Run("Field references", func(t *testing.T) {
+		uses := find(mainFile, "Name", models.ReferenceKindUse)
+		if len(uses) != 1 || uses[0].Target != "example.com/app/server.Server.Name" {
+			t.Errorf("Unexpected field references: %+v", uses)
+		}
+	})
+
+	t.Run("Standard library calls", func(t *testing.T) {
+		calls := find(serverFile, "ToUpper", models.ReferenceKindCall)
+		if len(calls) != 1 || calls[0].Target != "strings.ToUpper" {
+			t.Errorf("Unexpected standard library calls: %+v", calls)
+		}
+	})
+
+	t.Run("Local variables are not tracked", func(t *testing.T) {
+		for _, ref := range references[mainFile] {
+			if ref.Name == "s" || ref.Name == "start" {
+				t.Errorf("Unexpected reference to local variable: %+v", ref)
+			}
+		}
+	})
+}
+
+// TestReferenceService_Find tests refs and callers lookups on a stored index
+func TestReferenceService_Find(t *testing.T) {
+	indexPath := createSearchTestIndex(t, referenceTestFiles)
+	referenceService := services.NewReferenceService(lib.NewMockVectorStore(), &services.SilentLogger{})
+
+	lines := func(results *models.SearchResults) []string {
+		var found []string
+		for _, result := range results.Results {
+			found = append(found, fmt.Sprintf("%s:%d", filepath.Base(result.FilePath), result.StartLine))
+		}
+		return found
+	}
+
+	t.Run("References list the definition first", func(t *testing.T) {
+		results, err := referenceService.FindReferences(indexPath, models.NewSearchQuery("Server.Start"))
+		if err != nil {
+			t.Fatalf("FindReferences failed: %v", err)
+		}
+
+		found := lines(results)
+		if len(found) != 3 || found[0] != "server.go:9" {
+			t.Fatalf("Unexpected references: %v", found)
+		}
+		for _, result := range results.Results {
+			if result.MatchType != models.MatchTypeReference {
+				t.Errorf("Expected match type reference, got %s", result.MatchType)
+			}
+		}
+	})
+
+	t.Run("Callers only include calls", func(t *testing.T) {
+		results, err := referenceService.FindCallers(indexPath, models.NewSearchQuery("Start"))
+		if err != nil {
+			t.Fatalf("FindCallers failed: %v", err)
+		}
+
+		found := lines(results)
+		sort.Strings(found)
+		if len(found) != 1 || found[0] != "main.go:7" {
+			t.Fatalf("Unexpected callers: %v", found)
+		}
+
+		result := results.Results[0]
+		if result.Content != "\ts.Start()" || result.StartColumn != 4 || result.EndColumn != 9 {
+			t.Errorf("Unexpected result: %q columns %d-%d", result.Content, result.StartColumn, result.EndColumn)
+		}
+		if caller, _ := result.GetMetadata("caller"); caller != "example.com/app.run" {
+			t.Errorf("Expected caller example.com/app.run, got %v", caller)
+		}
+	})
+
+	t.Run("Unknown identifier", func(t *testing.T) {
+		results, err := referenceService.FindReferences(indexPath, models.NewSearchQuery("Missing"))
+		if err != nil {
+			t.Fatalf("FindReferences failed: %v", err)
+		}
+		if !results.IsEmpty() {
+			t.Errorf("Expected no results, got %v", lines(results))
+		}
+	})
+}
@@ -0,0 +1,237 @@
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"code-search/src/models"
+	"code-search/src/services"
+)
+
+// fakeIndexSearcher returns fixed scores for each index file
+type fakeIndexSearcher struct {
+	scores map[string][]float64
+}
+
+func (f *fakeIndexSearcher) Search(query *models.SearchQuery, indexPath string) (*models.SearchResults, error) {
+	scores, ok := f.scores[indexPath]
+	if !ok {
+		return nil, fmt.Errorf("index file does not exist: %s", indexPath)
+	}
+
+	results := models.NewSearchResults(query)
+	for i, score := range scores {
+		result := models.NewSearchResult(fmt.Sprintf("%s/file%d.go", filepath.Dir(indexPath), i), i+1, i+1, "content")
+		result.RelevanceScore = score
+		results.Results = append(results.Results, result)
+	}
+	results.TotalResults = len(scores)
+	results.SortResults()
+	return results.Page(query.Offset, query.MaxResults), nil
+}
+
+// TestSearchRepositories tests merging the rankings of several repositories
+func TestSearchRepositories(t *testing.T) {
+	searcher := &fakeIndexSearcher{scores: map[string][]float64{
+		"/src/api/index":  {0.9, 0.45},
+		"/src/web/index":  {0.3, 0.24, 0.03},
+		"/src/docs/index": {0.5},
+	}}
+	repositories := []services.Repository{
+		{Name: "api", Directory: "/src/api", IndexPath: "/src/api/index"},
+		{Name: "web", Directory: "/src/web", IndexPath: "/src/web/index"},
+	}
+
+	t.Run("Scores are normalised per repository", func(t *testing.T) {
+		query := models.NewSearchQuery("login")
+		results, err := services.SearchRepositories(searcher, query, repositories)
+		if err != nil {
+			t.Fatalf("SearchRepositories failed: %v", err)
+		}
+
+		// web scores far lower than api, but its best result still ranks beside api's
+		var got []string
+		for _, result := range results.Results {
+			got = append(got, fmt.Sprintf("%s %.2f", result.Repository, result.RelevanceScore))
+		}
+		want := []string{"api 1.00", "web 1.00", "web 0.78", "api 0.00", "web 0.00"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+		if results.TotalResults != 5 || results.Results[4].Rank != 5 {
+			t.Errorf("Expected 5 ranked results, got %d", results.TotalResults)
+		}
+	})
+
+	t.Run("Equal scores normalise to 1", func(t *testing.T) {
+		flat := &fakeIndexSearcher{scores: map[string][]float64{
+			"/src/api/index": {0.9, 0.5},
+			"/src/web/index": {0.2, 0.2},
+		}}
+		results, err := services.SearchRepositories(flat, models.NewSearchQuery("login"), repositories)
+		if err != nil {
+			t.Fatalf("SearchRepositories failed: %v", err)
+		}
+		var got []string
+		for _, result := range results.Results {
+			got = append(got, fmt.Sprintf("%s %.2f", result.Repository, result.RelevanceScore))
+		}
+		want := []string{"api 1.00", "web 1.00", "web 1.00", "api 0.00"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("Pages slice one merged ranking", func(t *testing.T) {
+		var pages []string
+		for offset := 0; offset < 5; offset += 2 {
+			query := models.NewSearchQuery("login")
+			query.MaxResults = 2
+			query.Offset = offset
+			results, err := services.SearchRepositories(searcher, query, repositories)
+			if err != nil {
+				t.Fatalf("SearchRepositories failed: %v", err)
+			}
+			for _, result := range results.Results {
+				pages = append(pages, fmt.Sprintf("%s %.2f", result.Repository, result.RelevanceScore))
+			}
+		}
+		want := []string{"api 1.00", "web 1.00", "web 0.78", "api 0.00", "web 0.00"}
+		if !reflect.DeepEqual(pages, want) {
+			t.Errorf("Expected pages to cover %v, got %v", want, pages)
+		}
+	})
+
+	t.Run("Pages span repositories", func(t *testing.T) {
+		query := models.NewSearchQuery("login")
+		query.MaxResults = 2
+		query.Offset = 2
+		results, err := services.SearchRepositories(searcher, query, repositories)
+		if err != nil {
+			t.Fatalf("SearchRepositories failed: %v", err)
+		}
+		if len(results.Results) != 2 || results.Results[0].Rank != 3 || !results.HasMore {
+			t.Errorf("Expected ranks 3-4 with more to follow, got %d results starting at rank %d", len(results.Results), results.Results[0].Rank)
+		}
+	})
+
+	t.Run("Paging stops at the servable window", func(t *testing.T) {
+		many := make([]float64, 1200)
+		for i := range many {
+			many[i] = 0.5
+		}
+		wide := &fakeIndexSearcher{scores: map[string][]float64{"/src/big/index": many}}
+		big := []services.Repository{{Name: "big", Directory: "/src/big", IndexPath: "/src/big/index"}}
+
+		query := models.NewSearchQuery("login")
+		query.MaxResults = 100
+		query.Offset = 900
+		results, err := services.SearchRepositories(wide, query, big)
+		if err != nil {
+			t.Fatalf("SearchRepositories failed: %v", err)
+		}
+		if len(results.Results) != 100 || results.HasMore || results.TotalResults != 1000 {
+			t.Errorf("Expected the last servable page, got %d results (more: %v, total %d)", len(results.Results), results.HasMore, results.TotalResults)
+		}
+
+		query.Offset = 1000
+		results, err = services.SearchRepositories(wide, query, big)
+		if err != nil {
+			t.Fatalf("SearchRepositories failed: %v", err)
+		}
+		if len(results.Results) != 0 || results.HasMore || results.NextCursor != "" {
+			t.Errorf("Expected an empty final page, got %d results (more: %v)", len(results.Results), results.HasMore)
+		}
+	})
+
+	t.Run("repo: filters select repositories", func(t *testing.T) {
+		query := models.NewSearchQuery("login -repo:api")
+		if err := query.ParseStructuredQuery(); err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		results, err := services.SearchRepositories(searcher, query, repositories)
+		if err != nil {
+			t.Fatalf("SearchRepositories failed: %v", err)
+		}
+		for _, result := range results.Results {
+			if result.Repository != "web" {
+				t.Errorf("Expected only web results, got %s", result.Repository)
+			}
+		}
+
+		query = models.NewSearchQuery("login repo:docs")
+		if err := query.ParseStructuredQuery(); err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if _, err := services.SearchRepositories(searcher, query, repositories); !errors.Is(err, services.ErrNoRepositories) {
+			t.Errorf("Expected ErrNoRepositories, got %v", err)
+		}
+	})
+
+	t.Run("Failures name the repository", func(t *testing.T) {
+		missing := append(repositories, services.Repository{Name: "gone", IndexPath: "/src/gone/index"})
+		_, err := services.SearchRepositories(searcher, models.NewSearchQuery("login"), missing)
+		if err == nil || !strings.HasPrefix(err.Error(), "gone: ") {
+			t.Errorf("Expected an error naming the repository, got %v", err)
+		}
+	})
+}
+
+// TestRepositoryLabels tests naming repositories after their directories
+func TestRepositoryLabels(t *testing.T) {
+	got := services.RepositoryLabels([]string{"/src/api", "/src/web", "/team/api", "/old/team/api"})
+	want := []string{"src/api", "web", "team/api", "old/team/api"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+// TestLoadWorkspace tests reading named and explicit workspace files
+func TestLoadWorkspace(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	path := services.WorkspacePath("product")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create workspaces directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("repositories:\n  - /src/api\n  - ../web\n"), 0644); err != nil {
+		t.Fatalf("Failed to write workspace: %v", err)
+	}
+
+	t.Run("By name", func(t *testing.T) {
+		workspace, err := services.LoadWorkspace("product")
+		if err != nil {
+			t.Fatalf("LoadWorkspace failed: %v", err)
+		}
+		want := []string{"/src/api", filepath.Join(configHome, "code-search", "web")}
+		if workspace.Name != "product" || !reflect.DeepEqual(workspace.Repositories, want) {
+			t.Errorf("Expected product with %v, got %s with %v", want, workspace.Name, workspace.Repositories)
+		}
+	})
+
+	t.Run("By path", func(t *testing.T) {
+		if _, err := services.LoadWorkspace(path); err != nil {
+			t.Errorf("LoadWorkspace failed: %v", err)
+		}
+	})
+
+	t.Run("Invalid workspaces fail", func(t *testing.T) {
+		if _, err := services.LoadWorkspace("missing"); err == nil {
+			t.Error("Expected an error for a missing workspace")
+		}
+
+		invalid := filepath.Join(t.TempDir(), "invalid.yaml")
+		if err := os.WriteFile(invalid, []byte("repos:\n  - /src/api\n"), 0644); err != nil {
+			t.Fatalf("Failed to write workspace: %v", err)
+		}
+		if _, err := services.LoadWorkspace(invalid); err == nil {
+			t.Error("Expected an error for an unknown setting")
+		}
+	})
+}
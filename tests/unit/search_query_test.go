@@ -0,0 +1,121 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"code-search/src/models"
+)
+
+// TestSearchQuery_IsCaseSensitive tests case sensitivity defaults and smart case
+func TestSearchQuery_IsCaseSensitive(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		searchType    models.SearchType
+		caseSensitive bool
+		smartCase     bool
+		expected      bool
+	}{
+		{"Text defaults to insensitive", "ID", models.SearchTypeText, false, false, false},
+		{"Regex defaults to sensitive", "id", models.SearchTypeRegex, false, false, true},
+		{"Case sensitive flag", "id", models.SearchTypeText, true, false, true},
+		{"Smart case lowercase", "id", models.SearchTypeRegex, false, true, false},
+		{"Smart case uppercase", "ID", models.SearchTypeText, false, true, true},
+		{"Smart case ignores regex escapes", `\S+\W`, models.SearchTypeRegex, false, true, false},
+		{"Case sensitive wins over smart case", "id", models.SearchTypeText, true, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := models.NewSearchQuery(tt.query)
+			query.SearchType = tt.searchType
+			query.CaseSensitive = tt.caseSensitive
+			query.SmartCase = tt.smartCase
+
+			if got := query.IsCaseSensitive(); got != tt.expected {
+				t.Errorf("Expected IsCaseSensitive() = %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestSearchQuery_LiteralPattern tests whole-word matching of terms with symbol edges
+func TestSearchQuery_LiteralPattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		term     string
+		line     string
+		expected bool
+	}{
+		{"Word edges", "Err", "return Err", true},
+		{"Word edges inside an identifier", "Err", "return ErrClosed", false},
+		{"Symbol at the end", "foo(", "x := foo(1)", true},
+		{"Symbol at the end still bounds the start", "foo(", "x := barfoo(1)", false},
+		{"Symbol at the start", "-verbose", "run -verbose", true},
+		{"Symbol at the start still bounds the end", "-verbose", "run -verbosely", false},
+		{"Symbols on both sides", "(ctx)", "call(ctx)", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := models.NewSearchQuery(tt.term)
+			query.WholeWord = true
+			pattern, err := query.LiteralPattern(tt.term)
+			if err != nil {
+				t.Fatalf("LiteralPattern failed: %v", err)
+			}
+			if got := pattern.MatchString(tt.line); got != tt.expected {
+				t.Errorf("Expected %q to match %q: %v, got %v (pattern %s)", tt.term, tt.line, tt.expected, got, pattern)
+			}
+		})
+	}
+
+	t.Run("Regex edges", func(t *testing.T) {
+		for source, lines := range map[string]map[string]bool{
+			`Err(or)?`: {"Error": true, "Err:": true, "Errors": false, "xErr": false},
+			`\w+\(`:    {"call(x)": true, "call()": true},
+		} {
+			query := models.NewSearchQuery(source)
+			query.SearchType = models.SearchTypeRegex
+			query.WholeWord = true
+			pattern, err := query.RegexPattern()
+			if err != nil {
+				t.Fatalf("RegexPattern failed: %v", err)
+			}
+			for line, expected := range lines {
+				if got := pattern.MatchString(line); got != expected {
+					t.Errorf("Expected %s to match %q: %v, got %v", pattern, line, expected, got)
+				}
+			}
+		}
+	})
+}
+
+// TestSearchQuery_TextTerms tests the terms a text search looks for
+func TestSearchQuery_TextTerms(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		searchType    models.SearchType
+		caseSensitive bool
+		expected      []string
+	}{
+		{"Text terms are kept as typed", "Retry?", models.SearchTypeText, false, []string{"Retry?"}},
+		{"Hybrid terms are normalised", "Where is parseConfig?", models.SearchTypeHybrid, false, []string{"where", "is", "parseconfig", "parse", "config"}},
+		{"Case-sensitive hybrid terms keep their case", "Where is parseConfig?", models.SearchTypeHybrid, true, []string{"Where", "is", "parseConfig"}},
+		{"Hybrid terms of symbols only", "???", models.SearchTypeHybrid, false, []string{"???"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := models.NewSearchQuery(tt.query)
+			query.SearchType = tt.searchType
+			query.CaseSensitive = tt.caseSensitive
+
+			if got := query.TextTerms(); strings.Join(got, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("Expected terms %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package unit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"code-search/src/models"
+)
+
+// newGroupingTestResults creates results in score order across two files and languages
+func newGroupingTestResults(t *testing.T, dir string) *models.SearchResults {
+	t.Helper()
+	results := models.NewSearchResults(models.NewSearchQuery("test"))
+
+	add := func(file string, line int, language string, score float64) {
+		result := models.NewSearchResult(filepath.Join(dir, file), line, line, "test")
+		result.Language = language
+		result.RelevanceScore = score
+		if err := results.AddResult(result); err != nil {
+			t.Fatalf("AddResult failed: %v", err)
+		}
+	}
+	add("b.go", 7, "Go", 0.9)
+	add("a.py", 3, "Python", 0.8)
+	add("b.go", 2, "Go", 0.7)
+	add("a.py", 1, "Python", 0.6)
+
+	return results
+}
+
+// TestSearchResults_GroupBy tests grouping results by file and language
+func TestSearchResults_GroupBy(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("Groups follow the order of their first result", func(t *testing.T) {
+		results := newGroupingTestResults(t, dir)
+		groups, err := results.GroupBy("file")
+		if err != nil {
+			t.Fatalf("GroupBy failed: %v", err)
+		}
+		if len(groups) != 2 || groups[0].Key != filepath.Join(dir, "b.go") || groups[1].Key != filepath.Join(dir, "a.py") {
+			t.Fatalf("Unexpected groups: %+v", groups)
+		}
+		if groups[0].Count != 2 || groups[0].Results[0].StartLine != 7 || groups[0].Results[1].StartLine != 2 {
+			t.Errorf("Unexpected results in first group: %+v", groups[0])
+		}
+	})
+
+	t.Run("By language", func(t *testing.T) {
+		groups, err := newGroupingTestResults(t, dir).GroupBy("language")
+		if err != nil {
+			t.Fatalf("GroupBy failed: %v", err)
+		}
+		if len(groups) != 2 || groups[0].Key != "Go" || groups[1].Key != "Python" || groups[1].Count != 2 {
+			t.Errorf("Unexpected groups: %+v", groups)
+		}
+	})
+
+	t.Run("Unknown field", func(t *testing.T) {
+		if _, err := newGroupingTestResults(t, dir).GroupBy("size"); err == nil {
+			t.Error("Expected an error for an unknown field")
+		}
+	})
+}
+
+// TestSearchResults_SortBy tests ordering results by score, path and modification time
+func TestSearchResults_SortBy(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.py", "b.go"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("test\n"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "b.go"), old, old); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+
+	order := func(results *models.SearchResults) []string {
+		var keys []string
+		for _, result := range results.Results {
+			keys = append(keys, fmt.Sprintf("%s:%d", filepath.Base(result.FilePath), result.StartLine))
+		}
+		return keys
+	}
+
+	tests := []struct {
+		sort     string
+		expected []string
+	}{
+		{"score", []string{"b.go:7", "a.py:3", "b.go:2", "a.py:1"}},
+		{"path", []string{"a.py:1", "a.py:3", "b.go:2", "b.go:7"}},
+		{"mtime", []string{"a.py:1", "a.py:3", "b.go:2", "b.go:7"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			results := newGroupingTestResults(t, dir)
+			if tt.sort == "score" {
+				// Shuffle so that sorting has work to do
+				results.Results[0], results.Results[3] = results.Results[3], results.Results[0]
+			}
+			if err := results.SortBy(tt.sort); err != nil {
+				t.Fatalf("SortBy failed: %v", err)
+			}
+			got := order(results)
+			for i := range tt.expected {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("Expected %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+
+	t.Run("Newest file first", func(t *testing.T) {
+		newer := time.Now().Add(time.Hour)
+		if err := os.Chtimes(filepath.Join(dir, "b.go"), newer, newer); err != nil {
+			t.Fatalf("Failed to set modification time: %v", err)
+		}
+		results := newGroupingTestResults(t, dir)
+		if err := results.SortBy("mtime"); err != nil {
+			t.Fatalf("SortBy failed: %v", err)
+		}
+		if filepath.Base(results.Results[0].FilePath) != "b.go" {
+			t.Errorf("Expected b.go first, got %v", order(results))
+		}
+	})
+
+	t.Run("Unknown order", func(t *testing.T) {
+		if err := newGroupingTestResults(t, dir).SortBy("size"); err == nil {
+			t.Error("Expected an error for an unknown order")
+		}
+	})
+}
@@ -0,0 +1,219 @@
+package unit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"code-search/src/lib"
+	"code-search/src/services"
+)
+
+// TestSearchServer tests the HTTP endpoints of the search server
+func TestSearchServer(t *testing.T) {
+	indexPath := createSearchTestIndex(t, map[string]string{
+		"api/handler.go":  "package api\n\n// TODO handle retries\n",
+		"store/store.go":  "package store\n\n// TODO close the store\n",
+		"store/cache.go":  "package store\n\n// cache entries\n",
+		"api/handler2.go": "package api\n\n// nothing to do\n",
+	})
+	root := filepath.Dir(filepath.Dir(indexPath))
+
+	reindexed := 0
+	reindex := func(files []string) (*services.IndexingResult, error) {
+		reindexed++
+		return &services.IndexingResult{Success: true, FilesIndexed: 4}, nil
+	}
+	server := services.NewSearchServer(root, indexPath, newTestSearchService(), reindex, &services.SilentLogger{})
+
+	send := func(t *testing.T, server *services.SearchServer, r *http.Request) (int, map[string]interface{}) {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, r)
+
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &decoded); err != nil {
+			t.Fatalf("Invalid JSON response %q: %v", recorder.Body.String(), err)
+		}
+		return recorder.Code, decoded
+	}
+	request := func(t *testing.T, method, target, body string) (int, map[string]interface{}) {
+		t.Helper()
+		return send(t, server, newServerRequest(method, target, body))
+	}
+
+	t.Run("Searches need a loaded index", func(t *testing.T) {
+		if code, _ := request(t, http.MethodGet, "/v1/search?q=TODO", ""); code != http.StatusServiceUnavailable {
+			t.Errorf("Expected 503 before loading, got %d", code)
+		}
+	})
+
+	if err := server.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	defer server.Close()
+
+	t.Run("GET and POST searches", func(t *testing.T) {
+		code, body := request(t, http.MethodGet, "/v1/search?q=TODO&type=exact", "")
+		if code != http.StatusOK || body["totalResults"] != float64(2) {
+			t.Errorf("Expected 2 results, got %d: %v", code, body)
+		}
+
+		code, body = request(t, http.MethodPost, "/v1/search", `{"query": "TODO", "search_type": "exact", "max_results": 1}`)
+		if code != http.StatusOK || body["displayed"] != float64(1) || body["has_more"] != true {
+			t.Errorf("Expected a first page of 1 result, got %d: %v", code, body)
+		}
+	})
+
+	t.Run("Searches can be limited to a directory inside the root", func(t *testing.T) {
+		code, body := request(t, http.MethodGet, "/v1/search?q=TODO&type=exact&dir=store", "")
+		results, _ := body["results"].([]interface{})
+		if code != http.StatusOK || len(results) != 1 {
+			t.Fatalf("Expected 1 result in store, got %d: %v", code, body)
+		}
+		if path := results[0].(map[string]interface{})["file_path"].(string); !strings.Contains(path, "/store/") {
+			t.Errorf("Expected a result in store, got %s", path)
+		}
+	})
+
+	t.Run("Directories outside the root are forbidden", func(t *testing.T) {
+		for _, dir := range []string{"../", "/etc", root + "-sibling"} {
+			code, _ := request(t, http.MethodGet, "/v1/search?q=TODO&dir="+dir, "")
+			if code != http.StatusForbidden {
+				t.Errorf("Expected 403 for %s, got %d", dir, code)
+			}
+		}
+	})
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		code, body := request(t, http.MethodGet, "/v1/search?q=(TODO%20OR%20FIXME&type=text", "")
+		if code != http.StatusBadRequest || body["column"] != float64(1) {
+			t.Errorf("Expected a parse error at column 1, got %d: %v", code, body)
+		}
+		if code, _ := request(t, http.MethodPost, "/v1/search", `{"query": "TODO", "unknown": 1}`); code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for unknown fields, got %d", code)
+		}
+		if code, _ := request(t, http.MethodDelete, "/v1/search", ""); code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected 405, got %d", code)
+		}
+	})
+
+	t.Run("Results outside the root are dropped before paging", func(t *testing.T) {
+		storeServer := services.NewSearchServer(filepath.Join(root, "store"), indexPath, newTestSearchService(), reindex, &services.SilentLogger{})
+		if err := storeServer.Load(); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		defer storeServer.Close()
+
+		code, body := send(t, storeServer, newServerRequest(http.MethodGet, "/v1/search?q=TODO&type=exact&max_results=1", ""))
+		results, _ := body["results"].([]interface{})
+		if code != http.StatusOK || len(results) != 1 || body["totalResults"] != float64(1) || body["has_more"] != false {
+			t.Errorf("Expected the single result inside store, got %d: %v", code, body)
+		}
+	})
+
+	t.Run("Requests are checked before routing", func(t *testing.T) {
+		foreign := newServerRequest(http.MethodGet, "/health", "")
+		foreign.Host = "attacker.example:7420"
+		if code, _ := send(t, server, foreign); code != http.StatusForbidden {
+			t.Errorf("Expected 403 for a foreign host, got %d", code)
+		}
+		for _, host := range []string{"localhost:7420", "[::1]:7420", "127.0.0.1"} {
+			local := newServerRequest(http.MethodGet, "/health", "")
+			local.Host = host
+			if code, _ := send(t, server, local); code != http.StatusOK {
+				t.Errorf("Expected 200 for host %s, got %d", host, code)
+			}
+		}
+
+		form := newServerRequest(http.MethodPost, "/v1/search", `{"query": "TODO"}`)
+		form.Header.Set("Content-Type", "text/plain")
+		if code, _ := send(t, server, form); code != http.StatusUnsupportedMediaType {
+			t.Errorf("Expected 415 for a text/plain body, got %d", code)
+		}
+	})
+
+	t.Run("Tokens are required once set", func(t *testing.T) {
+		tokenServer := services.NewSearchServer(root, indexPath, newTestSearchService(), reindex, &services.SilentLogger{})
+		tokenServer.RequireToken("s3cret")
+
+		if code, _ := send(t, tokenServer, newServerRequest(http.MethodGet, "/health", "")); code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 without a token, got %d", code)
+		}
+		wrong := newServerRequest(http.MethodGet, "/health", "")
+		wrong.Header.Set("Authorization", "Bearer guess")
+		if code, _ := send(t, tokenServer, wrong); code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 for a wrong token, got %d", code)
+		}
+		right := newServerRequest(http.MethodGet, "/health", "")
+		right.Header.Set("Authorization", "Bearer s3cret")
+		if code, _ := send(t, tokenServer, right); code != http.StatusOK {
+			t.Errorf("Expected 200 with the token, got %d", code)
+		}
+	})
+
+	t.Run("Status and reindex", func(t *testing.T) {
+		code, body := request(t, http.MethodPost, "/v1/reindex?wait=true", "")
+		if code != http.StatusOK || reindexed != 1 || body["files_indexed"] != float64(4) {
+			t.Errorf("Expected one reindex, got %d (%d runs): %v", code, reindexed, body)
+		}
+
+		code, body = request(t, http.MethodGet, "/v1/status", "")
+		if code != http.StatusOK || body["file_count"] != float64(4) || body["last_reindex"] == nil {
+			t.Errorf("Unexpected status %d: %v", code, body)
+		}
+	})
+
+	t.Run("Health and OpenAPI description", func(t *testing.T) {
+		if code, body := request(t, http.MethodGet, "/health", ""); code != http.StatusOK || body["index_loaded"] != true {
+			t.Errorf("Unexpected health %d: %v", code, body)
+		}
+		code, body := request(t, http.MethodGet, "/openapi.json", "")
+		paths, _ := body["paths"].(map[string]interface{})
+		if code != http.StatusOK || paths["/v1/search"] == nil {
+			t.Errorf("Expected an OpenAPI document describing /v1/search, got %d", code)
+		}
+	})
+}
+
+// TestSearchServer_Timeout tests that searches are bounded by SearchOptions.Timeout
+func TestSearchServer_Timeout(t *testing.T) {
+	var content strings.Builder
+	content.WriteString("package p\n")
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&content, "// TODO item %d\n", i)
+	}
+	indexPath := createSearchTestIndex(t, map[string]string{"todo.go": content.String()})
+
+	options := services.DefaultSearchOptions()
+	options.CacheResults = false
+	options.Timeout = time.Nanosecond
+	searchService := services.NewSearchService(lib.NewSimpleCodeParser(), lib.NewMockVectorStore(), &services.SilentLogger{}, options)
+
+	server := services.NewSearchServer(filepath.Dir(filepath.Dir(indexPath)), indexPath, searchService, nil, &services.SilentLogger{})
+	if err := server.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	defer server.Close()
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, newServerRequest(http.MethodGet, "/v1/search?q=TODO&type=exact", ""))
+	if recorder.Code != http.StatusGatewayTimeout {
+		t.Errorf("Expected 504, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+}
+
+// newServerRequest creates a request the way a local client sends it
+func newServerRequest(method, target, body string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.Host = "127.0.0.1:7420"
+	if method == http.MethodPost {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	return r
+}
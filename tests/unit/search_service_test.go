@@ -0,0 +1,460 @@
+package unit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"code-search/src/lib"
+	"code-search/src/models"
+	"code-search/src/services"
+)
+
+// TestSearchService_RegexSearch tests line-based and multiline regex search
+func TestSearchService_RegexSearch(t *testing.T) {
+	indexPath := createSearchTestIndex(t, map[string]string{
+		"handler.go": "package handler\n\nfunc Close(ctx context.Context) error {\n\treturn nil\n}\n\nfunc Open(name string) error {\n\treturn nil\n}\n",
+	})
+	searchService := newTestSearchService()
+
+	t.Run("Multiline pattern spans lines", func(t *testing.T) {
+		query := models.NewSearchQuery(`func (\w+)\(ctx[^)]*\)[^{]*\{\s*return nil`)
+		query.SearchType = models.SearchTypeRegex
+		query.Multiline = true
+
+		results, err := searchService.Search(query, indexPath)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+
+		if len(results.Results) != 1 {
+			t.Fatalf("Expected 1 result, got %d", len(results.Results))
+		}
+
+		result := results.Results[0]
+		if result.StartLine != 3 || result.EndLine != 4 {
+			t.Errorf("Expected lines 3-4, got %d-%d", result.StartLine, result.EndLine)
+		}
+
+		if len(result.Spans) != 1 {
+			t.Fatalf("Expected 1 span from the capture group, got %d", len(result.Spans))
+		}
+
+		span := result.Spans[0]
+		if span.Text != "Close" || span.StartLine != 3 || span.StartColumn != 6 || span.EndColumn != 11 {
+			t.Errorf("Unexpected span: %+v", span)
+		}
+
+		if len(result.Highlights) != 1 || result.Highlights[0] != "Close" {
+			t.Errorf("Expected highlight 'Close', got %v", result.Highlights)
+		}
+	})
+
+	t.Run("Line mode cannot span lines", func(t *testing.T) {
+		query := models.NewSearchQuery(`\{\s*return nil`)
+		query.SearchType = models.SearchTypeRegex
+
+		results, err := searchService.Search(query, indexPath)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+
+		if len(results.Results) != 0 {
+			t.Errorf("Expected no results in line mode, got %d", len(results.Results))
+		}
+	})
+
+	t.Run("Line mode records columns", func(t *testing.T) {
+		query := models.NewSearchQuery(`Open`)
+		query.SearchType = models.SearchTypeRegex
+
+		results, err := searchService.Search(query, indexPath)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+
+		if len(results.Results) != 1 {
+			t.Fatalf("Expected 1 result, got %d", len(results.Results))
+		}
+
+		result := results.Results[0]
+		if result.StartLine != 7 || result.StartColumn != 6 || result.EndColumn != 10 {
+			t.Errorf("Unexpected position: line %d, columns %d-%d", result.StartLine, result.StartColumn, result.EndColumn)
+		}
+	})
+
+	t.Run("Multiline requires regex", func(t *testing.T) {
+		query := models.NewSearchQuery("return nil")
+		query.SearchType = models.SearchTypeText
+		query.Multiline = true
+
+		if _, err := searchService.Search(query, indexPath); err == nil {
+			t.Error("Expected error for multiline text search")
+		}
+	})
+}
+
+// createSearchTestIndex writes files to a temporary directory, indexes them and returns the index path
+func createSearchTestIndex(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	index := models.NewCodeIndex(tempDir, lib.NewMockVectorStore())
+	entries := make(map[string]*models.FileEntry)
+	var goFiles []string
+
+	for name, content := range files {
+		filePath := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+
+		entry, err := models.NewFileEntry(filePath)
+		if err != nil {
+			t.Fatalf("Failed to create file entry for %s: %v", name, err)
+		}
+		entry.Symbols = lib.ExtractSymbols(filePath, content, entry.Language)
+		if err := index.AddFileEntry(entry); err != nil {
+			t.Fatalf("Failed to add file entry for %s: %v", name, err)
+		}
+
+		entries[filePath] = entry
+		if entry.Language == "Go" {
+			goFiles = append(goFiles, filePath)
+		}
+	}
+
+	references, err := lib.NewGoReferenceAnalyzer().Analyze(tempDir, goFiles)
+	if err != nil {
+		t.Fatalf("Failed to analyze Go references: %v", err)
+	}
+	for filePath, refs := range references {
+		entries[filePath].References = refs
+	}
+
+	indexPath := filepath.Join(tempDir, ".clindex", "data.index")
+	if err := index.Save(indexPath); err != nil {
+		t.Fatalf("Failed to save index: %v", err)
+	}
+
+	return indexPath
+}
+
+// newTestSearchService creates a search service with result caching disabled
+func newTestSearchService() *services.SearchService {
+	options := services.DefaultSearchOptions()
+	options.CacheResults = false
+
+	return services.NewSearchService(
+		lib.NewSimpleCodeParser(),
+		lib.NewMockVectorStore(),
+		&services.SilentLogger{},
+		options,
+	)
+}
+
+// TestSearchService_FuzzySearch tests edit-distance and subsequence fuzzy search
+func TestSearchService_FuzzySearch(t *testing.T) {
+	indexPath := createSearchTestIndex(t, map[string]string{
+		"auth.go": "package auth\n\ntype UserAuth struct{}\n\nfunc NewUserAuthService() *UserAuth {\n\treturn &UserAuth{}\n}\n",
+	})
+	searchService := newTestSearchService()
+
+	t.Run("Transposed characters", func(t *testing.T) {
+		query := models.NewSearchQuery("UsreAuth")
+		query.SearchType = models.SearchTypeFuzzy
+
+		results, err := searchService.Search(query, indexPath)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+
+		if len(results.Results) == 0 {
+			t.Fatal("Expected UsreAuth to find UserAuth")
+		}
+
+		result := results.Results[0]
+		if result.StartLine != 3 || len(result.Spans) == 0 || result.Spans[0].Text != "UserAuth" || result.Spans[0].StartColumn != 6 {
+			t.Errorf("Unexpected best result: line %d, spans %+v", result.StartLine, result.Spans)
+		}
+	})
+
+	t.Run("Max edits zero", func(t *testing.T) {
+		query := models.NewSearchQuery("UsreAuth")
+		query.SearchType = models.SearchTypeFuzzy
+		query.MaxEdits = 0
+
+		results, err := searchService.Search(query, indexPath)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+
+		for _, result := range results.Results {
+			if result.Spans[0].Text == "UserAuth" && result.StartLine == 3 {
+				t.Errorf("Expected no edit-distance match with max edits 0, got score %f", result.RelevanceScore)
+			}
+		}
+	})
+
+	t.Run("Subsequence highlights positions", func(t *testing.T) {
+		query := models.NewSearchQuery("NewUsrAuthSvc")
+		query.SearchType = models.SearchTypeFuzzy
+
+		results, err := searchService.Search(query, indexPath)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+
+		if len(results.Results) != 1 {
+			t.Fatalf("Expected 1 result, got %d", len(results.Results))
+		}
+
+		result := results.Results[0]
+		if result.StartLine != 5 || len(result.Spans) < 2 || result.Spans[0].Text != "NewUs" {
+			t.Errorf("Unexpected result: line %d, spans %+v", result.StartLine, result.Spans)
+		}
+	})
+
+	t.Run("OR takes the best alternative", func(t *testing.T) {
+		query := models.NewSearchQuery("(UsreAuth OR retryRequest) -return")
+		query.SearchType = models.SearchTypeFuzzy
+		if err := query.ParseStructuredQuery(); err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+
+		results, err := searchService.Search(query, indexPath)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+
+		var lines []int
+		for _, result := range results.Results {
+			lines = append(lines, result.StartLine)
+		}
+		sort.Ints(lines)
+		if fmt.Sprint(lines) != "[3 5]" {
+			t.Errorf("Expected the UserAuth lines without the negated return, got %v", lines)
+		}
+	})
+}
+
+// TestSearchService_CaseAndWord tests case-sensitive, smart-case and whole-word matching
+func TestSearchService_CaseAndWord(t *testing.T) {
+	indexPath := createSearchTestIndex(t, map[string]string{
+		"user.go": "package user\n\nvar ID = 1\nvar id = 2\nvar IDLE = 3\nvar ErrClosed = Err\n",
+	})
+	searchService := newTestSearchService()
+
+	search := func(t *testing.T, query *models.SearchQuery) []int {
+		t.Helper()
+		results, err := searchService.Search(query, indexPath)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+
+		var lines []int
+		for _, result := range results.Results {
+			lines = append(lines, result.StartLine)
+		}
+		sort.Ints(lines)
+		return lines
+	}
+
+	tests := []struct {
+		name          string
+		text          string
+		searchType    models.SearchType
+		caseSensitive bool
+		smartCase     bool
+		wholeWord     bool
+		expected      []int
+	}{
+		{"Exact is case-insensitive by default", "id", models.SearchTypeExact, false, false, false, []int{3, 4, 5}},
+		{"Exact case-sensitive", "ID", models.SearchTypeExact, true, false, false, []int{3, 5}},
+		{"Smart case with uppercase", "ID", models.SearchTypeText, false, true, true, []int{3}},
+		{"Smart case with lowercase", "id", models.SearchTypeText, false, true, true, []int{3, 4}},
+		{"Whole word regex", `Err`, models.SearchTypeRegex, false, false, true, []int{6}},
+		{"Regex case-insensitive with smart case", `idle`, models.SearchTypeRegex, false, true, false, []int{5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := models.NewSearchQuery(tt.text)
+			query.SearchType = tt.searchType
+			query.Threshold = 0
+			query.CaseSensitive = tt.caseSensitive
+			query.SmartCase = tt.smartCase
+			query.WholeWord = tt.wholeWord
+
+			lines := search(t, query)
+			if fmt.Sprint(lines) != fmt.Sprint(tt.expected) {
+				t.Errorf("Expected lines %v, got %v", tt.expected, lines)
+			}
+		})
+	}
+
+	t.Run("Whole word regex highlights only the word", func(t *testing.T) {
+		query := models.NewSearchQuery("Err")
+		query.SearchType = models.SearchTypeRegex
+		query.WholeWord = true
+
+		results, err := searchService.Search(query, indexPath)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+
+		if len(results.Results) != 1 || len(results.Results[0].Spans) != 1 || results.Results[0].Spans[0].StartColumn != 17 {
+			t.Errorf("Expected a single span at column 17, got %+v", results.Results)
+		}
+	})
+}
+
+// TestSearchService_TextSpans tests that text search reports each matched part of a line once
+func TestSearchService_TextSpans(t *testing.T) {
+	indexPath := createSearchTestIndex(t, map[string]string{
+		"policy.go": "package policy\n\nvar p = useRetryPolicy(retry)\n",
+	})
+	searchService := newTestSearchService()
+
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{"Terms within other terms", "retry retrypolicy", []string{"RetryPolicy@12", "retry@24"}},
+		{"Repeated terms", "retry retry", []string{"Retry@12", "retry@24"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := models.NewSearchQuery(tt.text)
+			query.SearchType = models.SearchTypeText
+			query.Threshold = 0
+
+			results, err := searchService.Search(query, indexPath)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if len(results.Results) != 1 {
+				t.Fatalf("Expected one result, got %d", len(results.Results))
+			}
+
+			var spans []string
+			for _, span := range results.Results[0].Spans {
+				spans = append(spans, fmt.Sprintf("%s@%d", span.Text, span.StartColumn))
+			}
+			if fmt.Sprint(spans) != fmt.Sprint(tt.expected) {
+				t.Errorf("Expected spans %v, got %v", tt.expected, spans)
+			}
+			if results.Results[0].StartColumn != 12 {
+				t.Errorf("Expected the result to start at the first span, got column %d", results.Results[0].StartColumn)
+			}
+		})
+	}
+}
+
+// TestSearchService_StructuredQuery tests boolean expressions and structured filters
+func TestSearchService_StructuredQuery(t *testing.T) {
+	indexPath := createSearchTestIndex(t, map[string]string{
+		"internal/retry.go":      "package internal\n\nfunc RetryHandler() {}\n\n// retry with backoff\n\n// deprecated retry\n",
+		"internal/retry_test.go": "package internal\n\n// retry in tests\n",
+		"internal/calc.go":       "package internal\n\nvar x = 1 - 2\n\nfunc check() {\n\tif (err != nil) {\n\t}\n}\n",
+	})
+	searchService := newTestSearchService()
+
+	search := func(t *testing.T, text string) []*models.SearchResult {
+		t.Helper()
+		query := models.NewSearchQuery(text)
+		query.SearchType = models.SearchTypeText
+		query.Threshold = 0.1
+		if err := query.ParseStructuredQuery(); err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+
+		results, err := searchService.Search(query, indexPath)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		return results.Results
+	}
+
+	t.Run("Negation and path exclusion", func(t *testing.T) {
+		results := search(t, "(retry OR backoff) -deprecated -path:_test.go")
+		if len(results) != 2 {
+			t.Fatalf("Expected 2 results, got %d", len(results))
+		}
+		for _, result := range results {
+			if strings.Contains(result.Content, "deprecated") || strings.HasSuffix(result.FilePath, "_test.go") {
+				t.Errorf("Unexpected result: %s: %s", result.FilePath, result.Content)
+			}
+		}
+	})
+
+	t.Run("Plain queries with operators and punctuation", func(t *testing.T) {
+		for text, line := range map[string]int{"1 - 2": 3, "if (err != nil)": 6} {
+			results := search(t, text)
+			if len(results) != 1 || results[0].StartLine != line {
+				t.Errorf("Expected %q to find line %d, got %d results", text, line, len(results))
+			}
+		}
+	})
+
+	t.Run("Symbol filter", func(t *testing.T) {
+		results := search(t, "sym:Handler retry")
+		if len(results) != 1 || results[0].StartLine != 3 {
+			t.Errorf("Expected only the RetryHandler definition, got %d results", len(results))
+		}
+	})
+}
+
+// TestSearchService_SearchIndex tests searching an index that is loaded once
+func TestSearchService_SearchIndex(t *testing.T) {
+	indexPath := createSearchTestIndex(t, map[string]string{
+		"handler.go": "package p\n\nfunc Handler() {}\n\n// handler retries\n",
+	})
+	searchService := newTestSearchService()
+
+	index, err := searchService.LoadIndex(indexPath)
+	if err != nil {
+		t.Fatalf("LoadIndex failed: %v", err)
+	}
+	defer index.Close()
+
+	// Later searches must not need the index file
+	if err := os.Remove(indexPath); err != nil {
+		t.Fatalf("Failed to remove index: %v", err)
+	}
+
+	t.Run("Results and context", func(t *testing.T) {
+		query := models.NewSearchQuery("handler")
+		query.SearchType = models.SearchTypeText
+		query.ContextBefore = 1
+
+		results, err := searchService.SearchIndex(query, index)
+		if err != nil {
+			t.Fatalf("SearchIndex failed: %v", err)
+		}
+		if results.TotalResults != 2 || results.Query != query {
+			t.Fatalf("Expected 2 results for the query, got %d", results.TotalResults)
+		}
+		for _, result := range results.Results {
+			if !result.HasContextLines() {
+				t.Errorf("Expected context lines for line %d", result.StartLine)
+			}
+		}
+	})
+
+	t.Run("Invalid queries are rejected", func(t *testing.T) {
+		query := models.NewSearchQuery("handler")
+		query.Offset = -1
+		if _, err := searchService.SearchIndex(query, index); err == nil {
+			t.Error("Expected a validation error")
+		}
+	})
+}
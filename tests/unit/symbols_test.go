@@ -0,0 +1,185 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"code-search/src/lib"
+	"code-search/src/models"
+	"code-search/src/services"
+)
+
+// TestExtractSymbols tests symbol extraction for Go and Python
+func TestExtractSymbols(t *testing.T) {
+	find := func(symbols []models.Symbol, name string) *models.Symbol {
+		for i := range symbols {
+			if symbols[i].Name == name {
+				return &symbols[i]
+			}
+		}
+		return nil
+	}
+
+	t.Run("Go declarations", func(t *testing.T) {
+		content := "package server\n\nconst MaxConns = 10\n\ntype Server struct {\n\tname string\n}\n\nfunc (s *Server) Start(port int) error {\n\treturn nil\n}\n\nfunc NewServer() *Server {\n\treturn &Server{}\n}\n"
+		symbols := lib.ExtractSymbols("server.go", content, "Go")
+
+		expected := []struct {
+			name      string
+			kind      models.SymbolKind
+			container string
+			startLine int
+			endLine   int
+		}{
+			{"MaxConns", models.SymbolKindConst, "", 3, 3},
+			{"Server", models.SymbolKindType, "", 5, 7},
+			{"Start", models.SymbolKindMethod, "Server", 9, 11},
+			{"NewServer", models.SymbolKindFunc, "", 13, 15},
+		}
+
+		if len(symbols) != len(expected) {
+			t.Fatalf("Expected %d symbols, got %d: %+v", len(expected), len(symbols), symbols)
+		}
+
+		for _, want := range expected {
+			symbol := find(symbols, want.name)
+			if symbol == nil {
+				t.Errorf("Missing symbol %s", want.name)
+				continue
+			}
+			if symbol.Kind != want.kind || symbol.Container != want.container || symbol.StartLine != want.startLine || symbol.EndLine != want.endLine {
+				t.Errorf("Unexpected symbol %s: %+v", want.name, symbol)
+			}
+		}
+
+		if start := find(symbols, "Start"); start != nil && start.Signature != "func (s *Server) Start(port int) error" {
+			t.Errorf("Unexpected signature: %q", start.Signature)
+		}
+	})
+
+	t.Run("Python classes and functions", func(t *testing.T) {
+		content := "TIMEOUT = 5\n\nclass UserService:\n    def find_user(self, uid):\n        return uid\n\ndef helper():\n    pass\n"
+		symbols := lib.ExtractSymbols("svc.py", content, "Python")
+
+		if class := find(symbols, "UserService"); class == nil || class.Kind != models.SymbolKindClass || class.EndLine != 5 {
+			t.Errorf("Unexpected class symbol: %+v", class)
+		}
+		if method := find(symbols, "find_user"); method == nil || method.Kind != models.SymbolKindMethod || method.Container != "UserService" {
+			t.Errorf("Unexpected method symbol: %+v", method)
+		}
+		if fn := find(symbols, "helper"); fn == nil || fn.Kind != models.SymbolKindFunc || fn.StartLine != 7 {
+			t.Errorf("Unexpected function symbol: %+v", fn)
+		}
+		if constant := find(symbols, "TIMEOUT"); constant == nil || constant.Kind != models.SymbolKindConst {
+			t.Errorf("Unexpected constant symbol: %+v", constant)
+		}
+	})
+
+	t.Run("Unsupported language", func(t *testing.T) {
+		if symbols := lib.ExtractSymbols("notes.md", "# Title\n", "Markdown"); len(symbols) != 0 {
+			t.Errorf("Expected no symbols, got %+v", symbols)
+		}
+	})
+}
+
+// TestASTCodeParser_ParseFileWithSymbols tests building the symbol table from the parse
+// that chunks the file
+func TestASTCodeParser_ParseFileWithSymbols(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "server.go")
+	content := "package server\n\ntype Server struct{}\n\nfunc (s *Server) Start() error {\n\treturn nil\n}\n"
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", filePath, err)
+	}
+
+	chunks, symbols, err := lib.NewASTCodeParser(nil).ParseFileWithSymbols(filePath)
+	if err != nil {
+		t.Fatalf("ParseFileWithSymbols failed: %v", err)
+	}
+	if len(chunks) == 0 {
+		t.Error("Expected chunks")
+	}
+	if !reflect.DeepEqual(symbols, lib.ExtractSymbols(filePath, content, "Go")) {
+		t.Errorf("Expected the symbols of ExtractSymbols, got %+v", symbols)
+	}
+	if len(symbols) != 2 || symbols[1].Container != "Server" || symbols[1].FilePath != filePath {
+		t.Errorf("Unexpected symbols: %+v", symbols)
+	}
+}
+
+// TestSymbolService_FindSymbols tests symbol lookup by name, prefix, glob and kind
+func TestSymbolService_FindSymbols(t *testing.T) {
+	indexPath := createSearchTestIndex(t, map[string]string{
+		"server.go": "package server\n\ntype Server struct{}\n\nfunc (s *Server) Start() error {\n\treturn nil\n}\n\nfunc (s *Server) Stop() error {\n\treturn nil\n}\n\nfunc NewServer() *Server {\n\treturn &Server{}\n}\n",
+	})
+	symbolService := services.NewSymbolService(lib.NewMockVectorStore(), &services.SilentLogger{})
+
+	names := func(t *testing.T, query services.SymbolQuery) []string {
+		t.Helper()
+		matches, err := symbolService.FindSymbols(indexPath, query)
+		if err != nil {
+			t.Fatalf("FindSymbols failed: %v", err)
+		}
+
+		var result []string
+		for _, match := range matches {
+			result = append(result, match.QualifiedName())
+		}
+		return result
+	}
+
+	t.Run("Exact match ranks first", func(t *testing.T) {
+		result := names(t, services.SymbolQuery{Pattern: "Server"})
+		if len(result) < 2 || result[0] != "Server" {
+			t.Errorf("Expected Server first, got %v", result)
+		}
+	})
+
+	t.Run("Qualified glob", func(t *testing.T) {
+		result := names(t, services.SymbolQuery{Pattern: "Server.St*"})
+		if len(result) != 2 || result[0] != "Server.Start" || result[1] != "Server.Stop" {
+			t.Errorf("Expected Server.Start and Server.Stop, got %v", result)
+		}
+	})
+
+	t.Run("Kind filter", func(t *testing.T) {
+		result := names(t, services.SymbolQuery{Pattern: "srv", Kind: models.SymbolKindFunc})
+		if len(result) != 1 || result[0] != "NewServer" {
+			t.Errorf("Expected NewServer, got %v", result)
+		}
+	})
+
+	t.Run("Max results", func(t *testing.T) {
+		if result := names(t, services.SymbolQuery{Pattern: "s", MaxResults: 1}); len(result) != 1 {
+			t.Errorf("Expected 1 result, got %v", result)
+		}
+	})
+}
+
+// TestSearchService_SymbolFilter tests that sym: keeps matches within a symbol's definition
+func TestSearchService_SymbolFilter(t *testing.T) {
+	indexPath := createSearchTestIndex(t, map[string]string{
+		"server.go": "package server\n\nimport \"fmt\"\n\nfunc Start() {\n\tfmt.Println(\"start\")\n}\n\nfunc Stop() {\n\tfmt.Println(\"stop\")\n}\n",
+	})
+	searchService := newTestSearchService()
+
+	query := models.NewSearchQuery("fmt sym:Start")
+	query.SearchType = models.SearchTypeExact
+	if err := query.ParseStructuredQuery(); err != nil {
+		t.Fatalf("ParseStructuredQuery failed: %v", err)
+	}
+
+	results, err := searchService.Search(query, indexPath)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if len(results.Results) != 1 || results.Results[0].StartLine != 6 {
+		var lines []int
+		for _, result := range results.Results {
+			lines = append(lines, result.StartLine)
+		}
+		t.Errorf("Expected only line 6 inside Start, got lines %v", lines)
+	}
+}
@@ -0,0 +1,139 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"code-search/src/lib"
+	"code-search/src/models"
+)
+
+// TestSynonymDictionary_Expand tests built-in and project synonyms
+func TestSynonymDictionary_Expand(t *testing.T) {
+	t.Run("Built-in abbreviations expand both ways", func(t *testing.T) {
+		dict := lib.NewSynonymDictionary()
+		if synonyms := dict.Expand("cfg"); !reflect.DeepEqual(synonyms, []string{"config", "configuration"}) {
+			t.Errorf("Unexpected cfg synonyms: %v", synonyms)
+		}
+		if synonyms := dict.Expand("Error"); !reflect.DeepEqual(synonyms, []string{"err"}) {
+			t.Errorf("Unexpected error synonyms: %v", synonyms)
+		}
+	})
+
+	t.Run("Expansions of one abbreviation are not synonyms of each other", func(t *testing.T) {
+		for _, synonym := range lib.NewSynonymDictionary().Expand("authentication") {
+			if synonym == "authorization" {
+				t.Error("authentication should not expand to authorization")
+			}
+		}
+	})
+
+	t.Run("Project file extends the dictionary", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, lib.SynonymFileName), []byte(`{"Kubernetes": ["k8s", "kube"]}`), 0644); err != nil {
+			t.Fatalf("Failed to write synonym file: %v", err)
+		}
+
+		dict, err := lib.LoadSynonymDictionary(dir)
+		if err != nil {
+			t.Fatalf("LoadSynonymDictionary failed: %v", err)
+		}
+		if synonyms := dict.Expand("kube"); !reflect.DeepEqual(synonyms, []string{"kubernetes"}) {
+			t.Errorf("Unexpected kube synonyms: %v", synonyms)
+		}
+	})
+
+	t.Run("Invalid project file keeps built-in synonyms", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, lib.SynonymFileName), []byte(`["k8s"]`), 0644); err != nil {
+			t.Fatalf("Failed to write synonym file: %v", err)
+		}
+
+		dict, err := lib.LoadSynonymDictionary(dir)
+		if err == nil {
+			t.Error("Expected an error for a malformed synonym file")
+		}
+		if len(dict.Expand("cfg")) == 0 {
+			t.Error("Expected built-in synonyms despite the error")
+		}
+	})
+}
+
+// TestSearchService_Synonyms tests query expansion in text search
+func TestSearchService_Synonyms(t *testing.T) {
+	indexPath := createSearchTestIndex(t, map[string]string{
+		"store.go": "package store\n\nfunc removeUser() {}\n\nfunc deleteUser() {}\n\nvar stderr = 1\n\nfunc LoadConfiguration() {}\n",
+	})
+	searchService := newTestSearchService()
+
+	search := func(t *testing.T, text string, noSynonyms bool) map[string]*models.SearchResult {
+		t.Helper()
+		query := models.NewSearchQuery(text)
+		query.SearchType = models.SearchTypeText
+		query.Threshold = 0
+		query.NoSynonyms = noSynonyms
+		if err := query.ParseStructuredQuery(); err != nil {
+			t.Fatalf("ParseStructuredQuery failed: %v", err)
+		}
+
+		results, err := searchService.Search(query, indexPath)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+
+		found := make(map[string]*models.SearchResult)
+		for _, result := range results.Results {
+			found[result.Content] = result
+		}
+		return found
+	}
+
+	t.Run("Synonym hits rank below literal hits", func(t *testing.T) {
+		found := search(t, "delete user", false)
+		literal, synonym := found["func deleteUser() {}"], found["func removeUser() {}"]
+		if literal == nil || synonym == nil {
+			t.Fatalf("Expected both users to be found, got %v", found)
+		}
+		if literal.MatchType != models.MatchTypeExact || synonym.MatchType != models.MatchTypeSynonym {
+			t.Errorf("Unexpected match types %s and %s", literal.MatchType, synonym.MatchType)
+		}
+		if synonym.RelevanceScore >= literal.RelevanceScore {
+			t.Errorf("Expected synonym score %.2f below literal score %.2f", synonym.RelevanceScore, literal.RelevanceScore)
+		}
+		if len(synonym.Spans) < 1 || synonym.Spans[0].Text != "remove" {
+			t.Errorf("Expected the synonym to be highlighted, got %+v", synonym.Spans)
+		}
+	})
+
+	t.Run("Abbreviations match whole sub-words only", func(t *testing.T) {
+		found := search(t, "error", false)
+		if _, ok := found["var stderr = 1"]; ok {
+			t.Error("err should not match inside stderr")
+		}
+	})
+
+	t.Run("Compound terms expand one sub-word", func(t *testing.T) {
+		found := search(t, "load_cfg", false)
+		if result := found["func LoadConfiguration() {}"]; result == nil || result.MatchType != models.MatchTypeSynonym {
+			t.Errorf("Expected LoadConfiguration as a synonym match, got %v", found)
+		}
+	})
+
+	t.Run("Expansion can be disabled", func(t *testing.T) {
+		if found := search(t, "rm", false); len(found) != 2 {
+			t.Errorf("Expected rm to find both users, got %v", found)
+		}
+		if found := search(t, "rm", true); len(found) != 0 {
+			t.Errorf("Expected no results without synonyms, got %v", found)
+		}
+	})
+
+	t.Run("Negated terms are not expanded", func(t *testing.T) {
+		found := search(t, "user -delete", false)
+		if _, ok := found["func removeUser() {}"]; !ok || len(found) != 1 {
+			t.Errorf("Expected only removeUser, got %v", found)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package unit
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"code-search/src/models"
+)
+
+// TestSplitIdentifier tests sub-word boundaries of identifiers
+func TestSplitIdentifier(t *testing.T) {
+	tests := []struct {
+		identifier string
+		expected   []string
+	}{
+		{"parseConfig", []string{"parse", "Config"}},
+		{"UserAuthenticator", []string{"User", "Authenticator"}},
+		{"parse_config", []string{"parse", "config"}},
+		{"parse-config", []string{"parse", "config"}},
+		{"HTTPServer", []string{"HTTP", "Server"}},
+		{"getHTTPResponse2xx", []string{"get", "HTTP", "Response", "2", "xx"}},
+		{"__init__", []string{"init"}},
+		{"MAX_RETRIES", []string{"MAX", "RETRIES"}},
+		{"utf8", []string{"utf", "8"}},
+		{"config", []string{"config"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.identifier, func(t *testing.T) {
+			if parts := models.SplitIdentifier(tt.identifier); !reflect.DeepEqual(parts, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, parts)
+			}
+		})
+	}
+}
+
+// TestTokenizeCode tests that compound identifiers keep the original token before their sub-words
+func TestTokenizeCode(t *testing.T) {
+	t.Run("Tokens and offsets", func(t *testing.T) {
+		var got []string
+		for _, token := range models.TokenizeCode("x := parseConfig(a-b, max-width)") {
+			got = append(got, fmt.Sprintf("%s@%d-%d:%v", token.Text, token.Start, token.End, token.Part))
+		}
+
+		expected := []string{
+			"x@0-1:false",
+			"parseConfig@5-16:false", "parse@5-10:true", "Config@10-16:true",
+			"a-b@17-20:false", "a@17-18:true", "b@19-20:true",
+			"max-width@22-31:false", "max@22-25:true", "width@26-31:true",
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("Hyphens before digits are not joined", func(t *testing.T) {
+		tokens := models.TokenizeCode("n-1")
+		if len(tokens) != 2 || tokens[0].Text != "n" || tokens[1].Text != "1" {
+			t.Errorf("Unexpected tokens: %+v", tokens)
+		}
+	})
+
+	t.Run("Query keywords", func(t *testing.T) {
+		keywords := models.NewSearchQuery("the parseConfig for user_auth").GetKeywords()
+		expected := []string{"parseconfig", "parse", "config", "user_auth", "user", "auth"}
+		if !reflect.DeepEqual(keywords, expected) {
+			t.Errorf("Expected %v, got %v", expected, keywords)
+		}
+	})
+}
+
+// TestSearchService_IdentifierTokens tests that text search matches terms across identifier styles
+func TestSearchService_IdentifierTokens(t *testing.T) {
+	indexPath := createSearchTestIndex(t, map[string]string{
+		"config.go": "package config\n\nfunc parseConfig() {}\n\nfunc ParseConfigFile() {}\n\nvar parse_config = 1\n\nvar config = parse\n",
+		"auth.go":   "package auth\n\ntype UserAuthenticator struct{}\n",
+	})
+	searchService := newTestSearchService()
+
+	search := func(t *testing.T, text string, wholeWord bool) []string {
+		t.Helper()
+		query := models.NewSearchQuery(text)
+		query.SearchType = models.SearchTypeText
+		query.Threshold = 0
+		query.WholeWord = wholeWord
+
+		results, err := searchService.Search(query, indexPath)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+
+		var found []string
+		for _, result := range results.Results {
+			found = append(found, result.Content)
+		}
+		sort.Strings(found)
+		return found
+	}
+
+	t.Run("Snake case query matches camelCase", func(t *testing.T) {
+		expected := []string{"func ParseConfigFile() {}", "func parseConfig() {}", "var parse_config = 1"}
+		if found := search(t, "parse_config", false); !reflect.DeepEqual(found, expected) {
+			t.Errorf("Expected %v, got %v", expected, found)
+		}
+	})
+
+	t.Run("Whole word sub-words", func(t *testing.T) {
+		expected := []string{"func ParseConfigFile() {}", "func parseConfig() {}", "var parse_config = 1"}
+		if found := search(t, "parseConfig", true); !reflect.DeepEqual(found, expected) {
+			t.Errorf("Expected %v, got %v", expected, found)
+		}
+	})
+
+	t.Run("Separate terms match one identifier", func(t *testing.T) {
+		if found := search(t, "user auth", false); len(found) != 1 || found[0] != "type UserAuthenticator struct{}" {
+			t.Errorf("Expected UserAuthenticator, got %v", found)
+		}
+	})
+
+	t.Run("Sub-word match is highlighted", func(t *testing.T) {
+		query := models.NewSearchQuery("parse-config")
+		query.SearchType = models.SearchTypeText
+		query.Threshold = 0
+
+		results, err := searchService.Search(query, indexPath)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		for _, result := range results.Results {
+			if result.Content == "func ParseConfigFile() {}" {
+				if len(result.Spans) != 1 || result.Spans[0].Text != "ParseConfig" {
+					t.Errorf("Expected ParseConfig to be highlighted, got %+v", result.Spans)
+				}
+				return
+			}
+		}
+		t.Errorf("ParseConfigFile not found in %d results", len(results.Results))
+	})
+}